@@ -0,0 +1,87 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// CheckAccessibilityPolicy is passed into options if HTML pages should be scanned for common accessibility problems,
+// which are recorded as the WarningMissingAlt, WarningMissingLang, and WarningHeadingOrder Warnings of the Page
+type CheckAccessibilityPolicy interface {
+	CheckAccessibility(ctx context.Context, url *url.URL) bool
+}
+
+// CheckAccessibility is a CheckAccessibilityPolicy with the same answer for every page
+type CheckAccessibility bool
+
+// CheckAccessibility satisfies CheckAccessibilityPolicy method
+func (c CheckAccessibility) CheckAccessibility(ctx context.Context, url *url.URL) bool {
+	return bool(c)
+}
+
+func (f *DefaultFactory) checkAccessibility(ctx context.Context, url *url.URL) bool {
+	if f.CheckAccessibilityPolicy != nil {
+		defer policyPanicked("CheckAccessibilityPolicy")
+		return f.CheckAccessibilityPolicy.CheckAccessibility(ctx, url)
+	}
+	return false
+}
+
+// scanAccessibility tokenizes the HTML looking for images without alternative text, a document without a language,
+// and headings which skip levels. It's a rough signal, not a substitute for a real accessibility audit.
+func scanAccessibility(body []byte) []PageWarning {
+	var result []PageWarning
+	var htmlSeen bool
+	var previousHeading int
+
+	z := html.NewTokenizer(bytes.NewReader(body))
+	pos := htmlPosition{line: 1, column: 1}
+	for {
+		tt := z.Next()
+		tokenPos := pos
+		pos.advance(z.Raw())
+
+		switch tt {
+		case html.ErrorToken:
+			if err := z.Err(); err == io.EOF && !htmlSeen {
+				result = append(result, PageWarning{Code: WarningMissingLang, Message: "there's no <html> element to give the page's language"})
+			}
+			return result
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := z.Token()
+			attrs := make(map[string]string, len(token.Attr))
+			for _, attr := range token.Attr {
+				attrs[strings.ToLower(attr.Key)] = attr.Val
+			}
+			_, hasAlt := attrs["alt"]
+
+			switch token.Data {
+			case "html":
+				htmlSeen = true
+				if len(strings.TrimSpace(attrs["lang"])) == 0 && len(strings.TrimSpace(attrs["xml:lang"])) == 0 {
+					result = append(result, tokenPos.warning(WarningMissingLang, "<html> has no lang attribute"))
+				}
+			case "img", "area":
+				if !hasAlt && !strings.EqualFold(attrs["role"], "presentation") && !strings.EqualFold(attrs["aria-hidden"], "true") {
+					result = append(result, tokenPos.warning(WarningMissingAlt, "<%s> of %q has no alt attribute", token.Data, attrs["src"]+attrs["href"]))
+				}
+			case "input":
+				if strings.EqualFold(attrs["type"], "image") && !hasAlt {
+					result = append(result, tokenPos.warning(WarningMissingAlt, "<input type=\"image\"> of %q has no alt attribute", attrs["src"]))
+				}
+			case "h1", "h2", "h3", "h4", "h5", "h6":
+				level := int(token.Data[1] - '0')
+				if previousHeading > 0 && level > previousHeading+1 {
+					result = append(result, tokenPos.warning(WarningHeadingOrder, "<h%d> follows <h%d>, skipping a level", level, previousHeading))
+				}
+				previousHeading = level
+			}
+		}
+	}
+}
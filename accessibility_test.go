@@ -0,0 +1,60 @@
+package resource
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type AccessibilitySuite struct {
+	suite.Suite
+	archive *MemoryResponseArchive
+}
+
+func (suite *AccessibilitySuite) SetupTest() {
+	suite.archive = NewMemoryResponseArchive()
+	suite.archive.Add("http://example.com/", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, `<html>
+<head><title>Example</title></head>
+<body>
+<h1>Title</h1>
+<img src="logo.png">
+<img src="spacer.gif" alt="">
+<img src="hidden.png" aria-hidden="true">
+<h2>Section</h2>
+<h4>Skipped</h4>
+<input type="image" src="go.png">
+</body>
+</html>`))
+}
+
+func (suite *AccessibilitySuite) TestAccessibilityWarnings() {
+	content, err := NewFactory(suite.archive, CheckAccessibility(true)).PageFromURL(context.Background(), "http://example.com/")
+	suite.Nil(err, "Should not get an error")
+	var codes []string
+	var lines []int
+	for _, warning := range content.(*Page).Warnings {
+		codes = append(codes, warning.Code)
+		lines = append(lines, warning.Line)
+	}
+	suite.Equal([]string{WarningMissingLang, WarningMissingAlt, WarningHeadingOrder, WarningMissingAlt}, codes)
+	suite.Equal([]int{1, 5, 9, 10}, lines)
+}
+
+func (suite *AccessibilitySuite) TestOptIn() {
+	content, err := NewFactory(suite.archive).PageFromURL(context.Background(), "http://example.com/")
+	suite.Nil(err, "Should not get an error")
+	suite.Empty(content.(*Page).Warnings, "Accessibility should only be checked if asked for")
+}
+
+func (suite *AccessibilitySuite) TestAccessiblePage() {
+	suite.Empty(scanAccessibility([]byte(`<html lang="en"><body><h2>One</h2><h3>Two</h3><h2>Three</h2><img src="a.png" alt="A"></body></html>`)))
+	warnings := scanAccessibility([]byte(`<p>fragment</p>`))
+	suite.Len(warnings, 1)
+	suite.Equal(WarningMissingLang, warnings[0].Code)
+}
+
+func TestAccessibilitySuite(t *testing.T) {
+	suite.Run(t, new(AccessibilitySuite))
+}
@@ -0,0 +1,129 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// ActivityStreamsMediaType is the media type of ActivityPub documents such as actors
+const ActivityStreamsMediaType = "application/activity+json"
+
+// activityStreamsProfile is the JSON-LD profile which makes application/ld+json an ActivityStreams document
+const activityStreamsProfile = "https://www.w3.org/ns/activitystreams"
+
+// FetchActivityPubActorPolicy is passed into options if we want the ActivityPub actor advertised by a page fetched
+type FetchActivityPubActorPolicy interface {
+	FetchActivityPubActor(context.Context, *url.URL) bool
+}
+
+// ActivityPubActor is the subset of an ActivityPub actor (person, group, service, ...) document useful for enrichment
+type ActivityPubActor struct {
+	ID                string          `json:"id"`
+	Type              string          `json:"type"`
+	PreferredUsername string          `json:"preferredUsername"`
+	Name              string          `json:"name,omitempty"`
+	Summary           string          `json:"summary,omitempty"`
+	Inbox             string          `json:"inbox,omitempty"`
+	Outbox            string          `json:"outbox,omitempty"`
+	Followers         string          `json:"followers,omitempty"`
+	Following         string          `json:"following,omitempty"`
+	ProfileURL        json.RawMessage `json:"url,omitempty"` // a string, a Link object, or an array of either
+}
+
+// Handle returns the fediverse handle of the actor, e.g. @user@mastodon.social
+func (a ActivityPubActor) Handle() (string, bool) {
+	id, err := url.Parse(a.ID)
+	if err != nil || len(a.PreferredUsername) == 0 || len(id.Hostname()) == 0 {
+		return "", false
+	}
+	return "@" + a.PreferredUsername + "@" + id.Hostname(), true
+}
+
+// IsActivityPubActor returns true if this is an rel="alternate" link to an ActivityStreams document
+func (l Link) IsActivityPubActor() bool {
+	if !l.HasRel("alternate") {
+		return false
+	}
+	mediaType, params, err := mime.ParseMediaType(l.Type)
+	if err != nil {
+		return false
+	}
+	return mediaType == ActivityStreamsMediaType || (mediaType == "application/ld+json" && params["profile"] == activityStreamsProfile)
+}
+
+// ActivityPubLinks returns the links to ActivityPub representations of the page or its author
+func (p Page) ActivityPubLinks() []Link {
+	var result []Link
+	for _, link := range p.Links {
+		if link.IsActivityPubActor() {
+			result = append(result, link)
+		}
+	}
+	return result
+}
+
+// FediverseHandles returns the handles from <meta name="fediverse:creator"> tags followed by the fetched actor's handle
+func (p Page) FediverseHandles() []string {
+	var result []string
+	if values, ok := MetaTags(p.MetaPropertyTags).Values("fediverse:creator"); ok {
+		for _, value := range values {
+			if handle, ok := value.(string); ok && len(strings.TrimSpace(handle)) > 0 {
+				result = append(result, strings.TrimSpace(handle))
+			}
+		}
+	}
+	if p.ActivityPubActor != nil {
+		if handle, ok := p.ActivityPubActor.Handle(); ok {
+			result = append(result, handle)
+		}
+	}
+	return result
+}
+
+// ActivityPubActor fetches and decodes the ActivityPub actor document at actorURL
+func (f *DefaultFactory) ActivityPubActor(ctx context.Context, actorURL string) (_ *ActivityPubActor, err error) {
+	defer recoverPolicyPanic(&err)
+	resp, err := f.fetch(ctx, actorURL, http.Header{"Accept": {ActivityStreamsMediaType + `, application/ld+json; profile="` + activityStreamsProfile + `"`}})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := new(ActivityPubActor)
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return nil, xerrors.Errorf("Unable to decode ActivityPub actor %q: %w", actorURL, err)
+	}
+	if len(result.ID) == 0 {
+		return nil, fmt.Errorf("ActivityPub actor %q has no id", actorURL)
+	}
+	return result, nil
+}
+
+func (f *DefaultFactory) fetchActivityPubActor(ctx context.Context, url *url.URL) bool {
+	if f.FetchActivityPubActorPolicy != nil {
+		defer policyPanicked("FetchActivityPubActorPolicy")
+		return f.FetchActivityPubActorPolicy.FetchActivityPubActor(ctx, url)
+	}
+	return false
+}
+
+// discoverActivityPubActor fetches the first advertised actor when the policy asks for it, failures are warnings
+func (f *DefaultFactory) discoverActivityPubActor(ctx context.Context, result *Page) {
+	links := result.ActivityPubLinks()
+	if len(links) == 0 || !f.fetchActivityPubActor(ctx, result.TargetURL) {
+		return
+	}
+	actor, err := f.ActivityPubActor(ctx, links[0].URL.String())
+	if err != nil {
+		result.Warnings = append(result.Warnings, PageWarning{Code: WarningRelatedFetchError, Message: err.Error()})
+		return
+	}
+	result.ActivityPubActor = actor
+}
@@ -0,0 +1,69 @@
+package resource
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type fetchActivityPubActor bool
+
+func (p fetchActivityPubActor) FetchActivityPubActor(context.Context, *url.URL) bool {
+	return bool(p)
+}
+
+type ActivityPubSuite struct {
+	suite.Suite
+	archive *MemoryResponseArchive
+}
+
+func (suite *ActivityPubSuite) SetupTest() {
+	suite.archive = NewMemoryResponseArchive()
+	suite.archive.Add("https://www.netspective.com/about", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, `<html><head>
+		<meta name="fediverse:creator" content="@snshah@mastodon.social">
+		<link rel="alternate" type="application/activity+json" href="https://social.netspective.com/users/netspective">
+	</head></html>`))
+	suite.archive.Add("https://social.netspective.com/users/netspective", archivedResponse(200, http.Header{"Content-Type": {ActivityStreamsMediaType}},
+		`{"@context": "https://www.w3.org/ns/activitystreams", "id": "https://social.netspective.com/users/netspective", "type": "Organization",
+		"preferredUsername": "netspective", "name": "Netspective", "url": ["https://social.netspective.com/@netspective"]}`))
+}
+
+func (suite *ActivityPubSuite) TestActorIsNotFetchedByDefault() {
+	content, err := NewFactory(suite.archive).PageFromURL(context.Background(), "https://www.netspective.com/about")
+	suite.Nil(err, "Should not get an error")
+
+	page := content.(*Page)
+	suite.Len(page.ActivityPubLinks(), 1, "Actor link should be detected")
+	suite.Nil(page.ActivityPubActor, "Actor should not be fetched without a policy")
+	suite.Equal([]string{"@snshah@mastodon.social"}, page.FediverseHandles())
+}
+
+func (suite *ActivityPubSuite) TestActorIsFetched() {
+	content, err := NewFactory(suite.archive, fetchActivityPubActor(true)).PageFromURL(context.Background(), "https://www.netspective.com/about")
+	suite.Nil(err, "Should not get an error")
+
+	page := content.(*Page)
+	suite.NotNil(page.ActivityPubActor, "Actor should be fetched")
+	suite.Equal("Organization", page.ActivityPubActor.Type)
+	suite.Equal([]string{"@snshah@mastodon.social", "@netspective@social.netspective.com"}, page.FediverseHandles())
+}
+
+func (suite *ActivityPubSuite) TestActorFetchFailureIsWarning() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://www.netspective.com/", archivedResponse(200, http.Header{"Content-Type": {"text/html"}},
+		`<html><head><link rel="alternate" type='application/ld+json; profile="https://www.w3.org/ns/activitystreams"' href="/actor"></head></html>`))
+
+	content, err := NewFactory(archive, fetchActivityPubActor(true)).PageFromURL(context.Background(), "https://www.netspective.com/")
+	suite.Nil(err, "Actor failures should not fail the page")
+	page := content.(*Page)
+	suite.Nil(page.ActivityPubActor)
+	suite.Len(page.Warnings, 1)
+	suite.Equal(WarningRelatedFetchError, page.Warnings[0].Code)
+}
+
+func TestActivityPubSuite(t *testing.T) {
+	suite.Run(t, new(ActivityPubSuite))
+}
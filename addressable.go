@@ -0,0 +1,129 @@
+package resource
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+	"golang.org/x/xerrors"
+)
+
+// ContentAddressableAttachmentCreator stores files under the SHA-256 of their content, e.g. ab/cd/abcd....pdf.
+// Downloads of content that already exists are discarded and the existing path is returned instead.
+type ContentAddressableAttachmentCreator struct {
+	FS            afero.Fs
+	BasePath      string
+	FanOutDirs    int  // number of two-character directories to fan the files out into
+	AutoAssignExt bool // if true, the sniffed file type's extension is kept on the content-addressed name
+}
+
+// NewContentAddressableAttachmentCreator creates a creator which stores files in basePath using the ab/cd/abcd... layout
+func NewContentAddressableAttachmentCreator(fs afero.Fs, basePath string) *ContentAddressableAttachmentCreator {
+	return &ContentAddressableAttachmentCreator{
+		FS:            fs,
+		BasePath:      basePath,
+		FanOutDirs:    2,
+		AutoAssignExt: true,
+	}
+}
+
+// CreateFile satisfies FileAttachmentCreator method, the file is created in a staging directory until FinalizeFile is called
+func (c *ContentAddressableAttachmentCreator) CreateFile(ctx context.Context, url *url.URL, t Type) (afero.Fs, afero.File, error) {
+	return c.CreateSniffedFile(ctx, url, t, SniffedType{})
+}
+
+// CreateSniffedFile satisfies SniffedFileAttachmentCreator method, the staged file is given the sniffed extension
+func (c *ContentAddressableAttachmentCreator) CreateSniffedFile(ctx context.Context, url *url.URL, t Type, sniffed SniffedType) (afero.Fs, afero.File, error) {
+	stagingPath := c.stagingPath()
+	if err := c.FS.MkdirAll(stagingPath, 0755); err != nil {
+		return c.FS, nil, xerrors.Errorf("Unable to create staging directory %q in resource.ContentAddressableAttachmentCreator: %w", stagingPath, err)
+	}
+
+	var extension string
+	if len(sniffed.Extension) > 0 {
+		extension = "." + sniffed.Extension
+	}
+	for {
+		suffix := make([]byte, 8)
+		if _, err := rand.Read(suffix); err != nil {
+			return c.FS, nil, err
+		}
+		name := filepath.Join(stagingPath, "download-"+hex.EncodeToString(suffix)+extension)
+		destFile, err := c.FS.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return c.FS, nil, err
+		}
+		return c.FS, destFile, nil
+	}
+}
+
+// AutoAssignExtension satisfies FileAttachmentCreator method
+func (c *ContentAddressableAttachmentCreator) AutoAssignExtension(ctx context.Context, url *url.URL, t Type) bool {
+	return c.AutoAssignExt
+}
+
+// FinalizeFile satisfies FileAttachmentFinalizer method, moving the staged file to its content address
+func (c *ContentAddressableAttachmentCreator) FinalizeFile(ctx context.Context, fs afero.Fs, path string, url *url.URL, t Type) (string, error) {
+	digest, err := c.digest(path)
+	if err != nil {
+		return path, err
+	}
+
+	destPath := c.ContentPath(digest, filepath.Ext(path))
+	if _, err := c.FS.Stat(destPath); err == nil {
+		// we already have this content so the download is a duplicate
+		c.FS.Remove(path)
+		return destPath, nil
+	}
+
+	if err := c.FS.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return path, xerrors.Errorf("Unable to create directory %q in resource.ContentAddressableAttachmentCreator: %w", filepath.Dir(destPath), err)
+	}
+	if err := c.FS.Rename(path, destPath); err != nil {
+		return path, xerrors.Errorf("Unable to move %q to %q in resource.ContentAddressableAttachmentCreator: %w", path, destPath, err)
+	}
+	return destPath, nil
+}
+
+// ContentPath returns where content with the given hex SHA-256 digest and extension is stored
+func (c *ContentAddressableAttachmentCreator) ContentPath(digest string, ext string) string {
+	return filepath.Join(c.BasePath, filepath.FromSlash(fanOutName(digest, c.FanOutDirs))+ext)
+}
+
+// Verify returns true if the content of the file at path still matches the digest in its name
+func (c *ContentAddressableAttachmentCreator) Verify(path string) (bool, error) {
+	digest, err := c.digest(path)
+	if err != nil {
+		return false, err
+	}
+	name := filepath.Base(path)
+	return strings.TrimSuffix(name, filepath.Ext(name)) == digest, nil
+}
+
+func (c *ContentAddressableAttachmentCreator) stagingPath() string {
+	return filepath.Join(c.BasePath, ".staging")
+}
+
+func (c *ContentAddressableAttachmentCreator) digest(path string) (string, error) {
+	file, err := c.FS.OpenFile(path, os.O_RDONLY, 0)
+	if err != nil {
+		return "", xerrors.Errorf("Unable to open %q in resource.ContentAddressableAttachmentCreator: %w", path, err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", xerrors.Errorf("Unable to hash %q in resource.ContentAddressableAttachmentCreator: %w", path, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,65 @@
+package resource
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/suite"
+)
+
+const testPDFContent = "%PDF-1.4\n%test content for lectio/resource\n"
+
+type AddressableSuite struct {
+	suite.Suite
+}
+
+func (suite *AddressableSuite) download(creator FileAttachmentCreator, urlText string) *FileAttachment {
+	ctx := context.Background()
+	u, _ := url.Parse(urlText)
+	t, _ := NewPageType("application/pdf")
+	resp := &http.Response{Body: ioutil.NopCloser(strings.NewReader(testPDFContent))}
+
+	ok, attachment, err := DownloadFileFromHTTPResp(ctx, creator, u, resp, t)
+	suite.Nil(err, "Should not get an error")
+	suite.True(ok, "Download should succeed")
+	return attachment.(*FileAttachment)
+}
+
+func (suite *AddressableSuite) TestDuplicateContentIsStoredOnce() {
+	fs := afero.NewMemMapFs()
+	creator := NewContentAddressableAttachmentCreator(fs, "cas")
+
+	sum := sha256.Sum256([]byte(testPDFContent))
+	digest := hex.EncodeToString(sum[:])
+	expectedPath := "cas/" + digest[0:2] + "/" + digest[2:4] + "/" + digest + ".pdf"
+
+	first := suite.download(creator, "http://ceur-ws.org/Vol-1401/paper-05.pdf")
+	suite.Equal(expectedPath, first.DestPath)
+
+	second := suite.download(creator, "http://mirror.example.com/paper-05.pdf")
+	suite.Equal(expectedPath, second.DestPath, "Duplicate content should return the existing path")
+
+	staged, err := afero.ReadDir(fs, "cas/.staging")
+	suite.Nil(err, "Staging directory should exist")
+	suite.Empty(staged, "Staged duplicates should be removed")
+
+	ok, err := creator.Verify(expectedPath)
+	suite.Nil(err, "Should not get an error")
+	suite.True(ok, "Content should match its address")
+
+	afero.WriteFile(fs, expectedPath, []byte("tampered"), 0644)
+	ok, err = creator.Verify(expectedPath)
+	suite.Nil(err, "Should not get an error")
+	suite.False(ok, "Tampered content should not match its address")
+}
+
+func TestAddressableSuite(t *testing.T) {
+	suite.Run(t, new(AddressableSuite))
+}
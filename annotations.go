@@ -0,0 +1,20 @@
+package resource
+
+// Annotations is passed into PageFromURL's options to attach the caller's own metadata to a URL, such as the ID of
+// the item it was found in, when it was discovered, or tags. They're copied onto the resulting Page (and so its
+// events) untouched, so results can be correlated without a map on the side. Several are merged, later keys winning.
+type Annotations map[string]interface{}
+
+// annotationsFrom merges the Annotations in options, nil if there are none
+func annotationsFrom(options []interface{}) Annotations {
+	var result Annotations
+	for _, annotations := range OptionsOf[Annotations](options) {
+		if result == nil {
+			result = make(Annotations, len(annotations))
+		}
+		for key, value := range annotations {
+			result[key] = value
+		}
+	}
+	return result
+}
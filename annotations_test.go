@@ -0,0 +1,55 @@
+package resource
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type AnnotationsSuite struct {
+	suite.Suite
+}
+
+func (suite *AnnotationsSuite) TestPassthrough() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("http://example.com/", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, testHTMLPage))
+	events := make(ChannelEventSink, 1)
+	discovered := time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	content, err := NewFactory(archive, events).PageFromURL(context.Background(), "http://example.com/",
+		Annotations{"sourceItemID": "item-7", "tags": []string{"a"}},
+		Annotations{"discoveredAt": discovered, "tags": []string{"b"}})
+	suite.Nil(err, "Should not get an error")
+	suite.Equal(Annotations{"sourceItemID": "item-7", "discoveredAt": discovered, "tags": []string{"b"}}, content.(*Page).Annotations)
+	suite.Equal("item-7", (<-events).Page.Annotations["sourceItemID"], "Events should carry the annotations")
+}
+
+func (suite *AnnotationsSuite) TestWorkerPassthrough() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("http://example.com/", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, testHTMLPage))
+	items := []QueuedURL{{URL: "http://example.com/", Annotations: Annotations{"sourceItemID": "item-7"}}}
+	queue := URLQueueFuncs{ReceiveFunc: func(ctx context.Context) (QueuedURL, bool, error) {
+		if len(items) == 0 {
+			return QueuedURL{}, false, nil
+		}
+		item := items[0]
+		items = items[1:]
+		return item, true, nil
+	}}
+
+	var annotations Annotations
+	worker := NewWorker(NewFactory(archive), queue)
+	worker.OnResult = func(item QueuedURL, content Content, err error) {
+		annotations = content.(*Page).Annotations
+	}
+	_, err := worker.Run(context.Background())
+	suite.Nil(err, "Should not get an error")
+	suite.Equal("item-7", annotations["sourceItemID"])
+}
+
+func TestAnnotationsSuite(t *testing.T) {
+	suite.Run(t, new(AnnotationsSuite))
+}
@@ -0,0 +1,78 @@
+package resource
+
+import (
+	"context"
+	"io"
+	"net/url"
+)
+
+// RetainBodyPolicy is passed into options if the HTML of pages should be kept in Page.Body after it's parsed
+type RetainBodyPolicy interface {
+	RetainBody(context.Context, *url.URL) bool
+}
+
+// RetainBody is a RetainBodyPolicy with the same answer for every page
+type RetainBody bool
+
+// RetainBody satisfies RetainBodyPolicy method
+func (r RetainBody) RetainBody(context.Context, *url.URL) bool {
+	return bool(r)
+}
+
+// StopOnDownloadErrors is a ContentDownloaderErrorPolicy with the same answer for every error
+type StopOnDownloadErrors bool
+
+// StopOnDownloadError satisfies ContentDownloaderErrorPolicy method
+func (s StopOnDownloadErrors) StopOnDownloadError(context.Context, *url.URL, Type, error) bool {
+	return bool(s)
+}
+
+// ChecksumRecorder is a DownloadSinkChain which computes digests, with the algorithms accepted by NewHashSink, of
+// every download and records them in FileAttachment.Checksums
+type ChecksumRecorder []string
+
+// DownloadSinks satisfies DownloadSinkChain method
+func (c ChecksumRecorder) DownloadSinks(ctx context.Context, url *url.URL, t Type) ([]DownloadSink, error) {
+	var result []DownloadSink
+	for _, algorithm := range c {
+		hashSink, ok := NewHashSink(algorithm)
+		if !ok {
+			continue
+		}
+		result = append(result, WriterSink{Writer: hashSink, OnFinish: func(ctx context.Context, attachment Attachment, err error) error {
+			hashSink.FinishDownload(ctx, attachment, err)
+			if fileAttachment, ok := attachment.(*FileAttachment); ok && err == nil && !fileAttachment.Preview {
+				if fileAttachment.Checksums == nil {
+					fileAttachment.Checksums = make(map[string]string)
+				}
+				fileAttachment.Checksums[hashSink.Algorithm] = hashSink.HexSum()
+			}
+			return nil
+		}})
+	}
+	return result, nil
+}
+
+// ArchivalProfile returns the options of a factory meant for archiving: HTML is retained in Page.Body, every
+// response is recorded into a WARC written to warc (if it's not nil), downloads are stored by creator with their
+// SHA-256 and SHA-1 digests recorded, and download errors fail the page instead of being ignored.
+// Capturing the assets a page references isn't available yet.
+func ArchivalProfile(creator FileAttachmentCreator, warc io.Writer) []interface{} {
+	result := []interface{}{
+		RetainBody(true),
+		StopOnDownloadErrors(true),
+		ChecksumRecorder{"sha-256", "sha-1"},
+	}
+	if creator != nil {
+		result = append(result, creator)
+	}
+	if warc != nil {
+		result = append(result, NewWARCWriter(warc))
+	}
+	return result
+}
+
+// NewArchivalFactory creates a factory with the ArchivalProfile, options override the profile's choices
+func NewArchivalFactory(creator FileAttachmentCreator, warc io.Writer, options ...interface{}) *DefaultFactory {
+	return NewFactory(append(ArchivalProfile(creator, warc), options...)...)
+}
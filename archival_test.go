@@ -0,0 +1,74 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ArchivalSuite struct {
+	suite.Suite
+}
+
+func (suite *ArchivalSuite) TestArchivalFactory() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/paper.pdf" {
+			w.Header().Set("Content-Type", "application/pdf")
+			io.WriteString(w, testPDFContent)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, testHTMLPage)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	var warc bytes.Buffer
+	factory := NewArchivalFactory(NewMemoryAttachmentCreator(nil), &warc)
+
+	content, err := factory.PageFromURL(ctx, server.URL+"/")
+	suite.Nil(err, "Should not get an error")
+	suite.Equal(testHTMLPage, string(content.(*Page).Body), "HTML should be retained")
+
+	content, err = factory.PageFromURL(ctx, server.URL+"/paper.pdf")
+	suite.Nil(err, "Should not get an error")
+	checksums := content.Attachment().(*FileAttachment).Checksums
+	suite.Len(checksums["sha-256"], 64, "SHA-256 should be recorded")
+	suite.Len(checksums["sha-1"], 40, "SHA-1 should be recorded")
+
+	// the recorded WARC can be replayed offline
+	archive := NewMemoryResponseArchive()
+	count, err := ImportWARC(archive, &warc)
+	suite.Nil(err, "Should not get an error")
+	suite.Equal(2, count)
+
+	content, err = NewFactory(archive).PageFromURL(ctx, server.URL+"/")
+	suite.Nil(err, "Should not get an error")
+	value, _, _ := content.MetaTag("og:site_name")
+	suite.Equal("Netspective", value)
+}
+
+func (suite *ArchivalSuite) TestTruncatedResponseIsMarked() {
+	var warc bytes.Buffer
+	writer := NewWARCWriter(&warc)
+	resp := archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, testHTMLPage)
+	resp.Status = "200 OK"
+	resp.Request, _ = http.NewRequest(http.MethodGet, "https://www.netspective.com/", nil)
+	suite.Nil(writer.RecordResponse(context.Background(), resp, []byte(testHTMLPage[:10]), false))
+
+	reader, _ := NewWARCReader(&warc)
+	record, err := reader.Next()
+	suite.Nil(err, "Should not get an error")
+	suite.Equal("unspecified", record.Header.Get("WARC-Truncated"))
+	suite.Equal("https://www.netspective.com/", record.TargetURI())
+}
+
+func TestArchivalSuite(t *testing.T) {
+	suite.Run(t, new(ArchivalSuite))
+}
@@ -0,0 +1,246 @@
+package resource
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/publicsuffix"
+)
+
+// The kinds of Asset a page references
+const (
+	AssetScript     = "script"
+	AssetStylesheet = "stylesheet"
+	AssetImage      = "image"
+	AssetFont       = "font"
+	AssetMedia      = "media"
+	AssetFrame      = "frame"
+	AssetOther      = "other"
+)
+
+// DefaultMaxAssetRequests is the most HEAD requests made to measure the assets of a page, any others are unmeasured
+var DefaultMaxAssetRequests = 100
+
+// assetRequestConcurrency is how many assets are measured at the same time
+const assetRequestConcurrency = 4
+
+// ScanAssetsPolicy is passed into options if the scripts, stylesheets, images and other assets an HTML page references
+// should be listed and measured, with HEAD requests, to estimate the page's Weight
+type ScanAssetsPolicy interface {
+	ScanAssets(ctx context.Context, url *url.URL) bool
+}
+
+// ScanAssets is a ScanAssetsPolicy with the same answer for every page
+type ScanAssets bool
+
+// ScanAssets satisfies ScanAssetsPolicy method
+func (s ScanAssets) ScanAssets(ctx context.Context, url *url.URL) bool {
+	return bool(s)
+}
+
+// Asset is a resource an HTML page references and which a browser would load with it
+type Asset struct {
+	URL        *url.URL `json:"url"`
+	Kind       string   `json:"kind"`
+	Size       int64    `json:"size"`       // from Content-Length, -1 if it's unknown
+	ThirdParty bool     `json:"thirdParty"` // true if it's not from the page's own registrable domain
+}
+
+// PageWeight estimates what loading a page costs, assets which couldn't be measured don't add to AssetBytes
+type PageWeight struct {
+	HTMLBytes         int64          `json:"htmlBytes"`
+	AssetBytes        int64          `json:"assetBytes"`
+	TotalBytes        int64          `json:"totalBytes"`
+	UnmeasuredAssets  int            `json:"unmeasuredAssets,omitempty"`
+	ResourceCounts    map[string]int `json:"resourceCounts"` // keyed by the Asset* kinds
+	ThirdPartyDomains []string       `json:"thirdPartyDomains,omitempty"`
+}
+
+type fetchMethodKey struct{}
+
+// withFetchMethod makes fetches with ctx use method rather than GET
+func withFetchMethod(ctx context.Context, method string) context.Context {
+	return context.WithValue(ctx, fetchMethodKey{}, method)
+}
+
+func fetchMethod(ctx context.Context) string {
+	if method, ok := ctx.Value(fetchMethodKey{}).(string); ok {
+		return method
+	}
+	return http.MethodGet
+}
+
+func (f *DefaultFactory) scanAssets(ctx context.Context, url *url.URL) bool {
+	if f.ScanAssetsPolicy != nil {
+		defer policyPanicked("ScanAssetsPolicy")
+		return f.ScanAssetsPolicy.ScanAssets(ctx, url)
+	}
+	return false
+}
+
+// assetFromHTMLNode returns the asset an element loads, data: and javascript: URLs aren't assets
+func assetFromHTMLNode(base *url.URL, n *html.Node) (Asset, bool) {
+	attrs := make(map[string]string, len(n.Attr))
+	for _, attr := range n.Attr {
+		attrs[strings.ToLower(attr.Key)] = attr.Val
+	}
+
+	var kind, ref string
+	switch strings.ToLower(n.Data) {
+	case "script":
+		kind, ref = AssetScript, attrs["src"]
+	case "link":
+		ref = attrs["href"]
+		rels := strings.Fields(strings.ToLower(attrs["rel"]))
+		for _, rel := range rels {
+			switch rel {
+			case "stylesheet":
+				kind = AssetStylesheet
+			case "icon", "apple-touch-icon":
+				kind = AssetImage
+			case "preload":
+				kind = map[string]string{"script": AssetScript, "style": AssetStylesheet, "image": AssetImage, "font": AssetFont,
+					"audio": AssetMedia, "video": AssetMedia}[strings.ToLower(attrs["as"])]
+			}
+		}
+	case "img", "input":
+		if n.Data == "img" || strings.EqualFold(attrs["type"], "image") {
+			kind, ref = AssetImage, attrs["src"]
+		}
+	case "video", "audio", "source", "track":
+		kind, ref = AssetMedia, attrs["src"]
+	case "iframe", "frame":
+		kind, ref = AssetFrame, attrs["src"]
+	case "embed":
+		kind, ref = AssetOther, attrs["src"]
+	case "object":
+		kind, ref = AssetOther, attrs["data"]
+	}
+	ref = strings.TrimSpace(ref)
+	if len(kind) == 0 || len(ref) == 0 {
+		return Asset{}, false
+	}
+	assetURL, err := base.Parse(ref)
+	if err != nil || (assetURL.Scheme != "http" && assetURL.Scheme != "https") {
+		return Asset{}, false
+	}
+	return Asset{URL: assetURL, Kind: kind, Size: -1, ThirdParty: isThirdParty(base, assetURL)}, true
+}
+
+// videoPosterAsset returns the image shown before a <video> plays
+func videoPosterAsset(base *url.URL, n *html.Node) (Asset, bool) {
+	if !strings.EqualFold(n.Data, "video") {
+		return Asset{}, false
+	}
+	for _, attr := range n.Attr {
+		if strings.EqualFold(attr.Key, "poster") && len(strings.TrimSpace(attr.Val)) > 0 {
+			if posterURL, err := base.Parse(strings.TrimSpace(attr.Val)); err == nil {
+				return Asset{URL: posterURL, Kind: AssetImage, Size: -1, ThirdParty: isThirdParty(base, posterURL)}, true
+			}
+		}
+	}
+	return Asset{}, false
+}
+
+// registrableDomain returns the public suffix plus one label of u's host, or the host itself if there's none
+func registrableDomain(u *url.URL) string {
+	host := strings.ToLower(u.Hostname())
+	if domain, err := publicsuffix.EffectiveTLDPlusOne(host); err == nil {
+		return domain
+	}
+	return host
+}
+
+func isThirdParty(page *url.URL, asset *url.URL) bool {
+	return registrableDomain(page) != registrableDomain(asset)
+}
+
+// addAsset records an asset, each URL only once
+func (p *Page) addAsset(asset Asset) {
+	for _, existing := range p.Assets {
+		if existing.URL.String() == asset.URL.String() {
+			return
+		}
+	}
+	p.Assets = append(p.Assets, asset)
+}
+
+// measureAssets sends a HEAD request for each of the page's assets, up to DefaultMaxAssetRequests, and totals the
+// page's Weight. Assets which fail or don't declare a Content-Length are left unmeasured.
+func (f *DefaultFactory) measureAssets(ctx context.Context, result *Page) {
+	if result.Weight == nil {
+		return
+	}
+	headCtx := withFetchMethod(ctx, http.MethodHead)
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < assetRequestConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				result.Assets[index].Size = f.assetSize(headCtx, result.Assets[index].URL)
+			}
+		}()
+	}
+	for index := range result.Assets {
+		if index >= DefaultMaxAssetRequests {
+			break
+		}
+		indexes <- index
+	}
+	close(indexes)
+	wg.Wait()
+
+	weight := result.Weight
+	weight.ResourceCounts = make(map[string]int)
+	thirdParty := make(map[string]bool)
+	for _, asset := range result.Assets {
+		weight.ResourceCounts[asset.Kind]++
+		if asset.Size >= 0 {
+			weight.AssetBytes += asset.Size
+		} else {
+			weight.UnmeasuredAssets++
+		}
+		if asset.ThirdParty {
+			thirdParty[registrableDomain(asset.URL)] = true
+		}
+	}
+	for domain := range thirdParty {
+		weight.ThirdPartyDomains = append(weight.ThirdPartyDomains, domain)
+	}
+	sort.Strings(weight.ThirdPartyDomains)
+	weight.TotalBytes = weight.HTMLBytes + weight.AssetBytes
+}
+
+// assetSize returns the Content-Length of the asset, -1 if it's unknown or measuring it failed
+func (f *DefaultFactory) assetSize(ctx context.Context, assetURL *url.URL) int64 {
+	size, err := f.measureAsset(ctx, assetURL)
+	if err != nil {
+		return -1
+	}
+	return size
+}
+
+// measureAsset returns the Content-Length of the asset. It's called from measureAssets' own goroutines, where a
+// policy's panic can't reach the caller's recoverPolicyPanic, so it's recovered here and fails just this asset.
+func (f *DefaultFactory) measureAsset(ctx context.Context, assetURL *url.URL) (size int64, err error) {
+	defer recoverPolicyPanic(&err)
+	resp, err := f.fetch(ctx, assetURL.String(), nil)
+	if err != nil {
+		return -1, err
+	}
+	defer resp.Body.Close()
+	if resp.ContentLength >= 0 || f.ResponseArchive == nil {
+		return resp.ContentLength, nil
+	}
+	// archived responses are whole so, unlike a HEAD response, their body can be measured
+	return io.Copy(ioutil.Discard, resp.Body)
+}
@@ -0,0 +1,122 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type AssetsSuite struct {
+	suite.Suite
+}
+
+const testAssetsHTML = `<html><head>
+	<link rel="stylesheet" href="/static/site.css">
+	<link rel="preload" href="/static/font.woff2" as="font">
+	<script src="https://cdn.example.net/lib.js"></script>
+	<script src="https://www.googletagmanager.com/gtag/js"></script>
+	<script>var inline = true;</script>
+</head><body>
+	<img src="/static/logo.png"><img src="/static/logo.png"><img src="data:image/gif;base64,R0lGODlhAQABAAAAACw=">
+	<video src="https://media.example.com/clip.mp4" poster="/static/poster.jpg"></video>
+</body></html>`
+
+func (suite *AssetsSuite) TestPageWeight() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://www.example.com/", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, testAssetsHTML))
+	archive.Add("https://www.example.com/static/site.css", archivedResponse(200, http.Header{"Content-Type": {"text/css"}, "Content-Length": {"1000"}}, strings.Repeat("a", 1000)))
+	archive.Add("https://www.example.com/static/font.woff2", archivedResponse(200, http.Header{"Content-Type": {"font/woff2"}}, strings.Repeat("f", 300)))
+	archive.Add("https://cdn.example.net/lib.js", archivedResponse(200, http.Header{"Content-Type": {"application/javascript"}}, strings.Repeat("j", 500)))
+	archive.Add("https://www.example.com/static/logo.png", archivedResponse(200, http.Header{"Content-Type": {"image/png"}}, strings.Repeat("p", 200)))
+	archive.Add("https://media.example.com/clip.mp4", archivedResponse(200, http.Header{"Content-Type": {"video/mp4"}}, strings.Repeat("v", 2000)))
+
+	content, err := NewFactory(archive, ScanAssets(true)).PageFromURL(context.Background(), "https://www.example.com/")
+	suite.Nil(err, "Should not get an error")
+	page := content.(*Page)
+	suite.Len(page.Assets, 7, "Inline scripts, data: URLs and repeats aren't assets")
+	weight := page.Weight
+	suite.Require().NotNil(weight)
+	suite.Equal(int64(len(testAssetsHTML)), weight.HTMLBytes)
+	suite.Equal(int64(1000+300+500+200+2000), weight.AssetBytes)
+	suite.Equal(weight.HTMLBytes+weight.AssetBytes, weight.TotalBytes)
+	suite.Equal(2, weight.UnmeasuredAssets, "The tag manager and poster aren't archived")
+	suite.Equal(map[string]int{AssetStylesheet: 1, AssetFont: 1, AssetScript: 2, AssetImage: 2, AssetMedia: 1}, weight.ResourceCounts)
+	suite.Equal([]string{"example.net", "googletagmanager.com"}, weight.ThirdPartyDomains, "Subdomains of the page's domain aren't third parties")
+}
+
+type panickyPNGAudit struct{}
+
+func (panickyPNGAudit) Audit(ctx context.Context, record AuditRecord) error {
+	if strings.HasSuffix(record.URL, ".png") {
+		panic("audit bug")
+	}
+	return nil
+}
+
+func (suite *AssetsSuite) TestPolicyPanicLeavesAssetUnmeasured() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://www.example.com/", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, testAssetsHTML))
+	archive.Add("https://www.example.com/static/site.css", archivedResponse(200, http.Header{"Content-Type": {"text/css"}}, strings.Repeat("a", 1000)))
+	archive.Add("https://www.example.com/static/logo.png", archivedResponse(200, http.Header{"Content-Type": {"image/png"}}, strings.Repeat("p", 200)))
+
+	content, err := NewFactory(archive, ScanAssets(true), panickyPNGAudit{}).PageFromURL(context.Background(), "https://www.example.com/")
+	suite.Nil(err, "A policy panicking while measuring an asset shouldn't fail the page")
+	page := content.(*Page)
+	for _, asset := range page.Assets {
+		switch asset.URL.Path {
+		case "/static/site.css":
+			suite.Equal(int64(1000), asset.Size)
+		case "/static/logo.png":
+			suite.Equal(int64(-1), asset.Size, "The asset whose audit panicked should be unmeasured")
+		}
+	}
+	suite.Equal(int64(1000), page.Weight.AssetBytes)
+}
+
+func (suite *AssetsSuite) TestHeadRequests() {
+	var mutex sync.Mutex
+	methods := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		methods[r.URL.Path] = r.Method
+		mutex.Unlock()
+		switch r.URL.Path {
+		case "/":
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, `<html><head><script src="/app.js"></script></head><body><img src="/missing.png"></body></html>`)
+		case "/app.js":
+			w.Header().Set("Content-Length", "12345")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	content, err := NewFactory(ScanAssets(true)).PageFromURL(context.Background(), server.URL)
+	suite.Nil(err, "Should not get an error")
+	page := content.(*Page)
+	suite.Equal(http.MethodHead, methods["/app.js"], "Assets should be measured without downloading them")
+	suite.Equal(http.MethodGet, methods["/"])
+	suite.Equal(int64(12345), page.Weight.AssetBytes)
+	suite.Equal(1, page.Weight.UnmeasuredAssets)
+	suite.Empty(page.Weight.ThirdPartyDomains)
+}
+
+func (suite *AssetsSuite) TestOptIn() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://www.example.com/", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, testAssetsHTML))
+	content, err := NewFactory(archive).PageFromURL(context.Background(), "https://www.example.com/")
+	suite.Nil(err, "Should not get an error")
+	suite.Nil(content.(*Page).Assets)
+	suite.Nil(content.(*Page).Weight)
+}
+
+func TestAssetsSuite(t *testing.T) {
+	suite.Run(t, new(AssetsSuite))
+}
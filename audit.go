@@ -0,0 +1,78 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// Sources of an AuditRecord
+const (
+	AuditSourceNetwork = "network"
+	AuditSourceArchive = "archive"
+)
+
+// AuditRecord describes one fetch, for deployments that must be able to prove what was downloaded and why
+type AuditRecord struct {
+	Time        time.Time     `json:"time"`
+	Requester   string        `json:"requester,omitempty"` // from ContextWithRequester
+	URL         string        `json:"url"`
+	FinalURL    string        `json:"finalURL,omitempty"` // after redirects
+	Source      string        `json:"source"`             // AuditSourceNetwork or AuditSourceArchive
+	Status      int           `json:"status,omitempty"`
+	ContentType string        `json:"contentType,omitempty"`
+	Bytes       int64         `json:"bytes"` // of the body, as read by the factory
+	Duration    time.Duration `json:"duration"`
+	Policies    []string      `json:"policies,omitempty"` // names of the PolicyBundles applied
+	Error       string        `json:"error,omitempty"`
+	RequestIdentity
+}
+
+// AuditSink is passed into options to receive an AuditRecord for every fetch, once its body has been consumed.
+// Auditing is best effort from the factory's point of view, a sink which must not lose records should buffer them.
+type AuditSink interface {
+	Audit(context.Context, AuditRecord) error
+}
+
+// JSONLinesAuditSink is an AuditSink writing one JSON object per line, safe for concurrent use
+type JSONLinesAuditSink struct {
+	mu *sync.Mutex
+	w  io.Writer
+}
+
+// NewJSONLinesAuditSink creates an AuditSink which writes JSON lines to w
+func NewJSONLinesAuditSink(w io.Writer) *JSONLinesAuditSink {
+	return &JSONLinesAuditSink{mu: new(sync.Mutex), w: w}
+}
+
+// Audit satisfies AuditSink method
+func (s *JSONLinesAuditSink) Audit(ctx context.Context, record AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.w.Write(append(line, '\n')); err != nil {
+		return xerrors.Errorf("Unable to write audit record for %q: %w", record.URL, err)
+	}
+	return nil
+}
+
+type requesterContextKey struct{}
+
+// ContextWithRequester returns a context which attributes the fetches made with it to requester (a user, service,
+// or job) in AuditRecords
+func ContextWithRequester(ctx context.Context, requester string) context.Context {
+	return context.WithValue(ctx, requesterContextKey{}, requester)
+}
+
+// RequesterFromContext returns the requester set by ContextWithRequester
+func RequesterFromContext(ctx context.Context) string {
+	requester, _ := ctx.Value(requesterContextKey{}).(string)
+	return requester
+}
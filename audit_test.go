@@ -0,0 +1,71 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type AuditSuite struct {
+	suite.Suite
+}
+
+type memoryAuditSink struct {
+	records []AuditRecord
+}
+
+func (s *memoryAuditSink) Audit(ctx context.Context, record AuditRecord) error {
+	s.records = append(s.records, record)
+	return nil
+}
+
+func (suite *AuditSuite) TestAuditRecords() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("http://example.com/page", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, testHTMLPage))
+	archive.Add("http://example.com/missing", archivedResponse(404, http.Header{"Content-Type": {"text/html"}}, ""))
+
+	sink := new(memoryAuditSink)
+	factory := NewFactory(archive, sink, NewPolicyBundle("strict", HTMLHeadOnly(false)))
+	ctx := ContextWithRequester(context.Background(), "job-42")
+
+	_, err := factory.PageFromURL(ctx, "http://example.com/page")
+	suite.Nil(err, "Should not get an error")
+	_, err = factory.PageFromURL(ctx, "http://example.com/missing")
+	suite.NotNil(err, "Should get an error")
+
+	suite.Len(sink.records, 2)
+	record := sink.records[0]
+	suite.Equal("job-42", record.Requester)
+	suite.Equal("http://example.com/page", record.URL)
+	suite.Equal(AuditSourceArchive, record.Source)
+	suite.Equal(200, record.Status)
+	suite.Equal(int64(len(testHTMLPage)), record.Bytes)
+	suite.Equal([]string{"strict"}, record.Policies)
+	suite.Empty(record.Error)
+
+	record = sink.records[1]
+	suite.Equal(404, record.Status)
+	suite.NotEmpty(record.Error)
+}
+
+func (suite *AuditSuite) TestJSONLinesAuditSink() {
+	var buf bytes.Buffer
+	sink := NewJSONLinesAuditSink(&buf)
+	suite.Nil(sink.Audit(context.Background(), AuditRecord{URL: "http://example.com/a", Status: 200}))
+	suite.Nil(sink.Audit(context.Background(), AuditRecord{URL: "http://example.com/b", Status: 404}))
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	suite.Len(lines, 2)
+	var record AuditRecord
+	suite.Nil(json.Unmarshal(lines[1], &record))
+	suite.Equal("http://example.com/b", record.URL)
+	suite.Equal(404, record.Status)
+}
+
+func TestAuditSuite(t *testing.T) {
+	suite.Run(t, new(AuditSuite))
+}
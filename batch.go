@@ -0,0 +1,178 @@
+package resource
+
+import (
+	"context"
+	"sync"
+)
+
+// BatchConcurrency is passed into PagesFromURLs options to resolve that many URLs at the same time, 4 if not set
+type BatchConcurrency int
+
+// BatchResult is the outcome of one or more URLs of a batch, SourceURLs are all the submitted URLs which were merged
+// into it (in input order) and URL is the first of them
+type BatchResult struct {
+	URL        string          `json:"url"`
+	SourceURLs []string        `json:"sourceURLs"`
+	Content    Content         `json:"content,omitempty"`
+	Err        error           `json:"-"`
+	Issues     *IssueCollector `json:"issues,omitempty"` // the page's warnings and Err, including those of merged duplicates
+}
+
+// CollectIssues gathers the issues of every result of a batch
+func CollectIssues(results []BatchResult) *IssueCollector {
+	result := NewIssueCollector()
+	for _, batchResult := range results {
+		if batchResult.Issues != nil {
+			result.Merge(batchResult.Issues)
+		}
+	}
+	return result
+}
+
+// DuplicateResolutionPolicy is passed into options to merge the results of batch URLs which lead to the same content,
+// such as tracking variants of one article. Pages with the same non-empty key are merged into the first one.
+type DuplicateResolutionPolicy interface {
+	DuplicateKey(ctx context.Context, page *Page) string
+}
+
+// MergeByTargetURL is a DuplicateResolutionPolicy which merges URLs that resolve (e.g. through redirects) to the same
+// URL
+type MergeByTargetURL struct{}
+
+// DuplicateKey satisfies DuplicateResolutionPolicy method
+func (MergeByTargetURL) DuplicateKey(ctx context.Context, page *Page) string {
+	if page.TargetURL == nil {
+		return ""
+	}
+	return page.TargetURL.String()
+}
+
+// MergeByCanonical is a DuplicateResolutionPolicy which merges URLs whose pages declare the same canonical URL, or
+// failing that resolve to the same URL
+type MergeByCanonical struct{}
+
+// DuplicateKey satisfies DuplicateResolutionPolicy method
+func (MergeByCanonical) DuplicateKey(ctx context.Context, page *Page) string {
+	if canonical, ok := page.Canonical(); ok && canonical != nil {
+		return canonical.String()
+	}
+	return MergeByTargetURL{}.DuplicateKey(ctx, page)
+}
+
+// PagesFromURLs resolves a batch of URLs, with the options applying to every one of them. Without a
+// DuplicateResolutionPolicy there's a result for each URL in input order, with one the URLs which lead to the same
+// content are merged and a URL submitted more than once is only fetched once. Failures are never merged.
+func (f *DefaultFactory) PagesFromURLs(ctx context.Context, urls []string, options ...interface{}) []BatchResult {
+	options = flattenOptions(options)
+	concurrency := 4
+	policy := f.DuplicateResolutionPolicy
+	var pageOptions []interface{}
+	for _, option := range options {
+		if instance, ok := option.(BatchConcurrency); ok && instance > 0 {
+			concurrency = int(instance)
+		}
+		if instance, ok := option.(DuplicateResolutionPolicy); ok {
+			policy = instance
+		}
+		if !isKnownOption(option, batchOptions) {
+			pageOptions = append(pageOptions, option)
+		}
+	}
+
+	// identical URLs are only fetched once when duplicates are being merged
+	unique := urls
+	if policy != nil {
+		unique = nil
+		seen := make(map[string]bool, len(urls))
+		for _, urlText := range urls {
+			if !seen[urlText] {
+				seen[urlText] = true
+				unique = append(unique, urlText)
+			}
+		}
+	}
+
+	results := make([]BatchResult, len(unique))
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range work {
+				content, err := f.PageFromURL(ctx, unique[index], pageOptions...)
+				issues := NewIssueCollector()
+				if page, ok := content.(*Page); ok && page != nil {
+					issues.Merge(page)
+				}
+				issues.AddError(unique[index], err)
+				results[index] = BatchResult{URL: unique[index], SourceURLs: []string{unique[index]}, Content: content, Err: err, Issues: issues}
+			}
+		}()
+	}
+	for index := range unique {
+		work <- index
+	}
+	close(work)
+	wg.Wait()
+
+	// files left by downloads which never finished are orphans once the batch is over
+	if manager, _ := OptionOf[*CleanupManager](f.downloadOptions(options)); manager != nil {
+		manager.Cleanup()
+	}
+
+	if policy == nil {
+		return results
+	}
+	return mergeDuplicates(ctx, policy, urls, results)
+}
+
+// mergeDuplicates merges results with the same DuplicateKey, and adds the URLs submitted more than once
+func mergeDuplicates(ctx context.Context, policy DuplicateResolutionPolicy, urls []string, results []BatchResult) []BatchResult {
+	byURL := make(map[string]int, len(results))
+	byKey := make(map[string]int)
+	var merged []BatchResult
+	for _, result := range results {
+		page, ok := result.Content.(*Page)
+		key := ""
+		if result.Err == nil && ok {
+			var err error
+			if key, err = duplicateKey(ctx, policy, page); err != nil {
+				result.Err = err
+				if result.Issues != nil {
+					result.Issues.AddError(result.URL, err)
+				}
+			}
+		}
+		if index, ok := byKey[key]; ok && len(key) > 0 {
+			merged[index].SourceURLs = append(merged[index].SourceURLs, result.URL)
+			if merged[index].Issues != nil && result.Issues != nil {
+				merged[index].Issues.Merge(result.Issues)
+			}
+			byURL[result.URL] = index
+			continue
+		}
+		if len(key) > 0 {
+			byKey[key] = len(merged)
+		}
+		byURL[result.URL] = len(merged)
+		merged = append(merged, result)
+	}
+
+	// the repeats of a URL were dropped before fetching
+	counted := make(map[string]bool, len(urls))
+	for _, urlText := range urls {
+		if counted[urlText] {
+			index := byURL[urlText]
+			merged[index].SourceURLs = append(merged[index].SourceURLs, urlText)
+		}
+		counted[urlText] = true
+	}
+	return merged
+}
+
+// duplicateKey returns the policy's key for page, or a PolicyPanicError if the policy panics
+func duplicateKey(ctx context.Context, policy DuplicateResolutionPolicy, page *Page) (_ string, err error) {
+	defer recoverPolicyPanic(&err)
+	return callPolicy("DuplicateResolutionPolicy", func() string { return policy.DuplicateKey(ctx, page) }), nil
+}
@@ -0,0 +1,73 @@
+package resource
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type BatchSuite struct {
+	suite.Suite
+	archive *MemoryResponseArchive
+}
+
+func (suite *BatchSuite) SetupTest() {
+	html := func(canonical string) string {
+		return `<html><head><link rel="canonical" href="` + canonical + `"></head></html>`
+	}
+	suite.archive = NewMemoryResponseArchive()
+	suite.archive.Add("http://example.com/article", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, html("http://example.com/article")))
+	suite.archive.Add("http://example.com/article?utm_source=feed", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, html("http://example.com/article")))
+	suite.archive.Add("http://short.example/a", archivedResponse(301, http.Header{"Location": {"http://example.com/article"}}, ""))
+	suite.archive.Add("http://example.com/other", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, html("http://example.com/other")))
+}
+
+func (suite *BatchSuite) urls() []string {
+	return []string{
+		"http://example.com/article",
+		"http://example.com/missing",
+		"http://short.example/a",
+		"http://example.com/other",
+		"http://example.com/article?utm_source=feed",
+		"http://example.com/missing",
+		"http://example.com/article",
+	}
+}
+
+func (suite *BatchSuite) TestWithoutMerging() {
+	results := NewFactory(suite.archive).PagesFromURLs(context.Background(), suite.urls())
+	suite.Len(results, 7)
+	suite.NotNil(results[1].Err)
+	suite.Equal("http://example.com/other", results[3].URL)
+}
+
+func (suite *BatchSuite) TestMergeByTargetURL() {
+	results := NewFactory(suite.archive).PagesFromURLs(context.Background(), suite.urls(), MergeByTargetURL{}, BatchConcurrency(2))
+	suite.Len(results, 4)
+	suite.Equal([]string{"http://example.com/article", "http://short.example/a", "http://example.com/article"}, results[0].SourceURLs)
+	suite.Equal([]string{"http://example.com/missing", "http://example.com/missing"}, results[1].SourceURLs)
+	suite.NotNil(results[1].Err)
+	suite.Equal([]string{"http://example.com/other"}, results[2].SourceURLs)
+	suite.Equal([]string{"http://example.com/article?utm_source=feed"}, results[3].SourceURLs)
+}
+
+func (suite *BatchSuite) TestIssues() {
+	results := NewFactory(suite.archive).PagesFromURLs(context.Background(), suite.urls(), MergeByTargetURL{})
+	suite.Len(results[1].Issues.ByCode(ErrorMessageKey(ErrorCodeNotArchived)), 1, "A repeated URL is only fetched, and fails, once")
+	suite.False(results[0].Issues.HasErrors())
+
+	issues := CollectIssues(results)
+	suite.Equal(1, issues.CountBySeverity()[IssueSeverityError])
+}
+
+func (suite *BatchSuite) TestMergeByCanonical() {
+	results := NewFactory(suite.archive, MergeByCanonical{}).PagesFromURLs(context.Background(), suite.urls())
+	suite.Len(results, 3)
+	suite.Equal([]string{"http://example.com/article", "http://short.example/a", "http://example.com/article?utm_source=feed", "http://example.com/article"}, results[0].SourceURLs)
+}
+
+func TestBatchSuite(t *testing.T) {
+	suite.Run(t, new(BatchSuite))
+}
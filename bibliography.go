@@ -0,0 +1,332 @@
+package resource
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/xerrors"
+)
+
+// CSLName is an author in CSL-JSON, either split into family and given names or kept literal
+type CSLName struct {
+	Family  string `json:"family,omitempty"`
+	Given   string `json:"given,omitempty"`
+	Literal string `json:"literal,omitempty"`
+}
+
+// CSLDate is a CSL-JSON date, DateParts holds [year], [year, month] or [year, month, day] depending on how precise the
+// citation's date was; Raw is used when the date couldn't be parsed
+type CSLDate struct {
+	DateParts [][]int `json:"date-parts,omitempty"`
+	Raw       string  `json:"raw,omitempty"`
+}
+
+// CSLItem is a Citation Style Language (CSL-JSON) item, the format Zotero, Mendeley and citeproc processors read
+type CSLItem struct {
+	ID                  string    `json:"id"`
+	Type                string    `json:"type"`
+	Title               string    `json:"title,omitempty"`
+	Author              []CSLName `json:"author,omitempty"`
+	ContainerTitle      string    `json:"container-title,omitempty"`
+	ContainerTitleShort string    `json:"container-title-short,omitempty"`
+	Publisher           string    `json:"publisher,omitempty"`
+	Issued              *CSLDate  `json:"issued,omitempty"`
+	Volume              string    `json:"volume,omitempty"`
+	Issue               string    `json:"issue,omitempty"`
+	Page                string    `json:"page,omitempty"`
+	DOI                 string    `json:"DOI,omitempty"`
+	ISSN                string    `json:"ISSN,omitempty"`
+	ISBN                string    `json:"ISBN,omitempty"`
+	PMID                string    `json:"PMID,omitempty"`
+	URL                 string    `json:"URL,omitempty"`
+	Language            string    `json:"language,omitempty"`
+	Keyword             string    `json:"keyword,omitempty"` // comma separated, as CSL-JSON has it
+	Note                string    `json:"note,omitempty"`
+}
+
+// NewCSLItem creates the CSL-JSON item of a page's citation metadata, false is returned if the page has none
+func NewCSLItem(page *Page) (*CSLItem, bool) {
+	citation := page.Citation
+	if citation == nil {
+		return nil, false
+	}
+
+	result := &CSLItem{
+		ID:                  citationKey(citation),
+		Type:                cslType(citation),
+		Title:               citation.Title,
+		ContainerTitle:      citation.Journal,
+		ContainerTitleShort: citation.JournalAbbrev,
+		Publisher:           citation.Publisher,
+		Volume:              citation.Volume,
+		Issue:               citation.Issue,
+		Page:                citationPages(citation, "-"),
+		DOI:                 citation.DOI,
+		ISSN:                citation.ISSN,
+		ISBN:                citation.ISBN,
+		PMID:                citation.PMID,
+		Language:            citation.Language,
+		Keyword:             strings.Join(citation.Keywords, ", "),
+	}
+	if len(result.ContainerTitle) == 0 {
+		result.ContainerTitle = citation.ConferenceTitle
+	}
+	if link := pageLink(page); link != nil {
+		result.URL = link.String()
+	}
+	if len(citation.ArXivID) > 0 {
+		result.Note = "arXiv: " + citation.ArXivID
+	}
+	for _, author := range citation.Authors {
+		family, given := splitAuthorName(author)
+		if len(given) == 0 {
+			result.Author = append(result.Author, CSLName{Literal: family})
+		} else {
+			result.Author = append(result.Author, CSLName{Family: family, Given: given})
+		}
+	}
+	if parts := citationDateParts(citation); len(parts) > 0 {
+		result.Issued = &CSLDate{DateParts: [][]int{parts}}
+	} else if len(citation.Date) > 0 {
+		result.Issued = &CSLDate{Raw: citation.Date}
+	}
+	return result, true
+}
+
+// WriteCSLJSON writes the citations of pages as a CSL-JSON array, pages without citation metadata are skipped
+func WriteCSLJSON(w io.Writer, pages []*Page) error {
+	items := []*CSLItem{}
+	keys := make(map[string]int)
+	for _, page := range pages {
+		if item, ok := NewCSLItem(page); ok {
+			item.ID = uniqueCitationKey(keys, item.ID)
+			items = append(items, item)
+		}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(items); err != nil {
+		return xerrors.Errorf("Unable to write CSL-JSON: %w", err)
+	}
+	return nil
+}
+
+// BibTeXEntry formats the citation of a page as a BibTeX entry, false is returned if the page has none
+func BibTeXEntry(page *Page) (string, bool) {
+	if page.Citation == nil {
+		return "", false
+	}
+	return bibTeXEntry(page, citationKey(page.Citation)), true
+}
+
+// WriteBibTeX writes the citations of pages as a BibTeX database, pages without citation metadata are skipped and
+// repeated keys are made unique with a, b, c... suffixes
+func WriteBibTeX(w io.Writer, pages []*Page) error {
+	keys := make(map[string]int)
+	first := true
+	for _, page := range pages {
+		if page.Citation == nil {
+			continue
+		}
+		entry := bibTeXEntry(page, uniqueCitationKey(keys, citationKey(page.Citation)))
+		if !first {
+			entry = "\n" + entry
+		}
+		first = false
+		if _, err := io.WriteString(w, entry); err != nil {
+			return xerrors.Errorf("Unable to write BibTeX: %w", err)
+		}
+	}
+	return nil
+}
+
+var bibTeXMonths = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+
+func bibTeXEntry(page *Page, key string) string {
+	citation := page.Citation
+	var fields [][2]string
+	field := func(name, value string) {
+		if value = strings.TrimSpace(value); len(value) > 0 {
+			fields = append(fields, [2]string{name, "{" + escapeBibTeX(value) + "}"})
+		}
+	}
+
+	entryType := bibTeXType(citation)
+	field("title", citation.Title)
+	field("author", strings.Join(citation.Authors, " and "))
+	switch entryType {
+	case "article":
+		field("journal", citation.Journal)
+	case "inproceedings":
+		field("booktitle", citation.ConferenceTitle)
+	}
+	field("publisher", citation.Publisher)
+	parts := citationDateParts(citation)
+	if len(parts) > 0 {
+		fields = append(fields, [2]string{"year", strconv.Itoa(parts[0])})
+	}
+	if len(parts) > 1 {
+		fields = append(fields, [2]string{"month", bibTeXMonths[parts[1]-1]})
+	}
+	field("volume", citation.Volume)
+	field("number", citation.Issue)
+	field("pages", citationPages(citation, "--"))
+	field("doi", citation.DOI)
+	field("issn", citation.ISSN)
+	field("isbn", citation.ISBN)
+	field("pmid", citation.PMID)
+	if len(citation.ArXivID) > 0 {
+		field("eprint", citation.ArXivID)
+		field("archiveprefix", "arXiv")
+	}
+	if link := pageLink(page); link != nil {
+		field("url", link.String())
+	}
+	field("language", citation.Language)
+	field("keywords", strings.Join(citation.Keywords, ", "))
+
+	var entry strings.Builder
+	fmt.Fprintf(&entry, "@%s{%s", entryType, key)
+	for _, f := range fields {
+		fmt.Fprintf(&entry, ",\n  %s = %s", f[0], f[1])
+	}
+	entry.WriteString("\n}\n")
+	return entry.String()
+}
+
+var bibTeXEscapes = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	"{", `\{`,
+	"}", `\}`,
+	"&", `\&`,
+	"%", `\%`,
+	"$", `\$`,
+	"#", `\#`,
+	"_", `\_`,
+	"~", `\textasciitilde{}`,
+	"^", `\textasciicircum{}`,
+)
+
+func escapeBibTeX(value string) string {
+	return bibTeXEscapes.Replace(value)
+}
+
+func cslType(citation *Citation) string {
+	switch {
+	case len(citation.Journal) > 0:
+		return "article-journal"
+	case len(citation.ConferenceTitle) > 0:
+		return "paper-conference"
+	case len(citation.ISBN) > 0:
+		return "book"
+	case len(citation.ArXivID) > 0:
+		return "article"
+	}
+	return "webpage"
+}
+
+func bibTeXType(citation *Citation) string {
+	switch {
+	case len(citation.Journal) > 0:
+		return "article"
+	case len(citation.ConferenceTitle) > 0:
+		return "inproceedings"
+	case len(citation.ISBN) > 0:
+		return "book"
+	}
+	return "misc"
+}
+
+// splitAuthorName splits "Last, First" and "First Last" into family and given names, a single name is returned as the
+// family name with an empty given name
+func splitAuthorName(name string) (family string, given string) {
+	if comma := strings.Index(name, ","); comma >= 0 {
+		return strings.TrimSpace(name[:comma]), strings.TrimSpace(name[comma+1:])
+	}
+	words := strings.Fields(name)
+	if len(words) < 2 {
+		return strings.TrimSpace(name), ""
+	}
+	return words[len(words)-1], strings.Join(words[:len(words)-1], " ")
+}
+
+func citationPages(citation *Citation, separator string) string {
+	if len(citation.FirstPage) > 0 && len(citation.LastPage) > 0 {
+		return citation.FirstPage + separator + citation.LastPage
+	}
+	return citation.FirstPage
+}
+
+var citationDateNumbers = regexp.MustCompile(`\d+`)
+
+// citationDateParts returns the year, month and day of the citation's date, only as many as the date was written with
+func citationDateParts(citation *Citation) []int {
+	if citation.Published.IsZero() {
+		return nil
+	}
+	published := citation.Published
+	parts := []int{published.Year(), int(published.Month()), published.Day()}
+	precision := len(citationDateNumbers.FindAllString(citation.Date, 3))
+	if precision < 1 || precision > 3 {
+		precision = 3
+	}
+	return parts[:precision]
+}
+
+var citationKeyWords = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// citationKey creates a key in the usual BibTeX form, the first author's family name, the year and the first
+// significant word of the title, e.g. vaswani2017attention
+func citationKey(citation *Citation) string {
+	var key strings.Builder
+	if len(citation.Authors) > 0 {
+		family, _ := splitAuthorName(citation.Authors[0])
+		key.WriteString(citationKeyWord(family))
+	}
+	if parts := citationDateParts(citation); len(parts) > 0 {
+		key.WriteString(strconv.Itoa(parts[0]))
+	}
+	for _, word := range citationKeyWords.FindAllString(citation.Title, -1) {
+		switch strings.ToLower(word) {
+		case "a", "an", "the", "on", "of", "in", "for", "and", "to":
+			continue
+		}
+		key.WriteString(citationKeyWord(word))
+		break
+	}
+	if key.Len() == 0 {
+		if len(citation.DOI) > 0 {
+			return citationKeyWord(citation.DOI)
+		}
+		return "citation"
+	}
+	return key.String()
+}
+
+// citationKeyWord lower cases a word and drops anything that isn't a letter or digit
+func citationKeyWord(word string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, word)
+}
+
+func uniqueCitationKey(keys map[string]int, key string) string {
+	count := keys[key]
+	keys[key] = count + 1
+	if count == 0 {
+		return key
+	}
+	suffix := ""
+	for n := count; n > 0; n = (n - 1) / 26 {
+		suffix = string(rune('a'+(n-1)%26)) + suffix
+	}
+	return key + suffix
+}
@@ -0,0 +1,141 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+const testScholarlyPage = `<html><head><title>Attention Is All You Need</title>
+<link rel="canonical" href="https://journal.example.com/article/5678">
+<meta name="citation_title" content="Attention Is All You Need">
+<meta name="citation_author" content="Vaswani, Ashish">
+<meta name="citation_author" content="Noam Shazeer">
+<meta name="citation_author" content="DeepMind">
+<meta name="citation_journal_title" content="Journal of Examples &amp; Proofs">
+<meta name="citation_journal_abbrev" content="J. Ex.">
+<meta name="citation_publication_date" content="2017/06/12">
+<meta name="citation_volume" content="30">
+<meta name="citation_firstpage" content="5998">
+<meta name="citation_lastpage" content="6008">
+<meta name="citation_doi" content="10.1234/example.5678">
+<meta name="citation_keywords" content="transformers; attention">
+</head></html>`
+
+const testPreprintPage = `<html><head>
+<meta name="citation_title" content="On the 100% Solution">
+<meta name="citation_author" content="Vaswani, Ashish">
+<meta name="citation_date" content="2017">
+<meta name="citation_arxiv_id" content="1706.03762">
+</head></html>`
+
+type BibliographySuite struct {
+	suite.Suite
+	article  *Page
+	preprint *Page
+	plain    *Page
+}
+
+func (suite *BibliographySuite) SetupSuite() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://journal.example.com/article/5678?ref=toc", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, testScholarlyPage))
+	archive.Add("https://arxiv.example.com/abs/1706.03762", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, testPreprintPage))
+	archive.Add("https://www.netspective.com/about", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, testFeedUndated))
+	factory := NewFactory(archive)
+	page := func(target string) *Page {
+		content, err := factory.PageFromURL(context.Background(), target)
+		suite.Require().Nil(err, "Should not get an error")
+		return content.(*Page)
+	}
+	suite.article = page("https://journal.example.com/article/5678?ref=toc")
+	suite.preprint = page("https://arxiv.example.com/abs/1706.03762")
+	suite.plain = page("https://www.netspective.com/about")
+}
+
+func (suite *BibliographySuite) TestCSLItem() {
+	item, ok := NewCSLItem(suite.article)
+	suite.Require().True(ok)
+	suite.Equal("vaswani2017attention", item.ID)
+	suite.Equal("article-journal", item.Type)
+	suite.Equal([]CSLName{{Family: "Vaswani", Given: "Ashish"}, {Family: "Shazeer", Given: "Noam"}, {Literal: "DeepMind"}}, item.Author)
+	suite.Equal("Journal of Examples & Proofs", item.ContainerTitle)
+	suite.Equal("J. Ex.", item.ContainerTitleShort)
+	suite.Equal(&CSLDate{DateParts: [][]int{{2017, 6, 12}}}, item.Issued)
+	suite.Equal("5998-6008", item.Page)
+	suite.Equal("https://journal.example.com/article/5678", item.URL, "Canonical URL should be used")
+	suite.Equal("transformers, attention", item.Keyword)
+
+	preprint, ok := NewCSLItem(suite.preprint)
+	suite.Require().True(ok)
+	suite.Equal("article", preprint.Type)
+	suite.Equal(&CSLDate{DateParts: [][]int{{2017}}}, preprint.Issued, "Only the year was given")
+	suite.Equal("arXiv: 1706.03762", preprint.Note)
+
+	_, ok = NewCSLItem(suite.plain)
+	suite.False(ok, "Pages without citation metadata have no CSL item")
+}
+
+func (suite *BibliographySuite) TestWriteCSLJSON() {
+	var buffer bytes.Buffer
+	suite.Require().Nil(WriteCSLJSON(&buffer, []*Page{suite.article, suite.plain, suite.article}))
+
+	var items []map[string]interface{}
+	suite.Require().Nil(json.Unmarshal(buffer.Bytes(), &items), buffer.String())
+	suite.Require().Len(items, 2)
+	suite.Equal("vaswani2017attention", items[0]["id"])
+	suite.Equal("vaswani2017attentiona", items[1]["id"], "Repeated keys should be made unique")
+	suite.Equal("10.1234/example.5678", items[0]["DOI"])
+	suite.Equal([]interface{}{[]interface{}{2017.0, 6.0, 12.0}}, items[0]["issued"].(map[string]interface{})["date-parts"])
+
+	buffer.Reset()
+	suite.Require().Nil(WriteCSLJSON(&buffer, []*Page{suite.plain}))
+	suite.Equal("[]\n", buffer.String())
+}
+
+func (suite *BibliographySuite) TestBibTeXEntry() {
+	entry, ok := BibTeXEntry(suite.article)
+	suite.Require().True(ok)
+	suite.Equal(`@article{vaswani2017attention,
+  title = {Attention Is All You Need},
+  author = {Vaswani, Ashish and Noam Shazeer and DeepMind},
+  journal = {Journal of Examples \& Proofs},
+  year = 2017,
+  month = jun,
+  volume = {30},
+  pages = {5998--6008},
+  doi = {10.1234/example.5678},
+  url = {https://journal.example.com/article/5678},
+  keywords = {transformers, attention}
+}
+`, entry)
+
+	entry, ok = BibTeXEntry(suite.preprint)
+	suite.Require().True(ok)
+	suite.Equal(`@misc{vaswani2017100,
+  title = {On the 100\% Solution},
+  author = {Vaswani, Ashish},
+  year = 2017,
+  eprint = {1706.03762},
+  archiveprefix = {arXiv},
+  url = {https://arxiv.example.com/abs/1706.03762}
+}
+`, entry)
+
+	_, ok = BibTeXEntry(suite.plain)
+	suite.False(ok)
+}
+
+func (suite *BibliographySuite) TestWriteBibTeX() {
+	var buffer bytes.Buffer
+	suite.Require().Nil(WriteBibTeX(&buffer, []*Page{suite.plain, suite.preprint, suite.article}))
+	suite.Contains(buffer.String(), "@misc{vaswani2017100,")
+	suite.Contains(buffer.String(), "}\n\n@article{vaswani2017attention,")
+}
+
+func TestBibliographySuite(t *testing.T) {
+	suite.Run(t, new(BibliographySuite))
+}
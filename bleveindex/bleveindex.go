@@ -0,0 +1,76 @@
+// Package bleveindex is a resource.Indexer which makes harvested pages searchable in a Bleve index. It's a module of
+// its own so that only applications which use it depend on Bleve.
+package bleveindex
+
+import (
+	"context"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/lectio/resource"
+	"golang.org/x/xerrors"
+)
+
+// Indexer is a resource.Indexer which indexes pages in Index, keyed by their URL
+type Indexer struct {
+	Index bleve.Index
+}
+
+// New creates an Indexer for an index the caller has opened
+func New(index bleve.Index) *Indexer {
+	return &Indexer{Index: index}
+}
+
+// Open opens the index at path, creating it with Bleve's default mapping if it doesn't exist yet
+func Open(path string) (*Indexer, error) {
+	index, err := bleve.Open(path)
+	if xerrors.Is(err, bleve.ErrorIndexPathDoesNotExist) {
+		index, err = bleve.New(path, bleve.NewIndexMapping())
+	}
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to open Bleve index %q: %w", path, err)
+	}
+	return New(index), nil
+}
+
+// IndexPage satisfies resource.Indexer method
+func (i *Indexer) IndexPage(ctx context.Context, document resource.IndexDocument) error {
+	fields := map[string]interface{}{
+		"url":      document.URL,
+		"title":    document.Title,
+		"text":     document.Text,
+		"resolved": document.Resolved,
+	}
+	if len(document.Description) > 0 {
+		fields["description"] = document.Description
+	}
+	if len(document.Language) > 0 {
+		fields["language"] = document.Language
+	}
+	if len(document.Meta) > 0 {
+		fields["meta"] = document.Meta
+	}
+	if len(document.Annotations) > 0 {
+		fields["annotations"] = map[string]interface{}(document.Annotations)
+	}
+	return i.Index.Index(document.URL, fields)
+}
+
+// Search returns the URLs of up to size pages matching a Bleve query string such as "harvest +language:en", best
+// matches first
+func (i *Indexer) Search(ctx context.Context, query string, size int) ([]string, error) {
+	request := bleve.NewSearchRequestOptions(bleve.NewQueryStringQuery(query), size, 0, false)
+	result, err := i.Index.SearchInContext(ctx, request)
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to search Bleve index for %q: %w", query, err)
+	}
+	urls := make([]string, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		urls = append(urls, hit.ID)
+	}
+	return urls, nil
+}
+
+// Close closes the index
+func (i *Indexer) Close() error {
+	return i.Index.Close()
+}
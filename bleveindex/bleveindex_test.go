@@ -0,0 +1,82 @@
+package bleveindex
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/lectio/resource"
+	"github.com/stretchr/testify/suite"
+)
+
+type IndexerSuite struct {
+	suite.Suite
+	indexer *Indexer
+}
+
+func (suite *IndexerSuite) SetupTest() {
+	index, err := bleve.NewMemOnly(bleve.NewIndexMapping())
+	suite.Require().Nil(err, "Should not get an error")
+	suite.indexer = New(index)
+}
+
+func (suite *IndexerSuite) TearDownTest() {
+	suite.indexer.Close()
+}
+
+func (suite *IndexerSuite) TestIndexAndSearch() {
+	ctx := context.Background()
+	suite.Nil(suite.indexer.IndexPage(ctx, resource.IndexDocument{
+		URL:         "https://www.netspective.com/harvesting",
+		Title:       "Harvesting the Web",
+		Description: "Crawling, parsing and indexing",
+		Language:    "en",
+		Text:        "Pages are fetched, parsed and indexed.",
+		Meta:        map[string]string{"og:site_name": "Netspective"},
+		Resolved:    time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)}))
+	suite.Nil(suite.indexer.IndexPage(ctx, resource.IndexDocument{
+		URL:   "https://www.netspective.com/about",
+		Title: "About Netspective",
+		Text:  "A healthcare technology company."}))
+
+	urls, err := suite.indexer.Search(ctx, "parsed", 10)
+	suite.Nil(err, "Should not get an error")
+	suite.Equal([]string{"https://www.netspective.com/harvesting"}, urls)
+
+	urls, _ = suite.indexer.Search(ctx, "title:netspective", 10)
+	suite.Equal([]string{"https://www.netspective.com/about"}, urls)
+
+	count, _ := suite.indexer.Index.DocCount()
+	suite.Equal(uint64(2), count)
+}
+
+func (suite *IndexerSuite) TestFactoryIndexesResolvedPages() {
+	archive := resource.NewMemoryResponseArchive()
+	archive.AddRaw("https://www.netspective.com/", []byte("HTTP/1.1 200 OK\r\nContent-Type: text/html\r\n\r\n"+
+		"<html><head><title>Netspective</title></head><body><p>Digital health strategy.</p></body></html>"))
+
+	_, err := resource.NewFactory(archive, suite.indexer).PageFromURL(context.Background(), "https://www.netspective.com/")
+	suite.Nil(err, "Should not get an error")
+	urls, _ := suite.indexer.Search(context.Background(), "strategy", 10)
+	suite.Equal([]string{"https://www.netspective.com/"}, urls)
+}
+
+func (suite *IndexerSuite) TestOpenCreatesIndex() {
+	path := filepath.Join(suite.T().TempDir(), "pages.bleve")
+	indexer, err := Open(path)
+	suite.Require().Nil(err, "Should not get an error")
+	suite.Nil(indexer.IndexPage(context.Background(), resource.IndexDocument{URL: "https://www.netspective.com/", Text: "kept"}))
+	suite.Nil(indexer.Close())
+
+	indexer, err = Open(path)
+	suite.Require().Nil(err, "An existing index should be reopened")
+	defer indexer.Close()
+	count, _ := indexer.Index.DocCount()
+	suite.Equal(uint64(1), count)
+}
+
+func TestIndexerSuite(t *testing.T) {
+	suite.Run(t, new(IndexerSuite))
+}
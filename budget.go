@@ -0,0 +1,199 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// The limits of a FetchBudget, as reported in BudgetUsage.Exceeded and BudgetExceededError.Limit
+const (
+	BudgetLimitDuration  = "duration"
+	BudgetLimitBytes     = "bytes"
+	BudgetLimitRedirects = "redirects"
+)
+
+// FetchBudget is passed into options, the factory's or PageFromURL's for a single URL, to bound the resources used
+// for a URL. It's enforced across resolving redirects, reading and parsing the content, downloading attachments, and
+// fetching related documents such as manifests. Zero means no limit, negative MaxRedirects means none are followed.
+type FetchBudget struct {
+	MaxDuration  time.Duration
+	MaxBytes     int64 // of all the response bodies read
+	MaxRedirects int
+}
+
+// BudgetUsage reports what a fetch with a FetchBudget consumed, Exceeded names the limit which stopped it (if any)
+type BudgetUsage struct {
+	Duration  time.Duration `json:"duration"`
+	Bytes     int64         `json:"bytes"`
+	Redirects int           `json:"redirects"`
+	Exceeded  string        `json:"exceeded,omitempty"`
+}
+
+// budgetTracker keeps the usage of a FetchBudget, it's carried in the context so every fetch made for the URL counts
+type budgetTracker struct {
+	budget  FetchBudget
+	clock   Clock
+	started time.Time
+
+	mu    sync.Mutex
+	usage BudgetUsage
+}
+
+type budgetContextKey struct{}
+
+// withFetchBudget starts tracking budget in the returned context, which is cancelled once MaxDuration has passed on
+// clock, the same clock the usage's Duration is measured on
+func withFetchBudget(ctx context.Context, budget FetchBudget, clock Clock) (context.Context, *budgetTracker, context.CancelFunc) {
+	tracker := &budgetTracker{budget: budget, clock: clock, started: clock.Now()}
+	ctx = context.WithValue(ctx, budgetContextKey{}, tracker)
+	if budget.MaxDuration <= 0 {
+		ctx, cancel := context.WithCancel(ctx)
+		return ctx, tracker, cancel
+	}
+
+	budgeted := &budgetContext{Context: ctx, done: make(chan struct{})}
+	timer := clock.NewTimer(budget.MaxDuration)
+	go func() {
+		defer timer.Stop()
+		select {
+		case <-timer.C():
+			tracker.exceed(BudgetLimitDuration)
+			budgeted.cancel(context.DeadlineExceeded)
+		case <-ctx.Done():
+			budgeted.cancel(ctx.Err())
+		case <-budgeted.done:
+		}
+	}()
+	return budgeted, tracker, func() { budgeted.cancel(context.Canceled) }
+}
+
+// budgetContext is cancelled when its parent is, or with context.DeadlineExceeded when the budget's MaxDuration
+// timer fires. The parent's Deadline is kept since the timer may not run on the system clock.
+type budgetContext struct {
+	context.Context
+
+	mu   sync.Mutex
+	done chan struct{}
+	err  error
+}
+
+func (c *budgetContext) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *budgetContext) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+func (c *budgetContext) cancel(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil {
+		c.err = err
+		close(c.done)
+	}
+}
+
+func budgetFromContext(ctx context.Context) *budgetTracker {
+	tracker, _ := ctx.Value(budgetContextKey{}).(*budgetTracker)
+	return tracker
+}
+
+// fetchBudget returns the FetchBudget in the call's options, or else the factory's
+func (f *DefaultFactory) fetchBudget(options []interface{}) *FetchBudget {
+	for _, option := range options {
+		switch budget := option.(type) {
+		case FetchBudget:
+			return &budget
+		case *FetchBudget:
+			return budget
+		}
+	}
+	return f.FetchBudget
+}
+
+func (t *budgetTracker) exceed(limit string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.usage.Exceeded) == 0 {
+		t.usage.Exceeded = limit
+	}
+}
+
+// finish returns the usage. MaxDuration is only reported as exceeded if the budget's own timer fired, not when the
+// caller's context ran out first.
+func (t *budgetTracker) finish() BudgetUsage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.usage.Duration = t.clock.Now().Sub(t.started)
+	return t.usage
+}
+
+// checkRedirect wraps a client's CheckRedirect to count redirects against the budget
+func (t *budgetTracker) checkRedirect(next func(req *http.Request, via []*http.Request) error) func(req *http.Request, via []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		t.mu.Lock()
+		t.usage.Redirects++
+		redirects := t.usage.Redirects
+		t.mu.Unlock()
+		if t.budget.MaxRedirects < 0 || (t.budget.MaxRedirects > 0 && redirects > t.budget.MaxRedirects) {
+			t.exceed(BudgetLimitRedirects)
+			return fmt.Errorf("stopped after %d redirects", redirects-1)
+		}
+		if next != nil {
+			return next(req, via)
+		}
+		if len(via) >= 10 {
+			return fmt.Errorf("stopped after 10 redirects")
+		}
+		return nil
+	}
+}
+
+// budgetedClient returns a copy of client which counts redirects against the budget
+func (t *budgetTracker) budgetedClient(client *http.Client) *http.Client {
+	result := *client
+	result.CheckRedirect = t.checkRedirect(client.CheckRedirect)
+	return &result
+}
+
+// budgetBody counts the bytes read from a response body against the budget, failing reads beyond MaxBytes
+type budgetBody struct {
+	io.ReadCloser
+	tracker *budgetTracker
+}
+
+func (b budgetBody) Read(p []byte) (int, error) {
+	t := b.tracker
+	if t.budget.MaxBytes <= 0 {
+		n, err := b.ReadCloser.Read(p)
+		t.mu.Lock()
+		t.usage.Bytes += int64(n)
+		t.mu.Unlock()
+		return n, err
+	}
+
+	t.mu.Lock()
+	remaining := t.budget.MaxBytes - t.usage.Bytes
+	t.mu.Unlock()
+	// one extra byte is asked for so that a body which ends exactly at the limit isn't counted as exceeding it
+	if int64(len(p)) > remaining+1 {
+		p = p[:remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) > remaining {
+		n = int(remaining)
+		t.exceed(BudgetLimitBytes)
+		err = fmt.Errorf("fetch budget of %d bytes exceeded", t.budget.MaxBytes)
+	}
+	t.mu.Lock()
+	t.usage.Bytes += int64(n)
+	t.mu.Unlock()
+	return n, err
+}
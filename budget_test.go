@@ -0,0 +1,115 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/xerrors"
+)
+
+type BudgetSuite struct {
+	suite.Suite
+	server *httptest.Server
+}
+
+func (suite *BudgetSuite) SetupTest() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, testHTMLPage)
+	})
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/moved", http.StatusFound)
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/page", http.StatusFound)
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	})
+	suite.server = httptest.NewServer(mux)
+}
+
+func (suite *BudgetSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+func (suite *BudgetSuite) TestUsageIsReported() {
+	content, err := NewFactory().PageFromURL(context.Background(), suite.server.URL+"/redirect", FetchBudget{MaxBytes: 1 << 20})
+	suite.Nil(err, "Should not get an error")
+	usage := content.(*Page).BudgetUsage
+	suite.NotNil(usage)
+	suite.Equal(2, usage.Redirects)
+	suite.Equal(int64(len(testHTMLPage)), usage.Bytes)
+	suite.Empty(usage.Exceeded)
+}
+
+func (suite *BudgetSuite) TestRedirectsExceeded() {
+	_, err := NewFactory(FetchBudget{MaxRedirects: 1}).PageFromURL(context.Background(), suite.server.URL+"/redirect")
+	var budgetErr *BudgetExceededError
+	suite.True(xerrors.As(err, &budgetErr), "Should get a budget error")
+	suite.Equal(BudgetLimitRedirects, budgetErr.Limit)
+}
+
+func (suite *BudgetSuite) TestBytesExceeded() {
+	limit := int64(strings.Index(testHTMLPage, "</head>") / 2)
+	content, err := NewFactory().PageFromURL(context.Background(), suite.server.URL+"/page", &FetchBudget{MaxBytes: limit})
+	var budgetErr *BudgetExceededError
+	suite.True(xerrors.As(err, &budgetErr), "Should get a budget error")
+	suite.Equal(BudgetLimitBytes, budgetErr.Limit)
+	suite.NotNil(content, "Content read within the budget should be returned")
+	suite.Equal(limit, content.(*Page).BudgetUsage.Bytes)
+}
+
+func (suite *BudgetSuite) TestDurationExceeded() {
+	started := time.Now()
+	_, err := NewFactory().PageFromURL(context.Background(), suite.server.URL+"/slow", FetchBudget{MaxDuration: 50 * time.Millisecond})
+	var budgetErr *BudgetExceededError
+	suite.True(xerrors.As(err, &budgetErr), "Should get a budget error")
+	suite.Equal(BudgetLimitDuration, budgetErr.Limit)
+	suite.True(time.Since(started) < 2*time.Second, "Fetch should stop when the budget runs out")
+}
+
+func (suite *BudgetSuite) TestDurationOnFactoryClock() {
+	clock := NewManualClock(time.Date(2019, 6, 2, 12, 0, 0, 0, time.UTC))
+	done := make(chan error)
+	var content Content
+	go func() {
+		var err error
+		content, err = NewFactory(clock).PageFromURL(context.Background(), suite.server.URL+"/slow", FetchBudget{MaxDuration: time.Minute})
+		done <- err
+	}()
+	for clock.Timers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	clock.Advance(time.Minute)
+	err := <-done
+	var budgetErr *BudgetExceededError
+	suite.True(xerrors.As(err, &budgetErr), "Should get a budget error once the factory's clock passes MaxDuration")
+	suite.Equal(BudgetLimitDuration, budgetErr.Limit)
+	suite.Equal(time.Minute, budgetErr.Usage.Duration, "Usage should be measured on the same clock as the limit")
+	suite.Nil(content)
+}
+
+func (suite *BudgetSuite) TestCallerDeadlineIsNotBudget() {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := NewFactory().PageFromURL(ctx, suite.server.URL+"/slow", FetchBudget{MaxDuration: time.Hour})
+	suite.NotNil(err, "Should get an error")
+	var budgetErr *BudgetExceededError
+	suite.False(xerrors.As(err, &budgetErr), "The caller's own deadline shouldn't be blamed on the budget")
+	suite.True(xerrors.Is(err, context.DeadlineExceeded), "The caller's deadline should be reported")
+}
+
+func TestBudgetSuite(t *testing.T) {
+	suite.Run(t, new(BudgetSuite))
+}
@@ -0,0 +1,74 @@
+package resource
+
+// PolicyBundle is a named group of options (policies, creators, sinks, ...) which can be shared as a curated
+// configuration, e.g. "strict-security" or "fast-preview". Bundles may be passed anywhere options are accepted,
+// including inside other bundles, and their options are applied in order so later options override earlier ones.
+type PolicyBundle struct {
+	Name    string
+	options []interface{}
+}
+
+// NewPolicyBundle creates a bundle of the given options
+func NewPolicyBundle(name string, options ...interface{}) PolicyBundle {
+	return PolicyBundle{Name: name, options: append([]interface{}(nil), options...)}
+}
+
+// With returns a copy of the bundle with more options, which override the bundle's own
+func (b PolicyBundle) With(options ...interface{}) PolicyBundle {
+	result := make([]interface{}, 0, len(b.options)+len(options))
+	result = append(result, b.options...)
+	return PolicyBundle{Name: b.Name, options: append(result, options...)}
+}
+
+// Named returns a copy of the bundle with a different name
+func (b PolicyBundle) Named(name string) PolicyBundle {
+	return PolicyBundle{Name: name, options: b.options}
+}
+
+// Options returns the bundle's options with any nested bundles expanded
+func (b PolicyBundle) Options() []interface{} {
+	return flattenOptions(b.options)
+}
+
+// Names returns the name of the bundle followed by the names of the bundles nested in it
+func (b PolicyBundle) Names() []string {
+	result := []string{b.Name}
+	for _, option := range b.options {
+		switch nested := option.(type) {
+		case PolicyBundle:
+			result = append(result, nested.Names()...)
+		case *PolicyBundle:
+			result = append(result, nested.Names()...)
+		}
+	}
+	return result
+}
+
+// flattenOptions expands the bundles in options where they appear, so that option type assertions see their contents
+func flattenOptions(options []interface{}) []interface{} {
+	hasBundles := false
+	for _, option := range options {
+		switch option.(type) {
+		case PolicyBundle, *PolicyBundle:
+			hasBundles = true
+		}
+	}
+	if !hasBundles {
+		return options
+	}
+
+	result := make([]interface{}, 0, len(options))
+	for _, option := range options {
+		switch bundle := option.(type) {
+		case PolicyBundle:
+			result = append(result, bundle.Options()...)
+		case *PolicyBundle:
+			if bundle != nil {
+				result = append(result, bundle.Options()...)
+			}
+		default:
+			result = append(result, option)
+		}
+	}
+	return result
+}
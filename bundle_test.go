@@ -0,0 +1,43 @@
+package resource
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type BundleSuite struct {
+	suite.Suite
+}
+
+func (suite *BundleSuite) TestWithOverrides() {
+	base := NewPolicyBundle("fast-preview", HTMLBodyLimit(1024), HedgeAfter(time.Second))
+	strict := NewPolicyBundle("strict-security", StopOnDownloadErrors(true))
+	custom := base.With(HTMLBodyLimit(4096), strict).Named("team-preview")
+
+	suite.Equal([]string{"team-preview", "strict-security"}, custom.Names())
+	suite.Len(custom.Options(), 4, "Nested bundles should be expanded")
+	suite.Len(base.Options(), 2, "With should not change the original bundle")
+
+	factory := NewFactory(custom)
+	suite.Equal(HTMLBodyLimit(4096), factory.HTMLBodyLimitPolicy, "Later options should override earlier ones")
+	suite.Equal(HedgeAfter(time.Second), factory.HedgingPolicy)
+	suite.Equal(StopOnDownloadErrors(true), factory.ContentDownloaderErrorPolicy)
+}
+
+func (suite *BundleSuite) TestCallLevelBundle() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("http://ceur-ws.org/Vol-1401/paper-05.pdf", archivedResponse(200, http.Header{"Content-Type": {"application/pdf"}}, testPDFContent))
+
+	bundle := NewPolicyBundle("memory-downloads", NewMemoryAttachmentCreator(nil))
+	content, err := NewFactory(archive).PageFromURL(context.Background(), "http://ceur-ws.org/Vol-1401/paper-05.pdf", bundle)
+	suite.Nil(err, "Should not get an error")
+	suite.NotNil(content.Attachment(), "Creator in a call-level bundle should be used")
+}
+
+func TestBundleSuite(t *testing.T) {
+	suite.Run(t, new(BundleSuite))
+}
@@ -0,0 +1,434 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/afero"
+	"golang.org/x/xerrors"
+)
+
+// MaxCachedBodySize is the largest response body the factory keeps in its CacheProvider
+const MaxCachedBodySize = 16 << 20
+
+// CachedResponse is a successful GET response kept by a CacheProvider
+type CachedResponse struct {
+	URL     string      `json:"url"` // the final URL, after any redirects
+	Header  http.Header `json:"header"`
+	Body    []byte      `json:"body"`
+	Stored  time.Time   `json:"stored"`
+	Expires time.Time   `json:"expires"` // the response has to be revalidated after this, zero means always
+}
+
+// Fresh returns true if the response can be used without asking the server
+func (r *CachedResponse) Fresh(now time.Time) bool {
+	return now.Before(r.Expires)
+}
+
+// usableOnError returns true if the response's stale-if-error still allows it to be used when revalidating it fails
+func (r *CachedResponse) usableOnError(now time.Time) bool {
+	for _, directive := range strings.Split(strings.Join(r.Header["Cache-Control"], ","), ",") {
+		name, value := strings.TrimSpace(directive), ""
+		if i := strings.Index(name, "="); i >= 0 {
+			name, value = strings.TrimSpace(name[:i]), strings.Trim(strings.TrimSpace(name[i+1:]), `"`)
+		}
+		if strings.EqualFold(name, "stale-if-error") {
+			seconds, err := strconv.ParseInt(value, 10, 64)
+			return err == nil && now.Before(r.Expires.Add(time.Duration(seconds)*time.Second))
+		}
+	}
+	return false
+}
+
+// validators returns the ETag and Last-Modified the response can be revalidated with
+func (r *CachedResponse) validators() Validators {
+	return Validators{ETag: r.Header.Get("ETag"), LastModified: r.Header.Get("Last-Modified")}
+}
+
+// response returns the cached response as though it had just been fetched
+func (r *CachedResponse) response() (*http.Response, error) {
+	target, err := url.Parse(r.URL)
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to parse cached response URL %q: %w", r.URL, err)
+	}
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        r.Header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       &http.Request{Method: http.MethodGet, URL: target, Header: make(http.Header)},
+	}, nil
+}
+
+// revalidated returns a copy of the response with the headers of a 304 answer to its revalidation merged in
+func (r *CachedResponse) revalidated(header http.Header) *CachedResponse {
+	result := *r
+	result.Header = r.Header.Clone()
+	for key, values := range header {
+		switch http.CanonicalHeaderKey(key) {
+		case "Content-Length", "Content-Encoding", "Transfer-Encoding":
+			continue
+		}
+		result.Header[key] = values
+	}
+	return &result
+}
+
+// CacheProvider is passed into options to cache GET responses, so repeated harvesting doesn't download pages which
+// haven't changed. Responses are kept under their TenantCacheKey (so tenants of a TenantRouter don't share them) for
+// as long as their Cache-Control or Expires headers allow and are revalidated with a conditional GET after that.
+// Requests with their own headers, e.g. the validators passed to PageFromURL, bypass the cache. Only bodies which were
+// read to the end, up to MaxCachedBodySize, are kept. Cache errors don't fail the fetch, they're recorded on the Page
+// as WarningCacheError.
+type CacheProvider interface {
+	LoadResponse(ctx context.Context, key string) (*CachedResponse, bool, error)
+	StoreResponse(ctx context.Context, key string, response *CachedResponse) error
+}
+
+// CacheInvalidator is implemented by CacheProviders whose entries can be removed, e.g. so that editorial tools can
+// force a page to be fetched again once its publisher has updated it. cachedFetch also uses it to forget responses
+// the server says are gone or mustn't be stored.
+type CacheInvalidator interface {
+	Invalidate(ctx context.Context, urlText string) error         // removes urlText's response cached for the context's tenant
+	InvalidateHost(ctx context.Context, host string) (int, error) // removes every response cached for host, for every tenant
+	Sweep(ctx context.Context, olderThan time.Time) (int, error)  // removes the responses stored before olderThan
+}
+
+// CacheKey normalizes urlText so that equivalent URLs share a cache entry: the scheme and host are lower-cased, the
+// default port and the fragment are dropped and the query parameters are sorted.
+func CacheKey(urlText string) string {
+	u, err := url.Parse(urlText)
+	if err != nil || !u.IsAbs() {
+		return urlText
+	}
+	key := *u
+	key.Scheme = strings.ToLower(key.Scheme)
+	key.Host = strings.ToLower(key.Host)
+	if port := key.Port(); (key.Scheme == "http" && port == "80") || (key.Scheme == "https" && port == "443") {
+		key.Host = key.Hostname()
+	}
+	if len(key.Path) == 0 {
+		key.Path = "/"
+	}
+	if len(key.RawQuery) > 0 {
+		key.RawQuery = key.Query().Encode()
+	}
+	key.Fragment, key.RawFragment = "", ""
+	return key.String()
+}
+
+// TenantCacheKey is the CacheKey of urlText for tenant, whose cached responses are kept apart from other tenants
+// since their policies may fetch different content for the same URL. It's the CacheKey without a tenant.
+func TenantCacheKey(tenant string, urlText string) string {
+	key := CacheKey(urlText)
+	if len(tenant) == 0 {
+		return key
+	}
+	// CacheKey drops fragments, so one can't clash with a URL
+	return key + "#tenant=" + url.QueryEscape(tenant)
+}
+
+// cacheKeyFor returns the TenantCacheKey of urlText for the context's tenant
+func cacheKeyFor(ctx context.Context, urlText string) string {
+	return TenantCacheKey(RequestIdentityFromContext(ctx).Tenant, urlText)
+}
+
+// cacheKeyHost returns true if the URL key was cached under is on host, with or without a port
+func cacheKeyHost(key string, host string) bool {
+	u, err := url.Parse(key)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, host) || strings.EqualFold(u.Hostname(), host)
+}
+
+// MemoryCacheProvider is a CacheProvider which keeps responses in memory
+type MemoryCacheProvider struct {
+	mu        sync.RWMutex
+	responses map[string]*CachedResponse
+}
+
+// NewMemoryCacheProvider creates an empty in-memory response cache
+func NewMemoryCacheProvider() *MemoryCacheProvider {
+	return &MemoryCacheProvider{responses: make(map[string]*CachedResponse)}
+}
+
+// LoadResponse satisfies CacheProvider method
+func (c *MemoryCacheProvider) LoadResponse(ctx context.Context, key string) (*CachedResponse, bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	response, ok := c.responses[key]
+	return response, ok, nil
+}
+
+// StoreResponse satisfies CacheProvider method
+func (c *MemoryCacheProvider) StoreResponse(ctx context.Context, key string, response *CachedResponse) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.responses[key] = response
+	return nil
+}
+
+// Invalidate satisfies CacheInvalidator method
+func (c *MemoryCacheProvider) Invalidate(ctx context.Context, urlText string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.responses, cacheKeyFor(ctx, urlText))
+	return nil
+}
+
+// InvalidateHost satisfies CacheInvalidator method
+func (c *MemoryCacheProvider) InvalidateHost(ctx context.Context, host string) (int, error) {
+	return c.remove(func(key string, response *CachedResponse) bool { return cacheKeyHost(key, host) }), nil
+}
+
+// Sweep satisfies CacheInvalidator method
+func (c *MemoryCacheProvider) Sweep(ctx context.Context, olderThan time.Time) (int, error) {
+	return c.remove(func(key string, response *CachedResponse) bool { return response.Stored.Before(olderThan) }), nil
+}
+
+func (c *MemoryCacheProvider) remove(matches func(key string, response *CachedResponse) bool) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	removed := 0
+	for key, response := range c.responses {
+		if matches(key, response) {
+			delete(c.responses, key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// DiskCacheProvider is a CacheProvider which keeps each response as a JSON file in BasePath, named after the SHA-256
+// of its key, e.g. ab/abcd....json
+type DiskCacheProvider struct {
+	FS       afero.Fs
+	BasePath string
+}
+
+// NewDiskCacheProvider creates a response cache in basePath of fs
+func NewDiskCacheProvider(fs afero.Fs, basePath string) *DiskCacheProvider {
+	return &DiskCacheProvider{FS: fs, BasePath: basePath}
+}
+
+// diskCacheEntry is the file a DiskCacheProvider keeps a response in, with the key so that entries can be found by host
+type diskCacheEntry struct {
+	Key string `json:"key"`
+	CachedResponse
+}
+
+func (c *DiskCacheProvider) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(c.BasePath, name[:2], name+".json")
+}
+
+// LoadResponse satisfies CacheProvider method
+func (c *DiskCacheProvider) LoadResponse(ctx context.Context, key string) (*CachedResponse, bool, error) {
+	data, err := afero.ReadFile(c.FS, c.path(key))
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, xerrors.Errorf("Unable to read cached response for %q: %w", key, err)
+	}
+	entry := new(diskCacheEntry)
+	if err := json.Unmarshal(data, entry); err != nil {
+		return nil, false, xerrors.Errorf("Unable to decode cached response for %q: %w", key, err)
+	}
+	return &entry.CachedResponse, true, nil
+}
+
+// StoreResponse satisfies CacheProvider method, the file is written next to its final path and renamed into place so
+// that readers never see part of it
+func (c *DiskCacheProvider) StoreResponse(ctx context.Context, key string, response *CachedResponse) error {
+	data, err := json.Marshal(diskCacheEntry{Key: key, CachedResponse: *response})
+	if err != nil {
+		return xerrors.Errorf("Unable to encode cached response for %q: %w", key, err)
+	}
+	path := c.path(key)
+	if err := c.FS.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return xerrors.Errorf("Unable to create cache directory for %q: %w", key, err)
+	}
+	temp := path + ".tmp"
+	if err := afero.WriteFile(c.FS, temp, data, 0644); err != nil {
+		return xerrors.Errorf("Unable to write cached response for %q: %w", key, err)
+	}
+	if err := c.FS.Rename(temp, path); err != nil {
+		c.FS.Remove(temp)
+		return xerrors.Errorf("Unable to store cached response for %q: %w", key, err)
+	}
+	return nil
+}
+
+// Invalidate satisfies CacheInvalidator method
+func (c *DiskCacheProvider) Invalidate(ctx context.Context, urlText string) error {
+	key := cacheKeyFor(ctx, urlText)
+	if err := c.FS.Remove(c.path(key)); err != nil && !os.IsNotExist(err) {
+		return xerrors.Errorf("Unable to invalidate cached response for %q: %w", key, err)
+	}
+	return nil
+}
+
+// InvalidateHost satisfies CacheInvalidator method, every file in the cache is read to find the host's
+func (c *DiskCacheProvider) InvalidateHost(ctx context.Context, host string) (int, error) {
+	return c.remove(func(entry *diskCacheEntry) bool { return cacheKeyHost(entry.Key, host) })
+}
+
+// Sweep satisfies CacheInvalidator method, every file in the cache is read to find the old ones
+func (c *DiskCacheProvider) Sweep(ctx context.Context, olderThan time.Time) (int, error) {
+	return c.remove(func(entry *diskCacheEntry) bool { return entry.Stored.Before(olderThan) })
+}
+
+// remove walks the cache and removes the entries which match, files which can't be decoded are left alone
+func (c *DiskCacheProvider) remove(matches func(entry *diskCacheEntry) bool) (int, error) {
+	removed := 0
+	err := afero.Walk(c.FS, c.BasePath, func(path string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+		data, err := afero.ReadFile(c.FS, path)
+		if err != nil {
+			return err
+		}
+		entry := new(diskCacheEntry)
+		if json.Unmarshal(data, entry) != nil || !matches(entry) {
+			return nil
+		}
+		if err := c.FS.Remove(path); err != nil {
+			return err
+		}
+		removed++
+		return nil
+	})
+	if err != nil {
+		return removed, xerrors.Errorf("Unable to remove cached responses from %q: %w", c.BasePath, err)
+	}
+	return removed, nil
+}
+
+// cachedFetch answers GETs from the CacheProvider while they're fresh, revalidates them once they're stale and keeps
+// complete successful responses the server allows to be stored. A stale response is still used if revalidating it
+// fails and its stale-if-error allows that. How the cache answered is told to observeCache.
+func (f *DefaultFactory) cachedFetch(ctx context.Context, urlText string, header http.Header) (*http.Response, error) {
+	if f.CacheProvider == nil || f.ResponseArchive != nil || len(header) > 0 || fetchMethod(ctx) != http.MethodGet {
+		return f.retriedFetch(ctx, urlText, header)
+	}
+
+	key := cacheKeyFor(ctx, urlText)
+	var cached *CachedResponse
+	guardPolicy("CacheProvider", func() {
+		response, ok, err := f.CacheProvider.LoadResponse(ctx, key)
+		if err != nil {
+			f.cacheError(ctx, err)
+		} else if ok {
+			cached = response
+		}
+	})
+	if cached != nil && cached.Fresh(f.clock().Now()) {
+		if resp, err := cached.response(); err == nil {
+			f.observeCache(ctx, urlText, CacheStatusHit)
+			return resp, nil
+		}
+		cached = nil
+	}
+
+	var validators Validators
+	if cached != nil {
+		validators = cached.validators()
+	}
+	resp, err := f.retriedFetch(ctx, urlText, validators.header())
+	if err != nil {
+		var statusErr *InvalidHTTPRespStatusCodeError
+		isStatusErr := xerrors.As(err, &statusErr)
+		switch {
+		case !validators.IsZero() && isStatusErr && statusErr.HTTPStatusCode == http.StatusNotModified:
+			target, _ := url.Parse(cached.URL)
+			refreshed := cached.revalidated(statusErr.Header)
+			refreshed.Stored = f.clock().Now()
+			refreshed.Expires = f.contentExpiry(ctx, target, &http.Response{Header: refreshed.Header}, nil)
+			f.storeCachedResponse(ctx, key, refreshed)
+			f.observeCache(ctx, urlText, CacheStatusRevalidated)
+			return refreshed.response()
+		case cached != nil && ctx.Err() == nil && (!isStatusErr || statusErr.HTTPStatusCode >= 500) && cached.usableOnError(f.clock().Now()):
+			f.observeCache(ctx, urlText, CacheStatusStale)
+			return cached.response()
+		}
+		if cached != nil && isStatusErr && (statusErr.HTTPStatusCode == http.StatusNotFound || statusErr.HTTPStatusCode == http.StatusGone) {
+			f.invalidateCached(ctx, urlText)
+		}
+		f.observeCache(ctx, urlText, CacheStatusMiss)
+		return nil, err
+	}
+
+	f.observeCache(ctx, urlText, CacheStatusMiss)
+	if !cacheableResponse(resp) {
+		if cached != nil {
+			f.invalidateCached(ctx, urlText)
+		}
+		return resp, nil
+	}
+	resp.Body = &recordingBody{ReadCloser: resp.Body, limit: MaxCachedBodySize, record: func(body []byte, complete bool) error {
+		if complete {
+			f.storeCachedResponse(ctx, key, &CachedResponse{
+				URL:     resp.Request.URL.String(),
+				Header:  resp.Header.Clone(),
+				Body:    append([]byte(nil), body...),
+				Stored:  f.clock().Now(),
+				Expires: f.contentExpiry(ctx, resp.Request.URL, resp, nil),
+			})
+		}
+		return nil
+	}}
+	return resp, nil
+}
+
+// storeCachedResponse keeps response in the CacheProvider, the cache is advisory so failing to store doesn't fail
+// the fetch but is reported on the Page
+func (f *DefaultFactory) storeCachedResponse(ctx context.Context, key string, response *CachedResponse) {
+	guardPolicy("CacheProvider", func() { f.cacheError(ctx, f.CacheProvider.StoreResponse(ctx, key, response)) })
+}
+
+// invalidateCached forgets the response cached for urlText, if the CacheProvider is a CacheInvalidator. Like storing,
+// it's advisory.
+func (f *DefaultFactory) invalidateCached(ctx context.Context, urlText string) {
+	if invalidator, ok := f.CacheProvider.(CacheInvalidator); ok {
+		guardPolicy("CacheProvider", func() { f.cacheError(ctx, invalidator.Invalidate(ctx, urlText)) })
+	}
+}
+
+// cacheableResponse returns true if the server allows resp to be stored
+func cacheableResponse(resp *http.Response) bool {
+	if resp.Request == nil || resp.Request.URL == nil || resp.Header.Get("Vary") == "*" {
+		return false
+	}
+	for _, directive := range strings.Split(strings.Join(resp.Header["Cache-Control"], ","), ",") {
+		if strings.EqualFold(strings.TrimSpace(directive), "no-store") {
+			return false
+		}
+	}
+	return true
+}
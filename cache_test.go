@@ -0,0 +1,294 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/suite"
+)
+
+type brokenCache struct{}
+
+func (brokenCache) LoadResponse(ctx context.Context, key string) (*CachedResponse, bool, error) {
+	return nil, false, errors.New("cache disk is unreadable")
+}
+
+func (brokenCache) StoreResponse(ctx context.Context, key string, response *CachedResponse) error {
+	return errors.New("cache disk is full")
+}
+
+type CacheSuite struct {
+	suite.Suite
+	server       *httptest.Server
+	cacheControl string
+	requests     int
+	header       http.Header // of the last request
+}
+
+func (suite *CacheSuite) SetupTest() {
+	suite.cacheControl = "max-age=60"
+	suite.requests = 0
+	suite.header = nil
+	suite.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		suite.requests++
+		suite.header = r.Header.Clone()
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Cache-Control", suite.cacheControl)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, testHTMLPage)
+	}))
+}
+
+func (suite *CacheSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+func (suite *CacheSuite) harvest(factory *DefaultFactory, urlText string) *Page {
+	content, err := factory.PageFromURL(context.Background(), urlText)
+	suite.Nil(err, "Unable to harvest %q", urlText)
+	page, ok := content.(*Page)
+	suite.True(ok, "Content should be a Page")
+	return page
+}
+
+func siteName(page *Page) interface{} {
+	value, _, _ := page.MetaTag("og:site_name")
+	return value
+}
+
+func (suite *CacheSuite) TestFreshResponseIsNotFetchedAgain() {
+	clock := NewManualClock(time.Date(2019, 6, 2, 12, 0, 0, 0, time.UTC))
+	cache := NewMemoryCacheProvider()
+	factory := NewFactory(clock, cache)
+
+	first := suite.harvest(factory, suite.server.URL+"/page?b=2&a=1")
+	second := suite.harvest(factory, suite.server.URL+"/page?a=1&b=2#intro")
+	suite.Equal(1, suite.requests, "The second harvest should come from cache")
+	suite.Equal("Netspective", siteName(first))
+	suite.Equal(siteName(first), siteName(second))
+	suite.Equal(`"v1"`, second.ETag)
+
+	cached, ok, err := cache.LoadResponse(context.Background(), CacheKey(suite.server.URL+"/page?a=1&b=2"))
+	suite.Nil(err)
+	suite.True(ok)
+	suite.Equal(clock.Now(), cached.Stored)
+	suite.Equal(clock.Now().Add(time.Minute), cached.Expires)
+	suite.Equal(testHTMLPage, string(cached.Body))
+}
+
+func (suite *CacheSuite) TestStaleResponseIsRevalidated() {
+	clock := NewManualClock(time.Date(2019, 6, 2, 12, 0, 0, 0, time.UTC))
+	cache := NewMemoryCacheProvider()
+	factory := NewFactory(clock, cache)
+
+	suite.harvest(factory, suite.server.URL)
+	clock.Advance(2 * time.Minute)
+	page := suite.harvest(factory, suite.server.URL)
+	suite.Equal(2, suite.requests)
+	suite.Equal(`"v1"`, suite.header.Get("If-None-Match"), "A stale response should be revalidated")
+	suite.False(page.NotModified, "A revalidated response is served from cache, not as a 304")
+	suite.Equal("Netspective", siteName(page))
+
+	cached, _, _ := cache.LoadResponse(context.Background(), CacheKey(suite.server.URL))
+	suite.Equal(clock.Now().Add(time.Minute), cached.Expires, "Revalidation should refresh the expiry")
+
+	suite.harvest(factory, suite.server.URL)
+	suite.Equal(2, suite.requests)
+}
+
+func (suite *CacheSuite) TestNoStoreIsNotCached() {
+	suite.cacheControl = "no-store"
+	cache := NewMemoryCacheProvider()
+	factory := NewFactory(cache)
+
+	suite.harvest(factory, suite.server.URL)
+	suite.harvest(factory, suite.server.URL)
+	suite.Equal(2, suite.requests)
+	_, ok, _ := cache.LoadResponse(context.Background(), CacheKey(suite.server.URL))
+	suite.False(ok)
+}
+
+func (suite *CacheSuite) TestCallerValidatorsBypassCache() {
+	factory := NewFactory(NewMemoryCacheProvider())
+	suite.harvest(factory, suite.server.URL)
+
+	content, err := factory.PageFromURL(context.Background(), suite.server.URL, Validators{ETag: `"v1"`})
+	suite.Nil(err)
+	suite.True(IsNotModified(content))
+	suite.Equal(2, suite.requests)
+}
+
+func (suite *CacheSuite) TestDiskCacheProvider() {
+	fs := afero.NewMemMapFs()
+	clock := NewManualClock(time.Date(2019, 6, 2, 12, 0, 0, 0, time.UTC))
+
+	suite.harvest(NewFactory(clock, NewDiskCacheProvider(fs, "cache")), suite.server.URL)
+	page := suite.harvest(NewFactory(clock, NewDiskCacheProvider(fs, "cache")), suite.server.URL)
+	suite.Equal(1, suite.requests, "The cache should outlive the factory")
+	suite.Equal("Netspective", siteName(page))
+
+	files, err := afero.Glob(fs, "cache/*/*.json")
+	suite.Nil(err)
+	suite.Len(files, 1)
+	temps, _ := afero.Glob(fs, "cache/*/*.tmp")
+	suite.Empty(temps)
+
+	_, ok, err := NewDiskCacheProvider(fs, "cache").LoadResponse(context.Background(), "https://example.com/missing")
+	suite.Nil(err)
+	suite.False(ok)
+}
+
+func (suite *CacheSuite) testInvalidation(cache interface {
+	CacheProvider
+	CacheInvalidator
+}) {
+	ctx := context.Background()
+	stored := time.Date(2019, 6, 2, 12, 0, 0, 0, time.UTC)
+	for index, urlText := range []string{"https://example.com/a", "https://EXAMPLE.com/b", "https://example.com:8443/c", "https://other.org/d"} {
+		response := &CachedResponse{URL: urlText, Stored: stored.Add(time.Duration(index) * time.Hour)}
+		suite.Nil(cache.StoreResponse(ctx, CacheKey(urlText), response))
+	}
+	loaded := func(urlText string) bool {
+		_, ok, err := cache.LoadResponse(ctx, CacheKey(urlText))
+		suite.Nil(err)
+		return ok
+	}
+
+	suite.Nil(cache.Invalidate(ctx, "https://Example.com:443/a#top"), "Invalidate should go through CacheKey")
+	suite.False(loaded("https://example.com/a"))
+	suite.True(loaded("https://example.com/b"))
+	suite.Nil(cache.Invalidate(ctx, "https://example.com/missing"), "Invalidating what isn't cached isn't an error")
+
+	removed, err := cache.InvalidateHost(ctx, "example.com")
+	suite.Nil(err)
+	suite.Equal(2, removed, "Every port of the host should be removed")
+	suite.False(loaded("https://example.com/b"))
+	suite.False(loaded("https://example.com:8443/c"))
+	suite.True(loaded("https://other.org/d"))
+
+	removed, err = cache.Sweep(ctx, stored.Add(3*time.Hour))
+	suite.Nil(err)
+	suite.Equal(0, removed, "Nothing left was stored before the cutoff")
+	removed, err = cache.Sweep(ctx, stored.Add(4*time.Hour))
+	suite.Nil(err)
+	suite.Equal(1, removed)
+	suite.False(loaded("https://other.org/d"))
+}
+
+func (suite *CacheSuite) TestMemoryInvalidation() {
+	suite.testInvalidation(NewMemoryCacheProvider())
+}
+
+func (suite *CacheSuite) TestDiskInvalidation() {
+	suite.testInvalidation(NewDiskCacheProvider(afero.NewMemMapFs(), "cache"))
+
+	removed, err := NewDiskCacheProvider(afero.NewMemMapFs(), "empty").Sweep(context.Background(), time.Now())
+	suite.Nil(err, "A cache which was never written to has nothing to sweep")
+	suite.Equal(0, removed)
+}
+
+func (suite *CacheSuite) TestGoneResponsesAreInvalidated() {
+	clock := NewManualClock(time.Date(2019, 6, 2, 12, 0, 0, 0, time.UTC))
+	cache := NewMemoryCacheProvider()
+	factory := NewFactory(clock, cache)
+	suite.harvest(factory, suite.server.URL)
+
+	suite.server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	})
+	clock.Advance(2 * time.Minute)
+	_, err := factory.PageFromURL(context.Background(), suite.server.URL)
+	suite.NotNil(err)
+	_, ok, _ := cache.LoadResponse(context.Background(), CacheKey(suite.server.URL))
+	suite.False(ok, "A page that's gone shouldn't stay cached")
+}
+
+func (suite *CacheSuite) TestCacheErrorsAreWarnings() {
+	page := suite.harvest(NewFactory(brokenCache{}), suite.server.URL)
+	suite.Equal("Netspective", siteName(page), "A broken cache shouldn't fail the fetch")
+	suite.Equal(CacheStatusMiss, page.CacheStatus)
+	var cacheWarnings []PageWarning
+	for _, warning := range page.Warnings {
+		if warning.Code == WarningCacheError {
+			cacheWarnings = append(cacheWarnings, warning)
+		}
+	}
+	suite.Equal([]PageWarning{
+		{Code: WarningCacheError, Message: "cache disk is unreadable"},
+		{Code: WarningCacheError, Message: "cache disk is full"},
+	}, cacheWarnings)
+}
+
+// tenantHeader is a request preparer which sends the tenant's secret
+type tenantHeader string
+
+func (h tenantHeader) OnPrepareHTTPRequest(ctx context.Context, client *http.Client, req *http.Request) {
+	req.Header.Set("X-Tenant-Secret", string(h))
+}
+
+func (suite *CacheSuite) TestTenantsDontShareCache() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=600")
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprintf(w, "<html><head><title>%s</title></head></html>", r.Header.Get("X-Tenant-Secret"))
+	}))
+	defer server.Close()
+	router := NewTenantRouter().
+		Tenant("a", TenantPolicy{Bundle: NewPolicyBundle("a", tenantHeader("secret of a"))}).
+		Tenant("b", TenantPolicy{Bundle: NewPolicyBundle("b", tenantHeader("secret of b"))})
+	cache := NewMemoryCacheProvider()
+	factory := NewFactory(cache, router)
+
+	title := func(tenant string) string {
+		content, err := factory.PageFromURL(ContextWithTenant(context.Background(), tenant), server.URL)
+		suite.Require().Nil(err, "Unable to harvest for tenant %q", tenant)
+		return content.(*Page).Title
+	}
+	suite.Equal("secret of a", title("a"))
+	suite.Equal("secret of b", title("b"), "A tenant mustn't be answered from another tenant's cache")
+	suite.Equal(CacheStatusHit, func() string {
+		content, _ := factory.PageFromURL(ContextWithTenant(context.Background(), "b"), server.URL)
+		return content.(*Page).CacheStatus
+	}(), "A tenant should still be answered from its own cache")
+
+	_, ok, _ := cache.LoadResponse(context.Background(), TenantCacheKey("a", server.URL))
+	suite.True(ok)
+	suite.Nil(cache.Invalidate(ContextWithTenant(context.Background(), "a"), server.URL))
+	_, ok, _ = cache.LoadResponse(context.Background(), TenantCacheKey("a", server.URL))
+	suite.False(ok, "Invalidate should remove the response of the context's tenant")
+	_, ok, _ = cache.LoadResponse(context.Background(), TenantCacheKey("b", server.URL))
+	suite.True(ok, "Other tenants' responses should be kept")
+	removed, _ := cache.InvalidateHost(context.Background(), "127.0.0.1")
+	suite.Equal(1, removed, "InvalidateHost should remove the host's responses for every tenant")
+}
+
+func (suite *CacheSuite) TestCacheKey() {
+	suite.Equal("https://example.com/", CacheKey("HTTPS://Example.COM:443"))
+	suite.Equal("http://example.com:8080/a?x=1&y=2", CacheKey("http://example.com:8080/a?y=2&x=1#top"))
+	suite.Equal("not a url", CacheKey("not a url"))
+	suite.Equal("https://example.com/a", TenantCacheKey("", "https://example.com/a#top"))
+	suite.Equal("https://example.com/a#tenant=acme+co", TenantCacheKey("acme co", "https://example.com/a#top"))
+}
+
+func (suite *CacheSuite) TestWithCacheProvider() {
+	factory, err := NewFactoryWithOptions(WithCacheProvider(NewMemoryCacheProvider()))
+	suite.Nil(err)
+	suite.NotNil(factory.CacheProvider)
+
+	_, err = NewFactoryWithOptions(WithCacheProvider(NewMemoryCacheProvider()), WithResponseArchive(NewMemoryResponseArchive()))
+	suite.NotNil(err, "A cache can't be combined with a response archive")
+}
+
+func TestCacheSuite(t *testing.T) {
+	suite.Run(t, new(CacheSuite))
+}
@@ -0,0 +1,132 @@
+package resource
+
+import (
+	"context"
+	"sync"
+)
+
+// How the CacheProvider answered a fetch, see Page.CacheStatus
+const (
+	CacheStatusHit         = "hit"         // the cached response was fresh, the server wasn't asked
+	CacheStatusMiss        = "miss"        // nothing usable was cached, the response was fetched
+	CacheStatusRevalidated = "revalidated" // the cached response was stale and the server said it hasn't changed
+	CacheStatusStale       = "stale"       // the cached response was stale and revalidating it failed, its stale-if-error allowed it anyway
+)
+
+// CacheObserver is passed into options to be told how the CacheProvider answered each fetch, e.g. to count hits and
+// misses while tuning TTLs. Observations are advisory, like events.
+type CacheObserver interface {
+	ObserveCache(ctx context.Context, urlText string, status string)
+}
+
+// CacheStats counts how the CacheProvider answered fetches
+type CacheStats struct {
+	Hits        int64 `json:"hits"`
+	Misses      int64 `json:"misses"`
+	Revalidated int64 `json:"revalidated"`
+	Stale       int64 `json:"stale"`
+}
+
+// Lookups returns the number of fetches the cache was asked about
+func (s CacheStats) Lookups() int64 {
+	return s.Hits + s.Misses + s.Revalidated + s.Stale
+}
+
+// HitRatio returns the fraction of lookups answered with a cached body (hit, revalidated or stale), 0 if there
+// haven't been any lookups
+func (s CacheStats) HitRatio() float64 {
+	if s.Lookups() == 0 {
+		return 0
+	}
+	return float64(s.Hits+s.Revalidated+s.Stale) / float64(s.Lookups())
+}
+
+// CacheCounters is a CacheObserver which counts the outcomes, safe for concurrent use
+type CacheCounters struct {
+	mu    sync.Mutex
+	stats CacheStats
+}
+
+// NewCacheCounters creates CacheCounters starting at zero
+func NewCacheCounters() *CacheCounters {
+	return new(CacheCounters)
+}
+
+// ObserveCache satisfies CacheObserver method
+func (c *CacheCounters) ObserveCache(ctx context.Context, urlText string, status string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch status {
+	case CacheStatusHit:
+		c.stats.Hits++
+	case CacheStatusMiss:
+		c.stats.Misses++
+	case CacheStatusRevalidated:
+		c.stats.Revalidated++
+	case CacheStatusStale:
+		c.stats.Stale++
+	}
+}
+
+// Stats returns the counts so far
+func (c *CacheCounters) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+type cacheLookupKey struct{}
+
+// cacheLookup is where cachedFetch records how the cache answered a PageFromURL call's fetch, for its Page. Later
+// fetches made while resolving the page (e.g. its oEmbed) don't change the status, but their cache errors are kept.
+type cacheLookup struct {
+	mu     sync.Mutex
+	status string
+	errs   []error
+}
+
+// withCacheLookup returns a context for recording how the cache answers, nil if there's no CacheProvider
+func (f *DefaultFactory) withCacheLookup(ctx context.Context) (context.Context, *cacheLookup) {
+	if f.CacheProvider == nil {
+		return ctx, nil
+	}
+	lookup := new(cacheLookup)
+	return context.WithValue(ctx, cacheLookupKey{}, lookup), lookup
+}
+
+// annotate sets the Page's CacheStatus and records the cache's errors as WarningCacheError
+func (l *cacheLookup) annotate(content Content) {
+	page, ok := content.(*Page)
+	if l == nil || !ok || page == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	page.CacheStatus = l.status
+	for _, err := range l.errs {
+		page.Warnings = append(page.Warnings, PageWarning{Code: WarningCacheError, Message: err.Error()})
+	}
+}
+
+// cacheError records an error from the CacheProvider, which doesn't fail the fetch but is reported on the Page
+func (f *DefaultFactory) cacheError(ctx context.Context, err error) {
+	if lookup, ok := ctx.Value(cacheLookupKey{}).(*cacheLookup); ok && err != nil {
+		lookup.mu.Lock()
+		lookup.errs = append(lookup.errs, err)
+		lookup.mu.Unlock()
+	}
+}
+
+// observeCache records how the cache answered the fetch of urlText and tells any CacheObserver
+func (f *DefaultFactory) observeCache(ctx context.Context, urlText string, status string) {
+	if lookup, ok := ctx.Value(cacheLookupKey{}).(*cacheLookup); ok {
+		lookup.mu.Lock()
+		if len(lookup.status) == 0 {
+			lookup.status = status
+		}
+		lookup.mu.Unlock()
+	}
+	if f.CacheObserver != nil {
+		guardPolicy("CacheObserver", func() { f.CacheObserver.ObserveCache(ctx, urlText, status) })
+	}
+}
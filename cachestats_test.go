@@ -0,0 +1,103 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CacheStatsSuite struct {
+	suite.Suite
+	server       *httptest.Server
+	cacheControl string
+	status       int
+}
+
+func (suite *CacheStatsSuite) SetupTest() {
+	suite.cacheControl = "max-age=60"
+	suite.status = http.StatusOK
+	suite.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if suite.status != http.StatusOK {
+			w.WriteHeader(suite.status)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Cache-Control", suite.cacheControl)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, testHTMLPage)
+	}))
+}
+
+func (suite *CacheStatsSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+func (suite *CacheStatsSuite) cacheStatus(factory *DefaultFactory) string {
+	content, err := factory.PageFromURL(context.Background(), suite.server.URL)
+	suite.Nil(err, "Should not get an error")
+	return content.(*Page).CacheStatus
+}
+
+func (suite *CacheStatsSuite) TestStatuses() {
+	clock := NewManualClock(time.Date(2019, 6, 2, 12, 0, 0, 0, time.UTC))
+	counters := NewCacheCounters()
+	factory := NewFactory(clock, NewMemoryCacheProvider(), counters)
+
+	suite.Equal(CacheStatusMiss, suite.cacheStatus(factory))
+	suite.Equal(CacheStatusHit, suite.cacheStatus(factory))
+	clock.Advance(2 * time.Minute)
+	suite.Equal(CacheStatusRevalidated, suite.cacheStatus(factory))
+	suite.Equal(CacheStatusHit, suite.cacheStatus(factory))
+
+	stats := counters.Stats()
+	suite.Equal(CacheStats{Hits: 2, Misses: 1, Revalidated: 1}, stats)
+	suite.Equal(int64(4), stats.Lookups())
+	suite.Equal(0.75, stats.HitRatio())
+
+	suite.Empty(suite.cacheStatus(NewFactory()), "There's no status without a cache")
+}
+
+func (suite *CacheStatsSuite) TestStaleIfError() {
+	clock := NewManualClock(time.Date(2019, 6, 2, 12, 0, 0, 0, time.UTC))
+	counters := NewCacheCounters()
+	factory := NewFactory(clock, NewMemoryCacheProvider(), counters)
+
+	suite.cacheControl = "max-age=60, stale-if-error=300"
+	suite.cacheStatus(factory)
+	suite.status = http.StatusServiceUnavailable
+	clock.Advance(2 * time.Minute)
+	suite.Equal(CacheStatusStale, suite.cacheStatus(factory), "A server error within stale-if-error should serve the stale copy")
+
+	clock.Advance(10 * time.Minute)
+	_, err := factory.PageFromURL(context.Background(), suite.server.URL)
+	suite.NotNil(err, "Past stale-if-error the server's error should be returned")
+	suite.Equal(CacheStats{Misses: 2, Stale: 1}, counters.Stats())
+}
+
+func (suite *CacheStatsSuite) TestStatusIsInEvents() {
+	events := make(ChannelEventSink, 10)
+	factory := NewFactory(NewMemoryCacheProvider(), events)
+	suite.cacheStatus(factory)
+	suite.cacheStatus(factory)
+	suite.Equal(CacheStatusMiss, (<-events).Page.CacheStatus)
+	suite.Equal(CacheStatusHit, (<-events).Page.CacheStatus)
+}
+
+func (suite *CacheStatsSuite) TestWithCacheObserver() {
+	factory, err := NewFactoryWithOptions(WithCacheProvider(NewMemoryCacheProvider()), WithCacheObserver(NewCacheCounters()))
+	suite.Nil(err)
+	suite.NotNil(factory.CacheObserver)
+}
+
+func TestCacheStatsSuite(t *testing.T) {
+	suite.Run(t, new(CacheStatsSuite))
+}
@@ -0,0 +1,103 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// Checksum is the expected digest of a download, it may be passed directly into options (e.g. from a link annotation)
+type Checksum struct {
+	Algorithm string // one of "md5", "sha-1", "sha-256", or "sha-512"
+	Value     []byte
+}
+
+// ExpectedChecksumProvider is passed into options if we want to supply the expected digest of downloads
+type ExpectedChecksumProvider interface {
+	ExpectedChecksum(context.Context, *url.URL) (Checksum, bool)
+}
+
+// checksumVerifier hashes a download so that it can be compared with its expected digest once complete
+type checksumVerifier struct {
+	expected Checksum
+	sink     *HashSink
+}
+
+// checksumVerifiers collects the expected checksums from the options and the Digest / Content-MD5 response headers
+func checksumVerifiers(ctx context.Context, creator FileAttachmentCreator, url *url.URL, resp *http.Response, options []interface{}) []checksumVerifier {
+	var checksums []Checksum
+	for _, option := range append([]interface{}{creator}, options...) {
+		if instance, ok := option.(Checksum); ok {
+			checksums = append(checksums, instance)
+		}
+		if instance, ok := option.(ExpectedChecksumProvider); ok {
+			guardPolicy("ExpectedChecksumProvider", func() {
+				if checksum, ok := instance.ExpectedChecksum(ctx, url); ok {
+					checksums = append(checksums, checksum)
+				}
+			})
+		}
+	}
+
+	// the headers describe the encoded body so they can't be verified if the transport transparently decompressed it
+	if !resp.Uncompressed {
+		checksums = append(checksums, checksumsFromHeader(resp.Header)...)
+	}
+
+	var result []checksumVerifier
+	for _, checksum := range checksums {
+		checksum.Algorithm = strings.ToLower(checksum.Algorithm)
+		if sink, ok := NewHashSink(checksum.Algorithm); ok {
+			result = append(result, checksumVerifier{expected: checksum, sink: sink})
+		}
+	}
+	return result
+}
+
+// checksumsFromHeader parses RFC 3230 Digest (e.g. "SHA-256=X48E9qOokqqrvdts8nOJRJN3OWDUoyWxBf7kbu9DBPE=") and Content-MD5 headers
+func checksumsFromHeader(header http.Header) []Checksum {
+	var result []Checksum
+	for _, digests := range header[http.CanonicalHeaderKey("Digest")] {
+		for _, digest := range strings.Split(digests, ",") {
+			parts := strings.SplitN(strings.TrimSpace(digest), "=", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			if value, err := base64.StdEncoding.DecodeString(parts[1]); err == nil {
+				result = append(result, Checksum{Algorithm: parts[0], Value: value})
+			}
+		}
+	}
+	if contentMD5 := header.Get("Content-MD5"); len(contentMD5) > 0 {
+		if value, err := base64.StdEncoding.DecodeString(strings.TrimSpace(contentMD5)); err == nil {
+			result = append(result, Checksum{Algorithm: "md5", Value: value})
+		}
+	}
+	return result
+}
+
+// verifyChecksums compares what was downloaded against each expected checksum and records the verified digests
+func verifyChecksums(url *url.URL, result *FileAttachment, verifiers []checksumVerifier) error {
+	for _, verifier := range verifiers {
+		if !bytes.Equal(verifier.sink.Sum, verifier.expected.Value) {
+			return &ChecksumMismatchError{
+				URL:       url.String(),
+				Algorithm: verifier.expected.Algorithm,
+				Expected:  hex.EncodeToString(verifier.expected.Value),
+				Actual:    verifier.sink.HexSum(),
+				Frame:     xerrors.Caller(xErrorsFrameCaller + 1),
+				Frames:    callerFrames(xErrorsFrameCaller + 1)}
+		}
+		if result.Checksums == nil {
+			result.Checksums = make(map[string]string)
+		}
+		result.Checksums[verifier.expected.Algorithm] = verifier.sink.HexSum()
+	}
+	return nil
+}
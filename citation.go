@@ -0,0 +1,130 @@
+package resource
+
+import (
+	"net/url"
+	"strings"
+	"time"
+)
+
+// CitationDateLayouts are tried, in order, for citation_publication_date and the other citation_*date tags, Highwire
+// Press asks for "2006/01/02" but publishers also use the other forms
+var CitationDateLayouts = []string{
+	"2006/01/02",
+	"2006-01-02",
+	"2006/1/2",
+	"2006/01",
+	"2006-01",
+	"2006",
+	time.RFC3339,
+}
+
+// Citation is the bibliographic record of a scholarly page, from its Highwire Press (Google Scholar) citation_* meta tags
+type Citation struct {
+	Title           string    `json:"title,omitempty"`
+	Authors         []string  `json:"authors,omitempty"` // in document order, as written (often "Last, First")
+	Institutions    []string  `json:"institutions,omitempty"`
+	Journal         string    `json:"journal,omitempty"`
+	JournalAbbrev   string    `json:"journalAbbrev,omitempty"`
+	ConferenceTitle string    `json:"conferenceTitle,omitempty"`
+	Publisher       string    `json:"publisher,omitempty"`
+	Date            string    `json:"date,omitempty"`      // the publication date as given
+	Published       time.Time `json:"published,omitempty"` // Date parsed with CitationDateLayouts, zero if it couldn't be
+	Volume          string    `json:"volume,omitempty"`
+	Issue           string    `json:"issue,omitempty"`
+	FirstPage       string    `json:"firstPage,omitempty"`
+	LastPage        string    `json:"lastPage,omitempty"`
+	DOI             string    `json:"doi,omitempty"` // without any "doi:" or https://doi.org/ prefix
+	ISSN            string    `json:"issn,omitempty"`
+	ISBN            string    `json:"isbn,omitempty"`
+	PMID            string    `json:"pmid,omitempty"`
+	ArXivID         string    `json:"arxivID,omitempty"`
+	Language        string    `json:"language,omitempty"`
+	Keywords        []string  `json:"keywords,omitempty"`
+	AbstractURL     *url.URL  `json:"abstractURL,omitempty"`
+	FullTextURL     *url.URL  `json:"fullTextURL,omitempty"`
+	PDFURL          *url.URL  `json:"pdfURL,omitempty"`
+}
+
+// CitationFromMetaTags reads the citation_* tags, relative URLs are resolved against base when it's not nil. False is
+// returned if there are no citation_* tags.
+func CitationFromMetaTags(tags MetaTags, base *url.URL) (*Citation, bool) {
+	found := false
+	for key := range tags {
+		if strings.HasPrefix(strings.ToLower(key), "citation_") {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, false
+	}
+
+	result := new(Citation)
+	result.Title = citationString(tags, "citation_title")
+	result.Authors = citationStrings(tags, "citation_author", "")
+	if len(result.Authors) == 0 {
+		// the older form lists every author in one tag
+		result.Authors = citationStrings(tags, "citation_authors", ";")
+	}
+	result.Institutions = citationStrings(tags, "citation_author_institution", "")
+	result.Journal = citationString(tags, "citation_journal_title")
+	result.JournalAbbrev = citationString(tags, "citation_journal_abbrev")
+	result.ConferenceTitle = citationString(tags, "citation_conference_title")
+	result.Publisher = citationString(tags, "citation_publisher")
+	for _, key := range []string{"citation_publication_date", "citation_date", "citation_online_date", "citation_cover_date", "citation_year"} {
+		if date := citationString(tags, key); len(date) > 0 {
+			result.Date = date
+			result.Published, _, _ = MetaTags{key: date}.GetTime(key, CitationDateLayouts...)
+			break
+		}
+	}
+	result.Volume = citationString(tags, "citation_volume")
+	result.Issue = citationString(tags, "citation_issue")
+	result.FirstPage = citationString(tags, "citation_firstpage")
+	result.LastPage = citationString(tags, "citation_lastpage")
+	result.DOI = normalizeDOI(citationString(tags, "citation_doi"))
+	result.ISSN = citationString(tags, "citation_issn")
+	result.ISBN = citationString(tags, "citation_isbn")
+	result.PMID = citationString(tags, "citation_pmid")
+	result.ArXivID = citationString(tags, "citation_arxiv_id")
+	result.Language = citationString(tags, "citation_language")
+	result.Keywords = citationStrings(tags, "citation_keywords", ";")
+	result.AbstractURL, _, _ = tags.GetURL("citation_abstract_html_url", base)
+	result.FullTextURL, _, _ = tags.GetURL("citation_fulltext_html_url", base)
+	result.PDFURL, _, _ = tags.GetURL("citation_pdf_url", base)
+	return result, true
+}
+
+func citationString(tags MetaTags, key string) string {
+	text, _ := tags.GetString(key)
+	return strings.TrimSpace(text)
+}
+
+// citationStrings returns every value of a repeated tag, each also split by separator if it's not blank
+func citationStrings(tags MetaTags, key string, separator string) []string {
+	values, _ := tags.Values(key)
+	var result []string
+	for _, value := range values {
+		text, _ := value.(string)
+		parts := []string{text}
+		if len(separator) > 0 {
+			parts = strings.Split(text, separator)
+		}
+		for _, part := range parts {
+			if part = strings.TrimSpace(part); len(part) > 0 {
+				result = append(result, part)
+			}
+		}
+	}
+	return result
+}
+
+// normalizeDOI strips the prefixes a DOI is commonly written with
+func normalizeDOI(doi string) string {
+	for _, prefix := range []string{"https://doi.org/", "http://doi.org/", "https://dx.doi.org/", "http://dx.doi.org/", "doi:"} {
+		if len(doi) >= len(prefix) && strings.EqualFold(doi[:len(prefix)], prefix) {
+			return strings.TrimSpace(doi[len(prefix):])
+		}
+	}
+	return doi
+}
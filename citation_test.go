@@ -0,0 +1,77 @@
+package resource
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CitationSuite struct {
+	suite.Suite
+}
+
+func (suite *CitationSuite) TestCitationMetaTags() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://journal.example.com/article/5678", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, `<html><head>
+		<meta name="citation_title" content="Attention Is All You Need">
+		<meta name="citation_author" content="Vaswani, Ashish">
+		<meta name="citation_author_institution" content="Google Brain">
+		<meta name="citation_author" content="Shazeer, Noam">
+		<meta name="citation_journal_title" content="Journal of Examples">
+		<meta name="citation_publisher" content="Example Press">
+		<meta name="citation_publication_date" content="2017/06/12">
+		<meta name="citation_volume" content="30">
+		<meta name="citation_issue" content="2">
+		<meta name="citation_firstpage" content="5998">
+		<meta name="citation_lastpage" content="6008">
+		<meta name="citation_doi" content="doi:10.1234/example.5678">
+		<meta name="citation_issn" content="1234-5678">
+		<meta name="citation_keywords" content="transformers; attention">
+		<meta name="citation_pdf_url" content="/article/5678.pdf">
+	</head></html>`))
+
+	content, err := NewFactory(archive).PageFromURL(context.Background(), "https://journal.example.com/article/5678")
+	suite.Nil(err, "Should not get an error")
+	citation := content.(*Page).Citation
+	suite.Require().NotNil(citation)
+	suite.Equal("Attention Is All You Need", citation.Title)
+	suite.Equal([]string{"Vaswani, Ashish", "Shazeer, Noam"}, citation.Authors)
+	suite.Equal([]string{"Google Brain"}, citation.Institutions)
+	suite.Equal("Journal of Examples", citation.Journal)
+	suite.Equal("Example Press", citation.Publisher)
+	suite.Equal("2017/06/12", citation.Date)
+	suite.Equal(time.Date(2017, 6, 12, 0, 0, 0, 0, time.UTC), citation.Published)
+	suite.Equal("30", citation.Volume)
+	suite.Equal("2", citation.Issue)
+	suite.Equal("5998", citation.FirstPage)
+	suite.Equal("6008", citation.LastPage)
+	suite.Equal("10.1234/example.5678", citation.DOI, "The doi: prefix should be removed")
+	suite.Equal("1234-5678", citation.ISSN)
+	suite.Equal([]string{"transformers", "attention"}, citation.Keywords)
+	suite.Require().NotNil(citation.PDFURL)
+	suite.Equal("https://journal.example.com/article/5678.pdf", citation.PDFURL.String(), "Relative URLs should be resolved")
+}
+
+func (suite *CitationSuite) TestCitationAlternateForms() {
+	citation, ok := CitationFromMetaTags(MetaTags{
+		"citation_authors":          "Vaswani, Ashish; Shazeer, Noam",
+		"citation_conference_title": "NeurIPS",
+		"citation_date":             "2017",
+		"citation_doi":              "https://doi.org/10.1234/example.5678",
+	}, nil)
+	suite.True(ok)
+	suite.Equal([]string{"Vaswani, Ashish", "Shazeer, Noam"}, citation.Authors)
+	suite.Equal("NeurIPS", citation.ConferenceTitle)
+	suite.Equal(2017, citation.Published.Year())
+	suite.Equal("10.1234/example.5678", citation.DOI)
+
+	_, ok = CitationFromMetaTags(MetaTags{"og:title": "Not scholarly"}, nil)
+	suite.False(ok, "Pages without citation_* tags have no citation")
+}
+
+func TestCitationSuite(t *testing.T) {
+	suite.Run(t, new(CitationSuite))
+}
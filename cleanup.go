@@ -0,0 +1,128 @@
+package resource
+
+import (
+	"os"
+	"sync"
+
+	"github.com/spf13/afero"
+	"golang.org/x/xerrors"
+)
+
+// TrackedFile is a file a download created, as reported by a CleanupManager
+type TrackedFile struct {
+	FS       afero.Fs `json:"-"`
+	Path     string   `json:"path"`
+	URL      string   `json:"url"`
+	Complete bool     `json:"complete"`         // true once the download succeeded, complete files are kept by Cleanup
+	Reason   string   `json:"reason,omitempty"` // why the file was removed, for files returned by Removed
+}
+
+// CleanupManager is passed into options to keep track of the files downloads create. A failed download's file is
+// removed as soon as it fails, Cleanup removes the files of downloads which never finished (e.g. a panicking sink)
+// and is called by PagesFromURLs once a batch is complete, and RemoveAll removes everything at shutdown. Removed
+// tells the caller which files were removed and why.
+type CleanupManager struct {
+	mutex   sync.Mutex
+	files   []*TrackedFile
+	removed []TrackedFile
+}
+
+// NewCleanupManager creates a manager which isn't tracking any files yet
+func NewCleanupManager() *CleanupManager {
+	return new(CleanupManager)
+}
+
+// Track starts tracking a file, downloads track the files they create so there's no need to call it for those
+func (m *CleanupManager) Track(fs afero.Fs, path string, url string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.files = append(m.files, &TrackedFile{FS: fs, Path: path, URL: url})
+}
+
+// Files returns the files being tracked
+func (m *CleanupManager) Files() []TrackedFile {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	result := make([]TrackedFile, 0, len(m.files))
+	for _, file := range m.files {
+		result = append(result, *file)
+	}
+	return result
+}
+
+// Removed returns the files which were removed, with the reason for each
+func (m *CleanupManager) Removed() []TrackedFile {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return append([]TrackedFile(nil), m.removed...)
+}
+
+// Cleanup removes the files of downloads which didn't complete, it returns the first error removing them
+func (m *CleanupManager) Cleanup() error {
+	return m.remove(func(file *TrackedFile) bool { return !file.Complete }, "incomplete")
+}
+
+// RemoveAll removes every tracked file, complete or not, it returns the first error removing them
+func (m *CleanupManager) RemoveAll() error {
+	return m.remove(func(file *TrackedFile) bool { return true }, "removed at shutdown")
+}
+
+func (m *CleanupManager) remove(selected func(*TrackedFile) bool, reason string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	var result error
+	kept := m.files[:0]
+	for _, file := range m.files {
+		if !selected(file) {
+			kept = append(kept, file)
+			continue
+		}
+		if err := removeTrackedFile(file.FS, file.Path); err != nil && result == nil {
+			result = xerrors.Errorf("Unable to remove %q in resource.CleanupManager: %w", file.Path, err)
+		}
+		file.Reason = reason
+		m.removed = append(m.removed, *file)
+	}
+	m.files = kept
+	return result
+}
+
+// finishDownload marks the file created at createdPath as complete, now at result.DestPath, along with the files
+// derived from it, or removes it if the download failed. Only the paths the download itself created are removed, the
+// path a FileAttachmentFinalizer returns may be a file shared with other downloads (e.g. a content address).
+func (m *CleanupManager) finishDownload(fs afero.Fs, createdPath string, result *FileAttachment, err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for index, file := range m.files {
+		if file.FS != fs || file.Path != createdPath {
+			continue
+		}
+		if err == nil {
+			file.Path = result.DestPath
+			file.Complete = true
+			for _, derived := range result.derivedFiles() {
+				m.files = append(m.files, &TrackedFile{FS: derived.DestFS, Path: derived.DestPath, URL: file.URL, Complete: true})
+			}
+			return
+		}
+		removeTrackedFile(fs, createdPath)
+		if len(result.ownedPath) > 0 && result.ownedPath != createdPath {
+			// the file was renamed before the download failed
+			removeTrackedFile(fs, result.ownedPath)
+		}
+		if len(result.ownedPath) > 0 {
+			file.Path = result.ownedPath
+		}
+		file.Reason = err.Error()
+		m.removed = append(m.removed, *file)
+		m.files = append(m.files[:index], m.files[index+1:]...)
+		return
+	}
+}
+
+func removeTrackedFile(fs afero.Fs, path string) error {
+	if err := fs.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
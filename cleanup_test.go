@@ -0,0 +1,139 @@
+package resource
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/suite"
+)
+
+type CleanupSuite struct {
+	suite.Suite
+	creator *FileSystemAttachmentCreator
+	manager *CleanupManager
+}
+
+func (suite *CleanupSuite) SetupTest() {
+	suite.creator = NewMemoryAttachmentCreator(nil)
+	suite.manager = NewCleanupManager()
+}
+
+func (suite *CleanupSuite) download(contentLength int64, options ...interface{}) (bool, Attachment, error) {
+	u, _ := url.Parse("http://ceur-ws.org/Vol-1401/paper-05.pdf")
+	t, _ := NewPageType("application/pdf")
+	resp := &http.Response{Header: make(http.Header), Body: ioutil.NopCloser(strings.NewReader(testPDFContent)), ContentLength: contentLength}
+	return DownloadFileFromHTTPResp(context.Background(), suite.creator, u, resp, t, append(options, suite.manager)...)
+}
+
+func (suite *CleanupSuite) exists(path string) bool {
+	exists, _ := afero.Exists(suite.creator.FS, path)
+	return exists
+}
+
+func (suite *CleanupSuite) TestFailedDownloadsAreRemoved() {
+	ok, _, err := suite.download(int64(len(testPDFContent) + 100))
+	suite.False(ok)
+	suite.NotNil(err, "Truncated download should fail")
+	suite.Empty(suite.manager.Files())
+
+	removed := suite.manager.Removed()
+	suite.Len(removed, 1, "The caller should learn about the removed file")
+	suite.False(suite.exists(removed[0].Path), "The partial file should be removed")
+	suite.Equal("http://ceur-ws.org/Vol-1401/paper-05.pdf", removed[0].URL)
+	suite.Contains(removed[0].Reason, "LECTIORES-302")
+
+	wrong := sha256.Sum256([]byte("something else"))
+	_, _, err = suite.download(-1, Checksum{Algorithm: "sha-256", Value: wrong[:]})
+	suite.NotNil(err, "Checksum mismatch should fail")
+	removed = suite.manager.Removed()
+	suite.Len(removed, 2)
+	suite.False(suite.exists(removed[1].Path), "Files failing verification should be removed")
+}
+
+func (suite *CleanupSuite) TestSharedFilesAreKept() {
+	creator := NewContentAddressableAttachmentCreator(afero.NewMemMapFs(), "cas")
+	u, _ := url.Parse("http://ceur-ws.org/Vol-1401/paper-05.pdf")
+	t, _ := NewPageType("application/pdf")
+	download := func(options ...interface{}) (Attachment, error) {
+		resp := &http.Response{Header: make(http.Header), Body: ioutil.NopCloser(strings.NewReader(testPDFContent)), ContentLength: -1}
+		_, attachment, err := DownloadFileFromHTTPResp(context.Background(), creator, u, resp, t, append(options, suite.manager)...)
+		return attachment, err
+	}
+
+	first, err := download()
+	suite.Require().Nil(err, "Should not get an error")
+	shared := first.(*FileAttachment).DestPath
+
+	wrong := sha256.Sum256([]byte("something else"))
+	_, err = download(Checksum{Algorithm: "sha-256", Value: wrong[:]})
+	suite.NotNil(err, "Checksum mismatch should fail")
+	exists, _ := afero.Exists(creator.FS, shared)
+	suite.True(exists, "A failed duplicate mustn't remove the file it shares with an earlier download")
+	removed := suite.manager.Removed()
+	suite.Require().Len(removed, 1)
+	suite.NotEqual(shared, removed[0].Path, "The failed download's own file should be reported")
+}
+
+func (suite *CleanupSuite) TestCompleteDownloadsAreKept() {
+	ok, attachment, err := suite.download(-1)
+	suite.True(ok)
+	suite.Nil(err, "Should not get an error")
+
+	files := suite.manager.Files()
+	suite.Len(files, 1)
+	suite.True(files[0].Complete)
+	suite.Equal(attachment.(*FileAttachment).DestPath, files[0].Path, "The file should be tracked at its final path")
+
+	suite.Nil(suite.manager.Cleanup())
+	suite.True(suite.exists(files[0].Path), "Cleanup should keep complete downloads")
+
+	suite.Nil(suite.manager.RemoveAll())
+	suite.False(suite.exists(files[0].Path), "RemoveAll should remove every download")
+	suite.Equal("removed at shutdown", suite.manager.Removed()[0].Reason)
+}
+
+func (suite *CleanupSuite) TestOrphansAreRemovedAfterBatch() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("http://ceur-ws.org/Vol-1401/paper-05.pdf", archivedResponse(200, http.Header{"Content-Type": {"application/pdf"}}, testPDFContent))
+	afero.WriteFile(suite.creator.FS, "/orphan.pdf", []byte("partial"), 0644)
+	suite.manager.Track(suite.creator.FS, "/orphan.pdf", "http://ceur-ws.org/orphan.pdf")
+
+	factory := NewFactory(archive, suite.creator, suite.manager)
+	results := factory.PagesFromURLs(context.Background(), []string{"http://ceur-ws.org/Vol-1401/paper-05.pdf"})
+	suite.Nil(results[0].Err, "Should not get an error")
+
+	files := suite.manager.Files()
+	suite.Len(files, 1)
+	suite.True(suite.exists(files[0].Path), "The completed download should be kept")
+	suite.False(suite.exists("/orphan.pdf"), "The orphan should be removed")
+	removed := suite.manager.Removed()
+	suite.Len(removed, 1)
+	suite.Equal("/orphan.pdf", removed[0].Path)
+	suite.Equal("incomplete", removed[0].Reason)
+}
+
+type failingRemoveFs struct {
+	afero.Fs
+}
+
+func (fs failingRemoveFs) Remove(name string) error {
+	return errors.New("read-only")
+}
+
+func (suite *CleanupSuite) TestRemoveErrors() {
+	suite.manager.Track(failingRemoveFs{afero.NewMemMapFs()}, "/partial.pdf", "http://ceur-ws.org/partial.pdf")
+	err := suite.manager.Cleanup()
+	suite.NotNil(err, "Failing to remove a file should be reported")
+	suite.Contains(err.Error(), "/partial.pdf")
+}
+
+func TestCleanupSuite(t *testing.T) {
+	suite.Run(t, new(CleanupSuite))
+}
@@ -0,0 +1,146 @@
+package resource
+
+import (
+	crand "crypto/rand"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is passed into options to replace the system clock in fetch timings, hedging, host profiles, and audit
+// records, so that time-dependent behavior can be tested without sleeping
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is the part of time.Timer that a Clock has to provide
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+// RandomSource is passed into options to replace the randomness of the factory's retry jitter, it's also what a
+// WARCWriter's record IDs come from. *math/rand.Rand satisfies it so a seeded source makes the results repeatable.
+type RandomSource interface {
+	Int63n(n int64) int64
+	Read(p []byte) (int, error)
+}
+
+// SystemClock is the Clock used when none is passed into options
+type SystemClock struct{}
+
+// Now satisfies Clock method
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// NewTimer satisfies Clock method
+func (SystemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+type systemTimer struct {
+	*time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+// SystemRandom is the RandomSource used when none is passed into options, identifiers come from crypto/rand
+type SystemRandom struct{}
+
+// Int63n satisfies RandomSource method
+func (SystemRandom) Int63n(n int64) int64 {
+	return rand.Int63n(n)
+}
+
+// Read satisfies RandomSource method
+func (SystemRandom) Read(p []byte) (int, error) {
+	return crand.Read(p)
+}
+
+// ManualClock is a Clock which only moves when told to, timers fire as Advance or Set passes their deadline
+type ManualClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*manualTimer
+}
+
+// NewManualClock creates a ManualClock stopped at start
+func NewManualClock(start time.Time) *ManualClock {
+	return &ManualClock{now: start}
+}
+
+// Now satisfies Clock method
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTimer satisfies Clock method, a timer for zero or less fires immediately
+func (c *ManualClock) NewTimer(d time.Duration) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &manualTimer{clock: c, deadline: c.now.Add(d), c: make(chan time.Time, 1)}
+	if d <= 0 {
+		t.c <- c.now
+		return t
+	}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Advance moves the clock forward by d
+func (c *ManualClock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set moves the clock to now, firing (in deadline order) the timers which are due
+func (c *ManualClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+	sort.SliceStable(c.timers, func(i, j int) bool { return c.timers[i].deadline.Before(c.timers[j].deadline) })
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		if t.deadline.After(now) {
+			pending = append(pending, t)
+			continue
+		}
+		t.c <- now
+	}
+	c.timers = pending
+}
+
+// Timers returns the number of timers waiting to fire, so that a test can wait for code to start waiting
+func (c *ManualClock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+type manualTimer struct {
+	clock    *ManualClock
+	deadline time.Time
+	c        chan time.Time
+}
+
+func (t *manualTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *manualTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	for i, pending := range t.clock.timers {
+		if pending == t {
+			t.clock.timers = append(t.clock.timers[:i], t.clock.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ClockSuite struct {
+	suite.Suite
+}
+
+func (suite *ClockSuite) TestManualClockTimers() {
+	start := time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)
+	clock := NewManualClock(start)
+	later := clock.NewTimer(time.Minute)
+	sooner := clock.NewTimer(time.Second)
+	stopped := clock.NewTimer(time.Second)
+	suite.True(stopped.Stop(), "Pending timer should stop")
+	suite.Equal(2, clock.Timers())
+
+	clock.Advance(time.Second)
+	suite.Equal(start.Add(time.Second), clock.Now())
+	suite.Equal(start.Add(time.Second), <-sooner.C())
+	select {
+	case <-later.C():
+		suite.Fail("Timer should not fire before its deadline")
+	default:
+	}
+
+	clock.Advance(time.Minute)
+	<-later.C()
+	suite.Equal(0, clock.Timers())
+	suite.False(later.Stop(), "Fired timer should not stop")
+}
+
+func (suite *ClockSuite) TestHedgingWithoutSleeping() {
+	var requests int32
+	stalled := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			close(stalled)
+			<-r.Context().Done()
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, testHTMLPage)
+	}))
+	defer server.Close()
+
+	clock := NewManualClock(time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC))
+	go func() {
+		<-stalled
+		for clock.Timers() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		clock.Advance(time.Hour)
+	}()
+
+	content, err := NewFactory(clock, HedgeAfter(time.Hour)).PageFromURL(context.Background(), server.URL)
+	suite.Nil(err, "Should not get an error")
+	value, _, _ := content.MetaTag("og:site_name")
+	suite.Equal("Netspective", value)
+	suite.Equal(int32(2), atomic.LoadInt32(&requests))
+}
+
+func (suite *ClockSuite) TestDeterministicWARCRecords() {
+	record := func() string {
+		var warc bytes.Buffer
+		writer := NewWARCWriter(&warc)
+		writer.Clock = NewManualClock(time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC))
+		writer.Random = rand.New(rand.NewSource(42))
+		resp := archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, testHTMLPage)
+		resp.Request, _ = http.NewRequest(http.MethodGet, "http://example.com/", nil)
+		suite.Nil(writer.RecordResponse(context.Background(), resp, []byte(testHTMLPage), true))
+		return warc.String()
+	}
+
+	first := record()
+	suite.True(strings.Contains(first, "WARC-Date: 2019-10-01T12:00:00Z"))
+	suite.Equal(first, record(), "Seeded randomness should repeat the record ID")
+}
+
+func TestClockSuite(t *testing.T) {
+	suite.Run(t, new(ClockSuite))
+}
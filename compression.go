@@ -0,0 +1,128 @@
+package resource
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+
+	"golang.org/x/xerrors"
+)
+
+// BodyCodec compresses the bodies of pages kept by a CompressedContentStore, implement it to use another algorithm
+// such as zstd
+type BodyCodec interface {
+	Encoding() string // stored in Page.BodyEncoding, e.g. "gzip"
+	Encode(body []byte) ([]byte, error)
+	Decode(encoded []byte) ([]byte, error)
+}
+
+// GzipBodyCodec is a BodyCodec which gzips bodies at Level, gzip.DefaultCompression if it's zero
+type GzipBodyCodec struct {
+	Level int
+}
+
+// Encoding satisfies BodyCodec method
+func (c GzipBodyCodec) Encoding() string {
+	return "gzip"
+}
+
+// Encode satisfies BodyCodec method
+func (c GzipBodyCodec) Encode(body []byte) ([]byte, error) {
+	level := c.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	var result bytes.Buffer
+	writer, err := gzip.NewWriterLevel(&result, level)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.Write(body); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return result.Bytes(), nil
+}
+
+// Decode satisfies BodyCodec method
+func (c GzipBodyCodec) Decode(encoded []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(encoded))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return ioutil.ReadAll(reader)
+}
+
+// CompressedContentStore is a ContentStore which compresses the retained Body of pages (see RetainBodyPolicy) before
+// they're stored in Store, and decompresses them again when they're loaded, so large harvests take less space.
+// Pages stored without a Body, or before compression was turned on, are passed through as they are.
+type CompressedContentStore struct {
+	Store ContentStore
+	Codec BodyCodec
+}
+
+// NewCompressedContentStore wraps store, a nil codec means GzipBodyCodec
+func NewCompressedContentStore(store ContentStore, codec BodyCodec) *CompressedContentStore {
+	if codec == nil {
+		codec = GzipBodyCodec{}
+	}
+	return &CompressedContentStore{Store: store, Codec: codec}
+}
+
+// StorePage satisfies ContentStore method, the page passed in isn't changed
+func (s *CompressedContentStore) StorePage(ctx context.Context, page *Page) error {
+	if len(page.Body) == 0 || len(page.BodyEncoding) > 0 {
+		return s.Store.StorePage(ctx, page)
+	}
+	encoded, err := s.Codec.Encode(page.Body)
+	if err != nil {
+		return xerrors.Errorf("Unable to compress the body of %q in resource.CompressedContentStore: %w", page.TargetURLText(), err)
+	}
+	compressed := *page
+	compressed.Body = encoded
+	compressed.BodyEncoding = s.Codec.Encoding()
+	return s.Store.StorePage(ctx, &compressed)
+}
+
+// LoadPage satisfies ContentStore method
+func (s *CompressedContentStore) LoadPage(ctx context.Context, urlText string) (*Page, bool, error) {
+	page, ok, err := s.Store.LoadPage(ctx, urlText)
+	if !ok || err != nil {
+		return page, ok, err
+	}
+	page, err = s.decompressed(page)
+	return page, err == nil, err
+}
+
+// WalkPages satisfies ContentStore method
+func (s *CompressedContentStore) WalkPages(ctx context.Context, fn func(*Page) error) error {
+	return s.Store.WalkPages(ctx, func(page *Page) error {
+		page, err := s.decompressed(page)
+		if err != nil {
+			return err
+		}
+		return fn(page)
+	})
+}
+
+// decompressed returns a copy of page with its Body decoded, leaving what's in the store compressed
+func (s *CompressedContentStore) decompressed(page *Page) (*Page, error) {
+	if len(page.BodyEncoding) == 0 {
+		return page, nil
+	}
+	if page.BodyEncoding != s.Codec.Encoding() {
+		return nil, xerrors.Errorf("Body of %q is %s encoded but resource.CompressedContentStore decodes %s", page.TargetURLText(), page.BodyEncoding, s.Codec.Encoding())
+	}
+	body, err := s.Codec.Decode(page.Body)
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to decompress the body of %q in resource.CompressedContentStore: %w", page.TargetURLText(), err)
+	}
+	result := *page
+	result.Body = body
+	result.BodyEncoding = ""
+	return &result, nil
+}
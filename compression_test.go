@@ -0,0 +1,88 @@
+package resource
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type upperBodyCodec struct{}
+
+func (upperBodyCodec) Encoding() string { return "upper" }
+func (upperBodyCodec) Encode(body []byte) ([]byte, error) {
+	return []byte(strings.ToUpper(string(body))), nil
+}
+func (upperBodyCodec) Decode(encoded []byte) ([]byte, error) {
+	return []byte(strings.ToLower(string(encoded))), nil
+}
+
+type CompressionSuite struct {
+	suite.Suite
+	memory *MemoryContentStore
+	store  *CompressedContentStore
+	page   *Page
+}
+
+func (suite *CompressionSuite) SetupTest() {
+	archive := NewMemoryResponseArchive()
+	body := testHTMLPage + strings.Repeat("<p>The same paragraph, again and again.</p>\n", 200)
+	archive.Add("https://www.netspective.com/", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, body))
+	content, err := NewFactory(archive, RetainBody(true)).PageFromURL(context.Background(), "https://www.netspective.com/")
+	suite.Require().Nil(err, "Should not get an error")
+	suite.page = content.(*Page)
+	suite.memory = NewMemoryContentStore()
+	suite.store = NewCompressedContentStore(suite.memory, nil)
+}
+
+func (suite *CompressionSuite) TestBodiesAreStoredCompressed() {
+	body := string(suite.page.Body)
+	suite.Nil(suite.store.StorePage(context.Background(), suite.page))
+	suite.Equal(body, string(suite.page.Body), "The page passed in shouldn't change")
+
+	stored, _, _ := suite.memory.LoadPage(context.Background(), "https://www.netspective.com/")
+	suite.Equal("gzip", stored.BodyEncoding)
+	suite.True(len(stored.Body) < len(body)/4, "The body should be compressed")
+
+	loaded, ok, err := suite.store.LoadPage(context.Background(), "https://www.netspective.com/")
+	suite.True(ok)
+	suite.Nil(err, "Should not get an error")
+	suite.Equal(body, string(loaded.Body), "Bodies should be decompressed transparently")
+	suite.Empty(loaded.BodyEncoding)
+	suite.Equal("gzip", stored.BodyEncoding, "What's in the store should stay compressed")
+
+	var walked []string
+	suite.Nil(suite.store.WalkPages(context.Background(), func(page *Page) error {
+		walked = append(walked, string(page.Body))
+		return nil
+	}))
+	suite.Equal([]string{body}, walked)
+}
+
+func (suite *CompressionSuite) TestPagesWithoutBodies() {
+	page := &Page{TargetURL: suite.page.TargetURL}
+	suite.Nil(suite.store.StorePage(context.Background(), page))
+	stored, _, _ := suite.memory.LoadPage(context.Background(), "https://www.netspective.com/")
+	suite.Equal(page, stored, "Pages without a body should be stored as they are")
+
+	_, ok, err := suite.store.LoadPage(context.Background(), "https://www.netspective.com/missing")
+	suite.False(ok)
+	suite.Nil(err)
+}
+
+func (suite *CompressionSuite) TestCodecs() {
+	upper := NewCompressedContentStore(suite.memory, upperBodyCodec{})
+	suite.Nil(upper.StorePage(context.Background(), suite.page))
+	stored, _, _ := suite.memory.LoadPage(context.Background(), "https://www.netspective.com/")
+	suite.Equal("upper", stored.BodyEncoding)
+
+	_, ok, err := suite.store.LoadPage(context.Background(), "https://www.netspective.com/")
+	suite.False(ok)
+	suite.Contains(err.Error(), "is upper encoded", "Bodies in another encoding can't be decoded")
+}
+
+func TestCompressionSuite(t *testing.T) {
+	suite.Run(t, new(CompressionSuite))
+}
@@ -0,0 +1,161 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/xerrors"
+)
+
+// Validators are the ETag and Last-Modified of a page harvested before. Passed into PageFromURL's options they make
+// it a conditional GET (If-None-Match and If-Modified-Since), and if the page hasn't changed PageFromURL returns a
+// Page with NotModified set instead of an error.
+type Validators struct {
+	ETag         string
+	LastModified string // as the server sent it
+}
+
+// ValidatorsOf returns the validators of a page
+func ValidatorsOf(page *Page) Validators {
+	return Validators{ETag: page.ETag, LastModified: page.LastModified}
+}
+
+// IsZero returns true if there are no validators, so the request wouldn't be conditional
+func (v Validators) IsZero() bool {
+	return len(v.ETag) == 0 && len(v.LastModified) == 0
+}
+
+// header returns the conditional request headers, nil if there are no validators
+func (v Validators) header() http.Header {
+	if v.IsZero() {
+		return nil
+	}
+	result := make(http.Header)
+	if len(v.ETag) > 0 {
+		result.Set("If-None-Match", v.ETag)
+	}
+	if len(v.LastModified) > 0 {
+		result.Set("If-Modified-Since", v.LastModified)
+	}
+	return result
+}
+
+// ValidatorProvider is passed into options to supply the validators of URLs harvested before, e.g. from a cache, so
+// that PageFromURL makes conditional GETs without each caller passing Validators. Validators passed to PageFromURL
+// take precedence. An error doesn't fail the fetch, which is made unconditionally and recorded as a
+// WarningValidatorsError.
+type ValidatorProvider interface {
+	Validators(ctx context.Context, urlText string) (Validators, bool, error)
+}
+
+// StoreValidators is a ValidatorProvider which uses the validators of the pages in a ContentStore
+type StoreValidators struct {
+	Store ContentStore
+}
+
+// Validators satisfies ValidatorProvider method
+func (s StoreValidators) Validators(ctx context.Context, urlText string) (Validators, bool, error) {
+	page, ok, err := s.Store.LoadPage(ctx, urlText)
+	if err != nil || !ok || page == nil {
+		return Validators{}, false, err
+	}
+	return ValidatorsOf(page), true, nil
+}
+
+// IsNotModified returns true if content is the result of a conditional GET for a page which hasn't changed
+func IsNotModified(content Content) bool {
+	page, ok := content.(*Page)
+	return ok && page != nil && page.NotModified
+}
+
+// validators returns the validators for a conditional GET of urlText, from options or the ValidatorProvider
+func (f *DefaultFactory) validators(ctx context.Context, urlText string, options []interface{}) (_ Validators, err error) {
+	if validators, ok := OptionOf[Validators](options); ok {
+		return validators, nil
+	}
+	provider, ok := OptionOf[ValidatorProvider](f.downloadOptions(options))
+	if !ok {
+		return Validators{}, nil
+	}
+	var validators Validators
+	guardPolicy("ValidatorProvider", func() { validators, _, err = provider.Validators(ctx, urlText) })
+	if err != nil {
+		return Validators{}, err
+	}
+	return validators, nil
+}
+
+// warnValidatorsError records on the page that the ValidatorProvider failed
+func warnValidatorsError(content Content, err error) {
+	if page, ok := content.(*Page); ok && page != nil && err != nil {
+		page.Warnings = append(page.Warnings, PageWarning{Code: WarningValidatorsError, Message: err.Error()})
+	}
+}
+
+// validatorsFailed returns err, wrapped in a ValidatorsFailedError if the ValidatorProvider failed and there's no
+// page to warn about it on
+func validatorsFailed(content Content, err error, validatorsErr error) error {
+	if page, ok := content.(*Page); err == nil || validatorsErr == nil || (ok && page != nil) {
+		return err
+	}
+	return &ValidatorsFailedError{Err: err, ValidatorsErr: validatorsErr}
+}
+
+// ValidatorsFailedError is returned by PageFromURL when the ValidatorProvider failed and so did the fetch, Err is the
+// cause of the failure. When the fetch returns a Page, ValidatorsErr is a WarningValidatorsError on the Page instead.
+type ValidatorsFailedError struct {
+	Err           error
+	ValidatorsErr error
+}
+
+// FormatError will print the ValidatorProvider's error followed by the cause
+func (e ValidatorsFailedError) FormatError(p xerrors.Printer) error {
+	p.Printf("[ValidatorProvider failed: %v]", e.ValidatorsErr)
+	return e.Err
+}
+
+// Format provide backwards compatibility with pre-xerrors package
+func (e ValidatorsFailedError) Format(f fmt.State, c rune) {
+	xerrors.FormatError(e, f, c)
+}
+
+// Error returns the ValidatorProvider's error followed by the cause
+func (e ValidatorsFailedError) Error() string {
+	return fmt.Sprint(e)
+}
+
+// Unwrap returns the cause
+func (e ValidatorsFailedError) Unwrap() error {
+	return e.Err
+}
+
+// notModifiedPage returns the page for a 304 answer to a conditional GET, false if err isn't one. The page only has
+// its URL, validators and expiry, and the Annotations passed in with it.
+func (f *DefaultFactory) notModifiedPage(ctx context.Context, urlText string, validators Validators, err error, options []interface{}) (*Page, bool) {
+	var statusErr *InvalidHTTPRespStatusCodeError
+	if validators.IsZero() || !xerrors.As(err, &statusErr) || statusErr.HTTPStatusCode != http.StatusNotModified {
+		return nil, false
+	}
+	target, parseErr := url.Parse(urlText)
+	if parseErr != nil {
+		return nil, false
+	}
+
+	result := new(Page)
+	result.MetaPropertyTags = make(map[string]interface{})
+	result.TargetURL = target
+	result.NotModified = true
+	result.Annotations = annotationsFrom(options)
+	result.Expires = f.contentExpiry(ctx, target, &http.Response{Header: statusErr.Header}, nil)
+	result.ETag, result.LastModified = validators.ETag, validators.LastModified
+	if etag := statusErr.Header.Get("ETag"); len(etag) > 0 {
+		result.ETag = etag
+	}
+	if lastModified := statusErr.Header.Get("Last-Modified"); len(lastModified) > 0 {
+		result.LastModified = lastModified
+	}
+	result.valid = true
+	return result, true
+}
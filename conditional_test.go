@@ -0,0 +1,140 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/xerrors"
+)
+
+const testLastModified = "Sat, 01 Jun 2019 12:00:00 GMT"
+
+type failingValidators struct{}
+
+func (failingValidators) Validators(ctx context.Context, urlText string) (Validators, bool, error) {
+	return Validators{}, false, errors.New("cache is down")
+}
+
+type ConditionalSuite struct {
+	suite.Suite
+	server *httptest.Server
+	header http.Header // of the last request
+}
+
+func (suite *ConditionalSuite) SetupTest() {
+	suite.header = nil
+	suite.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		suite.header = r.Header.Clone()
+		w.Header().Set("ETag", `"v2"`)
+		w.Header().Set("Last-Modified", testLastModified)
+		w.Header().Set("Cache-Control", "max-age=3600")
+		if r.Header.Get("If-None-Match") == `"v2"` || r.Header.Get("If-Modified-Since") == testLastModified {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, testHTMLPage)
+	}))
+}
+
+func (suite *ConditionalSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+func (suite *ConditionalSuite) TestNotModified() {
+	clock := NewManualClock(time.Date(2019, 6, 2, 12, 0, 0, 0, time.UTC))
+	content, err := NewFactory(clock).PageFromURL(context.Background(), suite.server.URL, Validators{ETag: `"v2"`}, Annotations{"collection": "guides"})
+	suite.Nil(err, "A 304 shouldn't be an error")
+	suite.True(IsNotModified(content))
+	suite.Equal(`"v2"`, suite.header.Get("If-None-Match"))
+	suite.Empty(suite.header.Get("If-Modified-Since"))
+
+	page := content.(*Page)
+	suite.True(page.IsValid())
+	suite.Equal(suite.server.URL, page.TargetURLText())
+	suite.Equal(`"v2"`, page.ETag)
+	suite.Equal(testLastModified, page.LastModified, "Validators should be updated from the response")
+	suite.Equal(clock.Now().Add(time.Hour), page.Expires)
+	suite.Equal(Annotations{"collection": "guides"}, page.Annotations)
+	suite.Empty(page.MetaPropertyTags)
+}
+
+func (suite *ConditionalSuite) TestModified() {
+	content, err := NewFactory().PageFromURL(context.Background(), suite.server.URL, Validators{ETag: `"v1"`, LastModified: "Fri, 31 May 2019 12:00:00 GMT"})
+	suite.Nil(err, "Should not get an error")
+	suite.False(IsNotModified(content))
+	suite.Equal(`"v1"`, suite.header.Get("If-None-Match"))
+	suite.Equal("Fri, 31 May 2019 12:00:00 GMT", suite.header.Get("If-Modified-Since"))
+	suite.Equal(`"v2"`, content.(*Page).ETag)
+	siteName, _, _ := content.MetaTag("og:site_name")
+	suite.Equal("Netspective", siteName, "Changed pages should be parsed as usual")
+
+	_, err = NewFactory().PageFromURL(context.Background(), suite.server.URL)
+	suite.Nil(err, "Should not get an error")
+	suite.Empty(suite.header.Get("If-None-Match"), "Requests without validators shouldn't be conditional")
+}
+
+func (suite *ConditionalSuite) TestValidatorProvider() {
+	store := NewMemoryContentStore()
+	content, err := NewFactory().PageFromURL(context.Background(), suite.server.URL)
+	suite.Require().Nil(err, "Should not get an error")
+	suite.Require().Nil(store.StorePage(context.Background(), content.(*Page)))
+
+	factory := NewFactory(StoreValidators{Store: store})
+	content, err = factory.PageFromURL(context.Background(), suite.server.URL)
+	suite.Nil(err, "Should not get an error")
+	suite.True(IsNotModified(content), "Validators should come from the store")
+
+	content, err = factory.PageFromURL(context.Background(), suite.server.URL, Validators{})
+	suite.Nil(err, "Should not get an error")
+	suite.False(IsNotModified(content), "Validators passed in should take precedence")
+
+	content, err = NewFactory().PageFromURL(context.Background(), suite.server.URL, StoreValidators{Store: store})
+	suite.Nil(err, "Should not get an error")
+	suite.True(IsNotModified(content), "A ValidatorProvider may be passed to PageFromURL")
+}
+
+func (suite *ConditionalSuite) TestValidatorProviderErrors() {
+	content, err := NewFactory(failingValidators{}).PageFromURL(context.Background(), suite.server.URL)
+	suite.Nil(err, "A failing ValidatorProvider shouldn't fail the fetch")
+	suite.False(IsNotModified(content))
+	warnings := content.(*Page).Warnings
+	suite.Require().NotEmpty(warnings)
+	suite.Equal(PageWarning{Code: WarningValidatorsError, Message: "cache is down"}, warnings[len(warnings)-1])
+}
+
+func (suite *ConditionalSuite) TestValidatorProviderErrorsOnFailure() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://www.netspective.com/", archivedResponse(http.StatusInternalServerError, http.Header{}, ""))
+	for _, options := range [][]interface{}{nil, {FetchBudget{MaxBytes: 1 << 20}}} {
+		_, err := NewFactory(archive, failingValidators{}).PageFromURL(context.Background(), "https://www.netspective.com/", options...)
+		var failed *ValidatorsFailedError
+		suite.Require().True(xerrors.As(err, &failed), "The ValidatorProvider's error shouldn't be lost when the fetch fails")
+		suite.EqualError(failed.ValidatorsErr, "cache is down")
+		suite.Equal(ErrorCodeHTTPStatus, CodeOf(err), "The fetch's error should still be the cause")
+		suite.Contains(err.Error(), "cache is down")
+	}
+}
+
+func (suite *ConditionalSuite) TestUnconditional304() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://www.netspective.com/", archivedResponse(http.StatusNotModified, http.Header{}, ""))
+	_, err := NewFactory(archive).PageFromURL(context.Background(), "https://www.netspective.com/")
+	suite.Equal(ErrorCodeHTTPStatus, CodeOf(err), "A 304 to a request without validators is still an error")
+}
+
+func (suite *ConditionalSuite) TestWithBudget() {
+	content, err := NewFactory(FetchBudget{MaxBytes: 1 << 20}).PageFromURL(context.Background(), suite.server.URL, Validators{LastModified: testLastModified})
+	suite.Nil(err, "Should not get an error")
+	suite.True(IsNotModified(content))
+}
+
+func TestConditionalSuite(t *testing.T) {
+	suite.Run(t, new(ConditionalSuite))
+}
@@ -0,0 +1,258 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+	"golang.org/x/xerrors"
+)
+
+// ConfigEnvPrefix is the prefix of the environment variables which override a FactoryConfig
+const ConfigEnvPrefix = "LECTIO_RESOURCE_"
+
+// ConfigDuration is a time.Duration written as a string such as "90s" or "1m30s" in configuration files
+type ConfigDuration time.Duration
+
+// UnmarshalJSON accepts duration strings, or numbers of seconds
+func (d *ConfigDuration) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		var seconds float64
+		if err := json.Unmarshal(data, &seconds); err != nil {
+			return fmt.Errorf("duration must be a string like \"90s\" or a number of seconds: %s", data)
+		}
+		*d = ConfigDuration(seconds * float64(time.Second))
+		return nil
+	}
+	parsed, err := time.ParseDuration(text)
+	if err != nil {
+		return err
+	}
+	*d = ConfigDuration(parsed)
+	return nil
+}
+
+// MarshalJSON writes the duration as a string
+func (d ConfigDuration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// FactoryConfig is the deployment-tunable configuration of a factory, see NewFactoryFromConfig. Every field can be
+// overridden by an environment variable named ConfigEnvPrefix followed by the field's JSON name in upper snake case,
+// e.g. LECTIO_RESOURCE_USER_AGENT or LECTIO_RESOURCE_ALLOWED_TYPES (a comma separated list).
+type FactoryConfig struct {
+	Timeout                ConfigDuration `json:"timeout,omitempty"`                // overall HTTP client timeout, 90s if not set
+	MinTimeout             ConfigDuration `json:"minTimeout,omitempty"`             // with MaxTimeout, enables AdaptiveTimeouts
+	MaxTimeout             ConfigDuration `json:"maxTimeout,omitempty"`             // with MinTimeout, enables AdaptiveTimeouts
+	HedgeAfter             ConfigDuration `json:"hedgeAfter,omitempty"`             // enables request hedging
+	UserAgent              string         `json:"userAgent,omitempty"`              // sent with every request
+	MaxHTMLBytes           int64          `json:"maxHTMLBytes,omitempty"`           // HTMLBodyLimit
+	HeadOnly               bool           `json:"headOnly,omitempty"`               // HTMLHeadOnly
+	AttachmentDir          string         `json:"attachmentDir,omitempty"`          // downloads are stored here, no downloads if not set
+	AttachmentPreviewBytes int64          `json:"attachmentPreviewBytes,omitempty"` // AttachmentPreviewSize
+	AllowedTypes           []string       `json:"allowedTypes,omitempty"`           // media types (or "image/*" wildcards) which may be downloaded, all if not set
+	HostStatsPath          string         `json:"hostStatsPath,omitempty"`          // per-host statistics are loaded from, and may be saved to, this file
+	RetainBody             bool           `json:"retainBody,omitempty"`             // RetainBody
+	MaxIdleConns           int            `json:"maxIdleConns,omitempty"`           // ConnectionPool, DefaultConnectionPool if not set
+	MaxIdleConnsPerHost    int            `json:"maxIdleConnsPerHost,omitempty"`    // ConnectionPool, DefaultConnectionPool if not set
+	MaxConnsPerHost        int            `json:"maxConnsPerHost,omitempty"`        // ConnectionPool, no limit if not set
+	IdleConnTimeout        ConfigDuration `json:"idleConnTimeout,omitempty"`        // ConnectionPool, DefaultConnectionPool if not set
+	KeepAlive              ConfigDuration `json:"keepAlive,omitempty"`              // ConnectionPool, DefaultConnectionPool if not set
+	DisableKeepAlives      bool           `json:"disableKeepAlives,omitempty"`      // ConnectionPool
+	AddressFamily          AddressFamily  `json:"addressFamily,omitempty"`          // DialConfig, e.g. "prefer-ipv4" on networks with broken IPv6
+	FallbackDelay          ConfigDuration `json:"fallbackDelay,omitempty"`          // DialConfig
+}
+
+// LoadFactoryConfig reads a JSON configuration
+func LoadFactoryConfig(r io.Reader) (*FactoryConfig, error) {
+	result := new(FactoryConfig)
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(result); err != nil {
+		return nil, xerrors.Errorf("Unable to read factory configuration: %w", err)
+	}
+	return result, nil
+}
+
+// ApplyEnv overrides the configuration with any ConfigEnvPrefix environment variables, lookup is usually os.LookupEnv
+func (c *FactoryConfig) ApplyEnv(lookup func(string) (string, bool)) error {
+	durations := map[string]*ConfigDuration{"TIMEOUT": &c.Timeout, "MIN_TIMEOUT": &c.MinTimeout, "MAX_TIMEOUT": &c.MaxTimeout, "HEDGE_AFTER": &c.HedgeAfter, "IDLE_CONN_TIMEOUT": &c.IdleConnTimeout, "KEEP_ALIVE": &c.KeepAlive, "FALLBACK_DELAY": &c.FallbackDelay}
+	for name, field := range durations {
+		if value, ok := lookup(ConfigEnvPrefix + name); ok {
+			if err := field.UnmarshalJSON([]byte(strconv.Quote(value))); err != nil {
+				return xerrors.Errorf("Invalid %s%s: %w", ConfigEnvPrefix, name, err)
+			}
+		}
+	}
+	sizes := map[string]*int64{"MAX_HTML_BYTES": &c.MaxHTMLBytes, "ATTACHMENT_PREVIEW_BYTES": &c.AttachmentPreviewBytes}
+	for name, field := range sizes {
+		if value, ok := lookup(ConfigEnvPrefix + name); ok {
+			parsed, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return xerrors.Errorf("Invalid %s%s: %w", ConfigEnvPrefix, name, err)
+			}
+			*field = parsed
+		}
+	}
+	counts := map[string]*int{"MAX_IDLE_CONNS": &c.MaxIdleConns, "MAX_IDLE_CONNS_PER_HOST": &c.MaxIdleConnsPerHost, "MAX_CONNS_PER_HOST": &c.MaxConnsPerHost}
+	for name, field := range counts {
+		if value, ok := lookup(ConfigEnvPrefix + name); ok {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				return xerrors.Errorf("Invalid %s%s: %w", ConfigEnvPrefix, name, err)
+			}
+			*field = parsed
+		}
+	}
+	flags := map[string]*bool{"HEAD_ONLY": &c.HeadOnly, "RETAIN_BODY": &c.RetainBody, "DISABLE_KEEP_ALIVES": &c.DisableKeepAlives}
+	for name, field := range flags {
+		if value, ok := lookup(ConfigEnvPrefix + name); ok {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				return xerrors.Errorf("Invalid %s%s: %w", ConfigEnvPrefix, name, err)
+			}
+			*field = parsed
+		}
+	}
+	texts := map[string]*string{"USER_AGENT": &c.UserAgent, "ATTACHMENT_DIR": &c.AttachmentDir, "HOST_STATS_PATH": &c.HostStatsPath}
+	for name, field := range texts {
+		if value, ok := lookup(ConfigEnvPrefix + name); ok {
+			*field = value
+		}
+	}
+	if value, ok := lookup(ConfigEnvPrefix + "ADDRESS_FAMILY"); ok {
+		c.AddressFamily = AddressFamily(value)
+	}
+	if value, ok := lookup(ConfigEnvPrefix + "ALLOWED_TYPES"); ok {
+		c.AllowedTypes = nil
+		for _, mediaType := range strings.Split(value, ",") {
+			if mediaType = strings.TrimSpace(mediaType); len(mediaType) > 0 {
+				c.AllowedTypes = append(c.AllowedTypes, mediaType)
+			}
+		}
+	}
+	return nil
+}
+
+// Options returns the factory options described by the configuration, files are created on fs
+func (c FactoryConfig) Options(fs afero.Fs) ([]interface{}, error) {
+	var result []interface{}
+
+	timeout := time.Duration(c.Timeout)
+	if timeout <= 0 {
+		timeout = 90 * time.Second
+	}
+	switch c.AddressFamily {
+	case AnyAddressFamily, PreferIPv4, PreferIPv6, IPv4Only, IPv6Only:
+	default:
+		return nil, fmt.Errorf("addressFamily %q is not one of %q, %q, %q, or %q", c.AddressFamily, PreferIPv4, PreferIPv6, IPv4Only, IPv6Only)
+	}
+	dial := &DialConfig{AddressFamily: c.AddressFamily, FallbackDelay: time.Duration(c.FallbackDelay)}
+	client := &http.Client{Timeout: timeout, Transport: c.ConnectionPool().NewTransport(dial)}
+	result = append(result, func(ctx context.Context) *http.Client { return client })
+
+	if len(c.UserAgent) > 0 {
+		userAgent := c.UserAgent
+		result = append(result, func(ctx context.Context, client *http.Client, req *http.Request) {
+			req.Header.Set("User-Agent", userAgent)
+		})
+	}
+	if c.MinTimeout > 0 && c.MaxTimeout > 0 {
+		if c.MinTimeout > c.MaxTimeout {
+			return nil, fmt.Errorf("minTimeout %v is greater than maxTimeout %v", time.Duration(c.MinTimeout), time.Duration(c.MaxTimeout))
+		}
+		result = append(result, NewAdaptiveTimeouts(time.Duration(c.MinTimeout), time.Duration(c.MaxTimeout)))
+	}
+	if c.HedgeAfter > 0 {
+		result = append(result, HedgeAfter(c.HedgeAfter))
+	}
+	if c.MaxHTMLBytes > 0 {
+		result = append(result, HTMLBodyLimit(c.MaxHTMLBytes))
+	}
+	if c.HeadOnly {
+		result = append(result, HTMLHeadOnly(true))
+	}
+	if c.RetainBody {
+		result = append(result, RetainBody(true))
+	}
+	if len(c.AttachmentDir) > 0 {
+		if err := fs.MkdirAll(c.AttachmentDir, 0755); err != nil {
+			return nil, xerrors.Errorf("Unable to create attachmentDir %q: %w", c.AttachmentDir, err)
+		}
+		result = append(result, NewFileSystemAttachmentCreator(fs, c.AttachmentDir, nil))
+	}
+	if c.AttachmentPreviewBytes > 0 {
+		result = append(result, AttachmentPreviewSize(c.AttachmentPreviewBytes))
+	}
+	if len(c.AllowedTypes) > 0 {
+		result = append(result, AllowedAttachmentTypes(c.AllowedTypes))
+	}
+	if len(c.HostStatsPath) > 0 {
+		store, err := NewFileHostStatsStore(fs, c.HostStatsPath)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, store)
+	}
+	return result, nil
+}
+
+// ConnectionPool returns the pool settings of the configuration, DefaultConnectionPool for those not set
+func (c FactoryConfig) ConnectionPool() *ConnectionPool {
+	result := DefaultConnectionPool()
+	if c.MaxIdleConns > 0 {
+		result.MaxIdleConns = c.MaxIdleConns
+	}
+	if c.MaxIdleConnsPerHost > 0 {
+		result.MaxIdleConnsPerHost = c.MaxIdleConnsPerHost
+	}
+	if c.MaxConnsPerHost > 0 {
+		result.MaxConnsPerHost = c.MaxConnsPerHost
+	}
+	if c.IdleConnTimeout != 0 {
+		result.IdleConnTimeout = time.Duration(c.IdleConnTimeout)
+	}
+	if c.KeepAlive != 0 {
+		result.KeepAlive = time.Duration(c.KeepAlive)
+	}
+	result.DisableKeepAlives = c.DisableKeepAlives
+	return result
+}
+
+// NewFactoryFromConfig creates a factory from a configuration, after applying the process's environment overrides.
+// Files (attachments, host statistics) are on the OS file system. Options override the configuration's choices.
+func NewFactoryFromConfig(config FactoryConfig, options ...interface{}) (*DefaultFactory, error) {
+	if err := config.ApplyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+	configured, err := config.Options(afero.NewOsFs())
+	if err != nil {
+		return nil, err
+	}
+	result := NewFactory(append(configured, options...)...)
+	if result.invalid != nil {
+		return nil, result.invalid
+	}
+	return result, nil
+}
+
+// AllowedAttachmentTypes is an AttachmentDownloadPolicy which only downloads the listed media types, matched with
+// DefaultTypeRegistry so aliases, "type/*" wildcards, and "+json" style suffixes are supported
+type AllowedAttachmentTypes []string
+
+// ShouldDownload satisfies AttachmentDownloadPolicy method
+func (a AllowedAttachmentTypes) ShouldDownload(ctx context.Context, url *url.URL, t Type, contentLength int64, headers http.Header) bool {
+	if t == nil {
+		return false
+	}
+	return DefaultTypeRegistry.Is(t, a...)
+}
@@ -0,0 +1,75 @@
+package resource
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/suite"
+)
+
+type ConfigSuite struct {
+	suite.Suite
+}
+
+func (suite *ConfigSuite) TestLoadAndEnvOverrides() {
+	config, err := LoadFactoryConfig(strings.NewReader(`{
+		"timeout": "30s",
+		"hedgeAfter": 1.5,
+		"userAgent": "lectio-test/1.0",
+		"maxHTMLBytes": 65536,
+		"allowedTypes": ["application/pdf"]
+	}`))
+	suite.Nil(err, "Should not get an error")
+	suite.Equal(ConfigDuration(30*time.Second), config.Timeout)
+	suite.Equal(ConfigDuration(1500*time.Millisecond), config.HedgeAfter)
+
+	env := map[string]string{
+		"LECTIO_RESOURCE_TIMEOUT":        "10s",
+		"LECTIO_RESOURCE_ALLOWED_TYPES":  "application/pdf, image/*",
+		"LECTIO_RESOURCE_ATTACHMENT_DIR": "/downloads",
+	}
+	err = config.ApplyEnv(func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	})
+	suite.Nil(err, "Should not get an error")
+	suite.Equal(ConfigDuration(10*time.Second), config.Timeout)
+	suite.Equal([]string{"application/pdf", "image/*"}, config.AllowedTypes)
+
+	options, err := config.Options(afero.NewMemMapFs())
+	suite.Nil(err, "Should not get an error")
+	factory := NewFactory(options...)
+	suite.Equal(10*time.Second, factory.httpClient(context.Background()).Timeout)
+	suite.Equal(HTMLBodyLimit(65536), factory.HTMLBodyLimitPolicy)
+	suite.NotNil(factory.FileAttachmentCreator, "Attachment directory should enable downloads")
+
+	req, _ := http.NewRequest(http.MethodGet, "https://www.netspective.com/", nil)
+	factory.prepareHTTPRequest(context.Background(), nil, req)
+	suite.Equal("lectio-test/1.0", req.Header.Get("User-Agent"))
+
+	policy := AllowedAttachmentTypes(config.AllowedTypes)
+	png, _ := NewPageType("image/png")
+	zip, _ := NewPageType("application/zip")
+	suite.True(policy.ShouldDownload(context.Background(), nil, png, 0, nil), "Wildcards should match")
+	suite.False(policy.ShouldDownload(context.Background(), nil, zip, 0, nil))
+}
+
+func (suite *ConfigSuite) TestInvalidConfig() {
+	_, err := LoadFactoryConfig(strings.NewReader(`{"timeOutt": "30s"}`))
+	suite.NotNil(err, "Unknown fields should be rejected")
+
+	config := FactoryConfig{}
+	err = config.ApplyEnv(func(name string) (string, bool) { return "soon", name == "LECTIO_RESOURCE_TIMEOUT" })
+	suite.NotNil(err, "Invalid durations should be rejected")
+
+	_, err = FactoryConfig{MinTimeout: ConfigDuration(time.Minute), MaxTimeout: ConfigDuration(time.Second)}.Options(afero.NewMemMapFs())
+	suite.NotNil(err, "Conflicting timeouts should be rejected")
+}
+
+func TestConfigSuite(t *testing.T) {
+	suite.Run(t, new(ConfigSuite))
+}
@@ -1,7 +1,10 @@
 package resource
 
 import (
+	"io"
 	"net/url"
+	"os"
+	"time"
 )
 
 // MediaTypeParams contains what was parsed from MediaType
@@ -17,8 +20,12 @@ type Content interface {
 	Type() Type
 	IsHTML() bool
 	Redirect() (bool, string)
+	RedirectTarget() (*url.URL, time.Duration, bool)
 	MetaTags() (MetaTags, error)
 	MetaTag(key string) (interface{}, bool, error)
+	MetaTagAll(key string) ([]interface{}, bool, error)
+	MetaNamespace(namespace string) (MetaTags, error)
+	LinksByRel(rel string) []Link
 	Attachment() Attachment
 }
 
@@ -28,6 +35,13 @@ type Attachment interface {
 	IsValid() bool
 }
 
+// ReadableAttachment is an Attachment whose content can be read back after it was downloaded
+type ReadableAttachment interface {
+	Attachment
+	Open() (io.ReadCloser, error)
+	Stat() (os.FileInfo, error)
+}
+
 // Type defines the kind of content
 type Type interface {
 	ContentType() string
@@ -0,0 +1,38 @@
+package resource
+
+import (
+	"github.com/spf13/afero"
+	"golang.org/x/xerrors"
+)
+
+// TempDirAttachmentCreator stores attachments in a private directory created under os.TempDir
+type TempDirAttachmentCreator struct {
+	*FileSystemAttachmentCreator
+}
+
+// NewTempDirAttachmentCreator creates a new private temp directory (named with prefix) to store attachments in,
+// a nil strategy means HashNamingStrategy. Call Cleanup when the attachments are no longer needed.
+func NewTempDirAttachmentCreator(prefix string, strategy NamingStrategy) (*TempDirAttachmentCreator, error) {
+	fs := afero.NewOsFs()
+	dir, err := afero.TempDir(fs, "", prefix)
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to create temp directory in resource.NewTempDirAttachmentCreator: %w", err)
+	}
+	return &TempDirAttachmentCreator{NewFileSystemAttachmentCreator(fs, dir, strategy)}, nil
+}
+
+// Dir returns the temp directory attachments are stored in
+func (c *TempDirAttachmentCreator) Dir() string {
+	return c.BasePath
+}
+
+// Cleanup removes the temp directory and everything that was downloaded into it
+func (c *TempDirAttachmentCreator) Cleanup() error {
+	return c.FS.RemoveAll(c.BasePath)
+}
+
+// NewMemoryAttachmentCreator creates a FileAttachmentCreator which keeps attachments in an afero.MemMapFs,
+// a nil strategy means HashNamingStrategy
+func NewMemoryAttachmentCreator(strategy NamingStrategy) *FileSystemAttachmentCreator {
+	return NewFileSystemAttachmentCreator(afero.NewMemMapFs(), "", strategy)
+}
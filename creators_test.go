@@ -0,0 +1,51 @@
+package resource
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CreatorsSuite struct {
+	suite.Suite
+}
+
+func (suite *CreatorsSuite) TestTempDirCreatorCleanup() {
+	ctx := context.Background()
+	creator, err := NewTempDirAttachmentCreator("lectio-resource-test-", nil)
+	suite.Nil(err, "Should not get an error")
+
+	u, _ := url.Parse("http://ceur-ws.org/Vol-1401/paper-05.pdf")
+	_, file, err := creator.CreateFile(ctx, u, nil)
+	suite.Nil(err, "Should not get an error")
+	file.Close()
+
+	_, err = os.Stat(file.Name())
+	suite.Nil(err, "File should exist on disk")
+
+	suite.Nil(creator.Cleanup(), "Should not get an error")
+	_, err = os.Stat(creator.Dir())
+	suite.True(os.IsNotExist(err), "Temp directory should be removed")
+}
+
+func (suite *CreatorsSuite) TestMemoryCreator() {
+	ctx := context.Background()
+	creator := NewMemoryAttachmentCreator(URLPathNamingStrategy{})
+
+	u, _ := url.Parse("http://ceur-ws.org/Vol-1401/paper-05.pdf")
+	fs, file, err := creator.CreateFile(ctx, u, nil)
+	suite.Nil(err, "Should not get an error")
+	file.Close()
+
+	_, err = fs.Stat("ceur-ws.org/Vol-1401/paper-05.pdf")
+	suite.Nil(err, "File should exist in memory")
+	_, err = os.Stat("ceur-ws.org/Vol-1401/paper-05.pdf")
+	suite.True(os.IsNotExist(err), "File should not exist on disk")
+}
+
+func TestCreatorsSuite(t *testing.T) {
+	suite.Run(t, new(CreatorsSuite))
+}
@@ -0,0 +1,172 @@
+package resource
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// AddressFamily chooses between IPv4 and IPv6 when a host has both
+type AddressFamily string
+
+// The address families of DialConfig, AnyAddressFamily dials addresses in the order DNS returned them
+const (
+	AnyAddressFamily AddressFamily = ""
+	PreferIPv4       AddressFamily = "prefer-ipv4"
+	PreferIPv6       AddressFamily = "prefer-ipv6"
+	IPv4Only         AddressFamily = "ipv4"
+	IPv6Only         AddressFamily = "ipv6"
+)
+
+// DialConfig is passed into options to control how the built-in HTTP client dials, e.g. so that harvesters on
+// networks with broken IPv6 don't stall on every URL. Like ConnectionPool it has no effect on a client supplied by
+// an HTTPClientProvider.
+type DialConfig struct {
+	Timeout       time.Duration // for each connection attempt, 30s if not set
+	FallbackDelay time.Duration // how long the preferred family gets before the other is raced ("Happy Eyeballs"), 300ms if not set, negative disables the race
+	AddressFamily AddressFamily
+	Resolver      *net.Resolver                                                        // net.DefaultResolver if not set
+	DialContext   func(ctx context.Context, network, address string) (net.Conn, error) // a custom dialer, which replaces all of the above
+}
+
+// dialContext returns the dial function for an http.Transport, using keepAlive for TCP keep-alives and clock for
+// the fallback delay
+func (c *DialConfig) dialContext(keepAlive time.Duration, clock Clock) func(ctx context.Context, network, address string) (net.Conn, error) {
+	if c.DialContext != nil {
+		return c.DialContext
+	}
+
+	dialer := &net.Dialer{Timeout: c.Timeout, KeepAlive: keepAlive, FallbackDelay: c.FallbackDelay, Resolver: c.Resolver}
+	if dialer.Timeout <= 0 {
+		dialer.Timeout = 30 * time.Second
+	}
+	switch c.AddressFamily {
+	case IPv4Only:
+		return func(ctx context.Context, network, address string) (net.Conn, error) {
+			return dialer.DialContext(ctx, restrictNetwork(network, "4"), address)
+		}
+	case IPv6Only:
+		return func(ctx context.Context, network, address string) (net.Conn, error) {
+			return dialer.DialContext(ctx, restrictNetwork(network, "6"), address)
+		}
+	case PreferIPv4, PreferIPv6:
+		return func(ctx context.Context, network, address string) (net.Conn, error) {
+			return c.dialPreferred(ctx, clock, dialer, network, address)
+		}
+	}
+	return dialer.DialContext
+}
+
+// restrictNetwork turns "tcp" into "tcp4" or "tcp6", networks which are already specific are kept
+func restrictNetwork(network string, family string) string {
+	if network == "tcp" || network == "udp" {
+		return network + family
+	}
+	return network
+}
+
+type dialAttempt struct {
+	conn net.Conn
+	err  error
+}
+
+// dialPreferred dials the addresses of the preferred family first and, once FallbackDelay has passed (or they've
+// all failed), the addresses of the other family. Go's dialer only prefers whichever family DNS listed first.
+func (c *DialConfig) dialPreferred(ctx context.Context, clock Clock, dialer *net.Dialer, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	resolver := c.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	addrs, err := resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	var preferred, fallback []string
+	for _, addr := range addrs {
+		isIPv4 := addr.IP.To4() != nil
+		target := net.JoinHostPort(addr.String(), port)
+		if isIPv4 == (c.AddressFamily == PreferIPv4) {
+			preferred = append(preferred, target)
+		} else {
+			fallback = append(fallback, target)
+		}
+	}
+	if len(preferred) == 0 {
+		preferred, fallback = fallback, nil
+	}
+	if len(preferred) == 0 {
+		return nil, &net.DNSError{Err: "no addresses found", Name: host}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	single := *dialer
+	single.FallbackDelay = -1
+	dialAll := func(targets []string, results chan<- dialAttempt) {
+		var lastErr error
+		for _, target := range targets {
+			conn, err := single.DialContext(ctx, network, target)
+			if err == nil {
+				results <- dialAttempt{conn: conn}
+				return
+			}
+			lastErr = err
+		}
+		results <- dialAttempt{err: lastErr}
+	}
+
+	results := make(chan dialAttempt, 2)
+	go dialAll(preferred, results)
+	racing := 1
+	var fallbackTimer <-chan time.Time
+	if len(fallback) > 0 && c.FallbackDelay >= 0 {
+		delay := c.FallbackDelay
+		if delay == 0 {
+			delay = 300 * time.Millisecond
+		}
+		timer := clock.NewTimer(delay)
+		defer timer.Stop()
+		fallbackTimer = timer.C()
+	}
+	startFallback := func() {
+		if fallback != nil {
+			go dialAll(fallback, results)
+			fallback = nil
+			fallbackTimer = nil
+			racing++
+		}
+	}
+
+	var firstErr error
+	for {
+		select {
+		case <-fallbackTimer:
+			startFallback()
+		case attempt := <-results:
+			racing--
+			if attempt.err == nil {
+				if racing > 0 {
+					// the loser is cancelled but may still connect
+					go func() {
+						if late := <-results; late.err == nil {
+							late.conn.Close()
+						}
+					}()
+				}
+				return attempt.conn, nil
+			}
+			if firstErr == nil {
+				firstErr = attempt.err
+			}
+			startFallback()
+			if racing == 0 {
+				return nil, firstErr
+			}
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DialSuite struct {
+	suite.Suite
+	server *httptest.Server
+}
+
+func (suite *DialSuite) SetupTest() {
+	suite.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, testHTMLPage)
+	}))
+}
+
+func (suite *DialSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+func (suite *DialSuite) localhostURL() string {
+	u, _ := url.Parse(suite.server.URL)
+	return "http://localhost:" + u.Port() + "/"
+}
+
+func (suite *DialSuite) TestCustomDialer() {
+	var dials int32
+	dialer := &net.Dialer{}
+	config := &DialConfig{DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+		atomic.AddInt32(&dials, 1)
+		return dialer.DialContext(ctx, network, address)
+	}}
+	_, err := NewFactory(config).PageFromURL(context.Background(), suite.server.URL)
+	suite.Nil(err, "Should not get an error")
+	suite.Equal(int32(1), atomic.LoadInt32(&dials))
+}
+
+func (suite *DialSuite) TestAddressFamilies() {
+	// the test server only listens on IPv4 so preferring IPv6 has to fall back
+	for _, family := range []AddressFamily{AnyAddressFamily, PreferIPv4, PreferIPv6, IPv4Only} {
+		_, err := NewFactory(&DialConfig{AddressFamily: family}).PageFromURL(context.Background(), suite.localhostURL())
+		suite.Nil(err, "Should not get an error dialing with %q", family)
+	}
+
+	_, err := NewFactory(&DialConfig{AddressFamily: IPv6Only}).PageFromURL(context.Background(), suite.server.URL)
+	suite.NotNil(err, "IPv4 address should not be dialed when only IPv6 is allowed")
+}
+
+func (suite *DialSuite) TestRestrictNetwork() {
+	suite.Equal("tcp4", restrictNetwork("tcp", "4"))
+	suite.Equal("tcp6", restrictNetwork("tcp", "6"))
+	suite.Equal("tcp4", restrictNetwork("tcp4", "6"), "Specific networks should be kept")
+}
+
+func (suite *DialSuite) TestConfiguredAddressFamily() {
+	_, err := FactoryConfig{AddressFamily: "ipv5"}.Options(nil)
+	suite.NotNil(err, "Should reject unknown address families")
+}
+
+func TestDialSuite(t *testing.T) {
+	suite.Run(t, new(DialSuite))
+}
@@ -0,0 +1,141 @@
+package resource
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+)
+
+// ValueChange is a value which differs between two versions of a page, empty if absent
+type ValueChange struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// MetaTagChange is a meta tag which was added (Old is nil), removed (New is nil), or whose values changed
+type MetaTagChange struct {
+	Key string   `json:"key"`
+	Old []string `json:"old,omitempty"`
+	New []string `json:"new,omitempty"`
+}
+
+// PageDiff reports what changed between two versions of a page, fields are nil when nothing changed
+type PageDiff struct {
+	Title          *ValueChange    `json:"title,omitempty"`
+	Description    *ValueChange    `json:"description,omitempty"`
+	Canonical      *ValueChange    `json:"canonical,omitempty"`
+	MetaTags       []MetaTagChange `json:"metaTags,omitempty"` // sorted by key
+	Fingerprint    *ValueChange    `json:"fingerprint,omitempty"`
+	AttachmentHash *ValueChange    `json:"attachmentHash,omitempty"`
+}
+
+// HasChanges returns true if anything differs
+func (d PageDiff) HasChanges() bool {
+	return d.Title != nil || d.Description != nil || d.Canonical != nil || len(d.MetaTags) > 0 || d.Fingerprint != nil || d.AttachmentHash != nil
+}
+
+// DiffPages compares two versions of a page. The title and description are the first of the Open Graph, Twitter,
+// and plain meta tags, which are also diffed individually. Fingerprints are only compared when both pages have one.
+func DiffPages(old, new Page) PageDiff {
+	var result PageDiff
+	result.Title = valueChange(pageMetaText(old, "og:title", "twitter:title", "title"), pageMetaText(new, "og:title", "twitter:title", "title"))
+	result.Description = valueChange(pageMetaText(old, "og:description", "twitter:description", "description"), pageMetaText(new, "og:description", "twitter:description", "description"))
+	result.Canonical = valueChange(pageCanonical(old), pageCanonical(new))
+	result.MetaTags = diffMetaTags(old.MetaPropertyTags, new.MetaPropertyTags)
+	if len(old.Fingerprint) > 0 && len(new.Fingerprint) > 0 {
+		result.Fingerprint = valueChange(old.Fingerprint, new.Fingerprint)
+	}
+	result.AttachmentHash = valueChange(attachmentHash(old.DownloadedAttachment), attachmentHash(new.DownloadedAttachment))
+	return result
+}
+
+// fingerprint returns the hex SHA-256 of content
+func fingerprint(content []byte) string {
+	digest := sha256.Sum256(content)
+	return hex.EncodeToString(digest[:])
+}
+
+func valueChange(old, new string) *ValueChange {
+	if old == new {
+		return nil
+	}
+	return &ValueChange{Old: old, New: new}
+}
+
+// pageMetaText returns the first value of the first of keys that the page has
+func pageMetaText(page Page, keys ...string) string {
+	for _, key := range keys {
+		if values := metaTagStrings(page.MetaPropertyTags[key]); len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+func pageCanonical(page Page) string {
+	if canonical, ok := page.Canonical(); ok && canonical != nil {
+		return canonical.String()
+	}
+	return ""
+}
+
+// metaTagStrings returns the values of a tag as stored in Page.MetaPropertyTags
+func metaTagStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+func diffMetaTags(old, new map[string]interface{}) []MetaTagChange {
+	keys := make(map[string]bool)
+	for key := range old {
+		keys[key] = true
+	}
+	for key := range new {
+		keys[key] = true
+	}
+
+	var result []MetaTagChange
+	for key := range keys {
+		oldValues, newValues := metaTagStrings(old[key]), metaTagStrings(new[key])
+		if !equalStrings(oldValues, newValues) {
+			result = append(result, MetaTagChange{Key: key, Old: oldValues, New: newValues})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+	return result
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// attachmentHash returns the strongest recorded checksum of a downloaded file, see ChecksumRecorder
+func attachmentHash(attachment Attachment) string {
+	file, ok := attachment.(*FileAttachment)
+	if !ok || file == nil {
+		return ""
+	}
+	for _, algorithm := range []string{"sha-512", "sha-256", "sha-1", "md5"} {
+		if digest, ok := file.Checksums[algorithm]; ok {
+			return algorithm + ":" + digest
+		}
+	}
+	return ""
+}
@@ -0,0 +1,66 @@
+package resource
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DiffSuite struct {
+	suite.Suite
+}
+
+func (suite *DiffSuite) harvest(html string) Page {
+	archive := NewMemoryResponseArchive()
+	archive.Add("http://example.com/", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, html))
+	content, err := NewFactory(archive).PageFromURL(context.Background(), "http://example.com/")
+	suite.Nil(err, "Should not get an error")
+	return *content.(*Page)
+}
+
+func (suite *DiffSuite) TestIdenticalPages() {
+	old := suite.harvest(testHTMLPage)
+	diff := DiffPages(old, suite.harvest(testHTMLPage))
+	suite.False(diff.HasChanges(), "Same content should not differ")
+	suite.NotEmpty(old.Fingerprint)
+}
+
+func (suite *DiffSuite) TestChangedPage() {
+	old := suite.harvest(`<html><head>
+		<meta property="og:title" content="First">
+		<meta name="description" content="Same">
+		<meta property="article:tag" content="a">
+		<link rel="canonical" href="http://example.com/first">
+	</head></html>`)
+	new := suite.harvest(`<html><head>
+		<meta property="og:title" content="Second">
+		<meta name="description" content="Same">
+		<meta property="article:tag" content="a"><meta property="article:tag" content="b">
+		<meta name="author" content="Shahid">
+		<link rel="canonical" href="http://example.com/second">
+	</head></html>`)
+
+	diff := DiffPages(old, new)
+	suite.True(diff.HasChanges())
+	suite.Equal(&ValueChange{Old: "First", New: "Second"}, diff.Title)
+	suite.Nil(diff.Description, "Unchanged description should not be reported")
+	suite.Equal(&ValueChange{Old: "http://example.com/first", New: "http://example.com/second"}, diff.Canonical)
+	suite.NotNil(diff.Fingerprint)
+	suite.Equal([]MetaTagChange{
+		{Key: "article:tag", Old: []string{"a"}, New: []string{"a", "b"}},
+		{Key: "author", New: []string{"Shahid"}},
+		{Key: "og:title", Old: []string{"First"}, New: []string{"Second"}},
+	}, diff.MetaTags)
+}
+
+func (suite *DiffSuite) TestAttachmentHash() {
+	old := Page{DownloadedAttachment: &FileAttachment{Checksums: map[string]string{"sha-1": "aa", "sha-256": "bb"}}}
+	new := Page{DownloadedAttachment: &FileAttachment{Checksums: map[string]string{"sha-256": "cc"}}}
+	suite.Equal(&ValueChange{Old: "sha-256:bb", New: "sha-256:cc"}, DiffPages(old, new).AttachmentHash)
+}
+
+func TestDiffSuite(t *testing.T) {
+	suite.Run(t, new(DiffSuite))
+}
@@ -0,0 +1,90 @@
+package resource
+
+import (
+	"context"
+
+	"github.com/spf13/afero"
+	"golang.org/x/xerrors"
+)
+
+// DiskSpaceChecker is passed into options to check there's room for a download before it's written, so that a
+// download whose Content-Length is more than the space left fails straight away rather than filling the disk. It
+// returns the bytes available in dir of fs, false if it can't tell (the download then goes ahead).
+type DiskSpaceChecker interface {
+	AvailableDiskSpace(ctx context.Context, fs afero.Fs, dir string) (int64, bool, error)
+}
+
+// AttachmentLocation may be implemented by a FileAttachmentCreator to say where its files are created, which is
+// where a DiskSpaceChecker checks for space. Creators which don't implement it aren't checked.
+type AttachmentLocation interface {
+	AttachmentLocation() (afero.Fs, string)
+}
+
+// AttachmentLocation satisfies AttachmentLocation method
+func (c *FileSystemAttachmentCreator) AttachmentLocation() (afero.Fs, string) {
+	return c.FS, c.BasePath
+}
+
+// OSDiskSpaceChecker is a DiskSpaceChecker for directories of afero.OsFs, or of an afero.BasePathFs over it. The space
+// of other file systems (such as afero.MemMapFs), or on platforms it doesn't know how to ask, is unknown.
+type OSDiskSpaceChecker struct{}
+
+// AvailableDiskSpace satisfies DiskSpaceChecker method
+func (OSDiskSpaceChecker) AvailableDiskSpace(ctx context.Context, fs afero.Fs, dir string) (int64, bool, error) {
+	if len(dir) == 0 {
+		dir = "."
+	}
+	realPath, ok, err := osFilePath(fs, dir)
+	if !ok || err != nil {
+		return 0, false, err
+	}
+	return availableDiskSpace(realPath)
+}
+
+// osFilePath returns the path of name in the operating system's file system, false if fs isn't an afero.OsFs or an
+// afero.BasePathFs over one
+func osFilePath(fs afero.Fs, name string) (string, bool, error) {
+	if basePath, ok := fs.(*afero.BasePathFs); ok {
+		realPath, err := basePath.RealPath(name)
+		return realPath, err == nil, err
+	}
+	_, ok := fs.(*afero.OsFs)
+	return name, ok, nil
+}
+
+// AvailableDiskSpace is a DiskSpaceChecker which always reports the same space, e.g. to test how downloads behave
+// when the disk is full
+type AvailableDiskSpace int64
+
+// AvailableDiskSpace satisfies DiskSpaceChecker method
+func (a AvailableDiskSpace) AvailableDiskSpace(ctx context.Context, fs afero.Fs, dir string) (int64, bool, error) {
+	return int64(a), true, nil
+}
+
+// checkDiskSpace returns an InsufficientDiskSpaceError if the DiskSpaceChecker (the creator or in options) says
+// there's less than required bytes available where creator creates its files
+func checkDiskSpace(ctx context.Context, creator FileAttachmentCreator, urlText string, required int64, options []interface{}) error {
+	checker, _ := OptionOf[DiskSpaceChecker](append([]interface{}{creator}, options...))
+	location, ok := creator.(AttachmentLocation)
+	if checker == nil || !ok || required <= 0 {
+		return nil
+	}
+	fs, dir := location.AttachmentLocation()
+	var available int64
+	var known bool
+	var err error
+	guardPolicy("DiskSpaceChecker", func() { available, known, err = checker.AvailableDiskSpace(ctx, fs, dir) })
+	if err != nil {
+		return downloadError(urlText, "Unable to check disk space in resource.DownloadFile", err, callerFrames(xErrorsFrameCaller+1))
+	}
+	if known && available < required {
+		return &InsufficientDiskSpaceError{
+			URL:       urlText,
+			Dir:       dir,
+			Required:  required,
+			Available: available,
+			Frame:     xerrors.Caller(xErrorsFrameCaller + 1),
+			Frames:    callerFrames(xErrorsFrameCaller + 1)}
+	}
+	return nil
+}
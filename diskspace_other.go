@@ -0,0 +1,7 @@
+//go:build !linux && !darwin && !freebsd
+
+package resource
+
+func availableDiskSpace(dir string) (int64, bool, error) {
+	return 0, false, nil
+}
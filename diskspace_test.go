@@ -0,0 +1,72 @@
+package resource
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/xerrors"
+)
+
+type DiskSpaceSuite struct {
+	suite.Suite
+	creator *FileSystemAttachmentCreator
+}
+
+func (suite *DiskSpaceSuite) SetupTest() {
+	suite.creator = NewMemoryAttachmentCreator(nil)
+}
+
+func (suite *DiskSpaceSuite) download(contentLength int64, options ...interface{}) (bool, Attachment, error) {
+	u, _ := url.Parse("http://ceur-ws.org/Vol-1401/paper-05.pdf")
+	t, _ := NewPageType("application/pdf")
+	resp := &http.Response{Header: make(http.Header), Body: ioutil.NopCloser(strings.NewReader(testPDFContent)), ContentLength: contentLength}
+	return DownloadFileFromHTTPResp(context.Background(), suite.creator, u, resp, t, options...)
+}
+
+func (suite *DiskSpaceSuite) TestFailsFastWhenDiskIsFull() {
+	ok, _, err := suite.download(int64(len(testPDFContent)), AvailableDiskSpace(10))
+	suite.False(ok)
+	var insufficient *InsufficientDiskSpaceError
+	suite.True(xerrors.As(err, &insufficient), "Should get an InsufficientDiskSpaceError")
+	suite.Equal(int64(len(testPDFContent)), insufficient.Required)
+	suite.Equal(int64(10), insufficient.Available)
+	suite.Equal(ErrorCategoryDownload, CategoryOf(err))
+	suite.Equal("There isn't enough disk space to download http://ceur-ws.org/Vol-1401/paper-05.pdf", NewMessageCatalog().ErrorMessage("en", err))
+
+	files, _ := afero.ReadDir(suite.creator.FS, "/")
+	suite.Empty(files, "No file should be created")
+}
+
+func (suite *DiskSpaceSuite) TestDownloadsWhenThereIsRoom() {
+	ok, _, err := suite.download(int64(len(testPDFContent)), AvailableDiskSpace(len(testPDFContent)))
+	suite.True(ok)
+	suite.Nil(err, "Should not get an error")
+
+	ok, _, err = suite.download(-1, AvailableDiskSpace(0))
+	suite.True(ok, "Downloads of unknown size aren't checked")
+	suite.Nil(err, "Should not get an error")
+}
+
+func (suite *DiskSpaceSuite) TestOSDiskSpaceChecker() {
+	checker := OSDiskSpaceChecker{}
+	_, known, err := checker.AvailableDiskSpace(context.Background(), afero.NewMemMapFs(), "/")
+	suite.Nil(err)
+	suite.False(known, "Space on a memory file system is unknown")
+
+	available, known, err := checker.AvailableDiskSpace(context.Background(), afero.NewBasePathFs(afero.NewOsFs(), os.TempDir()), "/")
+	suite.Nil(err)
+	if known {
+		suite.True(available >= 0)
+	}
+}
+
+func TestDiskSpaceSuite(t *testing.T) {
+	suite.Run(t, new(DiskSpaceSuite))
+}
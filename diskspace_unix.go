@@ -0,0 +1,13 @@
+//go:build linux || darwin || freebsd
+
+package resource
+
+import "syscall"
+
+func availableDiskSpace(dir string) (int64, bool, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil {
+		return 0, false, err
+	}
+	return int64(stat.Bavail) * int64(stat.Bsize), true, nil
+}
@@ -0,0 +1,42 @@
+package resource
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+)
+
+// DefaultUnreadBodyDrainLimit is how much of a response body which the factory had no use for (neither parsed nor
+// downloaded) is read, so that its connection can be reused, when there's no UnreadBodyPolicy
+const DefaultUnreadBodyDrainLimit = 64 * 1024
+
+// UnreadBodyPolicy is passed into options to decide how much of an unused response body is read before it's closed.
+// A connection can only be reused once its body has been read to the end, so a small limit keeps the connections of
+// small responses while larger ones are closed rather than downloaded for nothing. Zero or less closes straight away.
+type UnreadBodyPolicy interface {
+	UnreadBodyDrainLimit(ctx context.Context, url *url.URL, t Type) int64
+}
+
+// DrainUnreadBody is an UnreadBodyPolicy with the same limit for every response
+type DrainUnreadBody int64
+
+// UnreadBodyDrainLimit satisfies UnreadBodyPolicy method
+func (d DrainUnreadBody) UnreadBodyDrainLimit(ctx context.Context, url *url.URL, t Type) int64 {
+	return int64(d)
+}
+
+// releaseBody drains (up to the policy's limit) and closes a response body, it's harmless if the body has already
+// been read and closed
+func (f *DefaultFactory) releaseBody(ctx context.Context, url *url.URL, resp *http.Response, t Type) {
+	limit := int64(DefaultUnreadBodyDrainLimit)
+	if f.UnreadBodyPolicy != nil {
+		limit = callPolicy("UnreadBodyPolicy", func() int64 { return f.UnreadBodyPolicy.UnreadBodyDrainLimit(ctx, url, t) })
+	}
+	if limit > 0 && resp.ContentLength <= limit {
+		// a declared length beyond the limit isn't worth reading, chunked bodies (-1) are read up to the limit
+		io.CopyN(ioutil.Discard, resp.Body, limit)
+	}
+	resp.Body.Close()
+}
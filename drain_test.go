@@ -0,0 +1,80 @@
+package resource
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DrainSuite struct {
+	suite.Suite
+}
+
+type trackedBody struct {
+	io.ReadCloser
+	read   int
+	closed bool
+}
+
+func (b *trackedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.read += n
+	return n, err
+}
+
+func (b *trackedBody) Close() error {
+	b.closed = true
+	return b.ReadCloser.Close()
+}
+
+// release fetches an unused (neither parsed nor downloaded) body and reports how it was released
+func (suite *DrainSuite) release(body string, options ...interface{}) *trackedBody {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	tracked := new(trackedBody)
+	tracking := func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp, err := next.RoundTrip(req)
+			if err == nil {
+				tracked.ReadCloser = resp.Body
+				resp.Body = tracked
+			}
+			return resp, err
+		})
+	}
+	content, err := NewFactory(append(options, tracking)...).PageFromURL(context.Background(), server.URL)
+	suite.Nil(err, "Should not get an error")
+	suite.True(content.IsValid())
+	return tracked
+}
+
+func (suite *DrainSuite) TestSmallUnusedBodyIsDrained() {
+	tracked := suite.release(strings.Repeat("x", 1024))
+	suite.True(tracked.closed, "Body should be closed")
+	suite.Equal(1024, tracked.read, "Body should be read to the end so the connection can be reused")
+}
+
+func (suite *DrainSuite) TestLargeUnusedBodyIsClosed() {
+	tracked := suite.release(strings.Repeat("x", 2*DefaultUnreadBodyDrainLimit))
+	suite.True(tracked.closed, "Body should be closed")
+	suite.Equal(0, tracked.read, "Body longer than the limit should not be read")
+
+	tracked = suite.release(strings.Repeat("x", 1024), DrainUnreadBody(0))
+	suite.True(tracked.closed, "Body should be closed")
+	suite.Equal(0, tracked.read, "Body should not be read when the policy says so")
+}
+
+func TestDrainSuite(t *testing.T) {
+	suite.Run(t, new(DrainSuite))
+}
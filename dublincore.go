@@ -0,0 +1,128 @@
+package resource
+
+import (
+	"sort"
+	"strings"
+)
+
+// DublinCore is the Dublin Core (https://www.dublincore.org/specifications/dublin-core/dcmi-terms/) description of a
+// page, from its DC.* and dcterms.* meta tags. Repeatable elements keep every value, in document order for each tag.
+type DublinCore struct {
+	Title                 string   `json:"title,omitempty"`
+	Alternative           string   `json:"alternative,omitempty"`
+	Creators              []string `json:"creators,omitempty"`
+	Contributors          []string `json:"contributors,omitempty"`
+	Subjects              []string `json:"subjects,omitempty"`
+	Description           string   `json:"description,omitempty"`
+	Abstract              string   `json:"abstract,omitempty"`
+	Publisher             string   `json:"publisher,omitempty"`
+	Date                  string   `json:"date,omitempty"`
+	Created               string   `json:"created,omitempty"`
+	Issued                string   `json:"issued,omitempty"`
+	Modified              string   `json:"modified,omitempty"`
+	Available             string   `json:"available,omitempty"`
+	Type                  string   `json:"type,omitempty"`
+	Format                string   `json:"format,omitempty"`
+	Identifiers           []string `json:"identifiers,omitempty"`
+	Source                string   `json:"source,omitempty"`
+	Language              string   `json:"language,omitempty"`
+	Relations             []string `json:"relations,omitempty"`
+	IsPartOf              string   `json:"isPartOf,omitempty"`
+	Coverage              string   `json:"coverage,omitempty"`
+	Rights                string   `json:"rights,omitempty"`
+	License               string   `json:"license,omitempty"`
+	AccessRights          string   `json:"accessRights,omitempty"`
+	BibliographicCitation string   `json:"bibliographicCitation,omitempty"`
+}
+
+// dublinCoreElements maps lower case element names, including the older qualified DC forms such as DC.date.issued,
+// to where they're kept
+var dublinCoreElements = map[string]func(*DublinCore, string){
+	"title":                 func(dc *DublinCore, v string) { setOnce(&dc.Title, v) },
+	"title.alternative":     func(dc *DublinCore, v string) { setOnce(&dc.Alternative, v) },
+	"alternative":           func(dc *DublinCore, v string) { setOnce(&dc.Alternative, v) },
+	"creator":               func(dc *DublinCore, v string) { dc.Creators = appendUnique(dc.Creators, v) },
+	"contributor":           func(dc *DublinCore, v string) { dc.Contributors = appendUnique(dc.Contributors, v) },
+	"subject":               func(dc *DublinCore, v string) { dc.Subjects = appendUnique(dc.Subjects, v) },
+	"description":           func(dc *DublinCore, v string) { setOnce(&dc.Description, v) },
+	"description.abstract":  func(dc *DublinCore, v string) { setOnce(&dc.Abstract, v) },
+	"abstract":              func(dc *DublinCore, v string) { setOnce(&dc.Abstract, v) },
+	"publisher":             func(dc *DublinCore, v string) { setOnce(&dc.Publisher, v) },
+	"date":                  func(dc *DublinCore, v string) { setOnce(&dc.Date, v) },
+	"date.created":          func(dc *DublinCore, v string) { setOnce(&dc.Created, v) },
+	"created":               func(dc *DublinCore, v string) { setOnce(&dc.Created, v) },
+	"date.issued":           func(dc *DublinCore, v string) { setOnce(&dc.Issued, v) },
+	"issued":                func(dc *DublinCore, v string) { setOnce(&dc.Issued, v) },
+	"date.modified":         func(dc *DublinCore, v string) { setOnce(&dc.Modified, v) },
+	"modified":              func(dc *DublinCore, v string) { setOnce(&dc.Modified, v) },
+	"date.available":        func(dc *DublinCore, v string) { setOnce(&dc.Available, v) },
+	"available":             func(dc *DublinCore, v string) { setOnce(&dc.Available, v) },
+	"type":                  func(dc *DublinCore, v string) { setOnce(&dc.Type, v) },
+	"format":                func(dc *DublinCore, v string) { setOnce(&dc.Format, v) },
+	"identifier":            func(dc *DublinCore, v string) { dc.Identifiers = appendUnique(dc.Identifiers, v) },
+	"source":                func(dc *DublinCore, v string) { setOnce(&dc.Source, v) },
+	"language":              func(dc *DublinCore, v string) { setOnce(&dc.Language, v) },
+	"relation":              func(dc *DublinCore, v string) { dc.Relations = appendUnique(dc.Relations, v) },
+	"relation.ispartof":     func(dc *DublinCore, v string) { setOnce(&dc.IsPartOf, v) },
+	"ispartof":              func(dc *DublinCore, v string) { setOnce(&dc.IsPartOf, v) },
+	"coverage":              func(dc *DublinCore, v string) { setOnce(&dc.Coverage, v) },
+	"rights":                func(dc *DublinCore, v string) { setOnce(&dc.Rights, v) },
+	"license":               func(dc *DublinCore, v string) { setOnce(&dc.License, v) },
+	"rights.license":        func(dc *DublinCore, v string) { setOnce(&dc.License, v) },
+	"accessrights":          func(dc *DublinCore, v string) { setOnce(&dc.AccessRights, v) },
+	"rights.accessrights":   func(dc *DublinCore, v string) { setOnce(&dc.AccessRights, v) },
+	"bibliographiccitation": func(dc *DublinCore, v string) { setOnce(&dc.BibliographicCitation, v) },
+	"identifier.citation":   func(dc *DublinCore, v string) { setOnce(&dc.BibliographicCitation, v) },
+}
+
+// dublinCorePrefixes are how DC tags are named, matched case-insensitively; dcterms is the more specific vocabulary
+// so its values are preferred
+var dublinCorePrefixes = []string{"dcterms.", "dcterms:", "dc.", "dc:"}
+
+// DublinCoreFromMetaTags reads the DC.* and dcterms.* tags, false is returned if there aren't any
+func DublinCoreFromMetaTags(tags MetaTags) (*DublinCore, bool) {
+	var result *DublinCore
+	for _, prefix := range dublinCorePrefixes {
+		// sorted so that differently cased spellings of the same element are always read in the same order
+		var keys []string
+		for key := range tags {
+			if len(key) > len(prefix) && strings.EqualFold(key[:len(prefix)], prefix) {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			set, ok := dublinCoreElements[strings.ToLower(key[len(prefix):])]
+			if !ok {
+				continue
+			}
+			values, _ := tags.Values(key)
+			for _, value := range values {
+				text, _ := value.(string)
+				if text = strings.TrimSpace(text); len(text) > 0 {
+					if result == nil {
+						result = new(DublinCore)
+					}
+					set(result, text)
+				}
+			}
+		}
+	}
+	return result, result != nil
+}
+
+func setOnce(field *string, value string) {
+	if len(*field) == 0 {
+		*field = value
+	}
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
@@ -0,0 +1,67 @@
+package resource
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DublinCoreSuite struct {
+	suite.Suite
+}
+
+func (suite *DublinCoreSuite) TestDublinCoreMetaTags() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://repository.example.edu/handle/1234/5678", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, `<html><head>
+		<link rel="schema.DC" href="http://purl.org/dc/elements/1.1/">
+		<link rel="schema.DCTERMS" href="http://purl.org/dc/terms/">
+		<meta name="DC.title" content="A Thesis">
+		<meta name="DC.creator" content="Doe, Jane">
+		<meta name="DC.creator" content="Roe, Richard">
+		<meta name="DC.subject" content="Harvesting">
+		<meta name="DC.subject" content="Metadata">
+		<meta name="DC.description" content="Plain description">
+		<meta name="DCTERMS.abstract" content="The abstract">
+		<meta name="DC.date.issued" content="2019-05-01">
+		<meta name="dcterms.modified" content="2019-06-01">
+		<meta name="DC.identifier" content="http://hdl.handle.net/1234/5678">
+		<meta name="DC.language" content="en">
+		<meta name="DC.rights" content="All rights reserved">
+		<meta name="DCTERMS.license" content="https://creativecommons.org/licenses/by/4.0/">
+		<meta name="DC.type" content="Thesis">
+		<meta property="og:title" content="Not Dublin Core">
+	</head></html>`))
+
+	content, err := NewFactory(archive).PageFromURL(context.Background(), "https://repository.example.edu/handle/1234/5678")
+	suite.Nil(err, "Should not get an error")
+	dc := content.(*Page).DublinCore
+	suite.Require().NotNil(dc)
+	suite.Equal("A Thesis", dc.Title)
+	suite.Equal([]string{"Doe, Jane", "Roe, Richard"}, dc.Creators)
+	suite.Equal([]string{"Harvesting", "Metadata"}, dc.Subjects)
+	suite.Equal("Plain description", dc.Description)
+	suite.Equal("The abstract", dc.Abstract)
+	suite.Equal("2019-05-01", dc.Issued, "Qualified DC should be understood")
+	suite.Equal("2019-06-01", dc.Modified)
+	suite.Equal([]string{"http://hdl.handle.net/1234/5678"}, dc.Identifiers)
+	suite.Equal("en", dc.Language)
+	suite.Equal("All rights reserved", dc.Rights)
+	suite.Equal("https://creativecommons.org/licenses/by/4.0/", dc.License)
+	suite.Equal("Thesis", dc.Type)
+}
+
+func (suite *DublinCoreSuite) TestDCTermsPreferred() {
+	dc, ok := DublinCoreFromMetaTags(MetaTags{"dc.title": "Elements title", "dcterms.title": "Terms title", "DC.Creator": "Doe, Jane", "dc.creator": "Doe, Jane"})
+	suite.True(ok)
+	suite.Equal("Terms title", dc.Title)
+	suite.Equal([]string{"Doe, Jane"}, dc.Creators, "Repeated values should only be kept once")
+
+	_, ok = DublinCoreFromMetaTags(MetaTags{"og:title": "Not Dublin Core", "dc.unknown": "ignored"})
+	suite.False(ok)
+}
+
+func TestDublinCoreSuite(t *testing.T) {
+	suite.Run(t, new(DublinCoreSuite))
+}
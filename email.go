@@ -0,0 +1,127 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+	"golang.org/x/xerrors"
+)
+
+// EmailMetaTagPrefix is prepended to the (lowercased) email header names when they are stored as meta tags,
+// e.g. the Subject header becomes the "email:subject" meta tag so MetaNamespace("email") returns all headers
+const EmailMetaTagPrefix = "email:"
+
+// isEmailMessage returns true for message/rfc822 responses and .eml files served with a generic type
+func isEmailMessage(url *url.URL, t Type) bool {
+	if t == nil {
+		return false
+	}
+	if DefaultTypeRegistry.Is(t, "message/rfc822") {
+		return true
+	}
+	switch DefaultTypeRegistry.Canonical(t.MediaType()) {
+	case "application/octet-stream", "text/plain":
+		return strings.EqualFold(path.Ext(url.Path), ".eml")
+	}
+	return false
+}
+
+// PageFromEmailFile creates a content instance from an email message (e.g. a saved .eml file) stored in fs
+func (f *DefaultFactory) PageFromEmailFile(ctx context.Context, fs afero.Fs, filePath string) (_ Content, err error) {
+	defer recoverPolicyPanic(&err)
+	file, err := fs.Open(filePath)
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to open email file %q: %w", filePath, err)
+	}
+	defer file.Close()
+
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		absPath = filePath
+	}
+	fileURL := &url.URL{Scheme: "file", Path: filepath.ToSlash(absPath)}
+
+	result := new(Page)
+	result.MetaPropertyTags = make(map[string]interface{})
+	result.TargetURL = fileURL
+	result.PageType, _ = NewPageType("message/rfc822")
+	if err := f.parseEmailMessage(ctx, fileURL, file, result); err != nil {
+		return result, err
+	}
+	result.valid = true
+	return result, nil
+}
+
+// parseEmailMessage stores the message headers as meta tags, feeds the first HTML part through the normal page
+// meta data parser, and exposes every other part as a ChildAttachment
+func (f *DefaultFactory) parseEmailMessage(ctx context.Context, url *url.URL, body io.Reader, result *Page) error {
+	message, err := mail.ReadMessage(body)
+	if err != nil {
+		return xerrors.Errorf("Unable to read email message: %w", err)
+	}
+
+	decoder := new(mime.WordDecoder)
+	for name, values := range message.Header {
+		for _, value := range values {
+			if decoded, err := decoder.DecodeHeader(value); err == nil {
+				value = decoded
+			}
+			result.addMetaPropertyTag(EmailMetaTagPrefix+strings.ToLower(name), value)
+		}
+	}
+	// the headers are meta tags even when the message has no HTML part
+	result.HTMLParsed = true
+
+	contentType := message.Header.Get("Content-Type")
+	if len(contentType) == 0 {
+		contentType = "text/plain"
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	var parts []mimePart
+	if strings.HasPrefix(mediaType, "multipart/") {
+		parts, err = readMIMEParts(mediaType, params, message.Body, 0)
+		if err != nil {
+			return err
+		}
+	} else {
+		data, err := ioutil.ReadAll(transferDecoder(message.Header.Get("Content-Transfer-Encoding"), message.Body))
+		if err != nil {
+			return xerrors.Errorf("Unable to read email body: %w", err)
+		}
+		parts = []mimePart{{Header: map[string][]string{"Content-Type": {contentType}}, MediaType: mediaType, Params: params, Data: data}}
+	}
+
+	rootFound := false
+	for _, part := range parts {
+		if !rootFound && DefaultTypeRegistry.Matches(part.MediaType, "text/html") && !isMIMEAttachment(part) {
+			rootFound = true
+			if f.htmlContentPolicies(ctx, url, result) {
+				partResp := &http.Response{Header: http.Header(part.Header), Body: ioutil.NopCloser(bytes.NewReader(part.Data)), ContentLength: -1}
+				result.parsePageMetaData(ctx, url, partResp)
+			}
+			continue
+		}
+		result.ChildAttachments = append(result.ChildAttachments, newPartAttachment(url, part))
+	}
+	return nil
+}
+
+// isMIMEAttachment returns true if the part was sent as a file attachment rather than as the message body
+func isMIMEAttachment(part mimePart) bool {
+	disposition, _, err := mime.ParseMediaType(part.Header.Get("Content-Disposition"))
+	return err == nil && disposition == "attachment"
+}
@@ -0,0 +1,89 @@
+package resource
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/suite"
+)
+
+const testEmailMessage = "From: Netspective <news@netspective.com>\r\n" +
+	"To: reader@example.com\r\n" +
+	"Subject: =?utf-8?q?Weekly_Digest?=\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/mixed; boundary=outer\r\n" +
+	"\r\n" +
+	"--outer\r\n" +
+	"Content-Type: multipart/alternative; boundary=inner\r\n" +
+	"\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/plain\r\n" +
+	"\r\n" +
+	"Read https://www.netspective.com/\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/html\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n" +
+	"\r\n" +
+	"<html><head><link rel=3D\"canonical\" href=3D\"https://www.netspective.com/digest\"></head></html>\r\n" +
+	"--inner--\r\n" +
+	"--outer\r\n" +
+	"Content-Type: application/pdf; name=\"digest.pdf\"\r\n" +
+	"Content-Disposition: attachment; filename=\"digest.pdf\"\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"JVBERi0xLjQK\r\n" +
+	"--outer--\r\n"
+
+type EmailSuite struct {
+	suite.Suite
+}
+
+func (suite *EmailSuite) checkDigest(content Content) {
+	value, ok, err := content.MetaTag("email:subject")
+	suite.Nil(err, "Should not get an error")
+	suite.True(ok, "Subject header should be a meta tag")
+	suite.Equal("Weekly Digest", value, "Encoded words should be decoded")
+
+	headers, _ := content.MetaNamespace("email")
+	suite.Equal("reader@example.com", headers["to"])
+
+	canonical, ok := content.(*Page).Canonical()
+	suite.True(ok, "HTML part should go through the page pipeline")
+	suite.Equal("https://www.netspective.com/digest", canonical.String())
+
+	children := content.(*Page).ChildAttachments
+	suite.Len(children, 2, "Plain text alternative and PDF should be child attachments")
+	pdf := children[1].(*PartAttachment)
+	suite.Equal("application/pdf", pdf.Type().MediaType())
+	info, _ := pdf.Stat()
+	suite.Equal("digest.pdf", info.Name())
+	reader, _ := pdf.Open()
+	data, _ := ioutil.ReadAll(reader)
+	suite.Equal("%PDF-1.4\n", string(data))
+}
+
+func (suite *EmailSuite) TestEmailFromURL() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://www.netspective.com/digest.eml", archivedResponse(200, http.Header{"Content-Type": {"message/rfc822"}}, testEmailMessage))
+
+	content, err := NewFactory(archive).PageFromURL(context.Background(), "https://www.netspective.com/digest.eml")
+	suite.Nil(err, "Should not get an error")
+	suite.checkDigest(content)
+}
+
+func (suite *EmailSuite) TestEmailFromFile() {
+	fs := afero.NewMemMapFs()
+	afero.WriteFile(fs, "/mail/digest.eml", []byte(testEmailMessage), 0644)
+
+	content, err := NewFactory().PageFromEmailFile(context.Background(), fs, "/mail/digest.eml")
+	suite.Nil(err, "Should not get an error")
+	suite.Equal("file:///mail/digest.eml", content.URL().String())
+	suite.checkDigest(content)
+}
+
+func TestEmailSuite(t *testing.T) {
+	suite.Run(t, new(EmailSuite))
+}
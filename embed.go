@@ -0,0 +1,222 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// The embeddable services whose URLs are recognized by EmbedTargetFromURL
+const (
+	EmbedProviderYouTube   = "YouTube"
+	EmbedProviderVimeo     = "Vimeo"
+	EmbedProviderTwitter   = "Twitter"
+	EmbedProviderTikTok    = "TikTok"
+	EmbedProviderInstagram = "Instagram"
+	EmbedProviderSpotify   = "Spotify"
+)
+
+// The kinds of thing an EmbedTarget identifies
+const (
+	EmbedKindVideo    = "video"
+	EmbedKindPost     = "post"
+	EmbedKindTrack    = "track"
+	EmbedKindAlbum    = "album"
+	EmbedKindPlaylist = "playlist"
+	EmbedKindEpisode  = "episode"
+	EmbedKindShow     = "show"
+)
+
+// FetchOEmbedPolicy is passed into options if we want the oEmbed data of embeddable pages fetched
+type FetchOEmbedPolicy interface {
+	FetchOEmbed(context.Context, *url.URL) bool
+}
+
+// OEmbed is an oEmbed (https://oembed.com/) response, the dimensions are pixels
+type OEmbed struct {
+	Type            string `json:"type"` // "photo", "video", "link", or "rich"
+	Version         string `json:"version"`
+	Title           string `json:"title,omitempty"`
+	AuthorName      string `json:"author_name,omitempty"`
+	AuthorURL       string `json:"author_url,omitempty"`
+	ProviderName    string `json:"provider_name,omitempty"`
+	ProviderURL     string `json:"provider_url,omitempty"`
+	CacheAge        int    `json:"cache_age,omitempty"`
+	ThumbnailURL    string `json:"thumbnail_url,omitempty"`
+	ThumbnailWidth  int    `json:"thumbnail_width,omitempty"`
+	ThumbnailHeight int    `json:"thumbnail_height,omitempty"`
+	URL             string `json:"url,omitempty"`  // the image, for "photo" responses
+	HTML            string `json:"html,omitempty"` // the embed code, for "video" and "rich" responses
+	Width           int    `json:"width,omitempty"`
+	Height          int    `json:"height,omitempty"`
+}
+
+// EmbedTarget is the post, video, or track on a social or media service that a page is about
+type EmbedTarget struct {
+	Provider string  `json:"provider"`
+	Kind     string  `json:"kind"`
+	ID       string  `json:"id"`                 // the service's own identifier, such as a YouTube video ID or tweet ID
+	Author   string  `json:"author,omitempty"`   // the account name, for services which put it in the URL
+	StartAt  string  `json:"startAt,omitempty"`  // the t= offset of video links, as given
+	OEmbed   *OEmbed `json:"oembed,omitempty"`   // only fetched if FetchOEmbedPolicy asks for it
+	Endpoint string  `json:"endpoint,omitempty"` // where OEmbed came from
+}
+
+var (
+	youTubeIDRegEx   = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+	numericIDRegEx   = regexp.MustCompile(`^[0-9]+$`)
+	shortcodeIDRegEx = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
+
+// knownOEmbedEndpoints are used when a page doesn't advertise its oEmbed endpoint with a <link>
+var knownOEmbedEndpoints = map[string]string{
+	EmbedProviderYouTube: "https://www.youtube.com/oembed",
+	EmbedProviderVimeo:   "https://vimeo.com/api/oembed.json",
+	EmbedProviderTwitter: "https://publish.twitter.com/oembed",
+	EmbedProviderTikTok:  "https://www.tiktok.com/oembed",
+	EmbedProviderSpotify: "https://open.spotify.com/oembed",
+}
+
+// EmbedTargetFromURL returns the embeddable post, video, or track that u links to, if it's on a known service
+func EmbedTargetFromURL(u *url.URL) (*EmbedTarget, bool) {
+	if u == nil {
+		return nil, false
+	}
+	host := strings.TrimPrefix(strings.TrimPrefix(strings.ToLower(u.Hostname()), "www."), "m.")
+	var segments []string
+	for _, segment := range strings.Split(u.Path, "/") {
+		if len(segment) > 0 {
+			segments = append(segments, segment)
+		}
+	}
+
+	switch host {
+	case "youtube.com", "music.youtube.com", "youtube-nocookie.com":
+		var id string
+		switch {
+		case len(segments) == 1 && segments[0] == "watch":
+			id = u.Query().Get("v")
+		case len(segments) == 2 && (segments[0] == "embed" || segments[0] == "shorts" || segments[0] == "live" || segments[0] == "v"):
+			id = segments[1]
+		}
+		if youTubeIDRegEx.MatchString(id) {
+			return &EmbedTarget{Provider: EmbedProviderYouTube, Kind: EmbedKindVideo, ID: id, StartAt: u.Query().Get("t")}, true
+		}
+	case "youtu.be":
+		if len(segments) == 1 && youTubeIDRegEx.MatchString(segments[0]) {
+			return &EmbedTarget{Provider: EmbedProviderYouTube, Kind: EmbedKindVideo, ID: segments[0], StartAt: u.Query().Get("t")}, true
+		}
+	case "vimeo.com", "player.vimeo.com":
+		// vimeo.com/76979871, vimeo.com/channels/staffpicks/76979871, player.vimeo.com/video/76979871
+		if len(segments) > 0 && numericIDRegEx.MatchString(segments[len(segments)-1]) {
+			return &EmbedTarget{Provider: EmbedProviderVimeo, Kind: EmbedKindVideo, ID: segments[len(segments)-1]}, true
+		}
+		// vimeo.com/76979871/abcdef0123 is an unlisted video and its privacy hash
+		if len(segments) == 2 && numericIDRegEx.MatchString(segments[0]) {
+			return &EmbedTarget{Provider: EmbedProviderVimeo, Kind: EmbedKindVideo, ID: segments[0]}, true
+		}
+	case "twitter.com", "mobile.twitter.com", "x.com":
+		// twitter.com/jack/status/20 and twitter.com/i/web/status/20
+		for i := 0; i+1 < len(segments); i++ {
+			if (segments[i] == "status" || segments[i] == "statuses") && numericIDRegEx.MatchString(segments[i+1]) {
+				target := &EmbedTarget{Provider: EmbedProviderTwitter, Kind: EmbedKindPost, ID: segments[i+1]}
+				if i == 1 && segments[0] != "i" {
+					target.Author = segments[0]
+				}
+				return target, true
+			}
+		}
+	case "tiktok.com":
+		// tiktok.com/@scout2015/video/6718335390845095173
+		if len(segments) == 3 && strings.HasPrefix(segments[0], "@") && segments[1] == "video" && numericIDRegEx.MatchString(segments[2]) {
+			return &EmbedTarget{Provider: EmbedProviderTikTok, Kind: EmbedKindVideo, ID: segments[2], Author: strings.TrimPrefix(segments[0], "@")}, true
+		}
+	case "instagram.com":
+		// instagram.com/p/B_7Tgw0hkkB, instagram.com/reel/B_7Tgw0hkkB
+		if len(segments) >= 2 && (segments[0] == "p" || segments[0] == "reel" || segments[0] == "tv") && shortcodeIDRegEx.MatchString(segments[1]) {
+			kind := EmbedKindPost
+			if segments[0] != "p" {
+				kind = EmbedKindVideo
+			}
+			return &EmbedTarget{Provider: EmbedProviderInstagram, Kind: kind, ID: segments[1]}, true
+		}
+	case "open.spotify.com":
+		// open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC, optionally with an intl-xx or embed prefix
+		if len(segments) > 0 && (strings.HasPrefix(segments[0], "intl-") || segments[0] == "embed") {
+			segments = segments[1:]
+		}
+		if len(segments) == 2 && shortcodeIDRegEx.MatchString(segments[1]) {
+			switch segments[0] {
+			case EmbedKindTrack, EmbedKindAlbum, EmbedKindPlaylist, EmbedKindEpisode, EmbedKindShow:
+				return &EmbedTarget{Provider: EmbedProviderSpotify, Kind: segments[0], ID: segments[1]}, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// OEmbedURL returns the oEmbed endpoint advertised by the page's <link rel="alternate" type="application/json+oembed">
+func (p Page) OEmbedURL() (*url.URL, bool) {
+	for _, link := range p.LinksByRel("alternate") {
+		if strings.EqualFold(link.Type, "application/json+oembed") {
+			return link.URL, true
+		}
+	}
+	return nil, false
+}
+
+// OEmbed fetches and decodes the oEmbed response at oembedURL, which must ask for the JSON format
+func (f *DefaultFactory) OEmbed(ctx context.Context, oembedURL string) (_ *OEmbed, err error) {
+	defer recoverPolicyPanic(&err)
+	resp, err := f.fetch(ctx, oembedURL, http.Header{"Accept": {"application/json"}})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := new(OEmbed)
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return nil, xerrors.Errorf("Unable to decode oEmbed response %q: %w", oembedURL, err)
+	}
+	return result, nil
+}
+
+func (f *DefaultFactory) fetchOEmbed(ctx context.Context, url *url.URL) bool {
+	if f.FetchOEmbedPolicy != nil {
+		defer policyPanicked("FetchOEmbedPolicy")
+		return f.FetchOEmbedPolicy.FetchOEmbed(ctx, url)
+	}
+	return false
+}
+
+// discoverEmbed recognizes embeddable pages and fetches their oEmbed data when the policy asks for it, the
+// endpoint the page advertises is preferred over the provider's well-known one; failures are warnings
+func (f *DefaultFactory) discoverEmbed(ctx context.Context, result *Page) {
+	target, ok := EmbedTargetFromURL(result.TargetURL)
+	if !ok {
+		return
+	}
+	result.Embed = target
+	if !f.fetchOEmbed(ctx, result.TargetURL) {
+		return
+	}
+
+	if endpoint, ok := result.OEmbedURL(); ok {
+		target.Endpoint = endpoint.String()
+	} else if endpoint, ok := knownOEmbedEndpoints[target.Provider]; ok {
+		target.Endpoint = endpoint + "?" + url.Values{"url": {result.TargetURL.String()}, "format": {"json"}}.Encode()
+	} else {
+		return
+	}
+	oembed, err := f.OEmbed(ctx, target.Endpoint)
+	if err != nil {
+		result.Warnings = append(result.Warnings, PageWarning{Code: WarningRelatedFetchError, Message: err.Error()})
+		return
+	}
+	target.OEmbed = oembed
+}
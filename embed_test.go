@@ -0,0 +1,120 @@
+package resource
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type fetchOEmbed bool
+
+func (p fetchOEmbed) FetchOEmbed(context.Context, *url.URL) bool {
+	return bool(p)
+}
+
+type EmbedSuite struct {
+	suite.Suite
+}
+
+func (suite *EmbedSuite) TestEmbedTargetFromURL() {
+	tests := []struct {
+		url      string
+		provider string
+		kind     string
+		id       string
+		author   string
+	}{
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ&t=42s", EmbedProviderYouTube, EmbedKindVideo, "dQw4w9WgXcQ", ""},
+		{"https://youtu.be/dQw4w9WgXcQ", EmbedProviderYouTube, EmbedKindVideo, "dQw4w9WgXcQ", ""},
+		{"https://m.youtube.com/shorts/dQw4w9WgXcQ", EmbedProviderYouTube, EmbedKindVideo, "dQw4w9WgXcQ", ""},
+		{"https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ", EmbedProviderYouTube, EmbedKindVideo, "dQw4w9WgXcQ", ""},
+		{"https://vimeo.com/76979871", EmbedProviderVimeo, EmbedKindVideo, "76979871", ""},
+		{"https://vimeo.com/channels/staffpicks/76979871", EmbedProviderVimeo, EmbedKindVideo, "76979871", ""},
+		{"https://vimeo.com/76979871/abcdef0123", EmbedProviderVimeo, EmbedKindVideo, "76979871", ""},
+		{"https://player.vimeo.com/video/76979871", EmbedProviderVimeo, EmbedKindVideo, "76979871", ""},
+		{"https://twitter.com/jack/status/20", EmbedProviderTwitter, EmbedKindPost, "20", "jack"},
+		{"https://x.com/jack/status/20/photo/1", EmbedProviderTwitter, EmbedKindPost, "20", "jack"},
+		{"https://twitter.com/i/web/status/20", EmbedProviderTwitter, EmbedKindPost, "20", ""},
+		{"https://www.tiktok.com/@scout2015/video/6718335390845095173", EmbedProviderTikTok, EmbedKindVideo, "6718335390845095173", "scout2015"},
+		{"https://www.instagram.com/p/B_7Tgw0hkkB/", EmbedProviderInstagram, EmbedKindPost, "B_7Tgw0hkkB", ""},
+		{"https://open.spotify.com/intl-de/track/4uLU6hMCjMI75M1A2tKUQC", EmbedProviderSpotify, EmbedKindTrack, "4uLU6hMCjMI75M1A2tKUQC", ""},
+	}
+	for _, test := range tests {
+		u, _ := url.Parse(test.url)
+		target, ok := EmbedTargetFromURL(u)
+		suite.Require().True(ok, test.url)
+		suite.Equal(test.provider, target.Provider, test.url)
+		suite.Equal(test.kind, target.Kind, test.url)
+		suite.Equal(test.id, target.ID, test.url)
+		suite.Equal(test.author, target.Author, test.url)
+	}
+
+	for _, notEmbed := range []string{"https://www.youtube.com/", "https://www.youtube.com/watch?v=short", "https://vimeo.com/about", "https://twitter.com/jack", "https://example.com/status/20"} {
+		u, _ := url.Parse(notEmbed)
+		_, ok := EmbedTargetFromURL(u)
+		suite.False(ok, notEmbed)
+	}
+}
+
+func (suite *EmbedSuite) TestAdvertisedOEmbed() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://vimeo.com/76979871", archivedResponse(200, http.Header{"Content-Type": {"text/html"}},
+		`<html><head><link rel="alternate" type="application/json+oembed" href="https://vimeo.com/api/oembed.json?url=https%3A%2F%2Fvimeo.com%2F76979871"></head></html>`))
+	archive.Add("https://vimeo.com/api/oembed.json?url=https%3A%2F%2Fvimeo.com%2F76979871", archivedResponse(200, http.Header{"Content-Type": {"application/json"}},
+		`{"type": "video", "version": "1.0", "title": "The New Vimeo Player", "author_name": "Vimeo", "width": 640, "height": 360,
+		"html": "<iframe src=\"https://player.vimeo.com/video/76979871\"></iframe>", "thumbnail_url": "https://i.vimeocdn.com/video/452001751_640.jpg"}`))
+
+	content, err := NewFactory(archive).PageFromURL(context.Background(), "https://vimeo.com/76979871")
+	suite.Nil(err, "Should not get an error")
+	embed := content.(*Page).Embed
+	suite.Require().NotNil(embed)
+	suite.Equal("76979871", embed.ID)
+	suite.Nil(embed.OEmbed, "oEmbed should not be fetched without a policy")
+
+	content, err = NewFactory(archive, fetchOEmbed(true)).PageFromURL(context.Background(), "https://vimeo.com/76979871")
+	suite.Nil(err, "Should not get an error")
+	embed = content.(*Page).Embed
+	suite.Require().NotNil(embed.OEmbed)
+	suite.Equal("video", embed.OEmbed.Type)
+	suite.Equal("The New Vimeo Player", embed.OEmbed.Title)
+	suite.Equal(640, embed.OEmbed.Width)
+	suite.Equal(360, embed.OEmbed.Height)
+	suite.Contains(embed.OEmbed.HTML, "player.vimeo.com")
+}
+
+func (suite *EmbedSuite) TestWellKnownOEmbed() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://youtu.be/dQw4w9WgXcQ", archivedResponse(301, http.Header{"Location": {"https://www.youtube.com/watch?v=dQw4w9WgXcQ"}}, ""))
+	archive.Add("https://www.youtube.com/watch?v=dQw4w9WgXcQ", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, testHTMLPage))
+	archive.Add("https://www.youtube.com/oembed?format=json&url=https%3A%2F%2Fwww.youtube.com%2Fwatch%3Fv%3DdQw4w9WgXcQ", archivedResponse(200, http.Header{"Content-Type": {"application/json"}},
+		`{"type": "video", "version": "1.0", "title": "Never Gonna Give You Up", "provider_name": "YouTube"}`))
+
+	content, err := NewFactory(archive, fetchOEmbed(true)).PageFromURL(context.Background(), "https://youtu.be/dQw4w9WgXcQ")
+	suite.Nil(err, "Should not get an error")
+	embed := content.(*Page).Embed
+	suite.Require().NotNil(embed)
+	suite.Equal("dQw4w9WgXcQ", embed.ID)
+	suite.Require().NotNil(embed.OEmbed, "The provider's endpoint should be used when none is advertised")
+	suite.Equal("Never Gonna Give You Up", embed.OEmbed.Title)
+}
+
+func (suite *EmbedSuite) TestOEmbedFailureIsWarning() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://twitter.com/jack/status/20", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, testHTMLPage))
+
+	content, err := NewFactory(archive, fetchOEmbed(true)).PageFromURL(context.Background(), "https://twitter.com/jack/status/20")
+	suite.Nil(err, "Should not get an error")
+	page := content.(*Page)
+	suite.Require().NotNil(page.Embed)
+	suite.Equal("20", page.Embed.ID)
+	suite.Nil(page.Embed.OEmbed)
+	suite.Require().NotEmpty(page.Warnings)
+	suite.Equal(WarningRelatedFetchError, page.Warnings[len(page.Warnings)-1].Code)
+}
+
+func TestEmbedSuite(t *testing.T) {
+	suite.Run(t, new(EmbedSuite))
+}
@@ -1,19 +1,220 @@
 package resource
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"golang.org/x/xerrors"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
 )
 
 // xErrorsFrameCaller is passed into error functions to indicate the default stack frame
 const xErrorsFrameCaller = 1
 
+// maxErrorStackDepth bounds the frames recorded when SetErrorStackDepth asks for the whole stack
+const maxErrorStackDepth = 64
+
+// errorStackDepth is how many frames errors record, see SetErrorStackDepth
+var errorStackDepth int32 = 1
+
+// SetErrorStackDepth sets how many frames of the call stack errors record, it's safe to call at any time. The default,
+// 1, records only where the error was reported from; less than 1 records the whole stack.
+func SetErrorStackDepth(depth int) {
+	atomic.StoreInt32(&errorStackDepth, int32(depth))
+}
+
+// ErrorFrames is where an error was reported from, followed by its callers when SetErrorStackDepth asks for them
+type ErrorFrames []xerrors.Frame
+
+// Format prints each frame, as xerrors.Frame does, when the error's detail is asked for
+func (frames ErrorFrames) Format(p xerrors.Printer) {
+	for _, frame := range frames {
+		frame.Format(p)
+	}
+}
+
+// first is the frame the error was reported from, for the Frame field errors had before they recorded ErrorFrames
+func (frames ErrorFrames) first() xerrors.Frame {
+	if len(frames) == 0 {
+		return xerrors.Frame{}
+	}
+	return frames[0]
+}
+
+// formatFrames prints an error's Frames, or its Frame if it was built without them (e.g. by a caller)
+func formatFrames(p xerrors.Printer, frame xerrors.Frame, frames ErrorFrames) {
+	if len(frames) == 0 {
+		frame.Format(p)
+		return
+	}
+	frames.Format(p)
+}
+
+// callerFrames is xerrors.Caller for as many frames as the error stack depth asks for, skip is the same as Caller's
+// so that helpers which create errors for their callers can pass xErrorsFrameCaller + 1
+func callerFrames(skip int) ErrorFrames {
+	depth := int(atomic.LoadInt32(&errorStackDepth))
+	if depth < 1 || depth > maxErrorStackDepth {
+		depth = maxErrorStackDepth
+	}
+	result := make(ErrorFrames, 0, depth)
+	for i := 0; i < depth; i++ {
+		frame := xerrors.Caller(skip + 1 + i)
+		if frame == (xerrors.Frame{}) {
+			break
+		}
+		result = append(result, frame)
+	}
+	return result
+}
+
+// The codes errors are reported with, as LECTIORES-<code>, and returned by CodeOf. They're stable: codes are only ever
+// added, never renumbered.
+const (
+	ErrorCodeUnknown               = 0
+	ErrorCodeTargetURLBlank        = 50
+	ErrorCodeTargetURLNil          = 51
+	ErrorCodeUnknownOption         = 52
+	ErrorCodeInvalidConfiguration  = 53
+	ErrorCodeRequestBuild          = 100
+	ErrorCodeDNS                   = 101
+	ErrorCodeTLS                   = 102
+	ErrorCodeTimeout               = 103
+	ErrorCodeConnection            = 104
+	ErrorCodeHTTPStatus            = 200
+	ErrorCodeContentType           = 250
+	ErrorCodeParse                 = 251
+	ErrorCodeTypeMismatch          = 300
+	ErrorCodeChecksumMismatch      = 301
+	ErrorCodeContentLengthMismatch = 302
+	ErrorCodeDownload              = 303
+	ErrorCodeInsufficientDiskSpace = 304
+	ErrorCodeNotArchived           = 400
+	ErrorCodeBudgetExceeded        = 500
+	ErrorCodeTenantRefused         = 501
+	ErrorCodePolicyPanic           = 502
+)
+
+// The categories error codes are grouped into, returned by CategoryOf
+const (
+	ErrorCategoryUnknown  = "unknown"
+	ErrorCategoryInput    = "input"
+	ErrorCategoryRequest  = "request"
+	ErrorCategoryDNS      = "dns"
+	ErrorCategoryTLS      = "tls"
+	ErrorCategoryTimeout  = "timeout"
+	ErrorCategoryNetwork  = "network"
+	ErrorCategoryStatus   = "status"
+	ErrorCategoryParse    = "parse"
+	ErrorCategoryDownload = "download"
+	ErrorCategoryPolicy   = "policy"
+	ErrorCategoryOffline  = "offline"
+)
+
+var errorCodeCategories = map[int]string{
+	ErrorCodeTargetURLBlank:        ErrorCategoryInput,
+	ErrorCodeTargetURLNil:          ErrorCategoryInput,
+	ErrorCodeUnknownOption:         ErrorCategoryInput,
+	ErrorCodeInvalidConfiguration:  ErrorCategoryInput,
+	ErrorCodeRequestBuild:          ErrorCategoryRequest,
+	ErrorCodeDNS:                   ErrorCategoryDNS,
+	ErrorCodeTLS:                   ErrorCategoryTLS,
+	ErrorCodeTimeout:               ErrorCategoryTimeout,
+	ErrorCodeConnection:            ErrorCategoryNetwork,
+	ErrorCodeHTTPStatus:            ErrorCategoryStatus,
+	ErrorCodeContentType:           ErrorCategoryParse,
+	ErrorCodeParse:                 ErrorCategoryParse,
+	ErrorCodeTypeMismatch:          ErrorCategoryPolicy,
+	ErrorCodeChecksumMismatch:      ErrorCategoryDownload,
+	ErrorCodeContentLengthMismatch: ErrorCategoryDownload,
+	ErrorCodeDownload:              ErrorCategoryDownload,
+	ErrorCodeInsufficientDiskSpace: ErrorCategoryDownload,
+	ErrorCodeNotArchived:           ErrorCategoryOffline,
+	ErrorCodeBudgetExceeded:        ErrorCategoryPolicy,
+	ErrorCodeTenantRefused:         ErrorCategoryPolicy,
+	ErrorCodePolicyPanic:           ErrorCategoryPolicy,
+}
+
+// codedError is satisfied by every error type of this package
+type codedError interface {
+	ErrorCode() int
+}
+
+// CodeOf returns the code of the first coded error in err's chain. Errors from outside this package, such as those of
+// the net and crypto/tls packages, are classified too. ErrorCodeUnknown is returned for nil or unrecognized errors.
+func CodeOf(err error) int {
+	if err == nil {
+		return ErrorCodeUnknown
+	}
+	var coded codedError
+	if xerrors.As(err, &coded) {
+		return coded.ErrorCode()
+	}
+	return transportErrorCode(err)
+}
+
+// CategoryOf returns the category (one of the ErrorCategory* constants) of err's code
+func CategoryOf(err error) string {
+	if category, ok := errorCodeCategories[CodeOf(err)]; ok {
+		return category
+	}
+	return ErrorCategoryUnknown
+}
+
+// transportErrorCode classifies the errors of making a request, ErrorCodeUnknown is returned if err isn't one of them
+func transportErrorCode(err error) int {
+	var dnsErr *net.DNSError
+	if xerrors.As(err, &dnsErr) {
+		if dnsErr.IsTimeout {
+			return ErrorCodeTimeout
+		}
+		return ErrorCodeDNS
+	}
+	var certErr x509.CertificateInvalidError
+	var hostnameErr x509.HostnameError
+	var authorityErr x509.UnknownAuthorityError
+	var recordErr tls.RecordHeaderError
+	if xerrors.As(err, &certErr) || xerrors.As(err, &hostnameErr) || xerrors.As(err, &authorityErr) || xerrors.As(err, &recordErr) {
+		return ErrorCodeTLS
+	}
+	if xerrors.Is(err, context.DeadlineExceeded) {
+		return ErrorCodeTimeout
+	}
+	var netErr net.Error
+	if xerrors.As(err, &netErr) && netErr.Timeout() {
+		return ErrorCodeTimeout
+	}
+	var opErr *net.OpError
+	var urlErr *url.Error
+	if xerrors.As(err, &opErr) || xerrors.As(err, &urlErr) {
+		return ErrorCodeConnection
+	}
+	return ErrorCodeUnknown
+}
+
 // Error is coded error for more granular tracking
 type Error struct {
 	URL string
 	Message string
 	Code    int
+	Err     error // the cause, if there is one
 	Frame   xerrors.Frame
+	Frames  ErrorFrames // Frame followed by its callers, see SetErrorStackDepth
+}
+
+// ErrorCode satisfies the interface CodeOf uses
+func (e Error) ErrorCode() int {
+	return e.Code
+}
+
+// Unwrap returns the cause of the error, if there is one
+func (e Error) Unwrap() error {
+	return e.Err
 }
 
 // FormatError will print a simple message to the Printer object. This will be what you see when you Println or use %s/%v in a formatted print statement.
@@ -23,8 +224,8 @@ func (e Error) FormatError(p xerrors.Printer) error {
 	} else {
 		p.Printf("LECTIORES-%d %s", e.Code, e.Message)
 	}
-	e.Frame.Format(p)
-	return nil
+	formatFrames(p, e.Frame, e.Frames)
+	return e.Err
 }
 
 // Format provide backwards compatibility with pre-xerrors package
@@ -37,19 +238,100 @@ func (e Error) Error() string {
 	return fmt.Sprint(e)
 }
 
-func targetURLIsBlankError(frame xerrors.Frame) *Error {
+func targetURLIsBlankError(frames ErrorFrames) *Error {
 	return &Error{
 		Message: "TargetURL is blank",
-		Code:    50,
-		Frame:   frame,
+		Code:    ErrorCodeTargetURLBlank,
+		Frame:   frames.first(),
+		Frames:  frames,
 	}
 }
 
-func targetURLIsNilError(frame xerrors.Frame) *Error {
+func targetURLIsNilError(frames ErrorFrames) *Error {
 	return &Error{
 		Message: "TargetURL is Nil",
-		Code:    51,
-		Frame:   frame,
+		Code:    ErrorCodeTargetURLNil,
+		Frame:   frames.first(),
+		Frames:  frames,
+	}
+}
+
+func unknownOptionError(option interface{}, frames ErrorFrames) *Error {
+	return &Error{
+		Message: fmt.Sprintf("Option of type %T isn't used here", option),
+		Code:    ErrorCodeUnknownOption,
+		Frame:   frames.first(),
+		Frames:  frames,
+	}
+}
+
+func attachmentTypeMismatchError(url string, mismatch *TypeMismatchWarning, frames ErrorFrames) *Error {
+	return &Error{
+		URL:     url,
+		Message: fmt.Sprintf("Declared type %s does not match sniffed type %s", mismatch.DeclaredMediaType, mismatch.SniffedMediaType),
+		Code:    ErrorCodeTypeMismatch,
+		Frame:   frames.first(),
+		Frames:  frames,
+	}
+}
+
+// requestError is returned when a request can't be made, its code is from the cause
+func requestError(url string, message string, err error, frames ErrorFrames) *Error {
+	code := transportErrorCode(err)
+	if code == ErrorCodeUnknown {
+		code = ErrorCodeConnection
+	}
+	return &Error{
+		URL:     url,
+		Message: message,
+		Code:    code,
+		Err:     err,
+		Frame:   frames.first(),
+		Frames:  frames,
+	}
+}
+
+func requestBuildError(url string, err error, frames ErrorFrames) *Error {
+	return &Error{
+		URL:     url,
+		Message: "Unable to create HTTP request",
+		Code:    ErrorCodeRequestBuild,
+		Err:     err,
+		Frame:   frames.first(),
+		Frames:  frames,
+	}
+}
+
+func contentTypeError(url string, err error, frames ErrorFrames) *Error {
+	return &Error{
+		URL:     url,
+		Message: "Unable to determine the type",
+		Code:    ErrorCodeContentType,
+		Err:     err,
+		Frame:   frames.first(),
+		Frames:  frames,
+	}
+}
+
+func parseError(url string, message string, err error, frames ErrorFrames) *Error {
+	return &Error{
+		URL:     url,
+		Message: message,
+		Code:    ErrorCodeParse,
+		Err:     err,
+		Frame:   frames.first(),
+		Frames:  frames,
+	}
+}
+
+func downloadError(url string, message string, err error, frames ErrorFrames) *Error {
+	return &Error{
+		URL:     url,
+		Message: message,
+		Code:    ErrorCodeDownload,
+		Err:     err,
+		Frame:   frames.first(),
+		Frames:  frames,
 	}
 }
 
@@ -57,13 +339,15 @@ func targetURLIsNilError(frame xerrors.Frame) *Error {
 type InvalidHTTPRespStatusCodeError struct {
 	URL string
 	HTTPStatusCode int
+	Header  http.Header // of the response, e.g. for the validators of a 304 or the Retry-After of a 503
 	Frame   xerrors.Frame
+	Frames  ErrorFrames
 }
 
 // FormatError will print a simple message to the Printer object. This will be what you see when you Println or use %s/%v in a formatted print statement.
 func (e InvalidHTTPRespStatusCodeError) FormatError(p xerrors.Printer) error {
 	p.Printf("LECTIORES-200 Expected HTTP Response Status Code 200, got %d (%s)", e.HTTPStatusCode, e.URL)
-	e.Frame.Format(p)
+	formatFrames(p, e.Frame, e.Frames)
 	return nil
 }
 
@@ -76,3 +360,230 @@ func (e InvalidHTTPRespStatusCodeError) Format(f fmt.State, c rune) {
 func (e InvalidHTTPRespStatusCodeError) Error() string {
 	return fmt.Sprint(e)
 }
+
+// ErrorCode satisfies the interface CodeOf uses
+func (e InvalidHTTPRespStatusCodeError) ErrorCode() int {
+	return ErrorCodeHTTPStatus
+}
+
+// ChecksumMismatchError is thrown when downloaded content doesn't match its expected digest
+type ChecksumMismatchError struct {
+	URL       string
+	Algorithm string
+	Expected  string
+	Actual    string
+	Frame     xerrors.Frame
+	Frames    ErrorFrames
+}
+
+// FormatError will print a simple message to the Printer object. This will be what you see when you Println or use %s/%v in a formatted print statement.
+func (e ChecksumMismatchError) FormatError(p xerrors.Printer) error {
+	p.Printf("LECTIORES-301 Expected %s checksum %s, got %s (%s)", e.Algorithm, e.Expected, e.Actual, e.URL)
+	formatFrames(p, e.Frame, e.Frames)
+	return nil
+}
+
+// Format provide backwards compatibility with pre-xerrors package
+func (e ChecksumMismatchError) Format(f fmt.State, c rune) {
+	xerrors.FormatError(e, f, c)
+}
+
+// Format provide backwards compatibility with pre-xerrors package
+func (e ChecksumMismatchError) Error() string {
+	return fmt.Sprint(e)
+}
+
+// ErrorCode satisfies the interface CodeOf uses
+func (e ChecksumMismatchError) ErrorCode() int {
+	return ErrorCodeChecksumMismatch
+}
+
+// InsufficientDiskSpaceError is thrown before a download starts when its Content-Length is more than the space
+// available where it would be written
+type InsufficientDiskSpaceError struct {
+	URL       string
+	Dir       string
+	Required  int64
+	Available int64
+	Frame     xerrors.Frame
+	Frames    ErrorFrames
+}
+
+// FormatError will print a simple message to the Printer object. This will be what you see when you Println or use %s/%v in a formatted print statement.
+func (e InsufficientDiskSpaceError) FormatError(p xerrors.Printer) error {
+	p.Printf("LECTIORES-304 Download needs %d bytes but only %d are available in %q (%s)", e.Required, e.Available, e.Dir, e.URL)
+	formatFrames(p, e.Frame, e.Frames)
+	return nil
+}
+
+// Format provide backwards compatibility with pre-xerrors package
+func (e InsufficientDiskSpaceError) Format(f fmt.State, c rune) {
+	xerrors.FormatError(e, f, c)
+}
+
+// Error returns the space needed and available
+func (e InsufficientDiskSpaceError) Error() string {
+	return fmt.Sprint(e)
+}
+
+// ErrorCode satisfies the interface CodeOf uses
+func (e InsufficientDiskSpaceError) ErrorCode() int {
+	return ErrorCodeInsufficientDiskSpace
+}
+
+// NotArchivedError is thrown when an offline factory's ResponseArchive has no response for a URL
+type NotArchivedError struct {
+	URL    string
+	Frame  xerrors.Frame
+	Frames ErrorFrames
+}
+
+// FormatError will print a simple message to the Printer object. This will be what you see when you Println or use %s/%v in a formatted print statement.
+func (e NotArchivedError) FormatError(p xerrors.Printer) error {
+	p.Printf("LECTIORES-400 Not archived, factory is offline (%s)", e.URL)
+	formatFrames(p, e.Frame, e.Frames)
+	return nil
+}
+
+// Format provide backwards compatibility with pre-xerrors package
+func (e NotArchivedError) Format(f fmt.State, c rune) {
+	xerrors.FormatError(e, f, c)
+}
+
+// Format provide backwards compatibility with pre-xerrors package
+func (e NotArchivedError) Error() string {
+	return fmt.Sprint(e)
+}
+
+// ErrorCode satisfies the interface CodeOf uses
+func (e NotArchivedError) ErrorCode() int {
+	return ErrorCodeNotArchived
+}
+
+// ContentLengthMismatchError is thrown when a download is truncated (or longer than its declared Content-Length)
+type ContentLengthMismatchError struct {
+	URL      string
+	Declared int64
+	Received int64
+	Frame    xerrors.Frame
+	Frames   ErrorFrames
+}
+
+// FormatError will print a simple message to the Printer object. This will be what you see when you Println or use %s/%v in a formatted print statement.
+func (e ContentLengthMismatchError) FormatError(p xerrors.Printer) error {
+	p.Printf("LECTIORES-302 Content-Length declared %d bytes, received %d (%s)", e.Declared, e.Received, e.URL)
+	formatFrames(p, e.Frame, e.Frames)
+	return nil
+}
+
+// Format provide backwards compatibility with pre-xerrors package
+func (e ContentLengthMismatchError) Format(f fmt.State, c rune) {
+	xerrors.FormatError(e, f, c)
+}
+
+// Format provide backwards compatibility with pre-xerrors package
+func (e ContentLengthMismatchError) Error() string {
+	return fmt.Sprint(e)
+}
+
+// ErrorCode satisfies the interface CodeOf uses
+func (e ContentLengthMismatchError) ErrorCode() int {
+	return ErrorCodeContentLengthMismatch
+}
+
+// BudgetExceededError is thrown when a fetch used more of its FetchBudget than allowed, Limit is one of the
+// BudgetLimit* constants
+type BudgetExceededError struct {
+	URL    string
+	Limit  string
+	Usage  BudgetUsage
+	Frame  xerrors.Frame
+	Frames ErrorFrames
+}
+
+// FormatError will print a simple message to the Printer object. This will be what you see when you Println or use %s/%v in a formatted print statement.
+func (e BudgetExceededError) FormatError(p xerrors.Printer) error {
+	p.Printf("LECTIORES-500 Fetch budget exceeded (%s) after %v, %d bytes, %d redirects (%s)", e.Limit, e.Usage.Duration, e.Usage.Bytes, e.Usage.Redirects, e.URL)
+	formatFrames(p, e.Frame, e.Frames)
+	return nil
+}
+
+// Format provide backwards compatibility with pre-xerrors package
+func (e BudgetExceededError) Format(f fmt.State, c rune) {
+	xerrors.FormatError(e, f, c)
+}
+
+// Format provide backwards compatibility with pre-xerrors package
+func (e BudgetExceededError) Error() string {
+	return fmt.Sprint(e)
+}
+
+// ErrorCode satisfies the interface CodeOf uses
+func (e BudgetExceededError) ErrorCode() int {
+	return ErrorCodeBudgetExceeded
+}
+
+// TenantRefusedError is thrown when a TenantRouter doesn't let a tenant resolve a URL, Limit is one of the
+// TenantLimit* constants
+type TenantRefusedError struct {
+	URL    string
+	Tenant string
+	Limit  string
+	Frame  xerrors.Frame
+	Frames ErrorFrames
+}
+
+// FormatError will print a simple message to the Printer object. This will be what you see when you Println or use %s/%v in a formatted print statement.
+func (e TenantRefusedError) FormatError(p xerrors.Printer) error {
+	p.Printf("LECTIORES-501 Tenant %q refused (%s): %s", e.Tenant, e.Limit, e.URL)
+	formatFrames(p, e.Frame, e.Frames)
+	return nil
+}
+
+// Format provide backwards compatibility with pre-xerrors package
+func (e TenantRefusedError) Format(f fmt.State, c rune) {
+	xerrors.FormatError(e, f, c)
+}
+
+// Format provide backwards compatibility with pre-xerrors package
+func (e TenantRefusedError) Error() string {
+	return fmt.Sprint(e)
+}
+
+// ErrorCode satisfies the interface CodeOf uses
+func (e TenantRefusedError) ErrorCode() int {
+	return ErrorCodeTenantRefused
+}
+
+// InvalidConfigurationError is returned by DefaultFactory.Validate, and by PageFromURL when the factory is invalid
+type InvalidConfigurationError struct {
+	Problems []ConfigurationProblem
+	Frame    xerrors.Frame
+	Frames   ErrorFrames
+}
+
+// FormatError will print a simple message to the Printer object. This will be what you see when you Println or use %s/%v in a formatted print statement.
+func (e InvalidConfigurationError) FormatError(p xerrors.Printer) error {
+	problems := make([]string, 0, len(e.Problems))
+	for _, problem := range e.Problems {
+		problems = append(problems, problem.Option+" "+problem.Problem)
+	}
+	p.Printf("LECTIORES-53 Invalid configuration: %s", strings.Join(problems, "; "))
+	formatFrames(p, e.Frame, e.Frames)
+	return nil
+}
+
+// Format provide backwards compatibility with pre-xerrors package
+func (e InvalidConfigurationError) Format(f fmt.State, c rune) {
+	xerrors.FormatError(e, f, c)
+}
+
+// Format provide backwards compatibility with pre-xerrors package
+func (e InvalidConfigurationError) Error() string {
+	return fmt.Sprint(e)
+}
+
+// ErrorCode satisfies the interface CodeOf uses
+func (e InvalidConfigurationError) ErrorCode() int {
+	return ErrorCodeInvalidConfiguration
+}
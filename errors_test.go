@@ -0,0 +1,107 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/xerrors"
+)
+
+type ErrorsSuite struct {
+	suite.Suite
+}
+
+func (suite *ErrorsSuite) TearDownTest() {
+	SetErrorStackDepth(1)
+}
+
+func (suite *ErrorsSuite) nilTargetURLError() error {
+	_, err := HashNamingStrategy{}.AttachmentName(context.Background(), nil, nil)
+	return err
+}
+
+func (suite *ErrorsSuite) TestDefaultDepth() {
+	err := suite.nilTargetURLError()
+	var coded *Error
+	suite.True(xerrors.As(err, &coded), "Should be a coded error")
+	suite.Len(coded.Frames, 1, "Only the frame the error was reported from should be recorded by default")
+	suite.Equal(coded.Frames[0], coded.Frame, "Frame should still be where the error was reported from")
+	suite.Contains(fmt.Sprintf("%+v", err), "nilTargetURLError", "The frame should be the caller of the failing method")
+}
+
+func (suite *ErrorsSuite) TestFullStack() {
+	SetErrorStackDepth(0)
+	err := suite.nilTargetURLError()
+	var coded *Error
+	suite.True(xerrors.As(err, &coded), "Should be a coded error")
+	suite.True(len(coded.Frames) > 2, "The whole stack should be recorded")
+	suite.Equal(coded.Frames[0], coded.Frame, "Frame should still be where the error was reported from")
+	suite.Contains(fmt.Sprintf("%+v", err), "TestFullStack", "Callers should be printed with the detail")
+	suite.Equal("LECTIORES-51 TargetURL is Nil", err.Error(), "Frames should only be printed with the detail")
+
+	SetErrorStackDepth(2)
+	err = suite.nilTargetURLError()
+	suite.True(xerrors.As(err, &coded))
+	suite.Len(coded.Frames, 2)
+}
+
+func (suite *ErrorsSuite) TestFrameOnly() {
+	err := &InvalidHTTPRespStatusCodeError{URL: "https://www.netspective.com/", HTTPStatusCode: 404, Frame: xerrors.Caller(0)}
+	suite.Contains(fmt.Sprintf("%+v", err), "TestFrameOnly", "Errors built with only a Frame should still print it")
+}
+
+func (suite *ErrorsSuite) TestCodeOf() {
+	suite.Equal(ErrorCodeTargetURLNil, CodeOf(suite.nilTargetURLError()))
+	suite.Equal(ErrorCodeNotArchived, CodeOf(xerrors.Errorf("Unable to fetch: %w", &NotArchivedError{URL: "https://www.netspective.com/"})), "Wrapped errors should keep their code")
+	suite.Equal(ErrorCodeHTTPStatus, CodeOf(&InvalidHTTPRespStatusCodeError{HTTPStatusCode: 404}))
+	suite.Equal(ErrorCodeChecksumMismatch, CodeOf(&ChecksumMismatchError{}))
+	suite.Equal(ErrorCodeContentLengthMismatch, CodeOf(&ContentLengthMismatchError{}))
+	suite.Equal(ErrorCodeBudgetExceeded, CodeOf(&BudgetExceededError{}))
+
+	suite.Equal(ErrorCodeDNS, CodeOf(&url.Error{Op: "Get", URL: "https://nowhere.invalid/", Err: &net.DNSError{Err: "no such host", Name: "nowhere.invalid"}}))
+	suite.Equal(ErrorCodeTimeout, CodeOf(xerrors.Errorf("Unable to fetch: %w", context.DeadlineExceeded)))
+	suite.Equal(ErrorCodeConnection, CodeOf(&net.OpError{Op: "dial", Err: errors.New("connection refused")}))
+	suite.Equal(ErrorCodeUnknown, CodeOf(errors.New("something else")))
+	suite.Equal(ErrorCodeUnknown, CodeOf(nil))
+}
+
+func (suite *ErrorsSuite) TestCategoryOf() {
+	suite.Equal(ErrorCategoryInput, CategoryOf(suite.nilTargetURLError()))
+	suite.Equal(ErrorCategoryStatus, CategoryOf(&InvalidHTTPRespStatusCodeError{HTTPStatusCode: 500}))
+	suite.Equal(ErrorCategoryTimeout, CategoryOf(context.DeadlineExceeded))
+	suite.Equal(ErrorCategoryPolicy, CategoryOf(&BudgetExceededError{}))
+	suite.Equal(ErrorCategoryUnknown, CategoryOf(errors.New("something else")))
+}
+
+func (suite *ErrorsSuite) TestFactoryErrorsAreCoded() {
+	ctx := context.Background()
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://www.netspective.com/broken", archivedResponse(200, http.Header{"Content-Type": {"text/html; charset"}}, testHTMLPage))
+	archive.Add("https://www.netspective.com/archive.mhtml", archivedResponse(200, http.Header{"Content-Type": {"multipart/related"}}, ""))
+	factory := NewFactory(archive)
+
+	_, err := factory.PageFromURL(ctx, "https://www.netspective.com/missing")
+	suite.Equal(ErrorCodeNotArchived, CodeOf(err))
+	suite.Equal(ErrorCategoryOffline, CategoryOf(err))
+
+	_, err = factory.PageFromURL(ctx, "https://www.netspective.com/broken")
+	suite.Equal(ErrorCodeContentType, CodeOf(err))
+
+	_, err = factory.PageFromURL(ctx, "https://www.netspective.com/archive.mhtml")
+	suite.Equal(ErrorCodeParse, CodeOf(err))
+	suite.Contains(err.Error(), "LECTIORES-251 Unable to parse multipart content", "The cause should follow the coded message")
+	suite.Contains(err.Error(), "no boundary parameter")
+
+	_, err = factory.PageFromURL(ctx, "")
+	suite.Equal(ErrorCodeTargetURLBlank, CodeOf(err))
+}
+
+func TestErrorsSuite(t *testing.T) {
+	suite.Run(t, new(ErrorsSuite))
+}
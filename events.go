@@ -0,0 +1,129 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// Types of Event
+const (
+	EventPageResolved         = "page-resolved"
+	EventRedirectDetected     = "redirect-detected"
+	EventAttachmentDownloaded = "attachment-downloaded"
+	EventContentChanged       = "content-changed"
+)
+
+// Event tells an EventSink about something the factory did, URL is the one that was asked for
+type Event struct {
+	Type        string     `json:"type"`
+	Time        time.Time  `json:"time"`
+	URL         string     `json:"url"`
+	Page        *Page      `json:"page,omitempty"`
+	RedirectURL string     `json:"redirectURL,omitempty"` // where a redirect (HTTP, meta refresh, or Refresh header) leads
+	Attachment  Attachment `json:"attachment,omitempty"`
+	Diff        *PageDiff  `json:"diff,omitempty"` // what changed, for EventContentChanged
+	RequestIdentity
+}
+
+// EventSink is passed into options to be told about pages being resolved, redirects, downloads, and content changes
+// found by RevalidateStore, so that other systems can react without polling. Events are advisory, so an error from
+// the sink doesn't fail what the factory was doing.
+type EventSink interface {
+	HandleEvent(ctx context.Context, event Event) error
+}
+
+// ChannelEventSink is an EventSink which sends events on a channel, waiting for a receiver unless the context is done
+type ChannelEventSink chan Event
+
+// HandleEvent satisfies EventSink method
+func (c ChannelEventSink) HandleEvent(ctx context.Context, event Event) error {
+	select {
+	case c <- event:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// WebhookEventSink is an EventSink which POSTs each event as JSON to a URL
+type WebhookEventSink struct {
+	URL    string
+	Client *http.Client // http.DefaultClient if not set
+	Header http.Header  // sent with every request, e.g. for authorization
+}
+
+// NewWebhookEventSink creates an EventSink which POSTs to url
+func NewWebhookEventSink(url string) *WebhookEventSink {
+	return &WebhookEventSink{URL: url, Client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// HandleEvent satisfies EventSink method, any status other than 2xx is an error
+func (w *WebhookEventSink) HandleEvent(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return xerrors.Errorf("Unable to encode %s event for %q: %w", event.Type, event.URL, err)
+	}
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return xerrors.Errorf("Unable to create webhook request: %w", err)
+	}
+	req = req.WithContext(ctx)
+	for key, values := range w.Header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return xerrors.Errorf("Unable to send %s event to webhook: %w", event.Type, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook %s answered %s event with status %d", w.URL, event.Type, resp.StatusCode)
+	}
+	return nil
+}
+
+// emit hands an event to the EventSink, if there is one
+func (f *DefaultFactory) emit(ctx context.Context, event Event) {
+	if f.EventSink == nil {
+		return
+	}
+	event.Time = f.clock().Now()
+	event.RequestIdentity = RequestIdentityFromContext(ctx)
+	guardPolicy("EventSink", func() { f.EventSink.HandleEvent(ctx, event) })
+}
+
+// emitPageEvents reports what resolving urlText produced
+func (f *DefaultFactory) emitPageEvents(ctx context.Context, urlText string, content Content) {
+	page, ok := content.(*Page)
+	if f.EventSink == nil || !ok {
+		return
+	}
+	f.emit(ctx, Event{Type: EventPageResolved, URL: urlText, Page: page})
+	if page.TargetURL != nil && page.TargetURL.String() != urlText {
+		f.emit(ctx, Event{Type: EventRedirectDetected, URL: urlText, Page: page, RedirectURL: page.TargetURL.String()})
+	}
+	if redirect, redirectURL := page.Redirect(); redirect {
+		if target, _, ok := page.RedirectTarget(); ok {
+			redirectURL = target.String()
+		}
+		f.emit(ctx, Event{Type: EventRedirectDetected, URL: urlText, Page: page, RedirectURL: redirectURL})
+	}
+	if page.DownloadedAttachment != nil {
+		f.emit(ctx, Event{Type: EventAttachmentDownloaded, URL: urlText, Page: page, Attachment: page.DownloadedAttachment})
+	}
+}
@@ -0,0 +1,70 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type EventsSuite struct {
+	suite.Suite
+}
+
+func (suite *EventsSuite) TestChannelEvents() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("http://example.com/old", archivedResponse(301, http.Header{"Location": {"/paper.pdf"}}, ""))
+	archive.Add("http://example.com/paper.pdf", archivedResponse(200, http.Header{"Content-Type": {"application/pdf"}}, testPDFContent))
+
+	events := make(ChannelEventSink, 10)
+	_, err := NewFactory(archive, events, NewMemoryAttachmentCreator(nil)).PageFromURL(context.Background(), "http://example.com/old")
+	suite.Nil(err, "Should not get an error")
+	close(events)
+
+	var types []string
+	for event := range events {
+		types = append(types, event.Type)
+		suite.Equal("http://example.com/old", event.URL)
+		suite.False(event.Time.IsZero())
+		if event.Type == EventRedirectDetected {
+			suite.Equal("http://example.com/paper.pdf", event.RedirectURL)
+		}
+		if event.Type == EventAttachmentDownloaded {
+			suite.NotNil(event.Attachment)
+		}
+	}
+	suite.Equal([]string{EventPageResolved, EventRedirectDetected, EventAttachmentDownloaded}, types)
+}
+
+func (suite *EventsSuite) TestWebhook() {
+	received := make(chan map[string]interface{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		suite.Equal("Bearer hook", r.Header.Get("Authorization"))
+		var event map[string]interface{}
+		suite.Nil(json.NewDecoder(r.Body).Decode(&event))
+		received <- event
+	}))
+	defer server.Close()
+
+	archive := NewMemoryResponseArchive()
+	archive.Add("http://example.com/", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, testHTMLPage))
+	webhook := NewWebhookEventSink(server.URL)
+	webhook.Header = http.Header{"Authorization": {"Bearer hook"}}
+	_, err := NewFactory(archive, webhook).PageFromURL(context.Background(), "http://example.com/")
+	suite.Nil(err, "Should not get an error")
+
+	event := <-received
+	suite.Equal(EventPageResolved, event["type"])
+	suite.Equal("http://example.com/", event["url"])
+	suite.NotNil(event["page"])
+
+	failing := NewWebhookEventSink("http://127.0.0.1:0/")
+	suite.NotNil(failing.HandleEvent(context.Background(), Event{Type: EventPageResolved}), "Unreachable webhook should be an error")
+}
+
+func TestEventsSuite(t *testing.T) {
+	suite.Run(t, new(EventsSuite))
+}
@@ -0,0 +1,146 @@
+package resource
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// DefaultExcerptLength is the most characters a page's excerpt has when there's no ExcerptPolicy
+const DefaultExcerptLength = 280
+
+// ExcerptPolicy is passed into options to say how many characters the excerpt of an HTML page (see Page.Excerpt)
+// may have, zero or less means pages don't get one from their body
+type ExcerptPolicy interface {
+	ExcerptLength(ctx context.Context, url *url.URL) int
+}
+
+// ExcerptLength is an ExcerptPolicy with the same length for every page
+type ExcerptLength int
+
+// ExcerptLength satisfies ExcerptPolicy method
+func (e ExcerptLength) ExcerptLength(ctx context.Context, url *url.URL) int {
+	return int(e)
+}
+
+func (f *DefaultFactory) excerptLength(ctx context.Context, url *url.URL) int {
+	if f.ExcerptPolicy != nil {
+		defer policyPanicked("ExcerptPolicy")
+		return f.ExcerptPolicy.ExcerptLength(ctx, url)
+	}
+	return DefaultExcerptLength
+}
+
+// Excerpt returns a plain text snippet of the page for lists of links: the start of the paragraphs of its <article>,
+// <main> or <body> (or of all the body text if it has no paragraphs), cut at a word and ended with "…" if it's longer
+// than the ExcerptPolicy allows. Pages without body text fall back to their meta description.
+func (p Page) Excerpt() string {
+	if len(p.ExcerptText) > 0 {
+		return p.ExcerptText
+	}
+	for _, key := range descriptionMetaTags {
+		if description, ok := MetaTags(p.MetaPropertyTags).GetString(key); ok && len(strings.TrimSpace(description)) > 0 {
+			return truncateText(description, DefaultExcerptLength)
+		}
+	}
+	return ""
+}
+
+// pageExcerpt returns the excerpt of a parsed HTML page, or its meta description if it has no text
+func (p *Page) pageExcerpt(doc *html.Node, length int) string {
+	if length <= 0 {
+		return ""
+	}
+	root := excerptRoot(doc)
+	words := excerptWords(root, length, true)
+	if len(words) == 0 {
+		words = excerptWords(root, length, false)
+	}
+	if len(words) > 0 {
+		return truncateText(strings.Join(words, " "), length)
+	}
+	for _, key := range descriptionMetaTags {
+		if description, ok := MetaTags(p.MetaPropertyTags).GetString(key); ok && len(strings.TrimSpace(description)) > 0 {
+			return truncateText(description, length)
+		}
+	}
+	return ""
+}
+
+// excerptRoot returns the first <article>, else the first <main>, else the <body> of doc
+func excerptRoot(doc *html.Node) *html.Node {
+	found := make(map[string]*html.Node)
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			name := strings.ToLower(n.Data)
+			switch name {
+			case "article", "main", "body":
+				if found[name] == nil {
+					found[name] = n
+				}
+			case "script", "style", "noscript", "template", "head":
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	for _, name := range []string{"article", "main", "body"} {
+		if found[name] != nil {
+			return found[name]
+		}
+	}
+	return doc
+}
+
+// excerptWords returns the words of the text under root, or only of its <p> elements if paragraphs is true, stopping
+// once there are more than length characters. Navigation, asides and footers are skipped.
+func excerptWords(root *html.Node, length int, paragraphs bool) []string {
+	var words []string
+	characters := 0
+	var walk func(n *html.Node, inParagraph bool)
+	walk = func(n *html.Node, inParagraph bool) {
+		if characters > length {
+			return
+		}
+		if n.Type == html.ElementNode {
+			switch strings.ToLower(n.Data) {
+			case "script", "style", "noscript", "template", "nav", "aside", "footer", "figure":
+				return
+			case "p":
+				inParagraph = true
+			}
+		}
+		if n.Type == html.TextNode && (inParagraph || !paragraphs) {
+			for _, word := range strings.Fields(n.Data) {
+				words = append(words, word)
+				characters += utf8.RuneCountInString(word) + 1
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c, inParagraph)
+		}
+	}
+	walk(root, false)
+	return words
+}
+
+// truncateText collapses the whitespace of text and, if it's longer than length characters, cuts it at a word and
+// ends it with "…" so that it's at most length characters
+func truncateText(text string, length int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if length <= 0 || utf8.RuneCountInString(text) <= length {
+		return text
+	}
+	cut := string([]rune(text)[:length-1])
+	if space := strings.LastIndex(cut, " "); space > 0 {
+		cut = cut[:space]
+	}
+	return strings.TrimRight(cut, " ,;:-") + "…"
+}
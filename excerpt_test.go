@@ -0,0 +1,83 @@
+package resource
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/suite"
+)
+
+const testExcerptArticle = `<html><head><title>Harvesting</title>
+<meta name="description" content="How harvesters work"></head>
+<body><nav><a href="/">Home</a> <a href="/blog">Blog</a></nav>
+<h1>Harvesting the Web</h1>
+<article><h2>Why</h2><figure><figcaption>A crawler</figcaption></figure>
+<p>Pages are   fetched, parsed and indexed by harvesters.</p>
+<p>Each step can fail; the harvester records warnings instead of giving up.</p></article>
+<footer>Copyright Netspective</footer></body></html>`
+
+type ExcerptSuite struct {
+	suite.Suite
+	archive *MemoryResponseArchive
+}
+
+func (suite *ExcerptSuite) SetupTest() {
+	suite.archive = NewMemoryResponseArchive()
+	suite.archive.Add("https://www.netspective.com/article", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, testExcerptArticle))
+	suite.archive.Add("https://www.netspective.com/headings", archivedResponse(200, http.Header{"Content-Type": {"text/html"}},
+		`<html><body><h1>Netspective</h1><div>Safety, privacy, and security</div></body></html>`))
+	suite.archive.Add("https://www.netspective.com/empty", archivedResponse(200, http.Header{"Content-Type": {"text/html"}},
+		`<html><head><meta property="og:description" content="  Technology   consulting "></head><body><script>var x = 1;</script></body></html>`))
+}
+
+func (suite *ExcerptSuite) page(factory *DefaultFactory, target string) *Page {
+	content, err := factory.PageFromURL(context.Background(), target)
+	suite.Require().Nil(err, "Should not get an error")
+	return content.(*Page)
+}
+
+func (suite *ExcerptSuite) TestParagraphs() {
+	page := suite.page(NewFactory(suite.archive), "https://www.netspective.com/article")
+	suite.Equal("Pages are fetched, parsed and indexed by harvesters. Each step can fail; the harvester records warnings instead of giving up.", page.Excerpt(),
+		"Only the article's paragraphs should be used")
+}
+
+func (suite *ExcerptSuite) TestLength() {
+	page := suite.page(NewFactory(suite.archive, ExcerptLength(40)), "https://www.netspective.com/article")
+	suite.Equal("Pages are fetched, parsed and indexed…", page.Excerpt())
+	suite.True(utf8.RuneCountInString(page.Excerpt()) <= 40)
+
+	page = suite.page(NewFactory(suite.archive, ExcerptLength(0)), "https://www.netspective.com/article")
+	suite.Empty(page.ExcerptText)
+	suite.Equal("How harvesters work", page.Excerpt(), "The description should be used without an excerpt")
+}
+
+func (suite *ExcerptSuite) TestWithoutParagraphs() {
+	page := suite.page(NewFactory(suite.archive), "https://www.netspective.com/headings")
+	suite.Equal("Netspective Safety, privacy, and security", page.Excerpt())
+}
+
+func (suite *ExcerptSuite) TestDescriptionFallback() {
+	page := suite.page(NewFactory(suite.archive), "https://www.netspective.com/empty")
+	suite.Equal("Technology consulting", page.Excerpt())
+	suite.Equal("Technology consulting", page.ExcerptText)
+
+	page.ExcerptText = ""
+	page.MetaPropertyTags["og:description"] = strings.Repeat("word ", 100)
+	suite.True(utf8.RuneCountInString(page.Excerpt()) <= DefaultExcerptLength, "Descriptions should be cut too")
+	suite.True(strings.HasSuffix(page.Excerpt(), "word…"))
+}
+
+func (suite *ExcerptSuite) TestTruncateText() {
+	suite.Equal("short", truncateText("  short ", 10))
+	suite.Equal("one two…", truncateText("one two, three", 10))
+	suite.Equal("abcdefghi…", truncateText("abcdefghijklmnop", 10), "Words longer than the excerpt should be cut")
+	suite.Equal("héllo…", truncateText("héllo wörld", 8), "Lengths should be in characters")
+}
+
+func TestExcerptSuite(t *testing.T) {
+	suite.Run(t, new(ExcerptSuite))
+}
@@ -0,0 +1,93 @@
+package resource
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ContentTTLPolicy is passed into options to give content a time to live when its cache headers don't say how long
+// it stays fresh. Zero or less means no default, leaving only the Last-Modified heuristic.
+type ContentTTLPolicy interface {
+	ContentTTL(ctx context.Context, url *url.URL, t Type) time.Duration
+}
+
+// ContentTTL is a ContentTTLPolicy with the same time to live for all content
+type ContentTTL time.Duration
+
+// ContentTTL satisfies ContentTTLPolicy method
+func (ttl ContentTTL) ContentTTL(ctx context.Context, url *url.URL, t Type) time.Duration {
+	return time.Duration(ttl)
+}
+
+// contentExpiry computes when a response stops being fresh, following RFC 7234: Cache-Control max-age (or no-cache
+// and no-store, which expire immediately) wins over Expires, otherwise the ContentTTLPolicy is used and failing that
+// a tenth of the time since Last-Modified. The response's Age is taken off. Zero means there's no way to tell.
+func (f *DefaultFactory) contentExpiry(ctx context.Context, url *url.URL, resp *http.Response, t Type) time.Time {
+	now := f.clock().Now()
+	lifetime, ok := freshnessLifetime(resp.Header, now)
+	if !ok && f.ContentTTLPolicy != nil {
+		if ttl := callPolicy("ContentTTLPolicy", func() time.Duration { return f.ContentTTLPolicy.ContentTTL(ctx, url, t) }); ttl > 0 {
+			lifetime, ok = ttl, true
+		}
+	}
+	if !ok {
+		lifetime, ok = heuristicLifetime(resp.Header)
+	}
+	if !ok {
+		return time.Time{}
+	}
+	if age, err := strconv.ParseInt(strings.TrimSpace(resp.Header.Get("Age")), 10, 64); err == nil && age > 0 {
+		lifetime -= time.Duration(age) * time.Second
+	}
+	return now.Add(lifetime)
+}
+
+// freshnessLifetime returns how long the response is fresh for according to Cache-Control and Expires, now stands
+// in for a missing Date
+func freshnessLifetime(header http.Header, now time.Time) (time.Duration, bool) {
+	for _, directive := range strings.Split(strings.Join(header["Cache-Control"], ","), ",") {
+		name, value := strings.TrimSpace(directive), ""
+		if i := strings.Index(name, "="); i >= 0 {
+			name, value = strings.TrimSpace(name[:i]), strings.Trim(strings.TrimSpace(name[i+1:]), `"`)
+		}
+		switch strings.ToLower(name) {
+		case "no-store", "no-cache":
+			return 0, true
+		case "max-age":
+			if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+				return time.Duration(seconds) * time.Second, true
+			}
+		}
+	}
+
+	if expiresText := header.Get("Expires"); len(expiresText) > 0 {
+		expires, err := http.ParseTime(expiresText)
+		if err != nil {
+			// an invalid Expires, such as "0", means already expired
+			return 0, true
+		}
+		date, err := http.ParseTime(header.Get("Date"))
+		if err != nil {
+			date = now
+		}
+		return expires.Sub(date), true
+	}
+	return 0, false
+}
+
+// heuristicLifetime guesses a lifetime of 10% of the time since the content was last modified
+func heuristicLifetime(header http.Header) (time.Duration, bool) {
+	lastModified, err := http.ParseTime(header.Get("Last-Modified"))
+	if err != nil {
+		return 0, false
+	}
+	date, err := http.ParseTime(header.Get("Date"))
+	if err != nil || !date.After(lastModified) {
+		return 0, false
+	}
+	return date.Sub(lastModified) / 10, true
+}
@@ -0,0 +1,58 @@
+package resource
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ExpirySuite struct {
+	suite.Suite
+	now time.Time
+}
+
+func (suite *ExpirySuite) SetupTest() {
+	suite.now = time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)
+}
+
+func (suite *ExpirySuite) expires(header http.Header, options ...interface{}) time.Time {
+	header.Set("Content-Type", "text/html")
+	archive := NewMemoryResponseArchive()
+	archive.Add("http://example.com/", archivedResponse(200, header, testHTMLPage))
+	content, err := NewFactory(append(options, archive, NewManualClock(suite.now))...).PageFromURL(context.Background(), "http://example.com/")
+	suite.Nil(err, "Should not get an error")
+	return content.(*Page).Expires
+}
+
+func (suite *ExpirySuite) TestCacheHeaders() {
+	date := suite.now.Format(http.TimeFormat)
+	suite.Equal(suite.now.Add(time.Hour), suite.expires(http.Header{"Cache-Control": {"public, max-age=3600"}}))
+	suite.Equal(suite.now.Add(50*time.Minute), suite.expires(http.Header{"Cache-Control": {"max-age=3600"}, "Age": {"600"}}))
+	suite.Equal(suite.now, suite.expires(http.Header{"Cache-Control": {"no-cache"}, "Expires": {suite.now.Add(time.Hour).Format(http.TimeFormat)}}))
+	suite.Equal(suite.now.Add(2*time.Hour), suite.expires(http.Header{"Date": {date}, "Expires": {suite.now.Add(2 * time.Hour).Format(http.TimeFormat)}}))
+	suite.Equal(suite.now, suite.expires(http.Header{"Expires": {"0"}}), "Invalid Expires should mean already expired")
+}
+
+func (suite *ExpirySuite) TestPolicyAndHeuristic() {
+	date := suite.now.Format(http.TimeFormat)
+	lastModified := suite.now.Add(-100 * time.Hour).Format(http.TimeFormat)
+	suite.Equal(suite.now.Add(10*time.Hour), suite.expires(http.Header{"Date": {date}, "Last-Modified": {lastModified}}))
+	suite.Equal(suite.now.Add(24*time.Hour), suite.expires(http.Header{"Date": {date}, "Last-Modified": {lastModified}}, ContentTTL(24*time.Hour)))
+	suite.Equal(suite.now.Add(time.Minute), suite.expires(http.Header{"Cache-Control": {"max-age=60"}}, ContentTTL(24*time.Hour)), "Cache headers should win over the policy")
+	suite.True(suite.expires(http.Header{}).IsZero())
+}
+
+func (suite *ExpirySuite) TestIsStale() {
+	page := Page{}
+	suite.True(page.IsStale(suite.now), "Content without expiry should be stale")
+	page.Expires = suite.now.Add(time.Hour)
+	suite.False(page.IsStale(suite.now))
+	suite.True(page.IsStale(suite.now.Add(time.Hour)))
+}
+
+func TestExpirySuite(t *testing.T) {
+	suite.Run(t, new(ExpirySuite))
+}
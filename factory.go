@@ -15,8 +15,18 @@ type Factory interface {
 
 // NewFactory creates a new thread-safe resource factory
 func NewFactory(options ...interface{}) *DefaultFactory {
-	f := &DefaultFactory{}
+	return newFactory(options)
+}
+
+// newFactory creates a factory from options, then sets fields with each of setters
+func newFactory(options []interface{}, setters ...func(*DefaultFactory)) *DefaultFactory {
+	f := &DefaultFactory{hostProfiles: &hostProfileCache{profiles: make(map[string]*HostProfile)}}
 	f.initOptions(options...)
+	for _, set := range setters {
+		set(f)
+	}
+	f.transport = f.connectionPool().newTransport(f.DialConfig, f.clock())
+	f.invalid = invalidConfiguration(f.configurationProblems(context.Background(), false))
 	return f
 }
 
@@ -30,6 +40,22 @@ type ParseMetaDataInHTMLContentPolicy interface {
 	ParseMetaDataInHTMLContent(context.Context, *url.URL) bool
 }
 
+// DetectRedirectsInHTMLContent is a DetectRedirectsPolicy with the same answer for every page
+type DetectRedirectsInHTMLContent bool
+
+// DetectRedirectsInHTMLContent satisfies DetectRedirectsPolicy method
+func (d DetectRedirectsInHTMLContent) DetectRedirectsInHTMLContent(ctx context.Context, url *url.URL) bool {
+	return bool(d)
+}
+
+// ParseMetaDataInHTMLContent is a ParseMetaDataInHTMLContentPolicy with the same answer for every page
+type ParseMetaDataInHTMLContent bool
+
+// ParseMetaDataInHTMLContent satisfies ParseMetaDataInHTMLContentPolicy method
+func (p ParseMetaDataInHTMLContent) ParseMetaDataInHTMLContent(ctx context.Context, url *url.URL) bool {
+	return bool(p)
+}
+
 // ContentDownloaderErrorPolicy is passed into options if we want to stop downloads on error
 type ContentDownloaderErrorPolicy interface {
 	StopOnDownloadError(context.Context, *url.URL, Type, error) bool
@@ -51,13 +77,68 @@ type DefaultFactory struct {
 	ProvideClientFunc                func(ctx context.Context) *http.Client
 	ReqPreparer                      HTTPRequestPreparer
 	PrepReqFunc                      func(ctx context.Context, client *http.Client, req *http.Request)
+	RedirectPolicy                   RedirectPolicy
 	DetectRedirectsPolicy            DetectRedirectsPolicy
 	ParseMetaDataInHTMLContentPolicy ParseMetaDataInHTMLContentPolicy
 	ContentDownloaderErrorPolicy     ContentDownloaderErrorPolicy
 	FileAttachmentCreator            FileAttachmentCreator
+	ResponseArchive                  ResponseArchive
+	FetchActivityPubActorPolicy      FetchActivityPubActorPolicy
+	FetchWebAppManifestPolicy        FetchWebAppManifestPolicy
+	FetchOEmbedPolicy                FetchOEmbedPolicy
+	ResolvePDFPolicy                 ResolvePDFPolicy
+	PDFURLPatterns                   PDFURLPatterns
+	CheckAccessibilityPolicy         CheckAccessibilityPolicy
+	ScanAssetsPolicy                 ScanAssetsPolicy
+	DetectTrackersPolicy             DetectTrackersPolicy
+	TrackerDomains                   TrackerDomains
+	IncludeBodyMetaDataPolicy        IncludeBodyMetaDataPolicy
+	ExcerptPolicy                    ExcerptPolicy
+	HostStatsStore                   HostStatsStore
+	RequestTimeoutPolicy             RequestTimeoutPolicy
+	HedgingPolicy                    HedgingPolicy
+	RetryPolicy                      RetryPolicy
+	CacheProvider                    CacheProvider
+	CacheObserver                    CacheObserver
+	HTMLBodyLimitPolicy              HTMLBodyLimitPolicy
+	HTMLHeadOnlyPolicy               HTMLHeadOnlyPolicy
+	RetainBodyPolicy                 RetainBodyPolicy
+	ResponseRecorder                 ResponseRecorder
+	DomainPolicyRouter               *DomainPolicyRouter
+	TenantRouter                     *TenantRouter
+	RoundTripperDecorators           []RoundTripperDecorator
+	ConnectionPool                   *ConnectionPool
+	DialConfig                       *DialConfig
+	UnreadBodyPolicy                 UnreadBodyPolicy
+	FetchBudget                      *FetchBudget
+	ContentTTLPolicy                 ContentTTLPolicy
+	EventSink                        EventSink
+	ContentScorer                    ContentScorer
+	Indexer                          Indexer
+	DuplicateResolutionPolicy        DuplicateResolutionPolicy
+	Shorteners                       Shorteners
+	AuditSink                        AuditSink
+	Clock                            Clock
+	RandomSource                     RandomSource
+
+	options      []interface{}     // everything passed to NewFactory, handed through to DownloadFileFromHTTPResp
+	hostProfiles *hostProfileCache // nil unless created by NewFactory
+	policyNames  []string          // the names of the PolicyBundles in options, for auditing
+	transport    *http.Transport   // shared by the built-in client, nil unless created by NewFactory
+	invalid      error             // the problems which aren't advisory, found when NewFactory created the factory
 }
 
 func (f *DefaultFactory) initOptions(options ...interface{}) {
+	for _, option := range options {
+		switch bundle := option.(type) {
+		case PolicyBundle:
+			f.policyNames = append(f.policyNames, bundle.Names()...)
+		case *PolicyBundle:
+			f.policyNames = append(f.policyNames, bundle.Names()...)
+		}
+	}
+	options = flattenOptions(options)
+	f.options = append(f.options, options...)
 	for _, option := range options {
 		if instance, ok := option.(HTTPClientProvider); ok {
 			f.ClientProvider = instance
@@ -65,12 +146,62 @@ func (f *DefaultFactory) initOptions(options ...interface{}) {
 		if fn, ok := option.(func(ctx context.Context) *http.Client); ok {
 			f.ProvideClientFunc = fn
 		}
+		if instance, ok := option.(RoundTripperDecorator); ok {
+			f.RoundTripperDecorators = append(f.RoundTripperDecorators, instance)
+		}
+		if fn, ok := option.(func(next http.RoundTripper) http.RoundTripper); ok {
+			f.RoundTripperDecorators = append(f.RoundTripperDecorators, RoundTripperDecoratorFunc(fn))
+		}
+		if instance, ok := option.(*ConnectionPool); ok {
+			f.ConnectionPool = instance
+		}
+		if instance, ok := option.(*DialConfig); ok {
+			f.DialConfig = instance
+		}
+		if instance, ok := option.(UnreadBodyPolicy); ok {
+			f.UnreadBodyPolicy = instance
+		}
+		if instance, ok := option.(FetchBudget); ok {
+			f.FetchBudget = &instance
+		}
+		if instance, ok := option.(*FetchBudget); ok {
+			f.FetchBudget = instance
+		}
+		if instance, ok := option.(ContentTTLPolicy); ok {
+			f.ContentTTLPolicy = instance
+		}
+		if instance, ok := option.(EventSink); ok {
+			f.EventSink = instance
+		}
+		if instance, ok := option.(ContentScorer); ok {
+			f.ContentScorer = instance
+		}
+		if instance, ok := option.(Indexer); ok {
+			f.Indexer = instance
+		}
+		if instance, ok := option.(DuplicateResolutionPolicy); ok {
+			f.DuplicateResolutionPolicy = instance
+		}
+		if instance, ok := option.(Shorteners); ok {
+			// merged into a new map, the current one may be shared with the factory this one was copied from
+			merged := make(Shorteners, len(f.Shorteners)+len(instance))
+			for host, provider := range f.Shorteners {
+				merged[host] = provider
+			}
+			for host, provider := range instance {
+				merged[host] = provider
+			}
+			f.Shorteners = merged
+		}
 		if instance, ok := option.(HTTPRequestPreparer); ok {
 			f.ReqPreparer = instance
 		}
 		if fn, ok := option.(func(ctx context.Context, client *http.Client, req *http.Request)); ok {
 			f.PrepReqFunc = fn
 		}
+		if instance, ok := option.(RedirectPolicy); ok {
+			f.RedirectPolicy = instance
+		}
 		if instance, ok := option.(DetectRedirectsPolicy); ok {
 			f.DetectRedirectsPolicy = instance
 		}
@@ -83,35 +214,156 @@ func (f *DefaultFactory) initOptions(options ...interface{}) {
 		if instance, ok := option.(FileAttachmentCreator); ok {
 			f.FileAttachmentCreator = instance
 		}
+		if instance, ok := option.(ResponseArchive); ok {
+			f.ResponseArchive = instance
+		}
+		if instance, ok := option.(FetchActivityPubActorPolicy); ok {
+			f.FetchActivityPubActorPolicy = instance
+		}
+		if instance, ok := option.(FetchWebAppManifestPolicy); ok {
+			f.FetchWebAppManifestPolicy = instance
+		}
+		if instance, ok := option.(FetchOEmbedPolicy); ok {
+			f.FetchOEmbedPolicy = instance
+		}
+		if instance, ok := option.(ResolvePDFPolicy); ok {
+			f.ResolvePDFPolicy = instance
+		}
+		if instance, ok := option.(PDFURLPatterns); ok {
+			f.PDFURLPatterns = append(f.PDFURLPatterns, instance...)
+		}
+		if instance, ok := option.(CheckAccessibilityPolicy); ok {
+			f.CheckAccessibilityPolicy = instance
+		}
+		if instance, ok := option.(ScanAssetsPolicy); ok {
+			f.ScanAssetsPolicy = instance
+		}
+		if instance, ok := option.(DetectTrackersPolicy); ok {
+			f.DetectTrackersPolicy = instance
+		}
+		if instance, ok := option.(TrackerDomains); ok {
+			f.TrackerDomains = append(f.TrackerDomains, instance...)
+		}
+		if instance, ok := option.(IncludeBodyMetaDataPolicy); ok {
+			f.IncludeBodyMetaDataPolicy = instance
+		}
+		if instance, ok := option.(ExcerptPolicy); ok {
+			f.ExcerptPolicy = instance
+		}
+		if instance, ok := option.(HostStatsStore); ok {
+			f.HostStatsStore = instance
+		}
+		if instance, ok := option.(RequestTimeoutPolicy); ok {
+			f.RequestTimeoutPolicy = instance
+		}
+		if instance, ok := option.(HedgingPolicy); ok {
+			f.HedgingPolicy = instance
+		}
+		if instance, ok := option.(RetryPolicy); ok {
+			f.RetryPolicy = instance
+		}
+		if instance, ok := option.(CacheProvider); ok {
+			f.CacheProvider = instance
+		}
+		if instance, ok := option.(CacheObserver); ok {
+			f.CacheObserver = instance
+		}
+		if instance, ok := option.(HTMLBodyLimitPolicy); ok {
+			f.HTMLBodyLimitPolicy = instance
+		}
+		if instance, ok := option.(HTMLHeadOnlyPolicy); ok {
+			f.HTMLHeadOnlyPolicy = instance
+		}
+		if instance, ok := option.(RetainBodyPolicy); ok {
+			f.RetainBodyPolicy = instance
+		}
+		if instance, ok := option.(ResponseRecorder); ok {
+			f.ResponseRecorder = instance
+		}
+		if instance, ok := option.(*DomainPolicyRouter); ok {
+			f.DomainPolicyRouter = instance
+		}
+		if instance, ok := option.(*TenantRouter); ok {
+			f.TenantRouter = instance
+		}
+		if instance, ok := option.(AuditSink); ok {
+			f.AuditSink = instance
+		}
+		if instance, ok := option.(Clock); ok {
+			f.Clock = instance
+		}
+		if instance, ok := option.(RandomSource); ok {
+			f.RandomSource = instance
+		}
 	}
 }
 
 func (f *DefaultFactory) httpClient(ctx context.Context) *http.Client {
+	return f.redirectingClient(ctx, decorateClient(f.baseHTTPClient(ctx), f.RoundTripperDecorators))
+}
+
+func (f *DefaultFactory) baseHTTPClient(ctx context.Context) *http.Client {
 	if f.ClientProvider != nil {
+		defer policyPanicked("HTTPClientProvider")
 		return f.ClientProvider.HTTPClient(ctx)
 	}
 
 	if f.ProvideClientFunc != nil {
+		defer policyPanicked("ProvideClientFunc")
 		return f.ProvideClientFunc(ctx)
 	}
 
-	return &http.Client{
+	client := &http.Client{
 		Timeout: time.Second * 90,
 	}
+	if f.transport != nil {
+		client.Transport = f.transport
+	}
+	return client
+}
+
+func (f *DefaultFactory) connectionPool() *ConnectionPool {
+	if f.ConnectionPool != nil {
+		return f.ConnectionPool
+	}
+	return DefaultConnectionPool()
+}
+
+func (f *DefaultFactory) clock() Clock {
+	if f.Clock != nil {
+		return f.Clock
+	}
+	return SystemClock{}
+}
+
+func (f *DefaultFactory) random() RandomSource {
+	if f.RandomSource != nil {
+		return f.RandomSource
+	}
+	return SystemRandom{}
 }
 
 func (f *DefaultFactory) prepareHTTPRequest(ctx context.Context, client *http.Client, req *http.Request) {
 	if f.ReqPreparer != nil {
-		f.ReqPreparer.OnPrepareHTTPRequest(ctx, client, req)
+		guardPolicy("HTTPRequestPreparer", func() { f.ReqPreparer.OnPrepareHTTPRequest(ctx, client, req) })
 	}
 
 	if f.PrepReqFunc != nil {
-		f.PrepReqFunc(ctx, client, req)
+		guardPolicy("PrepReqFunc", func() { f.PrepReqFunc(ctx, client, req) })
 	}
 }
 
+func (f *DefaultFactory) stopOnDownloadError(ctx context.Context, url *url.URL, t Type, err error) bool {
+	if f.ContentDownloaderErrorPolicy != nil {
+		defer policyPanicked("ContentDownloaderErrorPolicy")
+		return f.ContentDownloaderErrorPolicy.StopOnDownloadError(ctx, url, t, err)
+	}
+	return false
+}
+
 func (f *DefaultFactory) detectRedirectsInHTMLContent(ctx context.Context, url *url.URL) bool {
 	if f.DetectRedirectsPolicy != nil {
+		defer policyPanicked("DetectRedirectsPolicy")
 		return f.DetectRedirectsPolicy.DetectRedirectsInHTMLContent(ctx, url)
 	}
 	return true
@@ -119,37 +371,267 @@ func (f *DefaultFactory) detectRedirectsInHTMLContent(ctx context.Context, url *
 
 func (f *DefaultFactory) parseMetaDataInHTMLContent(ctx context.Context, url *url.URL) bool {
 	if f.ParseMetaDataInHTMLContentPolicy != nil {
-		f.ParseMetaDataInHTMLContentPolicy.ParseMetaDataInHTMLContent(ctx, url)
+		defer policyPanicked("ParseMetaDataInHTMLContentPolicy")
+		return f.ParseMetaDataInHTMLContentPolicy.ParseMetaDataInHTMLContent(ctx, url)
 	}
 	return true
 }
 
+// htmlContentPolicies records on result which of redirect detection and meta data parsing were asked for, false is
+// returned if neither was and the HTML needn't be parsed at all
+func (f *DefaultFactory) htmlContentPolicies(ctx context.Context, url *url.URL, result *Page) bool {
+	result.detectRedirects = f.detectRedirectsInHTMLContent(ctx, url)
+	result.parseMetaData = f.parseMetaDataInHTMLContent(ctx, url)
+	return result.detectRedirects || result.parseMetaData
+}
+
+// attachmentCreator returns the factory's FileAttachmentCreator or, if it has none, the last one in options
+func (f *DefaultFactory) attachmentCreator(options []interface{}) FileAttachmentCreator {
+	if f.FileAttachmentCreator != nil {
+		return f.FileAttachmentCreator
+	}
+	result, _ := OptionOf[FileAttachmentCreator](options)
+	return result
+}
+
+// downloadOptions combines the factory's options with the call's options, the call's options take precedence
+func (f *DefaultFactory) downloadOptions(options []interface{}) []interface{} {
+	result := make([]interface{}, 0, len(f.options)+len(options))
+	result = append(result, f.options...)
+	return append(result, options...)
+}
+
 // PageFromURL creates a content instance from the given URL and policy
 func (f *DefaultFactory) PageFromURL(ctx context.Context, origURLtext string, options ...interface{}) (Content, error) {
+	content, err := f.pageFromURL(ctx, origURLtext, options...)
+	return content, identifyError(ctx, err)
+}
+
+func (f *DefaultFactory) pageFromURL(ctx context.Context, origURLtext string, options ...interface{}) (result Content, err error) {
+	defer recoverPolicyPanic(&err)
 	if len(origURLtext) == 0 {
-		return nil, targetURLIsBlankError(xerrors.Caller(xErrorsFrameCaller))
+		return nil, targetURLIsBlankError(callerFrames(xErrorsFrameCaller))
+	}
+	if f.invalid != nil {
+		return nil, f.invalid
+	}
+
+	f, err = f.tenanted(ctx, origURLtext)
+	if err != nil {
+		return nil, err
+	}
+	options = flattenOptions(options)
+	if err := validateOptions(options, pageOptions); err != nil {
+		return nil, err
+	}
+	f = f.routed(origURLtext)
+	ctx, lookup := f.withCacheLookup(ctx)
+	validators, validatorsErr := f.validators(ctx, origURLtext, options)
+	defer func() { err = validatorsFailed(result, err, validatorsErr) }()
+	budget := f.fetchBudget(options)
+	if budget == nil {
+		resp, err := f.fetch(ctx, origURLtext, validators.header())
+		if err != nil {
+			if page, ok := f.notModifiedPage(ctx, origURLtext, validators, err, options); ok {
+				return page, nil
+			}
+			return nil, err
+		}
+		content, err := f.pageFromHTTPResponse(ctx, resp.Request.URL, resp, options...)
+		warnValidatorsError(content, validatorsErr)
+		lookup.annotate(content)
+		if err == nil {
+			f.finishPage(ctx, origURLtext, content)
+		}
+		return content, err
+	}
+
+	ctx, tracker, cancel := withFetchBudget(ctx, *budget, f.clock())
+	defer cancel()
+	var content Content
+	resp, err := f.fetch(ctx, origURLtext, validators.header())
+	if err == nil {
+		content, err = f.pageFromHTTPResponse(ctx, resp.Request.URL, resp, options...)
+		warnValidatorsError(content, validatorsErr)
+		lookup.annotate(content)
+	} else if page, ok := f.notModifiedPage(ctx, origURLtext, validators, err, options); ok {
+		content, err = page, nil
+	}
+	usage := tracker.finish()
+	if page, ok := content.(*Page); ok {
+		page.BudgetUsage = &usage
+	}
+	if err == nil && len(usage.Exceeded) == 0 && !IsNotModified(content) {
+		f.finishPage(ctx, origURLtext, content)
+	}
+	if len(usage.Exceeded) > 0 {
+		// the content, as far as the budget allowed, is still returned
+		return content, &BudgetExceededError{
+			URL:    origURLtext,
+			Limit:  usage.Exceeded,
+			Usage:  usage,
+			Frame:  xerrors.Caller(xErrorsFrameCaller),
+			Frames: callerFrames(xErrorsFrameCaller)}
+	}
+	return content, err
+}
+
+// finishPage adds what's only known once a URL has been fully resolved and tells the EventSink about it
+func (f *DefaultFactory) finishPage(ctx context.Context, urlText string, content Content) {
+	if page, ok := content.(*Page); ok {
+		page.ShortenedURL = f.shortenedURL(urlText)
+	}
+	f.scoreContent(ctx, content)
+	f.indexContent(ctx, content)
+	f.emitPageEvents(ctx, urlText, content)
+}
+
+// fetch retrieves urlText, with any extra request headers, from the ResponseArchive if there is one or else the network.
+// Any status other than 200 is an InvalidHTTPRespStatusCodeError, otherwise the caller must close the response body.
+func (f *DefaultFactory) fetch(ctx context.Context, urlText string, header http.Header) (*http.Response, error) {
+	resp, err := f.cachedFetch(ctx, urlText, header)
+	if err == nil {
+		if tracker := budgetFromContext(ctx); tracker != nil {
+			resp.Body = budgetBody{ReadCloser: resp.Body, tracker: tracker}
+		}
+	}
+	return resp, err
+}
+
+// auditedFetch tells any AuditSink about the fetch
+func (f *DefaultFactory) auditedFetch(ctx context.Context, urlText string, header http.Header) (*http.Response, error) {
+	if f.AuditSink == nil {
+		return f.fetchResponse(ctx, urlText, header)
+	}
+
+	started := f.clock().Now()
+	resp, err := f.fetchResponse(ctx, urlText, header)
+	record := AuditRecord{
+		Time:            started,
+		Requester:       RequesterFromContext(ctx),
+		RequestIdentity: RequestIdentityFromContext(ctx),
+		URL:             urlText,
+		Source:          AuditSourceNetwork,
+		Policies:        f.policyNames,
+	}
+	if f.ResponseArchive != nil {
+		record.Source = AuditSourceArchive
+	}
+	if err != nil {
+		var statusErr *InvalidHTTPRespStatusCodeError
+		if xerrors.As(err, &statusErr) {
+			record.Status = statusErr.HTTPStatusCode
+		}
+		record.Duration = f.clock().Now().Sub(started)
+		record.Error = err.Error()
+		guardPolicy("AuditSink", func() { f.AuditSink.Audit(ctx, record) })
+		return nil, err
+	}
+
+	record.Status = resp.StatusCode
+	record.FinalURL = resp.Request.URL.String()
+	record.ContentType = resp.Header.Get("Content-Type")
+	resp.Body = &countingBody{ReadCloser: resp.Body, record: func(bytes int64) {
+		record.Bytes = bytes
+		record.Duration = f.clock().Now().Sub(started)
+		guardPolicy("AuditSink", func() { f.AuditSink.Audit(ctx, record) })
+	}}
+	return resp, nil
+}
+
+// fetchResponse does the work of fetch
+func (f *DefaultFactory) fetchResponse(ctx context.Context, urlText string, header http.Header) (*http.Response, error) {
+	if f.ResponseArchive != nil {
+		return f.archivedResponse(ctx, urlText)
 	}
 
 	// Use the standard Go HTTP library method to retrieve the Content; the default will automatically follow redirects (e.g. HTTP redirects)
 	httpClient := f.httpClient(ctx)
-	req, reqErr := http.NewRequest(http.MethodGet, origURLtext, nil)
+	if tracker := budgetFromContext(ctx); tracker != nil {
+		httpClient = tracker.budgetedClient(httpClient)
+	}
+	req, reqErr := http.NewRequest(fetchMethod(ctx), urlText, nil)
 	if reqErr != nil {
-		return nil, xerrors.Errorf("Unable to create HTTP request: %w", reqErr)
+		return nil, requestBuildError(urlText, reqErr, callerFrames(xErrorsFrameCaller))
+	}
+	req = req.WithContext(ctx)
+	for key, values := range header {
+		req.Header[key] = values
 	}
 	f.prepareHTTPRequest(ctx, httpClient, req)
-	resp, getErr := httpClient.Do(req)
+	cancel := context.CancelFunc(func() {})
+	if f.RequestTimeoutPolicy != nil {
+		timeout := callPolicy("RequestTimeoutPolicy", func() time.Duration { return f.RequestTimeoutPolicy.RequestTimeout(ctx, req.URL) })
+		if timeout > 0 {
+			var timeoutCtx context.Context
+			timeoutCtx, cancel = context.WithTimeout(req.Context(), timeout)
+			req = req.WithContext(timeoutCtx)
+		}
+	}
+	started := f.clock().Now()
+	resp, getErr := f.do(ctx, httpClient, req)
 	if getErr != nil {
-		return nil, xerrors.Errorf("Unable to execute HTTP GET request: %w", getErr)
+		cancel()
+		f.recordHostFetch(ctx, hostKey(req.URL), HostFetchResult{At: started, Latency: f.clock().Now().Sub(started), Err: getErr})
+		return nil, requestError(urlText, "Unable to execute HTTP "+req.Method+" request", getErr, callerFrames(xErrorsFrameCaller))
 	}
 
 	if resp.StatusCode != 200 {
-		return nil, &InvalidHTTPRespStatusCodeError{
-			URL: origURLtext,
+		resp.Body.Close()
+		cancel()
+		err := &InvalidHTTPRespStatusCodeError{
+			URL: urlText,
 			HTTPStatusCode: resp.StatusCode,
-			Frame: xerrors.Caller(xErrorsFrameCaller)}
+			Header: resp.Header,
+			Frame: xerrors.Caller(xErrorsFrameCaller),
+			Frames: callerFrames(xErrorsFrameCaller)}
+		f.recordHostFetch(ctx, hostKey(req.URL), HostFetchResult{At: started, Latency: f.clock().Now().Sub(started), Err: err})
+		return nil, err
+	}
+
+	if f.ResponseRecorder != nil {
+		resp.Body = &recordingBody{ReadCloser: resp.Body, record: func(body []byte, complete bool) error {
+			return callPolicy("ResponseRecorder", func() error { return f.ResponseRecorder.RecordResponse(ctx, resp, body, complete) })
+		}}
 	}
 
-	return f.pageFromHTTPResponse(ctx, resp.Request.URL, resp, options...)
+	// the timeout covers reading the body too, so it's only cancelled once the body is closed
+	latency := f.clock().Now().Sub(started)
+	resp.Body = &countingBody{ReadCloser: resp.Body, record: func(bytes int64) {
+		cancel()
+		f.recordHostFetch(ctx, hostKey(req.URL), HostFetchResult{At: started, Latency: latency, Duration: f.clock().Now().Sub(started), Bytes: bytes})
+	}}
+	return resp, nil
+}
+
+// do sends the request, hedging it if the HedgingPolicy asks for that
+func (f *DefaultFactory) do(ctx context.Context, client *http.Client, req *http.Request) (*http.Response, error) {
+	if f.HedgingPolicy != nil {
+		delay := callPolicy("HedgingPolicy", func() time.Duration { return f.HedgingPolicy.HedgeDelay(ctx, req.URL) })
+		if delay > 0 {
+			return doHedged(f.clock(), client, req, delay)
+		}
+	}
+	return clientDo(client, req)
+}
+
+// clientDo sends req with client. The client's RoundTripper and CheckRedirect may be the caller's, so their panics are
+// policy panics.
+func clientDo(client *http.Client, req *http.Request) (resp *http.Response, err error) {
+	guardPolicy("RoundTripper", func() { resp, err = client.Do(req) })
+	return resp, err
+}
+
+// recordHostFetch tells the HostStatsStore and any HostFetchObserver about a fetch. The statistics are advisory so a
+// store which fails to record them doesn't fail the fetch.
+func (f *DefaultFactory) recordHostFetch(ctx context.Context, host string, result HostFetchResult) {
+	result.Identity = RequestIdentityFromContext(ctx)
+	if f.HostStatsStore != nil {
+		guardPolicy("HostStatsStore", func() { f.HostStatsStore.RecordFetch(ctx, host, result) })
+	}
+	if observer, ok := f.RequestTimeoutPolicy.(HostFetchObserver); ok {
+		guardPolicy("HostFetchObserver", func() { observer.ObserveHostFetch(ctx, host, result) })
+	}
 }
 
 // NewPageFromHTTPResponse will download and figure out what kind content we're dealing with
@@ -157,37 +639,82 @@ func (f *DefaultFactory) pageFromHTTPResponse(ctx context.Context, url *url.URL,
 	result := new(Page)
 	result.MetaPropertyTags = make(map[string]interface{})
 	result.TargetURL = url
+	result.Links = parseLinkHeader(url, resp.Header)
+	result.Annotations = annotationsFrom(options)
+	result.TLS = hostTLSInfo(resp.TLS)
+	// whatever isn't parsed or downloaded below must still be released so the connection can be reused
+	defer func() { f.releaseBody(ctx, url, resp, result.PageType) }()
+	if refresh := resp.Header.Get("Refresh"); len(refresh) > 0 && f.detectRedirectsInHTMLContent(ctx, url) {
+		if delayText, urlText, ok := parseRefreshContent(refresh); ok {
+			result.IsHeaderRedirect = true
+			result.RefreshHeaderURLText = urlText
+			result.RefreshHeaderDelay = refreshDelay(delayText)
+		}
+	}
 
 	contentType := resp.Header.Get("Content-Type")
 	if len(contentType) > 0 {
-		var err error
-		result.PageType, err = NewPageType(url, contentType)
+		pageType, err := NewPageType(contentType)
 		if err != nil {
-			return result, err
+			return result, contentTypeError(url.String(), err, callerFrames(xErrorsFrameCaller))
 		}
-		if result.IsHTML() && (f.detectRedirectsInHTMLContent(ctx, url) || f.parseMetaDataInHTMLContent(ctx, url)) {
-			result.parsePageMetaData(ctx, url, resp)
+		result.PageType = pageType
+	}
+	result.Expires = f.contentExpiry(ctx, url, resp, result.PageType)
+	result.ETag = resp.Header.Get("ETag")
+	result.LastModified = resp.Header.Get("Last-Modified")
+
+	if result.PageType != nil {
+		if isMultipartRelated(url, result.PageType) {
+			if err := f.parseMultipartRelated(ctx, url, resp, result); err != nil {
+				return result, parseError(url.String(), "Unable to parse multipart content", err, callerFrames(xErrorsFrameCaller))
+			}
+			result.valid = true
+			return result, nil
+		}
+		if isEmailMessage(url, result.PageType) {
+			defer resp.Body.Close()
+			if err := f.parseEmailMessage(ctx, url, resp.Body, result); err != nil {
+				return result, parseError(url.String(), "Unable to parse email message", err, callerFrames(xErrorsFrameCaller))
+			}
+			result.valid = true
+			return result, nil
+		}
+		if isMarkdown(url, result.PageType) && f.parseMetaDataInHTMLContent(ctx, url) {
+			result.parseMarkdown(ctx, url, resp)
 			result.HTMLParsed = true
 			result.valid = true
 			return result, nil
 		}
-	}
-
-	var attachmentCreator FileAttachmentCreator
-	for _, option := range options {
-		if instance, ok := option.(FileAttachmentCreator); ok {
-			attachmentCreator = instance
+		if result.IsHTML() && f.htmlContentPolicies(ctx, url, result) {
+			f.limitHTMLBody(ctx, url, resp)
+			result.retainBody = f.RetainBodyPolicy != nil && callPolicy("RetainBodyPolicy", func() bool { return f.RetainBodyPolicy.RetainBody(ctx, url) })
+			result.checkAccessibility = f.checkAccessibility(ctx, url)
+			result.scanAssets = f.scanAssets(ctx, url)
+			result.detectTrackers = f.detectTrackers(ctx, url)
+			result.includeBodyMetaData = f.includeBodyMetaData(ctx, url)
+			result.indexText = f.Indexer != nil
+			result.excerptLength = f.excerptLength(ctx, url)
+			result.parsePageMetaData(ctx, url, resp)
+			result.HTMLParsed = result.parseMetaData
+			f.discoverActivityPubActor(ctx, result)
+			f.discoverWebAppManifest(ctx, result)
+			f.discoverEmbed(ctx, result)
+			f.measureAssets(ctx, result)
+			f.findTrackers(result)
+			if err := f.discoverPDF(ctx, result, options); err != nil {
+				return result, err
+			}
+			result.valid = true
+			return result, nil
 		}
 	}
-	if f.FileAttachmentCreator != nil {
-		attachmentCreator = f.FileAttachmentCreator
-	}
 
-	if attachmentCreator != nil {
-		ok, attachment, err := DownloadFileFromHTTPResp(ctx, attachmentCreator, url, resp, result.PageType)
+	if attachmentCreator := f.attachmentCreator(options); attachmentCreator != nil {
+		ok, attachment, err := DownloadFileFromHTTPResp(ctx, attachmentCreator, url, resp, result.PageType, f.downloadOptions(options)...)
 		if err != nil {
 			if f.ContentDownloaderErrorPolicy != nil {
-				if f.ContentDownloaderErrorPolicy.StopOnDownloadError(ctx, url, result.PageType, err) {
+				if f.stopOnDownloadError(ctx, url, result.PageType, err) {
 					return result, err
 				}
 			}
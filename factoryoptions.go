@@ -0,0 +1,314 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/xerrors"
+)
+
+// FactoryOption configures the factory NewFactoryWithOptions creates. Unlike the interface{} options of NewFactory,
+// which are matched by type and silently ignored when nothing matches, every FactoryOption is made by one of the
+// With... functions, and mistakes such as nil values, repeated options, or options which can't work together are
+// returned as an InvalidConfigurationError.
+type FactoryOption func(*factoryBuilder)
+
+// factoryBuilder collects the options of NewFactoryWithOptions, those of WithOptions in the form NewFactory takes them
+// and the rest as setters of the factory's fields, so that a value which happens to satisfy other option interfaces
+// isn't used as those too. Every option is also kept as a source, to find the options whose fields conflict.
+type factoryBuilder struct {
+	options  []interface{}
+	setters  []func(*DefaultFactory)
+	sources  []factorySource
+	given    map[string]bool
+	problems []ConfigurationProblem
+}
+
+// factorySource is an option of NewFactoryWithOptions, named as problems report it, and how it sets the factory
+type factorySource struct {
+	name string
+	set  func(*DefaultFactory)
+}
+
+// fields returns the names of the factoryFetchFields the source sets
+func (s factorySource) fields() map[string]bool {
+	f := new(DefaultFactory)
+	s.set(f)
+	result := make(map[string]bool)
+	for _, field := range factoryFetchFields {
+		if field.isSet(f) {
+			result[field.name] = true
+		}
+	}
+	return result
+}
+
+func (b *factoryBuilder) problem(option string, format string, args ...interface{}) {
+	b.problems = append(b.problems, ConfigurationProblem{Option: option, Problem: fmt.Sprintf(format, args...)})
+}
+
+// set adds a factory option which may only be given once
+func (b *factoryBuilder) set(name string, isNil bool, setter func(*DefaultFactory)) {
+	switch {
+	case isNil:
+		b.problem(name, "is nil")
+	case b.given[name]:
+		b.problem(name, "is given more than once")
+	default:
+		b.setters = append(b.setters, setter)
+		b.sources = append(b.sources, factorySource{name: name, set: setter})
+	}
+	b.given[name] = true
+}
+
+// factoryFetchFields are the fields of the factory which decide how it fetches, they're what options conflict over
+var factoryFetchFields = []struct {
+	name  string
+	isSet func(*DefaultFactory) bool
+}{
+	{"ClientProvider", func(f *DefaultFactory) bool { return f.ClientProvider != nil }},
+	{"ReqPreparer", func(f *DefaultFactory) bool { return f.ReqPreparer != nil }},
+	{"RedirectPolicy", func(f *DefaultFactory) bool { return f.RedirectPolicy != nil }},
+	{"RetryPolicy", func(f *DefaultFactory) bool { return f.RetryPolicy != nil }},
+	{"CacheProvider", func(f *DefaultFactory) bool { return f.CacheProvider != nil }},
+	{"CacheObserver", func(f *DefaultFactory) bool { return f.CacheObserver != nil }},
+	{"RoundTripperDecorators", func(f *DefaultFactory) bool { return len(f.RoundTripperDecorators) > 0 }},
+	{"ConnectionPool", func(f *DefaultFactory) bool { return f.ConnectionPool != nil }},
+	{"DialConfig", func(f *DefaultFactory) bool { return f.DialConfig != nil }},
+	{"ResponseArchive", func(f *DefaultFactory) bool { return f.ResponseArchive != nil }},
+}
+
+// factoryOptionConflicts are the factory fields which can't be combined, an option setting the first is unused when
+// another option sets any of the others
+var factoryOptionConflicts = []struct {
+	field   string
+	with    []string
+	problem string
+}{
+	{"ClientProvider", []string{"ClientProvider"}, "conflicts with %s, there can only be one HTTP client"},
+	{"ConnectionPool", []string{"ClientProvider"}, "only configures the built-in HTTP client, which %s replaces"},
+	{"DialConfig", []string{"ClientProvider"}, "only configures the built-in HTTP client, which %s replaces"},
+	{"ClientProvider", []string{"ResponseArchive"}, "is unused because %s replays responses instead of fetching them"},
+	{"ReqPreparer", []string{"ResponseArchive"}, "is unused because %s replays responses instead of fetching them"},
+	{"RedirectPolicy", []string{"ResponseArchive"}, "is unused because %s replays responses instead of fetching them"},
+	{"RetryPolicy", []string{"ResponseArchive"}, "is unused because %s replays responses instead of fetching them"},
+	{"CacheProvider", []string{"ResponseArchive"}, "is unused because %s replays responses instead of fetching them"},
+	{"CacheObserver", []string{"ResponseArchive"}, "is unused because %s replays responses instead of fetching them"},
+	{"RoundTripperDecorators", []string{"ResponseArchive"}, "is unused because %s replays responses instead of fetching them"},
+	{"ConnectionPool", []string{"ResponseArchive"}, "is unused because %s replays responses instead of fetching them"},
+	{"DialConfig", []string{"ResponseArchive"}, "is unused because %s replays responses instead of fetching them"},
+}
+
+// conflicts reports the options which set conflicting fields of the factory, whichever With... function they came
+// from, so an option passed through WithOptions conflicts just like its typed With... option would
+func (b *factoryBuilder) conflicts() {
+	fields := make([]map[string]bool, len(b.sources))
+	for index, source := range b.sources {
+		fields[index] = source.fields()
+	}
+	for _, conflict := range factoryOptionConflicts {
+		for index, source := range b.sources {
+			if !fields[index][conflict.field] {
+				continue
+			}
+			if other, ok := b.conflictingSource(fields, index, conflict.field, conflict.with); ok {
+				b.problem(source.name, conflict.problem, other)
+			}
+		}
+	}
+}
+
+// conflictingSource returns the name of the first other source which sets one of the with fields. When field is one
+// of them, only the sources before index count, so that the first of the options setting it isn't reported.
+func (b *factoryBuilder) conflictingSource(fields []map[string]bool, index int, field string, with []string) (string, bool) {
+	sameField := false
+	for _, name := range with {
+		sameField = sameField || name == field
+	}
+	for other, source := range b.sources {
+		if other == index || (sameField && other > index) {
+			continue
+		}
+		for _, name := range with {
+			if fields[other][name] {
+				return source.name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// NewFactoryWithOptions creates a new thread-safe resource factory like NewFactory does, but returns an
+// InvalidConfigurationError listing every problem with options, and every problem Validate finds without calling
+// providers, instead of a factory which can't fetch anything. If every problem is Advisory the factory is returned
+// along with the error.
+func NewFactoryWithOptions(options ...FactoryOption) (*DefaultFactory, error) {
+	builder := &factoryBuilder{given: make(map[string]bool)}
+	for _, option := range options {
+		if option == nil {
+			builder.problem("FactoryOption", "is nil")
+			continue
+		}
+		option(builder)
+	}
+	builder.conflicts()
+
+	result := newFactory(builder.options, builder.setters...)
+	problems := append(builder.problems, result.configurationProblems(context.Background(), false)...)
+	if len(problems) == 0 {
+		return result, nil
+	}
+	err := &InvalidConfigurationError{Problems: problems, Frame: xerrors.Caller(xErrorsFrameCaller), Frames: callerFrames(xErrorsFrameCaller)}
+	if len(builder.problems) > 0 || result.invalid != nil {
+		return nil, err
+	}
+	// the factory works despite advisory problems, so it's returned along with them
+	return result, err
+}
+
+// fixedHTTPClient is the HTTPClientProvider of WithHTTPClient
+type fixedHTTPClient struct {
+	client *http.Client
+}
+
+func (c fixedHTTPClient) HTTPClient(ctx context.Context) *http.Client {
+	return c.client
+}
+
+// WithHTTPClient fetches with client instead of the factory's built-in client, it can't be combined with
+// WithHTTPClientProvider, WithConnectionPool or WithDialConfig
+func WithHTTPClient(client *http.Client) FactoryOption {
+	return func(b *factoryBuilder) {
+		b.set("WithHTTPClient", client == nil, func(f *DefaultFactory) { f.ClientProvider = fixedHTTPClient{client: client} })
+	}
+}
+
+// WithHTTPClientProvider fetches with the client provider returns for each request's context
+func WithHTTPClientProvider(provider HTTPClientProvider) FactoryOption {
+	return func(b *factoryBuilder) {
+		b.set("WithHTTPClientProvider", provider == nil, func(f *DefaultFactory) { f.ClientProvider = provider })
+	}
+}
+
+// WithRequestPreparer lets preparer add a user agent or do other work on every HTTP request before it's sent
+func WithRequestPreparer(preparer HTTPRequestPreparer) FactoryOption {
+	return func(b *factoryBuilder) {
+		b.set("WithRequestPreparer", preparer == nil, func(f *DefaultFactory) { f.ReqPreparer = preparer })
+	}
+}
+
+// WithRedirectPolicy checks every HTTP redirect with policy before it's followed
+func WithRedirectPolicy(policy RedirectPolicy) FactoryOption {
+	return func(b *factoryBuilder) {
+		b.set("WithRedirectPolicy", policy == nil, func(f *DefaultFactory) { f.RedirectPolicy = policy })
+	}
+}
+
+// WithRetryPolicy tries failed fetches again as policy says, e.g. with an ExponentialBackoff
+func WithRetryPolicy(policy RetryPolicy) FactoryOption {
+	return func(b *factoryBuilder) {
+		b.set("WithRetryPolicy", policy == nil, func(f *DefaultFactory) { f.RetryPolicy = policy })
+	}
+}
+
+// WithCacheProvider answers repeated GETs from cache while the server says they're fresh, e.g. with a
+// MemoryCacheProvider or DiskCacheProvider
+func WithCacheProvider(cache CacheProvider) FactoryOption {
+	return func(b *factoryBuilder) {
+		b.set("WithCacheProvider", cache == nil, func(f *DefaultFactory) { f.CacheProvider = cache })
+	}
+}
+
+// WithCacheObserver tells observer how the CacheProvider answered each fetch, e.g. with CacheCounters
+func WithCacheObserver(observer CacheObserver) FactoryOption {
+	return func(b *factoryBuilder) {
+		b.set("WithCacheObserver", observer == nil, func(f *DefaultFactory) { f.CacheObserver = observer })
+	}
+}
+
+// WithRoundTripperDecorators wraps the HTTP client's transport, the first decorator is the outermost. It may be given
+// more than once, the decorators are added in order.
+func WithRoundTripperDecorators(decorators ...RoundTripperDecorator) FactoryOption {
+	return func(b *factoryBuilder) {
+		b.given["WithRoundTripperDecorators"] = true
+		for _, decorator := range decorators {
+			if decorator == nil {
+				b.problem("WithRoundTripperDecorators", "has a nil decorator")
+				continue
+			}
+			decorator := decorator
+			setter := func(f *DefaultFactory) { f.RoundTripperDecorators = append(f.RoundTripperDecorators, decorator) }
+			b.setters = append(b.setters, setter)
+			b.sources = append(b.sources, factorySource{name: "WithRoundTripperDecorators", set: setter})
+		}
+	}
+}
+
+// WithConnectionPool sizes the connection pool of the factory's built-in HTTP client
+func WithConnectionPool(pool *ConnectionPool) FactoryOption {
+	return func(b *factoryBuilder) {
+		b.set("WithConnectionPool", pool == nil, func(f *DefaultFactory) { f.ConnectionPool = pool })
+	}
+}
+
+// WithDialConfig configures how the factory's built-in HTTP client dials
+func WithDialConfig(config *DialConfig) FactoryOption {
+	return func(b *factoryBuilder) {
+		b.set("WithDialConfig", config == nil, func(f *DefaultFactory) { f.DialConfig = config })
+	}
+}
+
+// WithResponseArchive replays responses from archive instead of fetching them, so options about fetching can't be
+// combined with it
+func WithResponseArchive(archive ResponseArchive) FactoryOption {
+	return func(b *factoryBuilder) {
+		b.set("WithResponseArchive", archive == nil, func(f *DefaultFactory) { f.ResponseArchive = archive })
+	}
+}
+
+// WithFileAttachmentCreator downloads attachments with creator
+func WithFileAttachmentCreator(creator FileAttachmentCreator) FactoryOption {
+	return func(b *factoryBuilder) {
+		b.set("WithFileAttachmentCreator", creator == nil, func(f *DefaultFactory) { f.FileAttachmentCreator = creator })
+	}
+}
+
+// WithFetchBudget limits the time, bytes and redirects of each PageFromURL call
+func WithFetchBudget(budget FetchBudget) FactoryOption {
+	return func(b *factoryBuilder) {
+		b.set("WithFetchBudget", false, func(f *DefaultFactory) { f.FetchBudget = &budget })
+	}
+}
+
+// WithEventSink emits the factory's page and attachment events to sink
+func WithEventSink(sink EventSink) FactoryOption {
+	return func(b *factoryBuilder) {
+		b.set("WithEventSink", sink == nil, func(f *DefaultFactory) { f.EventSink = sink })
+	}
+}
+
+// WithClock gives the factory the time, e.g. a ManualClock in tests
+func WithClock(clock Clock) FactoryOption {
+	return func(b *factoryBuilder) {
+		b.set("WithClock", clock == nil, func(f *DefaultFactory) { f.Clock = clock })
+	}
+}
+
+// WithOptions adds options of the kinds NewFactory takes, including PolicyBundles, for policies which have no
+// With... function of their own. Unlike NewFactory, options the factory wouldn't use are reported as problems. The
+// other With... options take precedence over these.
+func WithOptions(options ...interface{}) FactoryOption {
+	return func(b *factoryBuilder) {
+		for _, option := range flattenOptions(options) {
+			if option != nil && !isKnownOption(option, factoryOptions, pageOptions) {
+				b.problem("WithOptions", "option of type %T isn't used by factories", option)
+			}
+			option := option
+			b.sources = append(b.sources, factorySource{
+				name: fmt.Sprintf("WithOptions(%T)", option),
+				set:  func(f *DefaultFactory) { f.initOptions(option) }})
+		}
+		b.options = append(b.options, options...)
+	}
+}
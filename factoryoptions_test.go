@@ -0,0 +1,182 @@
+package resource
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/xerrors"
+)
+
+type FactoryOptionsSuite struct {
+	suite.Suite
+	server   *httptest.Server
+	prepared int
+}
+
+func (suite *FactoryOptionsSuite) SetupSuite() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><title>` + r.UserAgent() + `</title></head></html>`))
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/page", http.StatusMovedPermanently)
+	})
+	suite.server = httptest.NewServer(mux)
+}
+
+func (suite *FactoryOptionsSuite) TearDownSuite() {
+	suite.server.Close()
+}
+
+func (suite *FactoryOptionsSuite) OnPrepareHTTPRequest(ctx context.Context, client *http.Client, req *http.Request) {
+	suite.prepared++
+	req.Header.Set("User-Agent", "lectio-test")
+}
+
+func (suite *FactoryOptionsSuite) problems(err error) []ConfigurationProblem {
+	var invalid *InvalidConfigurationError
+	if !xerrors.As(err, &invalid) {
+		return nil
+	}
+	return invalid.Problems
+}
+
+func (suite *FactoryOptionsSuite) TestValidOptions() {
+	client := &http.Client{Timeout: 10 * time.Second}
+	factory, err := NewFactoryWithOptions(WithHTTPClient(client), WithRequestPreparer(suite), WithRedirectPolicy(MaxRedirects(1)),
+		WithOptions(HTMLBodyLimit(1024), NewPolicyBundle("preview", HTMLHeadOnly(true))))
+	suite.Require().Nil(err, "Should not get an error")
+	suite.Equal(client, factory.baseHTTPClient(context.Background()))
+	suite.Equal(suite, factory.ReqPreparer)
+	suite.Equal(MaxRedirects(1), factory.RedirectPolicy)
+	suite.Equal(HTMLHeadOnly(true), factory.HTMLHeadOnlyPolicy)
+
+	content, err := factory.PageFromURL(context.Background(), suite.server.URL+"/moved")
+	suite.Require().Nil(err, "Should not get an error")
+	suite.Equal("lectio-test", content.(*Page).Title, "Request preparer should be used")
+	suite.True(suite.prepared > 0)
+}
+
+func (suite *FactoryOptionsSuite) TestRedirectPolicy() {
+	factory, err := NewFactoryWithOptions(WithRedirectPolicy(MaxRedirects(0)))
+	suite.Require().Nil(err, "Should not get an error")
+	_, err = factory.PageFromURL(context.Background(), suite.server.URL+"/moved")
+	suite.NotNil(err, "Redirects shouldn't be followed")
+	suite.Contains(err.Error(), "stopped after 0 redirects")
+
+	factory = NewFactory(SameHostRedirects(true))
+	_, err = factory.PageFromURL(context.Background(), suite.server.URL+"/moved")
+	suite.Nil(err, "Same host redirects should be followed")
+
+	var checked []string
+	factory = NewFactory(RedirectPolicyFunc(func(ctx context.Context, req *http.Request, via []*http.Request) error {
+		checked = append(checked, req.URL.Path)
+		return nil
+	}))
+	_, err = factory.PageFromURL(context.Background(), suite.server.URL+"/moved")
+	suite.Nil(err, "Should not get an error")
+	suite.Equal([]string{"/page"}, checked, "The old options path should use redirect policies too")
+}
+
+func (suite *FactoryOptionsSuite) TestSameHostRedirects() {
+	original, _ := http.NewRequest(http.MethodGet, "https://www.netspective.com/a", nil)
+	sameHost, _ := http.NewRequest(http.MethodGet, "https://www.netspective.com/b", nil)
+	otherHost, _ := http.NewRequest(http.MethodGet, "https://example.com/b", nil)
+	suite.Nil(SameHostRedirects(true).CheckRedirect(context.Background(), sameHost, []*http.Request{original}))
+	suite.NotNil(SameHostRedirects(true).CheckRedirect(context.Background(), otherHost, []*http.Request{original}))
+	suite.Nil(SameHostRedirects(false).CheckRedirect(context.Background(), otherHost, []*http.Request{original}))
+}
+
+// clientPreparer is both an HTTPClientProvider and an HTTPRequestPreparer
+type clientPreparer struct{ fixedHTTPClient }
+
+func (clientPreparer) OnPrepareHTTPRequest(ctx context.Context, client *http.Client, req *http.Request) {
+}
+
+func (suite *FactoryOptionsSuite) TestOptionsOnlySetTheirOwnField() {
+	factory, err := NewFactoryWithOptions(WithRequestPreparer(clientPreparer{}))
+	suite.Require().Nil(err, "Should not get an error")
+	suite.Nil(factory.ClientProvider, "A request preparer shouldn't also be used as the HTTP client provider")
+	suite.NotNil(NewFactory(clientPreparer{}).ClientProvider, "NewFactory uses options as every kind they satisfy")
+}
+
+func (suite *FactoryOptionsSuite) TestInvalidOptions() {
+	factory, err := NewFactoryWithOptions(WithHTTPClient(nil), WithRequestPreparer(nil), WithRoundTripperDecorators(nil))
+	suite.Nil(factory)
+	suite.Equal([]ConfigurationProblem{
+		{Option: "WithHTTPClient", Problem: "is nil"},
+		{Option: "WithRequestPreparer", Problem: "is nil"},
+		{Option: "WithRoundTripperDecorators", Problem: "has a nil decorator"},
+	}, suite.problems(err))
+	suite.Equal(ErrorCodeInvalidConfiguration, CodeOf(err))
+
+	_, err = NewFactoryWithOptions(WithClock(NewManualClock(time.Now())), WithClock(NewManualClock(time.Now())))
+	suite.Equal([]ConfigurationProblem{{Option: "WithClock", Problem: "is given more than once"}}, suite.problems(err))
+
+	_, err = NewFactoryWithOptions(WithOptions("not an option", HTMLBodyLimit(1024)))
+	suite.Equal([]ConfigurationProblem{{Option: "WithOptions", Problem: "option of type string isn't used by factories"}}, suite.problems(err))
+}
+
+func (suite *FactoryOptionsSuite) TestConflictingOptions() {
+	_, err := NewFactoryWithOptions(WithHTTPClient(http.DefaultClient), WithHTTPClientProvider(fixedHTTPClient{client: http.DefaultClient}), WithConnectionPool(DefaultConnectionPool()))
+	suite.Equal([]ConfigurationProblem{
+		{Option: "WithHTTPClientProvider", Problem: "conflicts with WithHTTPClient, there can only be one HTTP client"},
+		{Option: "WithConnectionPool", Problem: "only configures the built-in HTTP client, which WithHTTPClient replaces"},
+	}, suite.problems(err))
+
+	_, err = NewFactoryWithOptions(WithResponseArchive(NewMemoryResponseArchive()), WithRequestPreparer(suite))
+	suite.Equal([]ConfigurationProblem{
+		{Option: "WithRequestPreparer", Problem: "is unused because WithResponseArchive replays responses instead of fetching them"},
+	}, suite.problems(err))
+}
+
+func (suite *FactoryOptionsSuite) TestConflictsThroughWithOptions() {
+	archive := NewMemoryResponseArchive()
+	_, err := NewFactoryWithOptions(WithOptions(archive), WithHTTPClient(http.DefaultClient))
+	suite.Equal([]ConfigurationProblem{
+		{Option: "WithHTTPClient", Problem: "is unused because WithOptions(*resource.MemoryResponseArchive) replays responses instead of fetching them"},
+	}, suite.problems(err))
+
+	_, err = NewFactoryWithOptions(WithOptions(&ExponentialBackoff{MaxAttempts: 3}), WithResponseArchive(archive))
+	suite.Equal([]ConfigurationProblem{
+		{Option: "WithOptions(*resource.ExponentialBackoff)", Problem: "is unused because WithResponseArchive replays responses instead of fetching them"},
+	}, suite.problems(err))
+
+	_, err = NewFactoryWithOptions(WithOptions(NewPolicyBundle("replay", archive), DefaultConnectionPool()))
+	suite.Equal([]ConfigurationProblem{
+		{Option: "WithOptions(*resource.ConnectionPool)", Problem: "is unused because WithOptions(*resource.MemoryResponseArchive) replays responses instead of fetching them"},
+	}, suite.problems(err), "Options inside a bundle should conflict too")
+
+	_, err = NewFactoryWithOptions(WithOptions(fixedHTTPClient{client: http.DefaultClient}), WithHTTPClient(http.DefaultClient))
+	suite.Equal([]ConfigurationProblem{
+		{Option: "WithHTTPClient", Problem: "conflicts with WithOptions(resource.fixedHTTPClient), there can only be one HTTP client"},
+	}, suite.problems(err))
+
+	_, err = NewFactoryWithOptions(WithOptions(archive), WithFetchBudget(FetchBudget{MaxBytes: 1 << 20}))
+	suite.Nil(err, "Options which don't affect fetching can be combined with an archive")
+}
+
+func (suite *FactoryOptionsSuite) TestValidateProblemsAreIncluded() {
+	factory, err := NewFactoryWithOptions(WithOptions(HTMLBodyLimit(0)), WithFetchBudget(FetchBudget{}))
+	suite.Equal([]ConfigurationProblem{
+		{Option: "HTMLBodyLimit", Problem: "is 0, leave it out to read whole pages", Advisory: true},
+		{Option: "FetchBudget", Problem: "doesn't limit anything", Advisory: true},
+	}, suite.problems(err))
+	suite.NotNil(factory, "A factory with only advisory problems should still be returned")
+
+	factory, err = NewFactoryWithOptions(WithOptions(HTMLBodyLimit(-1)), WithFetchBudget(FetchBudget{}))
+	suite.Len(suite.problems(err), 2)
+	suite.Nil(factory, "A factory which can't work shouldn't be returned")
+
+	_, err = NewFactoryWithOptions(WithOptions(Checksum{Algorithm: "sha-256"}))
+	suite.Nil(err, "Download policies can be used with creators passed to each call")
+}
+
+func TestFactoryOptionsSuite(t *testing.T) {
+	suite.Run(t, new(FactoryOptionsSuite))
+}
@@ -0,0 +1,287 @@
+package resource
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// Feed describes a feed that resolved pages are republished in, see WriteRSS and WriteAtom
+type Feed struct {
+	Title       string
+	Link        *url.URL // the web site the feed is for
+	Description string
+	Author      string    // Atom feeds need an author, for the feed or every entry
+	ID          string    // the Atom feed's permanent, unique identifier, Link if empty
+	Updated     time.Time // when the feed last changed, the latest of its items' dates if zero
+}
+
+// FeedItem is what a page contributes to a feed, see NewFeedItem
+type FeedItem struct {
+	Title       string
+	Link        *url.URL // the canonical URL of the page if it has one
+	Description string
+	Published   time.Time // zero if unknown
+	Updated     time.Time // zero if unknown
+	Authors     []string
+	Categories  []string
+}
+
+// feedModifiedTags are the meta tags which give when a page was last changed, in order of preference
+var feedModifiedTags = []string{"article:modified_time", "og:updated_time"}
+
+// NewFeedItem creates the feed item of a resolved page from its title, description, canonical URL, dates, authors,
+// and tags, using its citation metadata as well as its meta tags
+func NewFeedItem(page *Page) FeedItem {
+	document := NewIndexDocument(page)
+	result := FeedItem{Title: document.Title, Link: pageLink(page), Description: document.Description}
+	if len(result.Description) == 0 {
+		result.Description = page.Excerpt()
+	}
+
+	tags := MetaTags(page.MetaPropertyTags)
+	layouts := append(append([]string(nil), DefaultMetaTagTimeLayouts...), CitationDateLayouts...)
+	for _, key := range articleDateTags {
+		if published, ok, err := tags.GetTime(key, layouts...); ok && err == nil {
+			result.Published = published
+			break
+		}
+	}
+	for _, key := range feedModifiedTags {
+		if updated, ok, err := tags.GetTime(key, layouts...); ok && err == nil {
+			result.Updated = updated
+			break
+		}
+	}
+	if result.Updated.IsZero() && len(page.LastModified) > 0 {
+		result.Updated, _ = http.ParseTime(page.LastModified)
+	}
+
+	if page.Citation != nil {
+		if len(result.Title) == 0 {
+			result.Title = page.Citation.Title
+		}
+		if result.Published.IsZero() {
+			result.Published = page.Citation.Published
+		}
+		result.Authors = page.Citation.Authors
+		result.Categories = page.Citation.Keywords
+	}
+	if len(result.Authors) == 0 {
+		for _, key := range []string{"author", "article:author"} {
+			if values, ok := tags.Values(key); ok {
+				result.Authors = feedStrings(values)
+				break
+			}
+		}
+	}
+	if len(result.Categories) == 0 {
+		if values, ok := tags.Values("article:tag"); ok {
+			result.Categories = feedStrings(values)
+		}
+	}
+	return result
+}
+
+// pageLink is the canonical URL of page if it has an absolute one, otherwise the URL it was resolved to
+func pageLink(page *Page) *url.URL {
+	if canonical, ok := page.Canonical(); ok && canonical != nil && canonical.IsAbs() {
+		return canonical
+	}
+	return page.TargetURL
+}
+
+func feedStrings(values []interface{}) []string {
+	var result []string
+	for _, value := range values {
+		if text, ok := value.(string); ok && len(strings.TrimSpace(text)) > 0 {
+			result = append(result, strings.TrimSpace(text))
+		}
+	}
+	return result
+}
+
+// items returns the feed items of pages and the feed's updated time
+func (f Feed) items(pages []*Page) ([]FeedItem, time.Time) {
+	items := make([]FeedItem, 0, len(pages))
+	updated := f.Updated
+	for _, page := range pages {
+		item := NewFeedItem(page)
+		items = append(items, item)
+		if f.Updated.IsZero() {
+			for _, date := range []time.Time{item.Published, item.Updated} {
+				if date.After(updated) {
+					updated = date
+				}
+			}
+		}
+	}
+	return items, updated
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	DC      string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title,omitempty"`
+	Link        string   `xml:"link"`
+	Description string   `xml:"description,omitempty"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate,omitempty"`
+	Creators    []string `xml:"dc:creator"`
+	Categories  []string `xml:"category"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// WriteRSS writes pages as an RSS 2.0 feed, authors are given as Dublin Core creators because RSS's own author
+// element has to be an email address
+func (f Feed) WriteRSS(w io.Writer, pages []*Page) error {
+	items, updated := f.items(pages)
+	feed := rssFeed{Version: "2.0", DC: "http://purl.org/dc/elements/1.1/", Channel: rssChannel{
+		Title:       f.Title,
+		Link:        feedURL(f.Link),
+		Description: f.Description,
+	}}
+	if !updated.IsZero() {
+		feed.Channel.LastBuildDate = updated.Format(time.RFC1123Z)
+	}
+	for _, item := range items {
+		entry := rssItem{
+			Title:       item.Title,
+			Link:        feedURL(item.Link),
+			Description: item.Description,
+			GUID:        rssGUID{IsPermaLink: true, Value: feedURL(item.Link)},
+			Creators:    item.Authors,
+			Categories:  item.Categories,
+		}
+		if !item.Published.IsZero() {
+			entry.PubDate = item.Published.Format(time.RFC1123Z)
+		}
+		feed.Channel.Items = append(feed.Channel.Items, entry)
+	}
+	return writeFeed(w, feed)
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Authors []atomName  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href   string `xml:"href,attr"`
+	Rel    string `xml:"rel,attr,omitempty"`
+	Type   string `xml:"type,attr,omitempty"`
+	Length int64  `xml:"length,attr,omitempty"`
+}
+
+type atomName struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Links      []atomLink     `xml:"link"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published,omitempty"`
+	Summary    string         `xml:"summary,omitempty"`
+	Authors    []atomName     `xml:"author"`
+	Categories []atomCategory `xml:"category"`
+}
+
+// WriteAtom writes pages as an Atom feed. Entries without dates are given the feed's updated time, which Atom
+// requires, and the feed's Author if they don't have authors of their own.
+func (f Feed) WriteAtom(w io.Writer, pages []*Page) error {
+	items, updated := f.items(pages)
+	if updated.IsZero() {
+		return xerrors.New("Atom feed needs an updated time, none of its pages has a date")
+	}
+	feed := atomFeed{Title: f.Title, ID: f.ID, Updated: updated.Format(time.RFC3339)}
+	if len(feed.ID) == 0 {
+		feed.ID = feedURL(f.Link)
+	}
+	if f.Link != nil {
+		feed.Links = []atomLink{{Href: f.Link.String(), Rel: "alternate"}}
+	}
+	if len(f.Author) > 0 {
+		feed.Authors = []atomName{{Name: f.Author}}
+	}
+	for _, item := range items {
+		entry := atomEntry{
+			Title:   item.Title,
+			ID:      feedURL(item.Link),
+			Links:   []atomLink{{Href: feedURL(item.Link), Rel: "alternate"}},
+			Updated: updated.Format(time.RFC3339),
+			Summary: item.Description,
+		}
+		if !item.Updated.IsZero() {
+			entry.Updated = item.Updated.Format(time.RFC3339)
+		} else if !item.Published.IsZero() {
+			entry.Updated = item.Published.Format(time.RFC3339)
+		}
+		if !item.Published.IsZero() {
+			entry.Published = item.Published.Format(time.RFC3339)
+		}
+		for _, author := range item.Authors {
+			entry.Authors = append(entry.Authors, atomName{Name: author})
+		}
+		if len(entry.Authors) == 0 && len(feed.Authors) == 0 {
+			return xerrors.Errorf("Atom entry %q has no author and the feed has no Author", entry.ID)
+		}
+		for _, category := range item.Categories {
+			entry.Categories = append(entry.Categories, atomCategory{Term: category})
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return writeFeed(w, feed)
+}
+
+func feedURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	return u.String()
+}
+
+func writeFeed(w io.Writer, feed interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(feed); err != nil {
+		return xerrors.Errorf("Unable to write feed: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,137 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+const testFeedArticle = `<html lang="en"><head><title>Harvesting the Web</title>
+<link rel="canonical" href="https://www.netspective.com/guides/harvesting">
+<meta property="og:description" content="Crawling, parsing &amp; indexing">
+<meta property="article:published_time" content="2019-05-01T09:30:00Z">
+<meta property="article:modified_time" content="2019-05-20T10:00:00Z">
+<meta property="article:tag" content="crawling">
+<meta property="article:tag" content="search">
+<meta name="author" content="Shahid Shah">
+</head><body><p>Pages are fetched.</p></body></html>`
+
+const testFeedUndated = `<html><head><title>About</title><meta name="description" content="Who we are"></head><body></body></html>`
+
+type FeedSuite struct {
+	suite.Suite
+	pages []*Page
+	feed  Feed
+}
+
+func (suite *FeedSuite) SetupSuite() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://www.netspective.com/harvesting?utm_source=feed", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, testFeedArticle))
+	archive.Add("https://www.netspective.com/about", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, testFeedUndated))
+	factory := NewFactory(archive)
+	for _, target := range []string{"https://www.netspective.com/harvesting?utm_source=feed", "https://www.netspective.com/about"} {
+		content, err := factory.PageFromURL(context.Background(), target)
+		suite.Require().Nil(err, "Should not get an error")
+		suite.pages = append(suite.pages, content.(*Page))
+	}
+	link, _ := url.Parse("https://www.netspective.com/")
+	suite.feed = Feed{Title: "Netspective", Link: link, Description: "Guides", Author: "Netspective"}
+}
+
+func (suite *FeedSuite) TestFeedItem() {
+	item := NewFeedItem(suite.pages[0])
+	suite.Equal("Harvesting the Web", item.Title)
+	suite.Equal("https://www.netspective.com/guides/harvesting", item.Link.String(), "Canonical URL should be used")
+	suite.Equal("Crawling, parsing & indexing", item.Description)
+	suite.Equal(time.Date(2019, 5, 1, 9, 30, 0, 0, time.UTC), item.Published.UTC())
+	suite.Equal(time.Date(2019, 5, 20, 10, 0, 0, 0, time.UTC), item.Updated.UTC())
+	suite.Equal([]string{"Shahid Shah"}, item.Authors)
+	suite.Equal([]string{"crawling", "search"}, item.Categories)
+
+	undated := NewFeedItem(suite.pages[1])
+	suite.Equal("https://www.netspective.com/about", undated.Link.String())
+	suite.Equal("Who we are", undated.Description)
+	suite.True(undated.Published.IsZero())
+}
+
+func (suite *FeedSuite) TestRSS() {
+	var buffer bytes.Buffer
+	suite.Require().Nil(suite.feed.WriteRSS(&buffer, suite.pages))
+
+	var feed struct {
+		Channel struct {
+			Title         string `xml:"title"`
+			LastBuildDate string `xml:"lastBuildDate"`
+			Items         []struct {
+				Title      string   `xml:"title"`
+				Link       string   `xml:"link"`
+				GUID       string   `xml:"guid"`
+				PubDate    string   `xml:"pubDate"`
+				Creators   []string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+				Categories []string `xml:"category"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	suite.Require().Nil(xml.Unmarshal(buffer.Bytes(), &feed), buffer.String())
+	suite.Equal("Netspective", feed.Channel.Title)
+	suite.Equal("Mon, 20 May 2019 10:00:00 +0000", feed.Channel.LastBuildDate, "Latest item date should be used")
+	suite.Require().Len(feed.Channel.Items, 2)
+	item := feed.Channel.Items[0]
+	suite.Equal("Harvesting the Web", item.Title)
+	suite.Equal("https://www.netspective.com/guides/harvesting", item.Link)
+	suite.Equal(item.Link, item.GUID)
+	suite.Equal("Wed, 01 May 2019 09:30:00 +0000", item.PubDate)
+	suite.Equal([]string{"Shahid Shah"}, item.Creators)
+	suite.Equal([]string{"crawling", "search"}, item.Categories)
+	suite.Empty(feed.Channel.Items[1].PubDate)
+}
+
+func (suite *FeedSuite) TestAtom() {
+	var buffer bytes.Buffer
+	suite.Require().Nil(suite.feed.WriteAtom(&buffer, suite.pages))
+
+	var feed struct {
+		XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+		ID      string   `xml:"id"`
+		Updated string   `xml:"updated"`
+		Entries []struct {
+			ID        string `xml:"id"`
+			Updated   string `xml:"updated"`
+			Published string `xml:"published"`
+			Summary   string `xml:"summary"`
+			Author    []struct {
+				Name string `xml:"name"`
+			} `xml:"author"`
+			Categories []struct {
+				Term string `xml:"term,attr"`
+			} `xml:"category"`
+		} `xml:"entry"`
+	}
+	suite.Require().Nil(xml.Unmarshal(buffer.Bytes(), &feed), buffer.String())
+	suite.Equal("https://www.netspective.com/", feed.ID)
+	suite.Equal("2019-05-20T10:00:00Z", feed.Updated)
+	suite.Require().Len(feed.Entries, 2)
+	suite.Equal("2019-05-01T09:30:00Z", feed.Entries[0].Published)
+	suite.Equal("2019-05-20T10:00:00Z", feed.Entries[0].Updated)
+	suite.Equal("Shahid Shah", feed.Entries[0].Author[0].Name)
+	suite.Equal("search", feed.Entries[0].Categories[1].Term)
+	suite.Equal("2019-05-20T10:00:00Z", feed.Entries[1].Updated, "Undated entries should get the feed's updated time")
+	suite.Equal("Who we are", feed.Entries[1].Summary)
+}
+
+func (suite *FeedSuite) TestAtomRequirements() {
+	var buffer bytes.Buffer
+	suite.NotNil(Feed{Title: "Undated", Author: "Netspective"}.WriteAtom(&buffer, suite.pages[1:]), "Atom feeds need an updated time")
+	suite.NotNil(Feed{Title: "Anonymous", Updated: time.Now()}.WriteAtom(&buffer, suite.pages[1:]), "Atom entries need an author")
+	suite.Nil(Feed{Title: "Undated"}.WriteRSS(&buffer, suite.pages[1:]), "RSS doesn't need dates or authors")
+}
+
+func TestFeedSuite(t *testing.T) {
+	suite.Run(t, new(FeedSuite))
+}
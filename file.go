@@ -1,17 +1,23 @@
 package resource
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"github.com/spf13/afero"
-	"golang.org/x/xerrors"
 	"io"
+	"mime"
 	"net/http"
 	"net/url"
+	"os"
 	"path"
+	"path/filepath"
+	"strings"
+	"sync"
 
 	filetype "github.com/h2non/filetype"
 	"github.com/h2non/filetype/types"
+	"golang.org/x/xerrors"
 )
 
 // FileAttachmentCreator allows files of different types to be created
@@ -20,6 +26,65 @@ type FileAttachmentCreator interface {
 	AutoAssignExtension(context.Context, *url.URL, Type) bool
 }
 
+// SniffedType is what was learned from the first bytes of a download, before its file was created
+type SniffedType struct {
+	FileType  types.Type // types.Unknown if the content wasn't recognized
+	Extension string     // the extension (without the dot) the file should have, empty unless AutoAssignExtension is true and one is known
+}
+
+// SniffedFileAttachmentCreator may be implemented by a FileAttachmentCreator that wants to choose the path and extension
+// of a file using its sniffed type, instead of having its file renamed once the download is complete
+type SniffedFileAttachmentCreator interface {
+	FileAttachmentCreator
+	CreateSniffedFile(context.Context, *url.URL, Type, SniffedType) (afero.Fs, afero.File, error)
+}
+
+// FileAttachmentFinalizer may be implemented by a FileAttachmentCreator that needs to relocate a file once it's fully downloaded
+type FileAttachmentFinalizer interface {
+	FinalizeFile(ctx context.Context, fs afero.Fs, path string, url *url.URL, t Type) (string, error)
+}
+
+// AttachmentTypeMismatchPolicy is passed into options if we want to invalidate downloads whose content disagrees with their Content-Type
+type AttachmentTypeMismatchPolicy interface {
+	InvalidateOnTypeMismatch(context.Context, *url.URL, TypeMismatchWarning) bool
+}
+
+// AttachmentDownloadPolicy is passed into options if we want to decide, from the response metadata alone, whether
+// an attachment should be downloaded (e.g. to skip huge files, videos, or content that's already archived)
+type AttachmentDownloadPolicy interface {
+	ShouldDownload(ctx context.Context, url *url.URL, t Type, contentLength int64, headers http.Header) bool
+}
+
+// AttachmentPreviewPolicy is passed into options if we only want the first bytes of attachments, enough for type
+// sniffing, text sampling, or thumbnailing during triage passes. Zero or less means the whole attachment.
+type AttachmentPreviewPolicy interface {
+	PreviewBytes(ctx context.Context, url *url.URL, t Type) int64
+}
+
+// AttachmentPreviewSize is an AttachmentPreviewPolicy which previews the same number of bytes of every attachment
+type AttachmentPreviewSize int64
+
+// PreviewBytes satisfies AttachmentPreviewPolicy method
+func (s AttachmentPreviewSize) PreviewBytes(ctx context.Context, url *url.URL, t Type) int64 {
+	return int64(s)
+}
+
+// AttachmentExtensions is passed into options to override the extensions assigned by AutoAssignExtension,
+// it maps media types (e.g. "application/vnd.ms-excel") to extensions without the leading dot (e.g. "xls")
+type AttachmentExtensions map[string]string
+
+// PreserveOriginalFileNamePolicy is passed into options if downloads should keep the file name (and extension) from
+// the URL instead of the creator's name with a sniffed extension
+type PreserveOriginalFileNamePolicy interface {
+	PreserveOriginalFileName(context.Context, *url.URL) bool
+}
+
+// TypeMismatchWarning records that the sniffed content of a download disagrees with the server's Content-Type
+type TypeMismatchWarning struct {
+	DeclaredMediaType string `json:"declaredMediaType"`
+	SniffedMediaType  string `json:"sniffedMediaType"`
+}
+
 // FileAttachment manages any content that was downloaded for further inspection
 type FileAttachment struct {
 	ContentType Type       `json:"type"`
@@ -28,6 +93,19 @@ type FileAttachment struct {
 	DestPath    string     `json:"destPath"`
 	FileType    types.Type `json:"fileType"`
 	Valid       bool       `json:"valid"`
+
+	TypeMismatch *TypeMismatchWarning `json:"typeMismatch,omitempty"` // set when the sniffed file type disagrees with ContentType
+	Checksums    map[string]string    `json:"checksums,omitempty"`    // hex digests, by algorithm, that were verified against expected checksums
+	Preview      bool                 `json:"preview,omitempty"`      // true if only the first bytes were downloaded, see AttachmentPreviewPolicy
+	Profile      *DatasetProfile      `json:"profile,omitempty"`      // schema hints for tabular files, see AttachmentProfiler
+	Derived      []*FileAttachment    `json:"derived,omitempty"`      // attachments derived from this one, see AttachmentTransformer
+	Transform    string               `json:"transform,omitempty"`    // for derived attachments, the name of the transformation
+	Source       *FileAttachment      `json:"-"`                      // for derived attachments, the attachment they were derived from
+	Media        *MediaProfile        `json:"media,omitempty"`        // duration, codecs and dimensions of video and audio, see MediaProber
+	WordCount    int                  `json:"wordCount,omitempty"`    // for text extracted by PDFTextTransformer
+
+	createdPath string // where the file was created, before any renaming, for the CleanupManager
+	ownedPath   string // where the download's own file is after its renames, a FileAttachmentFinalizer may return another
 }
 
 // URL is the resource locator for this content
@@ -45,6 +123,22 @@ func (a FileAttachment) Type() Type {
 	return a.ContentType
 }
 
+// Open returns a reader for the downloaded file, the caller is responsible for closing it
+func (a FileAttachment) Open() (io.ReadCloser, error) {
+	if a.DestFS == nil {
+		return nil, fmt.Errorf("Attachment %q has no destination file system", a.DestPath)
+	}
+	return a.DestFS.Open(a.DestPath)
+}
+
+// Stat returns the size, modification time and other details of the downloaded file
+func (a FileAttachment) Stat() (os.FileInfo, error) {
+	if a.DestFS == nil {
+		return nil, fmt.Errorf("Attachment %q has no destination file system", a.DestPath)
+	}
+	return a.DestFS.Stat(a.DestPath)
+}
+
 // Delete removes the file that was downloaded
 func (a *FileAttachment) Delete() {
 	a.DestFS.Remove(a.DestPath)
@@ -52,9 +146,11 @@ func (a *FileAttachment) Delete() {
 
 // DownloadFileFromHTTPResp will download the URL as an "attachment" to a local file.
 // It's efficient because it will write as it downloads and not load the whole file into memory.
-func DownloadFileFromHTTPResp(ctx context.Context, creator FileAttachmentCreator, url *url.URL, resp *http.Response, typ Type, options ...interface{}) (bool, Attachment, error) {
+// If an AttachmentDownloadPolicy declines the download, false is returned with no attachment and no error.
+func DownloadFileFromHTTPResp(ctx context.Context, creator FileAttachmentCreator, url *url.URL, resp *http.Response, typ Type, options ...interface{}) (_ bool, _ Attachment, err error) {
+	defer recoverPolicyPanic(&err)
 	if url == nil {
-		return false, nil, fmt.Errorf("url is nil in resource.DownloadFile")
+		return false, nil, targetURLIsNilError(callerFrames(xErrorsFrameCaller))
 	}
 	if resp == nil {
 		return false, nil, fmt.Errorf("http.Response is nil in resource.DownloadFile")
@@ -68,45 +164,303 @@ func DownloadFileFromHTTPResp(ctx context.Context, creator FileAttachmentCreator
 		return false, result, fmt.Errorf("FileAttachmentCreator is nil in resource.DownloadFile")
 	}
 
-	fs, destFile, err := creator.CreateFile(ctx, url, typ)
+	if !shouldDownload(ctx, creator, url, resp, typ, options) {
+		resp.Body.Close()
+		return false, nil, nil
+	}
+
+	sinks, err := downloadSinks(ctx, creator, url, typ, options)
 	if err != nil {
-		return false, result, xerrors.Errorf("Unable to create file in resource.DownloadFile: %w", err)
+		return false, result, downloadError(url.String(), "Unable to create download sinks in resource.DownloadFile", err, callerFrames(xErrorsFrameCaller))
 	}
 
-	defer destFile.Close()
+	verifiers := checksumVerifiers(ctx, creator, url, resp, options)
+	writers := append([]DownloadSink(nil), sinks...)
+	for _, verifier := range verifiers {
+		writers = append(writers, verifier.sink)
+	}
+
+	ok, err := downloadFile(ctx, creator, url, resp, typ, result, writers, options)
+	if err == nil && !result.Preview {
+		for _, verifier := range verifiers {
+			verifier.sink.FinishDownload(ctx, result, nil)
+		}
+		if err = verifyChecksums(url, result, verifiers); err != nil {
+			ok = false
+			result.Valid = false
+		}
+	}
+	if ok && !result.Preview {
+		if profiler := attachmentProfiler(creator, options); profiler != nil {
+			// profiles are only hints so a file that can't be profiled is still a good download
+			guardPolicy("AttachmentProfiler", func() { result.Profile, _ = profiler.ProfileAttachment(ctx, url, result) })
+		}
+		if prober := mediaProber(creator, options); prober != nil && isMediaAttachment(result) {
+			guardPolicy("MediaProber", func() { result.Media, _ = prober.ProbeMedia(ctx, result) })
+		}
+	}
+	if ok && !result.Preview {
+		if err = transformAttachment(ctx, creator, result, options); err != nil {
+			ok, err = false, downloadError(url.String(), "Attachment transformer failed in resource.DownloadFile", err, callerFrames(xErrorsFrameCaller))
+			result.Valid = false
+		}
+	}
+	for _, sink := range sinks {
+		if sinkErr := callPolicy("DownloadSink", func() error { return sink.FinishDownload(ctx, result, err) }); sinkErr != nil && err == nil {
+			ok, err = false, downloadError(url.String(), "Download sink failed in resource.DownloadFile", sinkErr, callerFrames(xErrorsFrameCaller))
+			result.Valid = false
+		}
+	}
+	if manager, _ := OptionOf[*CleanupManager](options); manager != nil && len(result.createdPath) > 0 {
+		manager.finishDownload(result.DestFS, result.createdPath, result, err)
+	}
+	return ok, result, err
+}
+
+// downloadFile does the work of DownloadFileFromHTTPResp, copying the response into result's file and every sink
+func downloadFile(ctx context.Context, creator FileAttachmentCreator, url *url.URL, resp *http.Response, typ Type, result *FileAttachment, sinks []DownloadSink, options []interface{}) (bool, error) {
 	defer resp.Body.Close()
+
+	var body io.Reader = resp.Body
+	expectedLength := resp.ContentLength
+	if previewBytes := previewBytes(ctx, creator, url, typ, options); previewBytes > 0 {
+		// the rest of the body is abandoned when resp.Body is closed
+		body = io.LimitReader(resp.Body, previewBytes)
+		result.Preview = true
+		if expectedLength > previewBytes {
+			expectedLength = previewBytes
+		}
+	}
+
+	if err := checkDiskSpace(ctx, creator, url.String(), expectedLength, options); err != nil {
+		return false, err
+	}
+
+	// Sniff the file header (we only need the first 261 bytes) before the file is created so the creator can use it
+	head := make([]byte, 261)
+	headLen, err := io.ReadFull(body, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return false, downloadError(url.String(), "Unable to read file header in resource.DownloadFile", err, callerFrames(xErrorsFrameCaller))
+	}
+	head = head[:headLen]
+
+	fileType, fileTypeError := filetype.Match(head)
+	sniffed := fileTypeError == nil && fileType != types.Unknown
+	if sniffed {
+		result.FileType = fileType
+	}
+
+	if mismatch := detectTypeMismatch(typ, fileType, head); mismatch != nil {
+		result.TypeMismatch = mismatch
+		policy := attachmentTypeMismatchPolicy(creator, options)
+		if policy != nil && callPolicy("AttachmentTypeMismatchPolicy", func() bool { return policy.InvalidateOnTypeMismatch(ctx, url, *mismatch) }) {
+			return false, attachmentTypeMismatchError(url.String(), mismatch, callerFrames(xErrorsFrameCaller))
+		}
+	}
+
+	preserveFileName := preserveOriginalFileName(ctx, creator, url, options)
+	var extension string
+	if !preserveFileName && callPolicy("FileAttachmentCreator", func() bool { return creator.AutoAssignExtension(ctx, url, typ) }) {
+		extension, _ = assignedExtension(typ, fileType, sniffed, options)
+	}
+
+	var fs afero.Fs
+	var destFile afero.File
+	sniffedCreator, createsSniffedFiles := creator.(SniffedFileAttachmentCreator)
+	guardPolicy("FileAttachmentCreator", func() {
+		if createsSniffedFiles {
+			fs, destFile, err = sniffedCreator.CreateSniffedFile(ctx, url, typ, SniffedType{FileType: fileType, Extension: extension})
+		} else {
+			fs, destFile, err = creator.CreateFile(ctx, url, typ)
+		}
+	})
+	if err != nil {
+		return false, downloadError(url.String(), "Unable to create file in resource.DownloadFile", err, callerFrames(xErrorsFrameCaller))
+	}
+
+	defer destFile.Close()
 	result.DestFS = fs
 	result.DestPath = destFile.Name()
-	_, err = io.Copy(destFile, resp.Body)
+	result.createdPath = result.DestPath
+	result.ownedPath = result.DestPath
+	if manager, _ := OptionOf[*CleanupManager](options); manager != nil {
+		manager.Track(fs, result.DestPath, url.String())
+	}
+	writers := []io.Writer{destFile}
+	for _, sink := range sinks {
+		writers = append(writers, sink)
+	}
+
+	received, err := io.Copy(io.MultiWriter(writers...), io.MultiReader(bytes.NewReader(head), body))
+	if expectedLength > 0 && received != expectedLength {
+		return false, &ContentLengthMismatchError{
+			URL:      url.String(),
+			Declared: expectedLength,
+			Received: received,
+			Frame:    xerrors.Caller(xErrorsFrameCaller),
+			Frames:   callerFrames(xErrorsFrameCaller)}
+	}
 	if err != nil {
-		return false, result, xerrors.Errorf("Copy error during file download in resource.DownloadFile: %w", err)
+		return false, downloadError(url.String(), "Copy error during file download in resource.DownloadFile", err, callerFrames(xErrorsFrameCaller))
 	}
 	destFile.Close()
 
-	if creator.AutoAssignExtension(ctx, url, typ) {
-		// Open the just-downloaded file again since it was closed already
-		file, err := fs.Open(result.DestPath)
+	if preserveFileName {
+		if originalName := path.Base(url.Path); isPlainFileName(originalName) {
+			currentPath := result.DestPath
+			newPath, err := reservePath(fs, filepath.Join(filepath.Dir(currentPath), originalName), currentPath)
+			if err == nil && newPath != currentPath {
+				if err = fs.Rename(currentPath, newPath); err != nil {
+					fs.Remove(newPath)
+				}
+			}
+			if err != nil {
+				return false, downloadError(url.String(), "Unable to keep the original file name in resource.DownloadFile", err, callerFrames(xErrorsFrameCaller))
+			}
+			result.DestPath = newPath
+			result.ownedPath = newPath
+		}
+	} else if len(extension) > 0 && !createsSniffedFiles {
+		// change the extension so that it matches the file type we found
+		currentPath := result.DestPath
+		currentExtension := path.Ext(currentPath)
+		newPath := currentPath[0:len(currentPath)-len(currentExtension)] + "." + extension
+		fs.Rename(currentPath, newPath)
+		result.DestPath = newPath
+		result.ownedPath = newPath
+	}
+
+	if finalizer, ok := creator.(FileAttachmentFinalizer); ok {
+		var finalPath string
+		guardPolicy("FileAttachmentFinalizer", func() { finalPath, err = finalizer.FinalizeFile(ctx, fs, result.DestPath, url, typ) })
 		if err != nil {
-			return false, result, xerrors.Errorf("Unable to inspect file type in resource.DownloadFile: %w", err)
+			return false, downloadError(url.String(), "Unable to finalize file in resource.DownloadFile", err, callerFrames(xErrorsFrameCaller))
 		}
+		result.DestPath = finalPath
+	}
+
+	result.Valid = true
+	return true, nil
+}
 
-		// We only have to pass the file header = first 261 bytes
-		head := make([]byte, 261)
-		file.Read(head)
-		file.Close()
+// isPlainFileName returns true if name can be used as a file name as-is, it mustn't be empty, a directory reference or
+// contain a path separator
+func isPlainFileName(name string) bool {
+	switch name {
+	case "", ".", "..", "/":
+		return false
+	}
+	return !strings.ContainsAny(name, `/\`)
+}
 
-		fileType, fileTypeError := filetype.Match(head)
-		if fileTypeError == nil {
-			// change the extension so that it matches the file type we found
-			result.FileType = fileType
-			currentPath := result.DestPath
-			currentExtension := path.Ext(currentPath)
-			newPath := currentPath[0:len(currentPath)-len(currentExtension)] + "." + fileType.Extension
-			fs.Rename(currentPath, newPath)
-			result.DestPath = newPath
+// reservingPaths serializes reservePath so that file systems which ignore O_EXCL, like afero's MemMapFs, can't hand
+// the same name to two downloads either
+var reservingPaths sync.Mutex
+
+// reservePath returns wanted, or wanted with a "-1", "-2", ... suffix before its extension if that's already taken by
+// another file, so that downloads with the same original name don't replace each other. The returned name is reserved
+// by creating an empty file there, which the caller renames its download over. current is the download's own path and
+// counts as unused.
+func reservePath(fs afero.Fs, wanted string, current string) (string, error) {
+	reservingPaths.Lock()
+	defer reservingPaths.Unlock()
+
+	extension := filepath.Ext(wanted)
+	base := wanted[:len(wanted)-len(extension)]
+	candidate := wanted
+	for i := 1; ; i++ {
+		if candidate == current {
+			return candidate, nil
 		}
+		exists, err := afero.Exists(fs, candidate)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			reservation, err := fs.OpenFile(candidate, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+			if err == nil {
+				reservation.Close()
+				return candidate, nil
+			}
+			if !os.IsExist(err) {
+				return "", err
+			}
+		}
+		candidate = fmt.Sprintf("%s-%d%s", base, i, extension)
 	}
+}
 
-	result.Valid = true
-	return true, result, nil
+// detectTypeMismatch compares the declared type against what the file header looks like. Only confident sniffs are
+// reported: magic number matches, or HTML (the typical error page served in place of the real content).
+func detectTypeMismatch(declared Type, sniffed types.Type, head []byte) *TypeMismatchWarning {
+	if declared == nil || len(head) == 0 {
+		return nil
+	}
+	declaredMediaType := declared.MediaType()
+	if len(declaredMediaType) == 0 || declaredMediaType == "application/octet-stream" {
+		return nil
+	}
+
+	sniffedMediaType := sniffed.MIME.Value
+	if sniffed == types.Unknown {
+		detected, _, err := mime.ParseMediaType(http.DetectContentType(head))
+		if err != nil || detected != "text/html" {
+			return nil
+		}
+		sniffedMediaType = detected
+	}
+
+	// aliases, e.g. image/jpg for image/jpeg, are the same type
+	if DefaultTypeRegistry.Matches(declaredMediaType, sniffedMediaType) {
+		return nil
+	}
+	return &TypeMismatchWarning{DeclaredMediaType: declaredMediaType, SniffedMediaType: sniffedMediaType}
+}
+
+// assignedExtension chooses the extension for a download, custom AttachmentExtensions are checked for the sniffed
+// type first and then the declared type, before falling back to the sniffed type's own extension
+func assignedExtension(declared Type, sniffedType types.Type, sniffed bool, options []interface{}) (string, bool) {
+	if extensions, _ := OptionOf[AttachmentExtensions](options); extensions != nil {
+		if sniffed {
+			if extension, ok := extensions[sniffedType.MIME.Value]; ok {
+				return extension, true
+			}
+		}
+		if declared != nil {
+			if extension, ok := extensions[declared.MediaType()]; ok {
+				return extension, true
+			}
+		}
+	}
+
+	if sniffed {
+		return sniffedType.Extension, true
+	}
+	return "", false
+}
+
+func previewBytes(ctx context.Context, creator FileAttachmentCreator, url *url.URL, typ Type, options []interface{}) int64 {
+	policy, _ := OptionOf[AttachmentPreviewPolicy](append([]interface{}{creator}, options...))
+	if policy == nil {
+		return 0
+	}
+	defer policyPanicked("AttachmentPreviewPolicy")
+	return policy.PreviewBytes(ctx, url, typ)
+}
+
+func shouldDownload(ctx context.Context, creator FileAttachmentCreator, url *url.URL, resp *http.Response, typ Type, options []interface{}) bool {
+	policy, _ := OptionOf[AttachmentDownloadPolicy](append([]interface{}{creator}, options...))
+	defer policyPanicked("AttachmentDownloadPolicy")
+	return policy == nil || policy.ShouldDownload(ctx, url, typ, resp.ContentLength, resp.Header)
+}
+
+func preserveOriginalFileName(ctx context.Context, creator FileAttachmentCreator, url *url.URL, options []interface{}) bool {
+	policy, _ := OptionOf[PreserveOriginalFileNamePolicy](append([]interface{}{creator}, options...))
+	defer policyPanicked("PreserveOriginalFileNamePolicy")
+	return policy != nil && policy.PreserveOriginalFileName(ctx, url)
+}
+
+func attachmentTypeMismatchPolicy(creator FileAttachmentCreator, options []interface{}) AttachmentTypeMismatchPolicy {
+	result, _ := OptionOf[AttachmentTypeMismatchPolicy](append([]interface{}{creator}, options...))
+	return result
 }
@@ -0,0 +1,338 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/suite"
+)
+
+type FileSuite struct {
+	suite.Suite
+}
+
+func (suite *FileSuite) download(creator FileAttachmentCreator, urlText string, contentType string, body string, options ...interface{}) (bool, Attachment, error) {
+	ctx := context.Background()
+	u, _ := url.Parse(urlText)
+	t, _ := NewPageType(contentType)
+	resp := &http.Response{Header: make(http.Header), Body: ioutil.NopCloser(strings.NewReader(body)), ContentLength: -1}
+	return DownloadFileFromHTTPResp(ctx, creator, u, resp, t, options...)
+}
+
+func (suite *FileSuite) TestOpenAndStat() {
+	_, attachment, err := suite.download(NewMemoryAttachmentCreator(nil), "http://ceur-ws.org/Vol-1401/paper-05.pdf", "application/pdf", testPDFContent)
+	suite.Nil(err, "Should not get an error")
+
+	readable, ok := attachment.(ReadableAttachment)
+	suite.True(ok, "FileAttachment should be readable")
+
+	info, err := readable.Stat()
+	suite.Nil(err, "Should not get an error")
+	suite.Equal(int64(len(testPDFContent)), info.Size())
+
+	reader, err := readable.Open()
+	suite.Nil(err, "Should not get an error")
+	content, _ := ioutil.ReadAll(reader)
+	reader.Close()
+	suite.Equal(testPDFContent, string(content))
+}
+
+type invalidateOnMismatch struct{}
+
+func (invalidateOnMismatch) InvalidateOnTypeMismatch(ctx context.Context, url *url.URL, mismatch TypeMismatchWarning) bool {
+	return true
+}
+
+func (suite *FileSuite) TestTypeMismatchIsReported() {
+	const errorPage = "<!DOCTYPE html><html><head><title>Not Found</title></head><body>Gone</body></html>"
+
+	ok, attachment, err := suite.download(NewMemoryAttachmentCreator(nil), "http://ceur-ws.org/missing.pdf", "application/pdf", errorPage)
+	suite.Nil(err, "Without a policy the mismatch should only be a warning")
+	suite.True(ok, "Download should succeed")
+	fa := attachment.(*FileAttachment)
+	suite.True(fa.IsValid(), "Attachment should still be valid")
+	suite.NotNil(fa.TypeMismatch, "Mismatch should be recorded")
+	suite.Equal("application/pdf", fa.TypeMismatch.DeclaredMediaType)
+	suite.Equal("text/html", fa.TypeMismatch.SniffedMediaType)
+
+	ok, attachment, err = suite.download(NewMemoryAttachmentCreator(nil), "http://ceur-ws.org/missing.pdf", "application/pdf", errorPage, invalidateOnMismatch{})
+	suite.NotNil(err, "Policy should turn the mismatch into an error")
+	suite.False(ok, "Download should not succeed")
+	suite.False(attachment.IsValid(), "Attachment should be invalid")
+
+	_, attachment, err = suite.download(NewMemoryAttachmentCreator(nil), "http://ceur-ws.org/Vol-1401/paper-05.pdf", "application/pdf", testPDFContent, invalidateOnMismatch{})
+	suite.Nil(err, "Matching content should not be an error")
+	suite.Nil(attachment.(*FileAttachment).TypeMismatch, "Matching content should not be a mismatch")
+
+	const jpegContent = "\xff\xd8\xff\xe0\x00\x10JFIF\x00\x01\x01\x00\x00\x01\x00\x01\x00\x00"
+	for declared, body := range map[string]string{"image/jpg": jpegContent, "application/x-pdf": testPDFContent} {
+		ok, attachment, err = suite.download(NewMemoryAttachmentCreator(nil), "http://ceur-ws.org/Vol-1401/paper-05", declared, body, invalidateOnMismatch{})
+		suite.Nil(err, "%s is an alias of the sniffed type, not a mismatch", declared)
+		suite.True(ok)
+		suite.Nil(attachment.(*FileAttachment).TypeMismatch, "%s is an alias of the sniffed type, not a mismatch", declared)
+	}
+}
+
+type preserveFileName struct{}
+
+func (preserveFileName) PreserveOriginalFileName(ctx context.Context, url *url.URL) bool {
+	return true
+}
+
+func (suite *FileSuite) TestCustomExtensions() {
+	extensions := AttachmentExtensions{"application/pdf": "paper", "text/csv": "csv"}
+
+	_, attachment, err := suite.download(NewMemoryAttachmentCreator(nil), "http://ceur-ws.org/Vol-1401/paper-05", "application/pdf", testPDFContent, extensions)
+	suite.Nil(err, "Should not get an error")
+	suite.Equal(".paper", path.Ext(attachment.(*FileAttachment).DestPath), "Sniffed type should use the custom extension")
+
+	_, attachment, err = suite.download(NewMemoryAttachmentCreator(nil), "http://ceur-ws.org/data", "text/csv", "a,b\n1,2\n", extensions)
+	suite.Nil(err, "Should not get an error")
+	suite.Equal(".csv", path.Ext(attachment.(*FileAttachment).DestPath), "Declared type should use the custom extension")
+}
+
+func (suite *FileSuite) TestPreserveOriginalFileName() {
+	_, attachment, err := suite.download(NewMemoryAttachmentCreator(nil), "http://ceur-ws.org/Vol-1401/paper-05.bin", "application/pdf", testPDFContent, preserveFileName{})
+	suite.Nil(err, "Should not get an error")
+	suite.Equal("paper-05.bin", path.Base(attachment.(*FileAttachment).DestPath), "URL file name should be kept")
+}
+
+type failingRenameFs struct {
+	afero.Fs
+}
+
+func (failingRenameFs) Rename(oldname, newname string) error {
+	return errors.New("rename failed")
+}
+
+func (suite *FileSuite) TestPreservedFileNamesDontCollide() {
+	creator := NewMemoryAttachmentCreator(nil)
+	_, first, err := suite.download(creator, "http://ceur-ws.org/Vol-1401/download.pdf", "application/pdf", testPDFContent, preserveFileName{})
+	suite.Nil(err, "Should not get an error")
+	_, second, err := suite.download(creator, "http://ceur-ws.org/Vol-1402/download.pdf", "application/pdf", testPDFContent, preserveFileName{})
+	suite.Nil(err, "Should not get an error")
+
+	firstPath, secondPath := first.(*FileAttachment).DestPath, second.(*FileAttachment).DestPath
+	suite.Equal("download.pdf", path.Base(firstPath))
+	suite.Equal("download-1.pdf", path.Base(secondPath), "The second download shouldn't replace the first")
+	exists, _ := afero.Exists(creator.FS, firstPath)
+	suite.True(exists, "The first download should still be there")
+}
+
+// slowStatFs widens the window between checking that a name is free and using it
+type slowStatFs struct {
+	afero.Fs
+}
+
+func (fs slowStatFs) Stat(name string) (os.FileInfo, error) {
+	info, err := fs.Fs.Stat(name)
+	time.Sleep(5 * time.Millisecond)
+	return info, err
+}
+
+func (suite *FileSuite) TestConcurrentPreservedFileNamesDontCollide() {
+	tempDir, err := NewTempDirAttachmentCreator("lectio-resource-test-", nil)
+	suite.Require().Nil(err, "Should not get an error")
+	defer tempDir.Cleanup()
+
+	for _, creator := range []*FileSystemAttachmentCreator{NewFileSystemAttachmentCreator(slowStatFs{afero.NewMemMapFs()}, "", nil), tempDir.FileSystemAttachmentCreator} {
+		const downloads = 20
+		paths := make(chan string, downloads)
+		var wg sync.WaitGroup
+		for i := 0; i < downloads; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_, attachment, err := suite.download(creator, fmt.Sprintf("http://ceur-ws.org/Vol-%d/download.pdf", i), "application/pdf", testPDFContent, preserveFileName{})
+				suite.Nil(err, "Should not get an error")
+				paths <- attachment.(*FileAttachment).DestPath
+			}(i)
+		}
+		wg.Wait()
+		close(paths)
+
+		unique := make(map[string]bool)
+		for p := range paths {
+			unique[p] = true
+			exists, _ := afero.Exists(creator.FS, p)
+			suite.True(exists, "%s should still be there", p)
+		}
+		suite.Len(unique, downloads, "Every download should get its own file name")
+	}
+}
+
+func (suite *FileSuite) TestPreservedFileNameMustBePlain() {
+	_, attachment, err := suite.download(NewMemoryAttachmentCreator(nil), "http://ceur-ws.org/Vol-1401/..", "application/pdf", testPDFContent, preserveFileName{})
+	suite.Nil(err, "Should not get an error")
+	suite.NotEqual("..", path.Base(attachment.(*FileAttachment).DestPath), "A directory reference isn't a file name")
+	suite.False(isPlainFileName(".."))
+	suite.False(isPlainFileName(`a\b`))
+	suite.True(isPlainFileName("paper-05.pdf"))
+}
+
+func (suite *FileSuite) TestPreservedFileNameRenameError() {
+	creator := NewFileSystemAttachmentCreator(failingRenameFs{afero.NewMemMapFs()}, "", nil)
+	ok, _, err := suite.download(creator, "http://ceur-ws.org/Vol-1401/paper-05.bin", "application/pdf", testPDFContent, preserveFileName{})
+	suite.False(ok)
+	suite.NotNil(err, "A failed rename should be returned")
+	suite.Contains(err.Error(), "rename failed")
+	exists, _ := afero.Exists(creator.FS, "paper-05.bin")
+	suite.False(exists, "The reserved name should be released")
+}
+
+func (suite *FileSuite) TestDownloadSinks() {
+	var hashSink *HashSink
+	var copied bytes.Buffer
+	var finished Attachment
+	chain := DownloadSinkChainFunc(func(ctx context.Context, url *url.URL, t Type) ([]DownloadSink, error) {
+		hashSink, _ = NewHashSink("sha-256")
+		copySink := WriterSink{Writer: &copied, OnFinish: func(ctx context.Context, attachment Attachment, err error) error {
+			finished = attachment
+			return err
+		}}
+		return []DownloadSink{hashSink, copySink}, nil
+	})
+
+	_, attachment, err := suite.download(NewMemoryAttachmentCreator(nil), "http://ceur-ws.org/Vol-1401/paper-05.pdf", "application/pdf", testPDFContent, chain)
+	suite.Nil(err, "Should not get an error")
+
+	sum := sha256.Sum256([]byte(testPDFContent))
+	suite.Equal(hex.EncodeToString(sum[:]), hashSink.HexSum(), "Hash sink should see the whole download")
+	suite.Equal(testPDFContent, copied.String(), "Writer sink should see the whole download")
+	suite.Equal(attachment, finished, "Sinks should be told which attachment finished")
+}
+
+func (suite *FileSuite) TestExpectedChecksums() {
+	md5Sum := md5.Sum([]byte(testPDFContent))
+	sha256Sum := sha256.Sum256([]byte(testPDFContent))
+
+	_, attachment, err := suite.download(NewMemoryAttachmentCreator(nil), "http://ceur-ws.org/Vol-1401/paper-05.pdf", "application/pdf", testPDFContent, Checksum{Algorithm: "SHA-256", Value: sha256Sum[:]})
+	suite.Nil(err, "Should not get an error")
+	suite.True(attachment.IsValid(), "Attachment should be valid")
+	suite.Equal(hex.EncodeToString(sha256Sum[:]), attachment.(*FileAttachment).Checksums["sha-256"])
+
+	_, attachment, err = suite.download(NewMemoryAttachmentCreator(nil), "http://ceur-ws.org/Vol-1401/paper-05.pdf", "application/pdf", testPDFContent, Checksum{Algorithm: "md5", Value: sha256Sum[:16]})
+	suite.NotNil(err, "Should get an error")
+	suite.False(attachment.IsValid(), "Attachment should be invalid")
+	mismatch, ok := err.(*ChecksumMismatchError)
+	suite.True(ok, "Error should be a ChecksumMismatchError")
+	if ok {
+		suite.Equal("md5", mismatch.Algorithm)
+		suite.Equal(hex.EncodeToString(md5Sum[:]), mismatch.Actual)
+	}
+
+	header := make(http.Header)
+	header.Set("Digest", "SHA-256="+base64.StdEncoding.EncodeToString(sha256Sum[:])+", unknown=AAAA")
+	header.Set("Content-MD5", base64.StdEncoding.EncodeToString(md5Sum[:]))
+	_, err = downloadWithHeader(header)
+	suite.Nil(err, "Matching header digests should verify")
+
+	header.Set("Content-MD5", base64.StdEncoding.EncodeToString(sha256Sum[:16]))
+	_, err = downloadWithHeader(header)
+	suite.NotNil(err, "Mismatched header digests should fail")
+}
+
+func downloadWithHeader(header http.Header) (Attachment, error) {
+	ctx := context.Background()
+	u, _ := url.Parse("http://ceur-ws.org/Vol-1401/paper-05.pdf")
+	t, _ := NewPageType("application/pdf")
+	resp := &http.Response{Header: header, Body: ioutil.NopCloser(strings.NewReader(testPDFContent)), ContentLength: -1}
+	_, attachment, err := DownloadFileFromHTTPResp(ctx, NewMemoryAttachmentCreator(nil), u, resp, t)
+	return attachment, err
+}
+
+func (suite *FileSuite) TestTruncatedDownload() {
+	ctx := context.Background()
+	u, _ := url.Parse("http://ceur-ws.org/Vol-1401/paper-05.pdf")
+	t, _ := NewPageType("application/pdf")
+	resp := &http.Response{Header: make(http.Header), Body: ioutil.NopCloser(strings.NewReader(testPDFContent)), ContentLength: int64(len(testPDFContent) + 100)}
+
+	ok, attachment, err := DownloadFileFromHTTPResp(ctx, NewMemoryAttachmentCreator(nil), u, resp, t)
+	suite.False(ok, "Truncated download should not succeed")
+	suite.False(attachment.IsValid(), "Truncated download should be invalid")
+	mismatch, isMismatch := err.(*ContentLengthMismatchError)
+	suite.True(isMismatch, "Error should be a ContentLengthMismatchError")
+	if isMismatch {
+		suite.Equal(int64(len(testPDFContent)), mismatch.Received)
+	}
+}
+
+type maxSizeDownloadPolicy int64
+
+func (max maxSizeDownloadPolicy) ShouldDownload(ctx context.Context, url *url.URL, t Type, contentLength int64, headers http.Header) bool {
+	return contentLength >= 0 && contentLength <= int64(max)
+}
+
+func (suite *FileSuite) TestDownloadPolicyDeclines() {
+	creator := NewMemoryAttachmentCreator(nil)
+	ok, attachment, err := suite.download(creator, "http://ceur-ws.org/Vol-1401/paper-05.pdf", "application/pdf", testPDFContent, maxSizeDownloadPolicy(1024))
+	suite.False(ok, "Unknown sizes should be declined")
+	suite.Nil(attachment, "Declined downloads should not have an attachment")
+	suite.Nil(err, "Declined downloads should not be an error")
+
+	files, _ := afero.ReadDir(creator.FS, "/")
+	suite.Empty(files, "Declined downloads should not create files")
+}
+
+func (suite *FileSuite) TestPreviewDownload() {
+	ctx := context.Background()
+	u, _ := url.Parse("http://ceur-ws.org/Vol-1401/paper-05.pdf")
+	t, _ := NewPageType("application/pdf")
+	wrongDigest := sha256.Sum256([]byte("not the full content"))
+	resp := &http.Response{Header: make(http.Header), Body: ioutil.NopCloser(strings.NewReader(testPDFContent)), ContentLength: int64(len(testPDFContent))}
+	resp.Header.Set("Digest", "SHA-256="+base64.StdEncoding.EncodeToString(wrongDigest[:]))
+
+	ok, attachment, err := DownloadFileFromHTTPResp(ctx, NewMemoryAttachmentCreator(nil), u, resp, t, AttachmentPreviewSize(8))
+	suite.Nil(err, "Previews should not be checked against the full Content-Length or digest")
+	suite.True(ok, "Preview should succeed")
+
+	fa := attachment.(*FileAttachment)
+	suite.True(fa.Preview, "Attachment should be marked as a preview")
+	suite.Equal(".pdf", path.Ext(fa.DestPath), "Preview should still be sniffed")
+	info, _ := fa.Stat()
+	suite.Equal(int64(8), info.Size())
+}
+
+type recordingSniffedCreator struct {
+	*FileSystemAttachmentCreator
+	sniffed []SniffedType
+}
+
+func (c *recordingSniffedCreator) CreateSniffedFile(ctx context.Context, url *url.URL, t Type, sniffed SniffedType) (afero.Fs, afero.File, error) {
+	c.sniffed = append(c.sniffed, sniffed)
+	return c.FileSystemAttachmentCreator.CreateSniffedFile(ctx, url, t, sniffed)
+}
+
+func (suite *FileSuite) TestSniffBeforeCreate() {
+	creator := &recordingSniffedCreator{FileSystemAttachmentCreator: NewMemoryAttachmentCreator(URLPathNamingStrategy{OmitHost: true})}
+	_, attachment, err := suite.download(creator, "http://ceur-ws.org/Vol-1401/paper-05.download", "application/octet-stream", testPDFContent)
+	suite.Nil(err, "Should not get an error")
+
+	suite.Len(creator.sniffed, 1)
+	suite.Equal("pdf", creator.sniffed[0].Extension, "Creator should be told the extension up front")
+	suite.Equal("application/pdf", creator.sniffed[0].FileType.MIME.Value)
+	suite.Equal("Vol-1401/paper-05.pdf", attachment.(*FileAttachment).DestPath)
+
+	files, _ := afero.ReadDir(creator.FS, "Vol-1401")
+	suite.Len(files, 1, "No other files should have been created")
+}
+
+func TestFileSuite(t *testing.T) {
+	suite.Run(t, new(FileSuite))
+}
@@ -1,6 +1,6 @@
 module github.com/lectio/resource
 
-go 1.12
+go 1.18
 
 require (
 	github.com/h2non/filetype v1.0.8
@@ -0,0 +1,106 @@
+package resource
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// HedgingPolicy is passed into options if a second, identical, request should be sent when the first hasn't been
+// answered within the returned delay, the first response wins. Zero or less means no hedging.
+type HedgingPolicy interface {
+	HedgeDelay(ctx context.Context, url *url.URL) time.Duration
+}
+
+// HedgeAfter is a HedgingPolicy which hedges every request after the same delay
+type HedgeAfter time.Duration
+
+// HedgeDelay satisfies HedgingPolicy method
+func (h HedgeAfter) HedgeDelay(ctx context.Context, url *url.URL) time.Duration {
+	return time.Duration(h)
+}
+
+// cancelOnClose cancels the context of a request once its response body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+type hedgedAttempt struct {
+	index int
+	resp  *http.Response
+	err   error
+}
+
+// doHedged sends req and, if there's no response after delay, sends it again. The first response is returned and
+// the other request is cancelled. If the first request fails before the hedge is sent its error is returned, hedging
+// is about latency and isn't a retry policy.
+func doHedged(clock Clock, client *http.Client, req *http.Request, delay time.Duration) (*http.Response, error) {
+	attempts := make(chan hedgedAttempt, 2)
+	var cancels []context.CancelFunc
+	launch := func() {
+		ctx, cancel := context.WithCancel(req.Context())
+		index := len(cancels)
+		cancels = append(cancels, cancel)
+		go func() {
+			resp, err := hedgedDo(client, req.WithContext(ctx))
+			attempts <- hedgedAttempt{index: index, resp: resp, err: err}
+		}()
+	}
+
+	launch()
+	received := 0
+	timer := clock.NewTimer(delay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C():
+			if len(cancels) == 1 && received == 0 {
+				launch()
+			}
+		case attempt := <-attempts:
+			received++
+			if attempt.err == nil {
+				for index, cancel := range cancels {
+					if index != attempt.index {
+						cancel()
+					}
+				}
+				if outstanding := len(cancels) - received; outstanding > 0 {
+					go discardHedgedAttempts(attempts, outstanding)
+				}
+				attempt.resp.Body = cancelOnClose{ReadCloser: attempt.resp.Body, cancel: cancels[attempt.index]}
+				return attempt.resp, nil
+			}
+			cancels[attempt.index]()
+			if received == len(cancels) {
+				return nil, attempt.err
+			}
+		}
+	}
+}
+
+// hedgedDo sends one of the hedged requests. It runs in its own goroutine, where a policy's panic can't reach the
+// caller's recoverPolicyPanic, so the panic is recovered here and becomes this attempt's error.
+func hedgedDo(client *http.Client, req *http.Request) (resp *http.Response, err error) {
+	defer recoverPolicyPanic(&err)
+	return clientDo(client, req)
+}
+
+// discardHedgedAttempts releases the connections of the (already cancelled) requests which lost the race
+func discardHedgedAttempts(attempts chan hedgedAttempt, count int) {
+	for i := 0; i < count; i++ {
+		if attempt := <-attempts; attempt.err == nil {
+			attempt.resp.Body.Close()
+		}
+	}
+}
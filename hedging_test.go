@@ -0,0 +1,92 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/xerrors"
+)
+
+type HedgingSuite struct {
+	suite.Suite
+}
+
+func (suite *HedgingSuite) TestHedgedRequestWins() {
+	var requests int32
+	cancelled := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			// the first request stalls until the hedge wins and it's cancelled
+			select {
+			case <-r.Context().Done():
+				cancelled <- struct{}{}
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, testHTMLPage)
+	}))
+	defer server.Close()
+
+	started := time.Now()
+	content, err := NewFactory(HedgeAfter(20*time.Millisecond)).PageFromURL(context.Background(), server.URL)
+	suite.Nil(err, "Should not get an error")
+	suite.True(time.Since(started) < 2*time.Second, "Hedged request should answer before the stalled one")
+	value, _, _ := content.MetaTag("og:site_name")
+	suite.Equal("Netspective", value)
+
+	select {
+	case <-cancelled:
+	case <-time.After(2 * time.Second):
+		suite.Fail("Losing request should be cancelled")
+	}
+	suite.Equal(int32(2), atomic.LoadInt32(&requests))
+}
+
+func (suite *HedgingSuite) TestFastResponseIsNotHedged() {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, testHTMLPage)
+	}))
+	defer server.Close()
+
+	_, err := NewFactory(HedgeAfter(time.Second)).PageFromURL(context.Background(), server.URL)
+	suite.Nil(err, "Should not get an error")
+	suite.Equal(int32(1), atomic.LoadInt32(&requests))
+}
+
+func (suite *HedgingSuite) TestPolicyPanicInHedgedAttempt() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, testHTMLPage)
+	}))
+	defer server.Close()
+
+	panicky := RoundTripperDecoratorFunc(func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) { panic("transport bug") })
+	})
+	_, err := NewFactory(HedgeAfter(time.Second), panicky).PageFromURL(context.Background(), server.URL)
+	var panicked *PolicyPanicError
+	suite.True(xerrors.As(err, &panicked), "The attempt's panic should be returned as an error")
+	suite.Equal("RoundTripper", panicked.Policy)
+
+	redirects := RedirectPolicyFunc(func(ctx context.Context, req *http.Request, via []*http.Request) error { panic("redirect bug") })
+	redirecting := httptest.NewServer(http.RedirectHandler(server.URL, http.StatusFound))
+	defer redirecting.Close()
+	_, err = NewFactory(HedgeAfter(time.Second), redirects).PageFromURL(context.Background(), redirecting.URL)
+	suite.True(xerrors.As(err, &panicked), "The attempt's panic should be returned as an error")
+	suite.Equal("RedirectPolicy", panicked.Policy)
+}
+
+func TestHedgingSuite(t *testing.T) {
+	suite.Run(t, new(HedgingSuite))
+}
@@ -0,0 +1,206 @@
+package resource
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// HostProfileHeaders are the response headers of a host's home page which are kept in its HostProfile
+var HostProfileHeaders = []string{
+	"Server",
+	"X-Powered-By",
+	"Via",
+	"Strict-Transport-Security",
+	"Content-Security-Policy",
+	"X-Frame-Options",
+	"X-Content-Type-Options",
+	"Referrer-Policy",
+	"Cache-Control",
+	"Alt-Svc",
+}
+
+// HostTLSInfo describes the TLS connection and leaf certificate of a host
+type HostTLSInfo struct {
+	Version     string    `json:"version"`
+	CipherSuite uint16    `json:"cipherSuite"` // one of the tls.TLS_* constants
+	Subject     string    `json:"subject"`
+	Issuer      string    `json:"issuer"`
+	DNSNames    []string  `json:"dnsNames,omitempty"`
+	NotBefore   time.Time `json:"notBefore"`
+	NotAfter    time.Time `json:"notAfter"`
+}
+
+// HostProfile aggregates what's known about a host, for crawl planning and reporting
+type HostProfile struct {
+	Host         string       `json:"host"`
+	HomeURL      *url.URL     `json:"homeURL,omitempty"` // where the home page ended up after redirects
+	HasRobotsTxt bool         `json:"hasRobotsTxt"`
+	Sitemaps     []string     `json:"sitemaps,omitempty"` // from robots.txt, or /sitemap.xml if it exists
+	TLS          *HostTLSInfo `json:"tls,omitempty"`      // nil for plain HTTP or archived responses
+	Headers      http.Header  `json:"headers,omitempty"`  // the HostProfileHeaders sent with the home page
+	Favicon      *url.URL     `json:"favicon,omitempty"`
+	ProfiledAt   time.Time    `json:"profiledAt"`
+	Errors       []string     `json:"errors,omitempty"` // problems with individual probes, the profile is still usable
+}
+
+// HasSitemap returns true if a sitemap was advertised or found
+func (p HostProfile) HasSitemap() bool {
+	return len(p.Sitemaps) > 0
+}
+
+// hostProfileCache holds the profiles built by a factory, it's behind a pointer so factories can be copied
+type hostProfileCache struct {
+	mu       sync.Mutex
+	profiles map[string]*HostProfile
+}
+
+// HostProfile returns the profile of host (e.g. "www.netspective.com"), probing its home page, robots.txt, sitemap and
+// favicon the first time it's asked for and answering from the factory's cache after that
+func (f *DefaultFactory) HostProfile(ctx context.Context, host string) (_ *HostProfile, err error) {
+	defer recoverPolicyPanic(&err)
+	home, err := url.Parse(wellKnownURL(host, "/"))
+	if err != nil || len(home.Host) == 0 {
+		return nil, xerrors.Errorf("Unable to profile host %q: invalid host", host)
+	}
+	key := strings.ToLower(home.Host)
+	profiles := f.profileCache(ctx)
+
+	if profiles != nil {
+		profiles.mu.Lock()
+		cached, ok := profiles.profiles[key]
+		profiles.mu.Unlock()
+		if ok {
+			return cached, nil
+		}
+	}
+
+	result := f.profileHost(ctx, home)
+	if profiles != nil {
+		profiles.mu.Lock()
+		if cached, ok := profiles.profiles[key]; ok {
+			// another goroutine got there first
+			result = cached
+		} else {
+			profiles.profiles[key] = result
+		}
+		profiles.mu.Unlock()
+	}
+	return result, nil
+}
+
+func (f *DefaultFactory) profileHost(ctx context.Context, home *url.URL) *HostProfile {
+	result := &HostProfile{Host: home.Host, ProfiledAt: f.clock().Now()}
+	addError := func(err error) {
+		result.Errors = append(result.Errors, err.Error())
+	}
+
+	if resp, err := f.fetch(ctx, home.String(), nil); err != nil {
+		addError(err)
+	} else {
+		result.HomeURL = resp.Request.URL
+		result.TLS = hostTLSInfo(resp.TLS)
+		for _, name := range HostProfileHeaders {
+			if values := resp.Header[http.CanonicalHeaderKey(name)]; len(values) > 0 {
+				if result.Headers == nil {
+					result.Headers = make(http.Header)
+				}
+				result.Headers[http.CanonicalHeaderKey(name)] = values
+			}
+		}
+		if DefaultTypeRegistry.Matches(resp.Header.Get("Content-Type"), "text/html") {
+			page := &Page{TargetURL: result.HomeURL, MetaPropertyTags: make(map[string]interface{}), parseMetaData: true}
+			page.parsePageMetaData(ctx, result.HomeURL, resp)
+			for _, rel := range []string{"icon", "apple-touch-icon"} {
+				if links := page.LinksByRel(rel); len(links) > 0 && result.Favicon == nil {
+					result.Favicon = links[0].URL
+				}
+			}
+		} else {
+			resp.Body.Close()
+		}
+	}
+
+	if resp, err := f.fetch(ctx, wellKnownURL(home.String(), "/robots.txt"), nil); err != nil {
+		addError(err)
+	} else {
+		result.HasRobotsTxt = true
+		result.Sitemaps = robotsTxtSitemaps(resp.Body)
+		resp.Body.Close()
+	}
+
+	if !result.HasSitemap() {
+		sitemapURL := wellKnownURL(home.String(), "/sitemap.xml")
+		if f.probe(ctx, sitemapURL) {
+			result.Sitemaps = []string{sitemapURL}
+		}
+	}
+	if result.Favicon == nil {
+		faviconURL := wellKnownURL(home.String(), "/favicon.ico")
+		if f.probe(ctx, faviconURL) {
+			result.Favicon, _ = url.Parse(faviconURL)
+		}
+	}
+	return result
+}
+
+// probe returns true if urlText can be fetched, the body is discarded
+func (f *DefaultFactory) probe(ctx context.Context, urlText string) bool {
+	resp, err := f.fetch(ctx, urlText, nil)
+	if err != nil {
+		return false
+	}
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64*1024))
+	resp.Body.Close()
+	return true
+}
+
+// robotsTxtSitemaps returns the Sitemap: directives of a robots.txt file
+func robotsTxtSitemaps(r io.Reader) []string {
+	var result []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if index := strings.Index(line, "#"); index >= 0 {
+			line = line[:index]
+		}
+		if index := strings.Index(line, ":"); index > 0 && strings.EqualFold(strings.TrimSpace(line[:index]), "sitemap") {
+			if value := strings.TrimSpace(line[index+1:]); len(value) > 0 {
+				result = append(result, value)
+			}
+		}
+	}
+	return result
+}
+
+var tlsVersionNames = map[uint16]string{
+	tls.VersionTLS10: "TLS 1.0",
+	tls.VersionTLS11: "TLS 1.1",
+	tls.VersionTLS12: "TLS 1.2",
+	tls.VersionTLS13: "TLS 1.3",
+}
+
+func hostTLSInfo(state *tls.ConnectionState) *HostTLSInfo {
+	if state == nil {
+		return nil
+	}
+	result := &HostTLSInfo{Version: tlsVersionNames[state.Version], CipherSuite: state.CipherSuite}
+	if len(state.PeerCertificates) > 0 {
+		leaf := state.PeerCertificates[0]
+		result.Subject = leaf.Subject.String()
+		result.Issuer = leaf.Issuer.String()
+		result.DNSNames = leaf.DNSNames
+		result.NotBefore = leaf.NotBefore
+		result.NotAfter = leaf.NotAfter
+	}
+	return result
+}
@@ -0,0 +1,56 @@
+package resource
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type HostProfileSuite struct {
+	suite.Suite
+}
+
+func (suite *HostProfileSuite) TestHostProfile() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://www.netspective.com/", archivedResponse(200, http.Header{
+		"Content-Type": {"text/html"},
+		"Server":       {"nginx"},
+		"Set-Cookie":   {"session=secret"},
+	}, `<html><head><link rel="shortcut icon" href="/static/favicon.png"></head></html>`))
+	archive.Add("https://www.netspective.com/robots.txt", archivedResponse(200, http.Header{"Content-Type": {"text/plain"}},
+		"User-agent: *\nDisallow: /private\nSitemap: https://www.netspective.com/sitemap_index.xml # all sitemaps\n"))
+	factory := NewFactory(archive)
+
+	profile, err := factory.HostProfile(context.Background(), "www.netspective.com")
+	suite.Nil(err, "Should not get an error")
+	suite.True(profile.HasRobotsTxt)
+	suite.Equal([]string{"https://www.netspective.com/sitemap_index.xml"}, profile.Sitemaps)
+	suite.Equal("https://www.netspective.com/static/favicon.png", profile.Favicon.String())
+	suite.Equal("nginx", profile.Headers.Get("Server"))
+	suite.Empty(profile.Headers.Get("Set-Cookie"), "Only common headers should be kept")
+	suite.Nil(profile.TLS, "Archived responses have no TLS state")
+	suite.Empty(profile.Errors)
+
+	cached, _ := factory.HostProfile(context.Background(), "https://www.netspective.com/about")
+	suite.True(profile == cached, "Profiles should be cached per host")
+}
+
+func (suite *HostProfileSuite) TestProbedSitemapAndFavicon() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://example.com/", archivedResponse(200, http.Header{"Content-Type": {"application/pdf"}}, testPDFContent))
+	archive.Add("https://example.com/sitemap.xml", archivedResponse(200, http.Header{"Content-Type": {"application/xml"}}, "<urlset/>"))
+	archive.Add("https://example.com/favicon.ico", archivedResponse(200, http.Header{"Content-Type": {"image/x-icon"}}, "ico"))
+
+	profile, err := NewFactory(archive).HostProfile(context.Background(), "example.com")
+	suite.Nil(err, "Should not get an error")
+	suite.False(profile.HasRobotsTxt)
+	suite.Len(profile.Errors, 1, "Missing robots.txt should be recorded")
+	suite.True(profile.HasSitemap())
+	suite.Equal("https://example.com/favicon.ico", profile.Favicon.String())
+}
+
+func TestHostProfileSuite(t *testing.T) {
+	suite.Run(t, new(HostProfileSuite))
+}
@@ -0,0 +1,190 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/afero"
+	"golang.org/x/xerrors"
+)
+
+// HostStats are the accumulated results of fetches from a host, kept across runs by a persistent HostStatsStore
+type HostStats struct {
+	Host             string        `json:"host"`
+	Requests         int64         `json:"requests"`
+	Successes        int64         `json:"successes"`
+	Failures         int64         `json:"failures"`
+	TotalLatency     time.Duration `json:"totalLatency"` // time to response headers, summed over successful requests
+	BytesTransferred int64         `json:"bytesTransferred"`
+	LastRequestAt    time.Time     `json:"lastRequestAt"`
+	LastError        string        `json:"lastError,omitempty"`
+	LastErrorAt      time.Time     `json:"lastErrorAt,omitempty"`
+}
+
+// SuccessRate returns the fraction of requests which succeeded, 1 if there haven't been any requests
+func (s HostStats) SuccessRate() float64 {
+	if s.Requests == 0 {
+		return 1
+	}
+	return float64(s.Successes) / float64(s.Requests)
+}
+
+// AverageLatency returns the mean time to response headers of successful requests
+func (s HostStats) AverageLatency() time.Duration {
+	if s.Successes == 0 {
+		return 0
+	}
+	return s.TotalLatency / time.Duration(s.Successes)
+}
+
+// hostKey returns the host per-host statistics of u are kept under: lower case, with its port unless it's the
+// scheme's default
+func hostKey(u *url.URL) string {
+	host := strings.ToLower(u.Host)
+	if port := u.Port(); (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		host = strings.ToLower(u.Hostname())
+	}
+	return host
+}
+
+// HostFetchResult is what a HostStatsStore is told about each fetch
+type HostFetchResult struct {
+	At       time.Time
+	Latency  time.Duration // time to response headers
+	Duration time.Duration // time until the body was closed, zero for failures
+	Bytes    int64
+	Err      error
+	Identity RequestIdentity // of the fetch, from the context
+}
+
+// HostStatsStore is passed into options to track per-host fetch statistics. A TenantRouter's rate limits consult them
+// to slow down for unhealthy hosts (see TenantPolicy), and other health decisions can through the factory's
+// HostStatsStore field.
+type HostStatsStore interface {
+	HostStats(ctx context.Context, host string) (HostStats, bool, error)
+	RecordFetch(ctx context.Context, host string, result HostFetchResult) error
+}
+
+// MemoryHostStatsStore is a HostStatsStore for the lifetime of the process
+type MemoryHostStatsStore struct {
+	mu    sync.RWMutex
+	stats map[string]*HostStats
+}
+
+// NewMemoryHostStatsStore creates an empty MemoryHostStatsStore
+func NewMemoryHostStatsStore() *MemoryHostStatsStore {
+	return &MemoryHostStatsStore{stats: make(map[string]*HostStats)}
+}
+
+// HostStats satisfies HostStatsStore method
+func (s *MemoryHostStatsStore) HostStats(ctx context.Context, host string) (HostStats, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if stats, ok := s.stats[strings.ToLower(host)]; ok {
+		return *stats, true, nil
+	}
+	return HostStats{Host: host}, false, nil
+}
+
+// RecordFetch satisfies HostStatsStore method
+func (s *MemoryHostStatsStore) RecordFetch(ctx context.Context, host string, result HostFetchResult) error {
+	key := strings.ToLower(host)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats, ok := s.stats[key]
+	if !ok {
+		stats = &HostStats{Host: key}
+		s.stats[key] = stats
+	}
+
+	stats.Requests++
+	stats.LastRequestAt = result.At
+	stats.BytesTransferred += result.Bytes
+	if result.Err != nil {
+		stats.Failures++
+		stats.LastError = result.Err.Error()
+		stats.LastErrorAt = result.At
+	} else {
+		stats.Successes++
+		stats.TotalLatency += result.Latency
+	}
+	return nil
+}
+
+// Hosts returns a copy of the statistics of every host
+func (s *MemoryHostStatsStore) Hosts() []HostStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]HostStats, 0, len(s.stats))
+	for _, stats := range s.stats {
+		result = append(result, *stats)
+	}
+	return result
+}
+
+// FileHostStatsStore is a MemoryHostStatsStore which is loaded from, and saved to, a JSON file so statistics
+// survive across runs. Call Save when the run is complete (or periodically).
+type FileHostStatsStore struct {
+	*MemoryHostStatsStore
+	FS   afero.Fs
+	Path string
+}
+
+// NewFileHostStatsStore creates a FileHostStatsStore, loading the statistics in path if the file exists
+func NewFileHostStatsStore(fs afero.Fs, path string) (*FileHostStatsStore, error) {
+	result := &FileHostStatsStore{MemoryHostStatsStore: NewMemoryHostStatsStore(), FS: fs, Path: path}
+	file, err := fs.Open(path)
+	if os.IsNotExist(err) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to open host statistics %q: %w", path, err)
+	}
+	defer file.Close()
+
+	var hosts []HostStats
+	if err := json.NewDecoder(file).Decode(&hosts); err != nil && err != io.EOF {
+		return nil, xerrors.Errorf("Unable to decode host statistics %q: %w", path, err)
+	}
+	for i := range hosts {
+		result.stats[strings.ToLower(hosts[i].Host)] = &hosts[i]
+	}
+	return result, nil
+}
+
+// Save writes the statistics of every host to the store's file
+func (s *FileHostStatsStore) Save() error {
+	data, err := json.MarshalIndent(s.Hosts(), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := afero.WriteFile(s.FS, s.Path, data, 0644); err != nil {
+		return xerrors.Errorf("Unable to save host statistics %q: %w", s.Path, err)
+	}
+	return nil
+}
+
+// countingBody reports the number of bytes read from a response body once it has been closed
+type countingBody struct {
+	io.ReadCloser
+	record func(bytes int64)
+	bytes  int64
+	once   sync.Once
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.bytes += int64(n)
+	return n, err
+}
+
+func (b *countingBody) Close() error {
+	b.once.Do(func() { b.record(b.bytes) })
+	return b.ReadCloser.Close()
+}
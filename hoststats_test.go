@@ -0,0 +1,110 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/xerrors"
+)
+
+type HostStatsSuite struct {
+	suite.Suite
+	server *httptest.Server
+}
+
+func (suite *HostStatsSuite) SetupSuite() {
+	suite.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/unavailable" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, testHTMLPage)
+	}))
+}
+
+func (suite *HostStatsSuite) TearDownSuite() {
+	suite.server.Close()
+}
+
+func (suite *HostStatsSuite) TestFetchesAreRecorded() {
+	ctx := context.Background()
+	fs := afero.NewMemMapFs()
+	store, err := NewFileHostStatsStore(fs, "/stats.json")
+	suite.Nil(err, "Missing file should not be an error")
+
+	factory := NewFactory(store)
+	_, err = factory.PageFromURL(ctx, suite.server.URL+"/")
+	suite.Nil(err, "Should not get an error")
+	_, err = factory.PageFromURL(ctx, suite.server.URL+"/unavailable")
+	suite.NotNil(err, "Should get an error")
+
+	host, _ := url.Parse(suite.server.URL)
+	stats, ok, _ := store.HostStats(ctx, host.Host)
+	suite.True(ok, "Host should have statistics")
+	suite.Equal(int64(2), stats.Requests)
+	suite.Equal(int64(1), stats.Failures)
+	suite.Equal(0.5, stats.SuccessRate())
+	suite.Equal(int64(len(testHTMLPage)), stats.BytesTransferred)
+	suite.NotEmpty(stats.LastError)
+
+	suite.Nil(store.Save(), "Should not get an error")
+	reloaded, err := NewFileHostStatsStore(fs, "/stats.json")
+	suite.Nil(err, "Should not get an error")
+	stats, ok, _ = reloaded.HostStats(ctx, host.Host)
+	suite.True(ok, "Statistics should survive across runs")
+	suite.Equal(int64(2), stats.Requests)
+}
+
+func (suite *HostStatsSuite) TestHostKey() {
+	for urlText, key := range map[string]string{
+		"https://Example.com:443/a": "example.com",
+		"http://example.com:80/a":   "example.com",
+		"http://example.com:8080/a": "example.com:8080",
+		"https://example.com/a":     "example.com",
+	} {
+		u, _ := url.Parse(urlText)
+		suite.Equal(key, hostKey(u), urlText)
+	}
+}
+
+func (suite *HostStatsSuite) TestPacingFindsRecordedStats() {
+	clock := NewManualClock(time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC))
+	store := NewMemoryHostStatsStore()
+	router := NewTenantRouter().Tenant("acme", TenantPolicy{RequestsPerSecond: 1, Burst: 2 * hostPaceMinRequests})
+	factory := NewFactory(clock, store, router)
+	ctx, cancel := context.WithCancel(ContextWithTenant(context.Background(), "acme"))
+	defer cancel()
+	for index := 0; index < hostPaceMinRequests; index++ {
+		_, err := factory.PageFromURL(ctx, suite.server.URL+"/unavailable")
+		suite.NotNil(err, "Should get an error")
+	}
+	suite.Len(store.Hosts(), 1, "Every fetch from the host should be recorded under one key")
+
+	done := make(chan error)
+	go func() {
+		_, err := factory.PageFromURL(ctx, suite.server.URL+"/unavailable")
+		done <- err
+	}()
+	for clock.Timers() == 0 {
+		select {
+		case <-done:
+			suite.Fail("A host on a non-default port should be paced by its recorded statistics")
+			return
+		case <-time.After(time.Millisecond):
+		}
+	}
+	cancel()
+	suite.True(xerrors.Is(<-done, context.Canceled), "The paced request should wait for the rate limit")
+}
+
+func TestHostStatsSuite(t *testing.T) {
+	suite.Run(t, new(HostStatsSuite))
+}
@@ -0,0 +1,126 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// HTMLBodyLimitPolicy is passed into options if only the first bytes of HTML pages should be read, which is usually
+// enough for the meta data in <head>. Zero or less means the whole page.
+type HTMLBodyLimitPolicy interface {
+	HTMLBodyLimit(ctx context.Context, url *url.URL) int64
+}
+
+// HTMLBodyLimit is an HTMLBodyLimitPolicy which reads the same number of bytes of every page
+type HTMLBodyLimit int64
+
+// HTMLBodyLimit satisfies HTMLBodyLimitPolicy method
+func (l HTMLBodyLimit) HTMLBodyLimit(ctx context.Context, url *url.URL) int64 {
+	return int64(l)
+}
+
+// HTMLHeadOnlyPolicy is passed into options if reading an HTML page should stop once its </head> has been received
+type HTMLHeadOnlyPolicy interface {
+	HTMLHeadOnly(ctx context.Context, url *url.URL) bool
+}
+
+// HTMLHeadOnly is an HTMLHeadOnlyPolicy with the same answer for every page
+type HTMLHeadOnly bool
+
+// HTMLHeadOnly satisfies HTMLHeadOnlyPolicy method
+func (h HTMLHeadOnly) HTMLHeadOnly(ctx context.Context, url *url.URL) bool {
+	return bool(h)
+}
+
+// limitedBody keeps the original body's Close when its reads are limited
+type limitedBody struct {
+	io.Reader
+	io.Closer
+}
+
+// limitHTMLBody applies the HTMLBodyLimitPolicy and HTMLHeadOnlyPolicy to resp. The body is deliberately truncated
+// so its Content-Length no longer applies.
+func (f *DefaultFactory) limitHTMLBody(ctx context.Context, url *url.URL, resp *http.Response) {
+	var reader io.Reader = resp.Body
+	limited := false
+	if f.HTMLHeadOnlyPolicy != nil && callPolicy("HTMLHeadOnlyPolicy", func() bool { return f.HTMLHeadOnlyPolicy.HTMLHeadOnly(ctx, url) }) {
+		reader = &headOnlyReader{r: reader}
+		limited = true
+	}
+	if f.HTMLBodyLimitPolicy != nil {
+		if limit := callPolicy("HTMLBodyLimitPolicy", func() int64 { return f.HTMLBodyLimitPolicy.HTMLBodyLimit(ctx, url) }); limit > 0 {
+			reader = io.LimitReader(reader, limit)
+			limited = true
+		}
+	}
+	if limited {
+		resp.Body = limitedBody{Reader: reader, Closer: resp.Body}
+		resp.ContentLength = -1
+	}
+}
+
+// headEndTag is what headOnlyReader looks for, in lower case
+var headEndTag = []byte("</head")
+
+// headOnlyReader ends its stream with the </head> tag (in any case)
+type headOnlyReader struct {
+	r       io.Reader
+	tail    []byte // the end of the previous read, in case the tag is split across reads
+	closing bool   // </head was found and its '>' is next
+	done    bool
+}
+
+func (h *headOnlyReader) Read(p []byte) (int, error) {
+	if h.done {
+		return 0, io.EOF
+	}
+	n, err := h.r.Read(p)
+	if h.closing {
+		if closing := bytes.IndexByte(p[:n], '>'); closing >= 0 {
+			h.done = true
+			return closing + 1, nil
+		}
+		return n, err
+	}
+	window := append(h.tail, bytes.ToLower(p[:n])...)
+	if index := bytes.Index(window, headEndTag); index >= 0 {
+		// the tag can't be entirely in the tail so it ends in p, keep everything up to its closing '>'
+		end := index + len(headEndTag) - len(h.tail)
+		if closing := bytes.IndexByte(p[end:n], '>'); closing >= 0 {
+			h.done = true
+			return end + closing + 1, nil
+		}
+		h.closing = true
+		return end, err
+	} else if len(window) >= len(headEndTag) {
+		h.tail = append([]byte(nil), window[len(window)-len(headEndTag)+1:]...)
+	} else {
+		h.tail = window
+	}
+	return n, err
+}
+
+// IncludeBodyMetaDataPolicy is passed into options if <meta> and <link> elements in an HTML page's <body>, such as
+// those some CMS plugins emit, should be collected along with those in its <head>
+type IncludeBodyMetaDataPolicy interface {
+	IncludeBodyMetaData(ctx context.Context, url *url.URL) bool
+}
+
+// IncludeBodyMetaData is an IncludeBodyMetaDataPolicy with the same answer for every page
+type IncludeBodyMetaData bool
+
+// IncludeBodyMetaData satisfies IncludeBodyMetaDataPolicy method
+func (i IncludeBodyMetaData) IncludeBodyMetaData(ctx context.Context, url *url.URL) bool {
+	return bool(i)
+}
+
+func (f *DefaultFactory) includeBodyMetaData(ctx context.Context, url *url.URL) bool {
+	if f.IncludeBodyMetaDataPolicy != nil {
+		defer policyPanicked("IncludeBodyMetaDataPolicy")
+		return f.IncludeBodyMetaDataPolicy.IncludeBodyMetaData(ctx, url)
+	}
+	return false
+}
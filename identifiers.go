@@ -0,0 +1,177 @@
+package resource
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// The identifier schemes detected by Page.Identifiers
+const (
+	IdentifierDOI   = "doi"
+	IdentifierISBN  = "isbn"
+	IdentifierArXiv = "arxiv"
+	IdentifierPMID  = "pmid"
+)
+
+// The non-meta tag places an Identifier can be found in
+const (
+	IdentifierSourceCanonical = "canonical"
+	IdentifierSourceURL       = "url"
+)
+
+// Identifier is a bibliographic identifier of the content, for linking it to Crossref, PubMed, library catalogues and
+// the like
+type Identifier struct {
+	Scheme string `json:"scheme"` // one of the Identifier* constants
+	Value  string `json:"value"`  // normalized: DOIs without a prefix, ISBNs without hyphens, arXiv IDs without "arXiv:"
+	Source string `json:"source"` // the meta tag it was found in, or IdentifierSourceCanonical or IdentifierSourceURL
+}
+
+var (
+	doiRegEx           = regexp.MustCompile(`10\.\d{4,9}/[^\s"<>]+`)
+	prefixedDOIRegEx   = regexp.MustCompile(`(?i)(?:doi:\s*|doi\.org/)(10\.\d{4,9}/[^\s"<>?#]+)`)
+	isbnRegEx          = regexp.MustCompile(`(?:97[89][- ]?)?\d{1,5}[- ]?\d+[- ]?\d+[- ]?[\dX]`)
+	prefixedISBNRegEx  = regexp.MustCompile(`(?i)(?:urn:isbn:|isbn(?:-1[03])?:?\s*)((?:97[89][- ]?)?\d{1,5}[- ]?\d+[- ]?\d+[- ]?[\dX])`)
+	arXivIDRegEx       = regexp.MustCompile(`(?i)(?:\d{4}\.\d{4,5}|[a-z-]+(?:\.[a-z]{2})?/\d{7})(?:v\d+)?`)
+	prefixedArXivRegEx = regexp.MustCompile(`(?i)(?:arxiv:\s*|arxiv\.org/(?:abs|pdf)/)((?:\d{4}\.\d{4,5}|[a-z-]+(?:\.[a-z]{2})?/\d{7})(?:v\d+)?)`)
+	pmidRegEx          = regexp.MustCompile(`^\d{1,9}$`)
+	prefixedPMIDRegEx  = regexp.MustCompile(`(?i)(?:pmid:\s*|pubmed\.ncbi\.nlm\.nih\.gov/|ncbi\.nlm\.nih\.gov/pubmed/)(\d{1,9})\b`)
+)
+
+// Identifiers returns the DOIs, ISBNs, arXiv IDs, and PMIDs found in the meta tags, canonical URL, and URL of the
+// page, each only once
+func (p Page) Identifiers() []Identifier {
+	var result []Identifier
+	seen := make(map[string]bool)
+	add := func(found []Identifier) {
+		for _, identifier := range found {
+			key := identifier.Scheme + ":" + strings.ToLower(identifier.Value)
+			if !seen[key] {
+				seen[key] = true
+				result = append(result, identifier)
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(p.MetaPropertyTags))
+	for key := range p.MetaPropertyTags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		values, _ := MetaTags(p.MetaPropertyTags).Values(key)
+		for _, value := range values {
+			if text, ok := value.(string); ok {
+				add(identifiersIn(key, text))
+			}
+		}
+	}
+	if canonical, ok := p.Canonical(); ok {
+		add(identifiersIn(IdentifierSourceCanonical, identifierURLText(canonical)))
+	}
+	if p.TargetURL != nil {
+		add(identifiersIn(IdentifierSourceURL, identifierURLText(p.TargetURL)))
+	}
+	return result
+}
+
+// identifiersIn finds the identifiers in a value. Tags named for a scheme, such as citation_doi or book:isbn, may hold
+// a bare identifier (as may DC.identifier, for DOIs); anywhere else it must be prefixed ("doi:", "ISBN", "arXiv:") or
+// be in a resolver URL.
+func identifiersIn(source string, value string) []Identifier {
+	var result []Identifier
+	name := strings.ToLower(source)
+	isURL := source == IdentifierSourceCanonical || source == IdentifierSourceURL
+	named := func(scheme string) bool {
+		return !isURL && strings.Contains(name, scheme)
+	}
+
+	if match := prefixedDOIRegEx.FindStringSubmatch(value); match != nil {
+		result = append(result, Identifier{IdentifierDOI, trimDOI(match[1]), source})
+	} else if named(IdentifierDOI) || named("identifier") {
+		if match := doiRegEx.FindString(value); len(match) > 0 {
+			result = append(result, Identifier{IdentifierDOI, trimDOI(match), source})
+		}
+	}
+
+	var isbn string
+	if match := prefixedISBNRegEx.FindStringSubmatch(value); match != nil {
+		isbn = match[1]
+	} else if named(IdentifierISBN) {
+		isbn = isbnRegEx.FindString(value)
+	}
+	if isbn = normalizeISBN(isbn); len(isbn) > 0 {
+		result = append(result, Identifier{IdentifierISBN, isbn, source})
+	}
+
+	if match := prefixedArXivRegEx.FindStringSubmatch(value); match != nil {
+		result = append(result, Identifier{IdentifierArXiv, match[1], source})
+	} else if named(IdentifierArXiv) {
+		if match := arXivIDRegEx.FindString(value); len(match) > 0 {
+			result = append(result, Identifier{IdentifierArXiv, match, source})
+		}
+	}
+
+	if match := prefixedPMIDRegEx.FindStringSubmatch(value); match != nil {
+		result = append(result, Identifier{IdentifierPMID, match[1], source})
+	} else if named(IdentifierPMID) && pmidRegEx.MatchString(strings.TrimSpace(value)) {
+		result = append(result, Identifier{IdentifierPMID, strings.TrimSpace(value), source})
+	}
+	return result
+}
+
+// identifierURLText returns u with its path unescaped, since DOIs in resolver URLs are often percent-encoded
+func identifierURLText(u *url.URL) string {
+	if unescaped, err := url.PathUnescape(u.String()); err == nil {
+		return unescaped
+	}
+	return u.String()
+}
+
+// trimDOI removes the punctuation which usually follows, rather than belongs to, a DOI written in text
+func trimDOI(doi string) string {
+	doi = strings.TrimRight(doi, ".,;:'\"")
+	for strings.HasSuffix(doi, ")") && strings.Count(doi, "(") < strings.Count(doi, ")") {
+		doi = strings.TrimSuffix(doi, ")")
+	}
+	return doi
+}
+
+// normalizeISBN removes the hyphens and spaces of an ISBN, "" is returned if its check digit is wrong
+func normalizeISBN(isbn string) string {
+	isbn = strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(isbn))
+	switch len(isbn) {
+	case 10:
+		sum := 0
+		for i, c := range isbn {
+			digit := int(c - '0')
+			if c == 'X' && i == 9 {
+				digit = 10
+			} else if c < '0' || c > '9' {
+				return ""
+			}
+			sum += (10 - i) * digit
+		}
+		if sum%11 == 0 {
+			return isbn
+		}
+	case 13:
+		sum := 0
+		for i, c := range isbn {
+			if c < '0' || c > '9' {
+				return ""
+			}
+			weight := 1
+			if i%2 == 1 {
+				weight = 3
+			}
+			sum += weight * int(c-'0')
+		}
+		if sum%10 == 0 {
+			return isbn
+		}
+	}
+	return ""
+}
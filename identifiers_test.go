@@ -0,0 +1,61 @@
+package resource
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type IdentifiersSuite struct {
+	suite.Suite
+}
+
+func (suite *IdentifiersSuite) TestIdentifiersInMetaTags() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://journal.example.com/article/5678", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, `<html><head>
+		<link rel="canonical" href="https://doi.org/10.1234%2Fexample.5678">
+		<meta name="citation_doi" content="10.1234/example.5678">
+		<meta name="citation_pmid" content="31452104">
+		<meta name="citation_arxiv_id" content="1706.03762v5">
+		<meta name="DC.identifier" content="urn:isbn:978-0-306-40615-7">
+		<meta name="description" content="See also doi:10.5555/other.1 (published 2019).">
+	</head></html>`))
+
+	content, err := NewFactory(archive).PageFromURL(context.Background(), "https://journal.example.com/article/5678")
+	suite.Nil(err, "Should not get an error")
+	suite.Equal([]Identifier{
+		{IdentifierISBN, "9780306406157", "DC.identifier"},
+		{IdentifierArXiv, "1706.03762v5", "citation_arxiv_id"},
+		{IdentifierDOI, "10.1234/example.5678", "citation_doi"},
+		{IdentifierPMID, "31452104", "citation_pmid"},
+		{IdentifierDOI, "10.5555/other.1", "description"},
+	}, content.(*Page).Identifiers(), "The canonical DOI should only be listed once")
+}
+
+func (suite *IdentifiersSuite) TestIdentifiersInURLs() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://arxiv.org/abs/1706.03762", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, testHTMLPage))
+	archive.Add("https://pubmed.ncbi.nlm.nih.gov/31452104/", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, testHTMLPage))
+
+	content, err := NewFactory(archive).PageFromURL(context.Background(), "https://arxiv.org/abs/1706.03762")
+	suite.Nil(err, "Should not get an error")
+	suite.Contains(content.(*Page).Identifiers(), Identifier{IdentifierArXiv, "1706.03762", IdentifierSourceURL})
+
+	content, err = NewFactory(archive).PageFromURL(context.Background(), "https://pubmed.ncbi.nlm.nih.gov/31452104/")
+	suite.Nil(err, "Should not get an error")
+	suite.Contains(content.(*Page).Identifiers(), Identifier{IdentifierPMID, "31452104", IdentifierSourceURL})
+}
+
+func (suite *IdentifiersSuite) TestISBNCheckDigit() {
+	suite.Equal("0306406152", normalizeISBN("0-306-40615-2"))
+	suite.Equal("080442957X", normalizeISBN("0-8044-2957-X"))
+	suite.Equal("", normalizeISBN("0-306-40615-3"), "A wrong check digit isn't an ISBN")
+	suite.Empty(identifiersIn("book:isbn", "978-0-306-40615-8"))
+	suite.Empty(identifiersIn("description", "Call 0306406152 now"), "Bare numbers outside ISBN tags aren't ISBNs")
+}
+
+func TestIdentifiersSuite(t *testing.T) {
+	suite.Run(t, new(IdentifiersSuite))
+}
@@ -0,0 +1,118 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// RequestIdentity traces a fetch back to what asked for it. It's carried by the context, set with
+// ContextWithRequestID, ContextWithSourceLinkID, ContextWithTenant, or ContextWithRequestIdentity, and copied into
+// AuditRecords, Events, HostFetchResults, and the errors PageFromURL returns.
+type RequestIdentity struct {
+	RequestID    string `json:"requestID,omitempty"`    // the caller's ID of the request, e.g. from its own X-Request-ID
+	SourceLinkID string `json:"sourceLinkID,omitempty"` // the caller's ID of the link being resolved, e.g. a database key
+	Tenant       string `json:"tenant,omitempty"`       // who the fetch is for, in multi-tenant harvesters
+}
+
+// IsZero returns true if nothing is identified
+func (i RequestIdentity) IsZero() bool {
+	return i == RequestIdentity{}
+}
+
+type requestIdentityContextKey struct{}
+
+// ContextWithRequestIdentity returns a context carrying identity, replacing any identity it already had
+func ContextWithRequestIdentity(ctx context.Context, identity RequestIdentity) context.Context {
+	return context.WithValue(ctx, requestIdentityContextKey{}, identity)
+}
+
+// ContextWithRequestID returns a context carrying the request ID, keeping the rest of its identity
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	identity := RequestIdentityFromContext(ctx)
+	identity.RequestID = requestID
+	return ContextWithRequestIdentity(ctx, identity)
+}
+
+// ContextWithSourceLinkID returns a context carrying the source link ID, keeping the rest of its identity
+func ContextWithSourceLinkID(ctx context.Context, sourceLinkID string) context.Context {
+	identity := RequestIdentityFromContext(ctx)
+	identity.SourceLinkID = sourceLinkID
+	return ContextWithRequestIdentity(ctx, identity)
+}
+
+// ContextWithTenant returns a context carrying the tenant, keeping the rest of its identity
+func ContextWithTenant(ctx context.Context, tenant string) context.Context {
+	identity := RequestIdentityFromContext(ctx)
+	identity.Tenant = tenant
+	return ContextWithRequestIdentity(ctx, identity)
+}
+
+// RequestIdentityFromContext returns the identity the context carries, which is zero if there isn't one
+func RequestIdentityFromContext(ctx context.Context) RequestIdentity {
+	identity, _ := ctx.Value(requestIdentityContextKey{}).(RequestIdentity)
+	return identity
+}
+
+// IdentifiedError is returned by PageFromURL when its context carries a RequestIdentity, Err is the cause
+type IdentifiedError struct {
+	Identity RequestIdentity
+	Err      error
+}
+
+// FormatError will print the identity followed by the cause
+func (e IdentifiedError) FormatError(p xerrors.Printer) error {
+	var fields []string
+	if len(e.Identity.Tenant) > 0 {
+		fields = append(fields, "tenant="+e.Identity.Tenant)
+	}
+	if len(e.Identity.RequestID) > 0 {
+		fields = append(fields, "request="+e.Identity.RequestID)
+	}
+	if len(e.Identity.SourceLinkID) > 0 {
+		fields = append(fields, "link="+e.Identity.SourceLinkID)
+	}
+	p.Printf("[%s]", strings.Join(fields, " "))
+	return e.Err
+}
+
+// Format provide backwards compatibility with pre-xerrors package
+func (e IdentifiedError) Format(f fmt.State, c rune) {
+	xerrors.FormatError(e, f, c)
+}
+
+// Error returns the identity followed by the cause
+func (e IdentifiedError) Error() string {
+	return fmt.Sprint(e)
+}
+
+// Unwrap returns the cause
+func (e IdentifiedError) Unwrap() error {
+	return e.Err
+}
+
+// RequestIdentityOf returns the identity of the request err came from, false if it doesn't have one
+func RequestIdentityOf(err error) (RequestIdentity, bool) {
+	var identified *IdentifiedError
+	if xerrors.As(err, &identified) {
+		return identified.Identity, true
+	}
+	return RequestIdentity{}, false
+}
+
+// identifyError wraps err in an IdentifiedError if the context carries an identity
+func identifyError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	identity := RequestIdentityFromContext(ctx)
+	if identity.IsZero() {
+		return err
+	}
+	if _, ok := RequestIdentityOf(err); ok {
+		return err
+	}
+	return &IdentifiedError{Identity: identity, Err: err}
+}
@@ -0,0 +1,75 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/xerrors"
+)
+
+type IdentitySuite struct {
+	suite.Suite
+	archive *MemoryResponseArchive
+}
+
+func (suite *IdentitySuite) SetupTest() {
+	suite.archive = NewMemoryResponseArchive()
+	suite.archive.Add("http://example.com/page", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, testHTMLPage))
+}
+
+func (suite *IdentitySuite) TestContextAccessors() {
+	ctx := context.Background()
+	suite.True(RequestIdentityFromContext(ctx).IsZero())
+
+	ctx = ContextWithTenant(ctx, "acme")
+	ctx = ContextWithRequestID(ctx, "req-1")
+	ctx = ContextWithSourceLinkID(ctx, "link-7")
+	suite.Equal(RequestIdentity{RequestID: "req-1", SourceLinkID: "link-7", Tenant: "acme"}, RequestIdentityFromContext(ctx), "Each accessor should keep the others' values")
+
+	ctx = ContextWithRequestIdentity(ctx, RequestIdentity{Tenant: "other"})
+	suite.Equal(RequestIdentity{Tenant: "other"}, RequestIdentityFromContext(ctx))
+}
+
+func (suite *IdentitySuite) TestAttachedToRecords() {
+	sink := new(memoryAuditSink)
+	events := make(ChannelEventSink, 10)
+	factory := NewFactory(suite.archive, sink, events)
+	ctx := ContextWithRequestID(ContextWithTenant(context.Background(), "acme"), "req-1")
+
+	_, err := factory.PageFromURL(ctx, "http://example.com/page")
+	suite.Nil(err, "Should not get an error")
+
+	identity := RequestIdentity{RequestID: "req-1", Tenant: "acme"}
+	suite.Len(sink.records, 1)
+	suite.Equal(identity, sink.records[0].RequestIdentity)
+	event := <-events
+	suite.Equal(identity, event.RequestIdentity)
+
+	data, _ := json.Marshal(sink.records[0])
+	suite.Contains(string(data), `"requestID":"req-1","tenant":"acme"`, "The identity should be flattened into the record")
+}
+
+func (suite *IdentitySuite) TestAttachedToErrors() {
+	factory := NewFactory(suite.archive)
+	_, err := factory.PageFromURL(context.Background(), "http://example.com/missing")
+	_, ok := RequestIdentityOf(err)
+	suite.False(ok, "Errors shouldn't be wrapped when there's no identity")
+
+	ctx := ContextWithSourceLinkID(ContextWithTenant(context.Background(), "acme"), "link-7")
+	_, err = factory.PageFromURL(ctx, "http://example.com/missing")
+	identity, ok := RequestIdentityOf(err)
+	suite.True(ok)
+	suite.Equal(RequestIdentity{SourceLinkID: "link-7", Tenant: "acme"}, identity)
+	suite.Contains(err.Error(), "[tenant=acme link=link-7]")
+
+	var notArchived *NotArchivedError
+	suite.True(xerrors.As(err, &notArchived), "The cause should still be reachable")
+	suite.Equal(ErrorCodeNotArchived, CodeOf(err))
+}
+
+func TestIdentitySuite(t *testing.T) {
+	suite.Run(t, new(IdentitySuite))
+}
@@ -0,0 +1,107 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// IndexDocument is what an Indexer is given of a resolved page
+type IndexDocument struct {
+	URL         string            `json:"url"`
+	Title       string            `json:"title,omitempty"`
+	Description string            `json:"description,omitempty"` // from the og:description, twitter:description, or description meta tag
+	Language    string            `json:"language,omitempty"`
+	Meta        map[string]string `json:"meta,omitempty"` // the page's meta tags, the first value of repeated ones
+	Text        string            `json:"text,omitempty"` // the text of the HTML <body>, without scripts and styles
+	Annotations Annotations       `json:"annotations,omitempty"`
+	Resolved    time.Time         `json:"resolved"`
+	Page        *Page             `json:"-"` // for indexers which want more than the above
+}
+
+// Indexer is passed into options to make harvested pages searchable as they're resolved, without a separate
+// indexing pass. An error indexing a page doesn't fail it, it's recorded as a WarningIndexError.
+type Indexer interface {
+	IndexPage(ctx context.Context, document IndexDocument) error
+}
+
+// IndexerFunc allows a plain function to be used as an Indexer
+type IndexerFunc func(ctx context.Context, document IndexDocument) error
+
+// IndexPage satisfies Indexer method
+func (fn IndexerFunc) IndexPage(ctx context.Context, document IndexDocument) error {
+	return fn(ctx, document)
+}
+
+// descriptionMetaTags are where a page's description is looked for, in order of preference
+var descriptionMetaTags = []string{"og:description", "twitter:description", "description"}
+
+// NewIndexDocument creates the document an Indexer is given for page, Text is only available for pages which were
+// resolved by a factory with an Indexer
+func NewIndexDocument(page *Page) IndexDocument {
+	result := IndexDocument{
+		URL:         page.TargetURLText(),
+		Title:       page.Title,
+		Language:    page.Language,
+		Text:        page.text,
+		Annotations: page.Annotations,
+		Page:        page}
+	tags := MetaTags(page.MetaPropertyTags)
+	if len(tags) > 0 {
+		result.Meta = make(map[string]string, len(tags))
+		for key := range tags {
+			value, _ := tags.Value(key)
+			result.Meta[key] = fmt.Sprint(value)
+		}
+	}
+	for _, key := range descriptionMetaTags {
+		if description, ok := result.Meta[key]; ok && len(description) > 0 {
+			result.Description = description
+			break
+		}
+	}
+	if len(result.Title) == 0 {
+		result.Title = result.Meta["og:title"]
+	}
+	return result
+}
+
+// indexContent gives a resolved page to the Indexer
+func (f *DefaultFactory) indexContent(ctx context.Context, content Content) {
+	page, ok := content.(*Page)
+	if f.Indexer == nil || !ok {
+		return
+	}
+	document := NewIndexDocument(page)
+	document.Resolved = f.clock().Now()
+	if err := callPolicy("Indexer", func() error { return f.Indexer.IndexPage(ctx, document) }); err != nil {
+		page.Warnings = append(page.Warnings, PageWarning{Code: WarningIndexError, Message: err.Error()})
+	}
+}
+
+// bodyText returns the words of the text in the HTML <body>, as counted by countWords, separated by single spaces
+func bodyText(doc *html.Node) string {
+	var words []string
+	var walk func(n *html.Node, inBody bool)
+	walk = func(n *html.Node, inBody bool) {
+		if n.Type == html.ElementNode {
+			switch strings.ToLower(n.Data) {
+			case "script", "style", "noscript", "template":
+				return
+			case "body":
+				inBody = true
+			}
+		}
+		if inBody && n.Type == html.TextNode {
+			words = append(words, strings.Fields(n.Data)...)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c, inBody)
+		}
+	}
+	walk(doc, false)
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,76 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+const testIndexedPage = `<html lang="en"><head><title>Harvesting the Web</title>
+<meta name="description" content="How harvesters work">
+<meta property="og:description" content="Crawling, parsing and indexing">
+<style>body { color: red }</style></head>
+<body><h1>Harvesting</h1> <p>Pages are   fetched,
+parsed and indexed.</p><script>var ignored = true;</script></body></html>`
+
+type IndexingSuite struct {
+	suite.Suite
+	archive *MemoryResponseArchive
+	clock   *ManualClock
+	indexed []IndexDocument
+}
+
+func (suite *IndexingSuite) SetupTest() {
+	suite.archive = NewMemoryResponseArchive()
+	suite.archive.Add("https://www.netspective.com/harvesting", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, testIndexedPage))
+	suite.clock = NewManualClock(time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC))
+	suite.indexed = nil
+}
+
+func (suite *IndexingSuite) IndexPage(ctx context.Context, document IndexDocument) error {
+	suite.indexed = append(suite.indexed, document)
+	return nil
+}
+
+func (suite *IndexingSuite) TestPagesAreIndexedWhenResolved() {
+	factory := NewFactory(suite.archive, suite.clock, suite)
+	content, err := factory.PageFromURL(context.Background(), "https://www.netspective.com/harvesting", Annotations{"collection": "guides"})
+	suite.Nil(err, "Should not get an error")
+
+	suite.Require().Len(suite.indexed, 1)
+	document := suite.indexed[0]
+	suite.Equal("https://www.netspective.com/harvesting", document.URL)
+	suite.Equal("Harvesting the Web", document.Title)
+	suite.Equal("Crawling, parsing and indexing", document.Description, "og:description should be preferred")
+	suite.Equal("How harvesters work", document.Meta["description"])
+	suite.Equal("en", document.Language)
+	suite.Equal("Harvesting Pages are fetched, parsed and indexed.", document.Text, "Scripts and styles shouldn't be indexed")
+	suite.Equal(Annotations{"collection": "guides"}, document.Annotations)
+	suite.Equal(suite.clock.Now(), document.Resolved)
+	suite.Equal(content, document.Page)
+}
+
+func (suite *IndexingSuite) TestIndexErrorsAreWarnings() {
+	failing := IndexerFunc(func(ctx context.Context, document IndexDocument) error { return errors.New("index is read-only") })
+	content, err := NewFactory(suite.archive, failing).PageFromURL(context.Background(), "https://www.netspective.com/harvesting")
+	suite.Nil(err, "Indexing errors shouldn't fail the page")
+	warnings := content.(*Page).Warnings
+	suite.Require().NotEmpty(warnings)
+	suite.Equal(PageWarning{Code: WarningIndexError, Message: "index is read-only"}, warnings[len(warnings)-1])
+}
+
+func (suite *IndexingSuite) TestTextIsOnlyKeptForIndexers() {
+	content, err := NewFactory(suite.archive).PageFromURL(context.Background(), "https://www.netspective.com/harvesting")
+	suite.Nil(err, "Should not get an error")
+	document := NewIndexDocument(content.(*Page))
+	suite.Empty(document.Text)
+	suite.Equal("Harvesting the Web", document.Title)
+}
+
+func TestIndexingSuite(t *testing.T) {
+	suite.Run(t, new(IndexingSuite))
+}
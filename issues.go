@@ -0,0 +1,182 @@
+package resource
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// The severities of an Issue
+const (
+	IssueSeverityInfo    = "info"
+	IssueSeverityWarning = "warning"
+	IssueSeverityError   = "error"
+)
+
+// warningSeverities are the severities of the warning codes which aren't IssueSeverityWarning
+var warningSeverities = map[string]string{
+	WarningMissingAlt:   IssueSeverityInfo,
+	WarningMissingLang:  IssueSeverityInfo,
+	WarningHeadingOrder: IssueSeverityInfo,
+}
+
+// Issue is a problem found while resolving a URL, either one of its page's Warnings or the error it failed with
+type Issue struct {
+	URL      string `json:"url,omitempty"`
+	Code     string `json:"code"` // a Warning* code, or for errors their ErrorMessageKey, e.g. "LECTIORES-200"
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+}
+
+// Issues is anything which reports issues, such as a Page or an IssueCollector
+type Issues interface {
+	Issues() []Issue
+}
+
+// IssueFromWarning converts a page warning into an issue
+func IssueFromWarning(url string, warning PageWarning) Issue {
+	severity, ok := warningSeverities[warning.Code]
+	if !ok {
+		severity = IssueSeverityWarning
+	}
+	return Issue{URL: url, Code: warning.Code, Severity: severity, Message: warning.Message, Line: warning.Line, Column: warning.Column}
+}
+
+// IssueFromError converts an error into an issue, coded as CodeOf says
+func IssueFromError(url string, err error) Issue {
+	return Issue{URL: url, Code: ErrorMessageKey(CodeOf(err)), Severity: IssueSeverityError, Message: err.Error()}
+}
+
+// Issues satisfies Issues method, the page's Warnings are its issues
+func (p Page) Issues() []Issue {
+	var result []Issue
+	for _, warning := range p.Warnings {
+		result = append(result, IssueFromWarning(p.TargetURLText(), warning))
+	}
+	return result
+}
+
+// IssueCollector gathers issues, e.g. from every page of a batch, it's safe for concurrent use
+type IssueCollector struct {
+	mutex  sync.RWMutex
+	issues []Issue
+}
+
+// NewIssueCollector creates an empty collector
+func NewIssueCollector() *IssueCollector {
+	return new(IssueCollector)
+}
+
+// Add records issues
+func (c *IssueCollector) Add(issues ...Issue) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.issues = append(c.issues, issues...)
+}
+
+// AddError records err as an issue of url, nil errors are ignored
+func (c *IssueCollector) AddError(url string, err error) {
+	if err != nil {
+		c.Add(IssueFromError(url, err))
+	}
+}
+
+// Merge records the issues of a Page, another collector, or any other Issues
+func (c *IssueCollector) Merge(issues Issues) {
+	if issues != nil && issues != Issues(c) {
+		c.Add(issues.Issues()...)
+	}
+}
+
+// Issues satisfies Issues method, returning a copy of the issues in the order they were recorded
+func (c *IssueCollector) Issues() []Issue {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return append([]Issue(nil), c.issues...)
+}
+
+// Len returns how many issues were recorded
+func (c *IssueCollector) Len() int {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return len(c.issues)
+}
+
+// HasErrors returns true if any issue is an IssueSeverityError
+func (c *IssueCollector) HasErrors() bool {
+	return len(c.BySeverity(IssueSeverityError)) > 0
+}
+
+// CountBySeverity returns how many issues there are of each severity
+func (c *IssueCollector) CountBySeverity() map[string]int {
+	return c.count(func(issue Issue) string { return issue.Severity })
+}
+
+// CountByCode returns how many issues there are of each code
+func (c *IssueCollector) CountByCode() map[string]int {
+	return c.count(func(issue Issue) string { return issue.Code })
+}
+
+// BySeverity returns the issues of any of the severities
+func (c *IssueCollector) BySeverity(severities ...string) []Issue {
+	return c.filter(func(issue Issue) string { return issue.Severity }, severities)
+}
+
+// ByCode returns the issues with any of the codes
+func (c *IssueCollector) ByCode(codes ...string) []Issue {
+	return c.filter(func(issue Issue) string { return issue.Code }, codes)
+}
+
+func (c *IssueCollector) count(key func(Issue) string) map[string]int {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	result := make(map[string]int)
+	for _, issue := range c.issues {
+		result[key(issue)]++
+	}
+	return result
+}
+
+func (c *IssueCollector) filter(key func(Issue) string, values []string) []Issue {
+	wanted := make(map[string]bool, len(values))
+	for _, value := range values {
+		wanted[value] = true
+	}
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	var result []Issue
+	for _, issue := range c.issues {
+		if wanted[key(issue)] {
+			result = append(result, issue)
+		}
+	}
+	return result
+}
+
+// issueCollectorJSON is how an IssueCollector is serialized, the counts are for readers and ignored when decoding
+type issueCollectorJSON struct {
+	Issues []Issue        `json:"issues"`
+	Counts map[string]int `json:"counts"`
+}
+
+// MarshalJSON writes the issues along with their counts by severity
+func (c *IssueCollector) MarshalJSON() ([]byte, error) {
+	issues := c.Issues()
+	if issues == nil {
+		issues = []Issue{}
+	}
+	return json.Marshal(issueCollectorJSON{Issues: issues, Counts: c.CountBySeverity()})
+}
+
+// UnmarshalJSON replaces the issues with those read
+func (c *IssueCollector) UnmarshalJSON(data []byte) error {
+	var decoded issueCollectorJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.issues = decoded.Issues
+	return nil
+}
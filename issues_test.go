@@ -0,0 +1,87 @@
+package resource
+
+import (
+	"encoding/json"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type IssuesSuite struct {
+	suite.Suite
+}
+
+func (suite *IssuesSuite) page() *Page {
+	u, _ := url.Parse("https://www.netspective.com/")
+	return &Page{TargetURL: u, Warnings: []PageWarning{
+		{Code: WarningMetaMissingContent, Message: "<meta> has no content", Line: 3, Column: 1},
+		{Code: WarningMissingAlt, Message: "<img> has no alt attribute", Line: 9, Column: 5},
+	}}
+}
+
+func (suite *IssuesSuite) TestPageIssues() {
+	issues := suite.page().Issues()
+	suite.Len(issues, 2)
+	suite.Equal(Issue{URL: "https://www.netspective.com/", Code: WarningMetaMissingContent, Severity: IssueSeverityWarning, Message: "<meta> has no content", Line: 3, Column: 1}, issues[0])
+	suite.Equal(IssueSeverityInfo, issues[1].Severity, "Accessibility warnings should be informational")
+}
+
+func (suite *IssuesSuite) TestCollector() {
+	collector := NewIssueCollector()
+	collector.Merge(suite.page())
+	collector.AddError("https://www.netspective.com/missing", &NotArchivedError{URL: "https://www.netspective.com/missing"})
+	collector.AddError("https://www.netspective.com/fine", nil)
+
+	suite.Equal(3, collector.Len())
+	suite.True(collector.HasErrors())
+	suite.Equal(map[string]int{IssueSeverityWarning: 1, IssueSeverityInfo: 1, IssueSeverityError: 1}, collector.CountBySeverity())
+	suite.Equal(1, collector.CountByCode()["LECTIORES-400"])
+
+	errs := collector.BySeverity(IssueSeverityError)
+	suite.Len(errs, 1)
+	suite.Equal("https://www.netspective.com/missing", errs[0].URL)
+	suite.Len(collector.BySeverity(IssueSeverityError, IssueSeverityWarning), 2)
+	suite.Len(collector.ByCode(WarningMissingAlt), 1)
+	suite.Nil(collector.ByCode("unknown"))
+
+	other := NewIssueCollector()
+	other.Merge(collector)
+	other.Merge(other)
+	suite.Equal(collector.Issues(), other.Issues(), "Merging should copy every issue, merging itself nothing")
+}
+
+func (suite *IssuesSuite) TestConcurrentUse() {
+	collector := NewIssueCollector()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			collector.Merge(suite.page())
+			collector.CountByCode()
+		}()
+	}
+	wg.Wait()
+	suite.Equal(20, collector.Len())
+}
+
+func (suite *IssuesSuite) TestJSON() {
+	collector := NewIssueCollector()
+	collector.Merge(suite.page())
+	data, err := json.Marshal(collector)
+	suite.Nil(err, "Should not get an error")
+	suite.Contains(string(data), `"counts":{"info":1,"warning":1}`)
+
+	decoded := NewIssueCollector()
+	suite.Nil(json.Unmarshal(data, decoded), "Should not get an error")
+	suite.Equal(collector.Issues(), decoded.Issues())
+
+	data, _ = json.Marshal(NewIssueCollector())
+	suite.Equal(`{"issues":[],"counts":{}}`, string(data))
+}
+
+func TestIssuesSuite(t *testing.T) {
+	suite.Run(t, new(IssuesSuite))
+}
@@ -0,0 +1,105 @@
+package resource
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// The kinds of page told apart by Page.Kind
+const (
+	PageKindUnknown  = "unknown"
+	PageKindHomepage = "homepage" // the front page of a site
+	PageKindSection  = "section"  // a front page of part of a site, such as a category, tag, or archive listing
+	PageKindArticle  = "article"  // an individual story, post, or paper
+)
+
+var (
+	urlDateRegEx      = regexp.MustCompile(`/(?:19|20)\d{2}/(?:0?[1-9]|1[0-2])(?:/|$)|(?:19|20)\d{2}-(?:0[1-9]|1[0-2])-(?:0[1-9]|[12]\d|3[01])`)
+	urlArticleIDRegEx = regexp.MustCompile(`(?:^|[-_.])\d{5,}(?:$|[-_.])`)
+	indexPageRegEx    = regexp.MustCompile(`(?i)^(?:index|default|home)\.(?:html?|php|aspx?|jsp)$`)
+)
+
+// articleTypes are the og:type values of individual articles, compared in lower case
+var articleTypes = map[string]bool{"article": true, "blog": false, "blogposting": true, "newsarticle": true, "website": false}
+
+// articleDateTags are the meta tags which give an individual article's publication date
+var articleDateTags = []string{"article:published_time", "og:article:published_time", "citation_publication_date", "citation_date", "DC.date.issued", "dcterms.issued", "date", "pubdate", "parsely-pub-date", "sailthru.date"}
+
+// Kind classifies the page as a homepage, a section front, or an article from its URL (canonical, if it has one),
+// og:type, and whether it has a publication date. It's a heuristic, PageKindUnknown is returned when the signals
+// disagree or the page isn't HTML.
+func (p Page) Kind() string {
+	if !p.HTMLParsed {
+		return PageKindUnknown
+	}
+	pageURL := p.TargetURL
+	if canonical, ok := p.Canonical(); ok && canonical != nil && len(canonical.Host) > 0 {
+		pageURL = canonical
+	}
+	if pageURL == nil {
+		return PageKindUnknown
+	}
+
+	tags := MetaTags(p.MetaPropertyTags)
+	score := 0
+	if ogType, ok := tags.GetString("og:type"); ok {
+		if isArticle, known := articleTypes[strings.ToLower(strings.TrimSpace(ogType))]; known {
+			if isArticle {
+				score += 2
+			} else {
+				score--
+			}
+		}
+	}
+	for _, tag := range articleDateTags {
+		if date, ok := tags.GetString(tag); ok && len(strings.TrimSpace(date)) > 0 {
+			score++
+			break
+		}
+	}
+
+	segments := kindPathSegments(pageURL)
+	if len(segments) == 0 {
+		// a site's root is its homepage unless it says otherwise, e.g. a single-article site
+		if score >= 3 {
+			return PageKindArticle
+		}
+		return PageKindHomepage
+	}
+	if urlDateRegEx.MatchString(pageURL.Path) {
+		score++
+	}
+	last := segments[len(segments)-1]
+	switch {
+	case strings.Count(last, "-") >= 2 || urlArticleIDRegEx.MatchString(last):
+		// a slug like /the-title-of-the-story or an ID like /story/1234567
+		score++
+	case len(segments) == 1 || (len(segments) == 2 && (segments[0] == "category" || segments[0] == "tag" || segments[0] == "topics" || segments[0] == "section")):
+		score--
+	}
+
+	switch {
+	case score >= 2:
+		return PageKindArticle
+	case score <= -1:
+		return PageKindSection
+	case score == 0 && len(segments) == 1:
+		return PageKindSection
+	}
+	return PageKindUnknown
+}
+
+// kindPathSegments returns the path of u without empty segments or a trailing index page
+func kindPathSegments(u *url.URL) []string {
+	var result []string
+	for _, segment := range strings.Split(u.Path, "/") {
+		if len(segment) > 0 {
+			result = append(result, strings.ToLower(segment))
+		}
+	}
+	if len(result) > 0 && indexPageRegEx.MatchString(result[len(result)-1]) {
+		result = result[:len(result)-1]
+	}
+	return result
+}
@@ -0,0 +1,57 @@
+package resource
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type KindSuite struct {
+	suite.Suite
+}
+
+func (suite *KindSuite) page(urlText string, tags map[string]interface{}) Page {
+	u, _ := url.Parse(urlText)
+	if tags == nil {
+		tags = map[string]interface{}{}
+	}
+	return Page{TargetURL: u, MetaPropertyTags: tags, HTMLParsed: true}
+}
+
+func (suite *KindSuite) TestKinds() {
+	tests := []struct {
+		url  string
+		tags map[string]interface{}
+		kind string
+	}{
+		{"https://news.example.com/", map[string]interface{}{"og:type": "website"}, PageKindHomepage},
+		{"https://news.example.com/index.html", nil, PageKindHomepage},
+		{"https://news.example.com/politics/", map[string]interface{}{"og:type": "website"}, PageKindSection},
+		{"https://news.example.com/category/sports", nil, PageKindSection},
+		{"https://news.example.com/2019/10/01/a-story-with-a-slug", map[string]interface{}{"og:type": "article", "article:published_time": "2019-10-01T12:00:00Z"}, PageKindArticle},
+		{"https://news.example.com/world/story/12345678", map[string]interface{}{"date": "2019-10-01"}, PageKindArticle},
+		{"https://blog.example.com/posts/why-we-built-this", map[string]interface{}{"og:type": "article"}, PageKindArticle},
+		{"https://shop.example.com/products/widget", nil, PageKindUnknown},
+		{"https://one-article.example.com/", map[string]interface{}{"og:type": "article", "article:published_time": "2019-10-01"}, PageKindArticle},
+	}
+	for _, test := range tests {
+		suite.Equal(test.kind, suite.page(test.url, test.tags).Kind(), test.url)
+	}
+	suite.Equal(PageKindUnknown, Page{}.Kind(), "Unparsed content has no kind")
+}
+
+func (suite *KindSuite) TestCanonicalURL() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://news.example.com/amp?id=1", archivedResponse(200, http.Header{"Content-Type": {"text/html"}},
+		`<html><head><link rel="canonical" href="/2019/10/01/a-story-with-a-slug"><meta property="og:type" content="article"></head></html>`))
+	content, err := NewFactory(archive).PageFromURL(context.Background(), "https://news.example.com/amp?id=1")
+	suite.Nil(err, "Should not get an error")
+	suite.Equal(PageKindArticle, content.(*Page).Kind())
+}
+
+func TestKindSuite(t *testing.T) {
+	suite.Run(t, new(KindSuite))
+}
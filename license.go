@@ -0,0 +1,216 @@
+package resource
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Where a License was found, meta tags are identified by their own name
+const (
+	LicenseSourceLink   = "link"   // rel="license" in the Link header or an HTML <link>
+	LicenseSourceAnchor = "anchor" // an <a> or <area> with rel="license", as Creative Commons' license chooser produces
+	LicenseSourceRDFa   = "rdfa"   // an RDFa property such as cc:license or dcterms:license
+	LicenseSourceSPDX   = "spdx"   // an SPDX-License-Identifier comment
+)
+
+// License is a statement of the terms the content may be reused under
+type License struct {
+	URL    *url.URL `json:"url,omitempty"`
+	SPDX   string   `json:"spdx,omitempty"` // the SPDX license identifier (or expression), when it's known, e.g. "CC-BY-4.0"
+	Text   string   `json:"text,omitempty"` // what was written, when it's not a URL, e.g. "All rights reserved"
+	Source string   `json:"source"`
+}
+
+// licenseRDFaProperties are the RDFa properties which name a license, lower case
+var licenseRDFaProperties = map[string]bool{"cc:license": true, "dc:license": true, "dct:license": true, "dcterms:license": true, "xhv:license": true, "license": true, "schema:license": true}
+
+// licenseMetaTags are the meta tags, besides Dublin Core's, which may hold a license, in order of preference
+var licenseMetaTags = []string{"og:license", "license", "twitter:license"}
+
+var (
+	spdxCommentRegEx         = regexp.MustCompile(`SPDX-License-Identifier:\s*([A-Za-z0-9.+-]+(?:\s+(?:AND|OR|WITH)\s+[A-Za-z0-9.+-]+)*)`)
+	creativeCommonsURLRegEx  = regexp.MustCompile(`^/licenses/(by(?:-nc)?(?:-sa|-nd)?)/(\d\.\d)(?:/|$)`)
+	creativeCommonsZeroRegEx = regexp.MustCompile(`^/publicdomain/zero/1\.0(?:/|$)`)
+	gnuURLRegEx              = regexp.MustCompile(`^/licenses/(a?gpl|lgpl|fdl)-(\d\.\d)(?:\.html|\.en\.html)?$`)
+)
+
+// knownSPDX are the identifiers recognized when a license is written as text, keyed in upper case
+var knownSPDX = map[string]string{}
+
+func init() {
+	for _, id := range []string{
+		"CC-BY-1.0", "CC-BY-2.0", "CC-BY-2.5", "CC-BY-3.0", "CC-BY-4.0",
+		"CC-BY-SA-1.0", "CC-BY-SA-2.0", "CC-BY-SA-2.5", "CC-BY-SA-3.0", "CC-BY-SA-4.0",
+		"CC-BY-ND-1.0", "CC-BY-ND-2.0", "CC-BY-ND-2.5", "CC-BY-ND-3.0", "CC-BY-ND-4.0",
+		"CC-BY-NC-1.0", "CC-BY-NC-2.0", "CC-BY-NC-2.5", "CC-BY-NC-3.0", "CC-BY-NC-4.0",
+		"CC-BY-NC-SA-1.0", "CC-BY-NC-SA-2.0", "CC-BY-NC-SA-2.5", "CC-BY-NC-SA-3.0", "CC-BY-NC-SA-4.0",
+		"CC-BY-NC-ND-1.0", "CC-BY-NC-ND-2.0", "CC-BY-NC-ND-2.5", "CC-BY-NC-ND-3.0", "CC-BY-NC-ND-4.0",
+		"CC0-1.0", "MIT", "Apache-2.0", "BSD-2-Clause", "BSD-3-Clause", "ISC", "MPL-2.0", "Unlicense",
+		"GPL-2.0-only", "GPL-2.0-or-later", "GPL-3.0-only", "GPL-3.0-or-later", "LGPL-2.1-only", "LGPL-3.0-only",
+		"AGPL-3.0-only", "GFDL-1.3-only", "ODbL-1.0", "ODC-By-1.0", "PDDL-1.0",
+	} {
+		knownSPDX[strings.ToUpper(id)] = id
+	}
+}
+
+// Licenses returns every license statement found, the Link header and <link rel="license"> first, then the meta tags,
+// then those in the body of the HTML
+func (p Page) Licenses() []License {
+	var result []License
+	seen := make(map[string]bool)
+	add := func(license License) {
+		key := license.SPDX + "|" + license.Text
+		if license.URL != nil {
+			key += "|" + license.URL.String()
+		}
+		if !seen[key] {
+			seen[key] = true
+			result = append(result, license)
+		}
+	}
+
+	for _, link := range p.LinksByRel("license") {
+		add(newLicense(link.URL, "", LicenseSourceLink))
+	}
+	for _, tag := range licenseMetaTags {
+		if text, ok := MetaTags(p.MetaPropertyTags).GetString(tag); ok && len(strings.TrimSpace(text)) > 0 {
+			add(licenseFromText(p.TargetURL, text, tag))
+		}
+	}
+	if p.DublinCore != nil && len(p.DublinCore.License) > 0 {
+		add(licenseFromText(p.TargetURL, p.DublinCore.License, "dcterms.license"))
+	}
+	if p.DublinCore != nil && len(p.DublinCore.Rights) > 0 {
+		add(licenseFromText(p.TargetURL, p.DublinCore.Rights, "dc.rights"))
+	}
+	for _, license := range p.LicenseHints {
+		add(license)
+	}
+	return result
+}
+
+// License returns the preferred license statement, see Licenses
+func (p Page) License() (License, bool) {
+	licenses := p.Licenses()
+	if len(licenses) == 0 {
+		return License{}, false
+	}
+	return licenses[0], true
+}
+
+// licenseHintFromHTMLNode returns the license named by an <a rel="license"> or an RDFa license property
+func licenseHintFromHTMLNode(base *url.URL, n *html.Node) (License, bool) {
+	if strings.EqualFold(n.Data, "link") {
+		// already in the page's Links
+		return License{}, false
+	}
+	if strings.EqualFold(n.Data, "a") || strings.EqualFold(n.Data, "area") {
+		if link, ok := linkFromHTMLNode(base, n); ok && link.HasRel("license") {
+			return newLicense(link.URL, "", LicenseSourceAnchor), true
+		}
+	}
+
+	var isLicense bool
+	var value string
+	for _, attr := range n.Attr {
+		switch strings.ToLower(attr.Key) {
+		case "property", "rel":
+			for _, property := range strings.Fields(strings.ToLower(attr.Val)) {
+				isLicense = isLicense || licenseRDFaProperties[property]
+			}
+		case "resource", "href", "src":
+			value = attr.Val
+		case "content":
+			if len(value) == 0 {
+				value = attr.Val
+			}
+		}
+	}
+	if !isLicense || len(strings.TrimSpace(value)) == 0 {
+		return License{}, false
+	}
+	return licenseFromText(base, value, LicenseSourceRDFa), true
+}
+
+// spdxLicenseHints returns a license for every SPDX-License-Identifier comment in body
+func spdxLicenseHints(body []byte) []License {
+	var result []License
+	for _, match := range spdxCommentRegEx.FindAllSubmatch(body, -1) {
+		result = append(result, License{SPDX: string(match[1]), Source: LicenseSourceSPDX})
+	}
+	return result
+}
+
+// licenseFromText interprets text as a license URL if it looks like one, otherwise as an SPDX identifier or a statement
+func licenseFromText(base *url.URL, text string, source string) License {
+	text = strings.TrimSpace(text)
+	if strings.Contains(text, "://") || strings.HasPrefix(text, "/") {
+		if u, err := url.Parse(text); err == nil {
+			if base != nil {
+				u = base.ResolveReference(u)
+			}
+			return newLicense(u, "", source)
+		}
+	}
+	return newLicense(nil, text, source)
+}
+
+func newLicense(u *url.URL, text string, source string) License {
+	result := License{URL: u, Source: source}
+	if u != nil {
+		result.SPDX = spdxFromURL(u)
+		return result
+	}
+	// "CC BY-SA 4.0" and "cc-by-sa-4.0" are both CC-BY-SA-4.0
+	if id, ok := knownSPDX[strings.ToUpper(strings.Join(strings.Fields(text), "-"))]; ok {
+		result.SPDX = id
+	} else {
+		result.Text = text
+	}
+	return result
+}
+
+// spdxFromURL returns the SPDX identifier of the well-known license URLs
+func spdxFromURL(u *url.URL) string {
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	path := strings.ToLower(u.Path)
+	switch host {
+	case "creativecommons.org":
+		if match := creativeCommonsURLRegEx.FindStringSubmatch(path); match != nil {
+			return knownSPDX[strings.ToUpper("CC-"+match[1]+"-"+match[2])]
+		}
+		if creativeCommonsZeroRegEx.MatchString(path) {
+			return "CC0-1.0"
+		}
+	case "opensource.org", "spdx.org":
+		segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+		id := strings.TrimSuffix(strings.TrimSuffix(segments[len(segments)-1], ".html"), "-license")
+		if spdx, ok := knownSPDX[strings.ToUpper(id)]; ok {
+			return spdx
+		}
+	case "apache.org":
+		if strings.HasPrefix(path, "/licenses/license-2.0") {
+			return "Apache-2.0"
+		}
+	case "gnu.org":
+		if match := gnuURLRegEx.FindStringSubmatch(path); match != nil {
+			if match[1] == "fdl" {
+				return knownSPDX[strings.ToUpper("GFDL-"+match[2]+"-only")]
+			}
+			return knownSPDX[strings.ToUpper(match[1]+"-"+match[2]+"-only")]
+		}
+	case "opendatacommons.org":
+		switch {
+		case strings.HasPrefix(path, "/licenses/odbl/1-0"), strings.HasPrefix(path, "/licenses/odbl/1.0"):
+			return "ODbL-1.0"
+		case strings.HasPrefix(path, "/licenses/by/1-0"), strings.HasPrefix(path, "/licenses/by/1.0"):
+			return "ODC-By-1.0"
+		case strings.HasPrefix(path, "/licenses/pddl/1-0"), strings.HasPrefix(path, "/licenses/pddl/1.0"):
+			return "PDDL-1.0"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,71 @@
+package resource
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type LicenseSuite struct {
+	suite.Suite
+}
+
+func (suite *LicenseSuite) TestLicenseSources() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://blog.example.com/post", archivedResponse(200, http.Header{"Content-Type": {"text/html"}, "Link": {`<https://creativecommons.org/licenses/by-sa/4.0/>; rel="license"`}}, `<html><head>
+		<meta property="og:license" content="CC BY 4.0">
+		<meta name="DC.rights" content="Some rights reserved">
+	</head><body>
+		<!-- SPDX-License-Identifier: MIT OR Apache-2.0 -->
+		<p>Licensed under <a rel="license" href="http://creativecommons.org/publicdomain/zero/1.0/">CC0</a>.</p>
+		<span xmlns:cc="http://creativecommons.org/ns#" property="cc:license" resource="https://opensource.org/licenses/BSD-3-Clause"></span>
+	</body></html>`))
+
+	content, err := NewFactory(archive).PageFromURL(context.Background(), "https://blog.example.com/post")
+	suite.Nil(err, "Should not get an error")
+	page := content.(*Page)
+	licenses := page.Licenses()
+	suite.Require().Len(licenses, 6)
+	suite.Equal("CC-BY-SA-4.0", licenses[0].SPDX)
+	suite.Equal(LicenseSourceLink, licenses[0].Source)
+	suite.Equal(License{SPDX: "CC-BY-4.0", Source: "og:license"}, licenses[1])
+	suite.Equal(License{Text: "Some rights reserved", Source: "dc.rights"}, licenses[2])
+	suite.Equal("CC0-1.0", licenses[3].SPDX)
+	suite.Equal(LicenseSourceAnchor, licenses[3].Source)
+	suite.Equal("BSD-3-Clause", licenses[4].SPDX)
+	suite.Equal(LicenseSourceRDFa, licenses[4].Source)
+	suite.Equal(License{SPDX: "MIT OR Apache-2.0", Source: LicenseSourceSPDX}, licenses[5])
+
+	license, ok := page.License()
+	suite.True(ok)
+	suite.Equal(licenses[0], license, "The Link header should be preferred")
+}
+
+func (suite *LicenseSuite) TestNoLicense() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("http://example.com/", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, testHTMLPage))
+	content, err := NewFactory(archive).PageFromURL(context.Background(), "http://example.com/")
+	suite.Nil(err, "Should not get an error")
+	_, ok := content.(*Page).License()
+	suite.False(ok)
+}
+
+func (suite *LicenseSuite) TestSPDXFromURL() {
+	for licenseURL, spdx := range map[string]string{
+		"https://creativecommons.org/licenses/by-nc-nd/3.0/us/": "CC-BY-NC-ND-3.0",
+		"https://www.apache.org/licenses/LICENSE-2.0":           "Apache-2.0",
+		"https://www.gnu.org/licenses/gpl-3.0.html":             "GPL-3.0-only",
+		"https://opensource.org/licenses/MIT":                   "MIT",
+		"https://example.com/terms":                             "",
+	} {
+		u, _ := url.Parse(licenseURL)
+		suite.Equal(spdx, spdxFromURL(u), licenseURL)
+	}
+}
+
+func TestLicenseSuite(t *testing.T) {
+	suite.Run(t, new(LicenseSuite))
+}
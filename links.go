@@ -0,0 +1,172 @@
+package resource
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Sources of a Link
+const (
+	LinkSourceHTTPHeader = "header"
+	LinkSourceHTML       = "html"
+)
+
+// Link is a typed relationship from content to another resource, from either the HTTP Link header (RFC 8288) or an
+// HTML <link> element
+type Link struct {
+	URL      *url.URL          `json:"url"` // resolved against the content's URL
+	Rel      string            `json:"rel"` // space-separated relation types, lower case, e.g. "canonical" or "alternate"
+	Type     string            `json:"type,omitempty"`
+	Title    string            `json:"title,omitempty"`
+	HrefLang string            `json:"hreflang,omitempty"`
+	Media    string            `json:"media,omitempty"`
+	Params   map[string]string `json:"params,omitempty"` // any other attributes or header parameters
+	Source   string            `json:"source"`
+}
+
+// HasRel returns true if rel is one of the link's relation types
+func (l Link) HasRel(rel string) bool {
+	for _, r := range strings.Fields(l.Rel) {
+		if strings.EqualFold(r, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// FeedMediaTypes are the link types recognized as feeds by IsFeed, including JSON Feed (https://jsonfeed.org)
+var FeedMediaTypes = []string{
+	"application/rss+xml",
+	"application/atom+xml",
+	"application/feed+json",
+	"application/json+feed",
+	"application/rdf+xml",
+}
+
+// IsFeed returns true if this is an rel="alternate" link to an RSS, Atom, or JSON Feed
+func (l Link) IsFeed() bool {
+	if !l.HasRel("alternate") {
+		return false
+	}
+	mediaType := strings.ToLower(strings.TrimSpace(strings.SplitN(l.Type, ";", 2)[0]))
+	for _, feedType := range FeedMediaTypes {
+		if mediaType == feedType {
+			return true
+		}
+	}
+	return false
+}
+
+// newLink creates a Link from href and its attributes, returning false if href can't be used
+func newLink(base *url.URL, href string, attrs map[string]string, source string) (Link, bool) {
+	href = strings.TrimSpace(href)
+	if len(href) == 0 {
+		return Link{}, false
+	}
+	target, err := url.Parse(href)
+	if err != nil {
+		return Link{}, false
+	}
+	if base != nil {
+		target = base.ResolveReference(target)
+	}
+
+	result := Link{URL: target, Source: source}
+	for key, value := range attrs {
+		switch strings.ToLower(key) {
+		case "rel":
+			result.Rel = strings.ToLower(strings.Join(strings.Fields(value), " "))
+		case "type":
+			result.Type = value
+		case "title":
+			result.Title = value
+		case "hreflang":
+			result.HrefLang = value
+		case "media":
+			result.Media = value
+		default:
+			if result.Params == nil {
+				result.Params = make(map[string]string)
+			}
+			result.Params[strings.ToLower(key)] = value
+		}
+	}
+	return result, true
+}
+
+// linkFromHTMLNode creates a Link from an HTML element with an href such as <link>
+func linkFromHTMLNode(base *url.URL, n *html.Node) (Link, bool) {
+	var href string
+	attrs := make(map[string]string, len(n.Attr))
+	for _, attr := range n.Attr {
+		if strings.EqualFold(attr.Key, "href") {
+			href = attr.Val
+		} else {
+			attrs[attr.Key] = attr.Val
+		}
+	}
+	return newLink(base, href, attrs, LinkSourceHTML)
+}
+
+// parseLinkHeader parses every RFC 8288 Link header, e.g. `<https://example.com/2>; rel="next", </1>; rel=prev`
+func parseLinkHeader(base *url.URL, header http.Header) []Link {
+	var result []Link
+	for _, value := range header[http.CanonicalHeaderKey("Link")] {
+		for _, linkValue := range splitHeaderList(value, ',') {
+			linkValue = strings.TrimSpace(linkValue)
+			if !strings.HasPrefix(linkValue, "<") {
+				continue
+			}
+			end := strings.Index(linkValue, ">")
+			if end < 0 {
+				continue
+			}
+
+			href := linkValue[1:end]
+			attrs := make(map[string]string)
+			for _, param := range splitHeaderList(linkValue[end+1:], ';') {
+				parts := strings.SplitN(strings.TrimSpace(param), "=", 2)
+				key := strings.TrimSpace(parts[0])
+				if len(key) == 0 {
+					continue
+				}
+				var paramValue string
+				if len(parts) == 2 {
+					paramValue = strings.Trim(strings.TrimSpace(parts[1]), `"`)
+				}
+				// RFC 8288 says only the first occurrence of a parameter is used
+				if _, exists := attrs[key]; !exists {
+					attrs[key] = paramValue
+				}
+			}
+			if link, ok := newLink(base, href, attrs, LinkSourceHTTPHeader); ok {
+				result = append(result, link)
+			}
+		}
+	}
+	return result
+}
+
+// splitHeaderList splits on sep except inside quoted strings or <URI-References>
+func splitHeaderList(value string, sep rune) []string {
+	var result []string
+	var inQuotes, inURI bool
+	start := 0
+	for i, r := range value {
+		switch {
+		case r == '"' && !inURI:
+			inQuotes = !inQuotes
+		case r == '<' && !inQuotes:
+			inURI = true
+		case r == '>' && !inQuotes:
+			inURI = false
+		case r == sep && !inQuotes && !inURI:
+			result = append(result, value[start:i])
+			start = i + 1
+		}
+	}
+	return append(result, value[start:])
+}
@@ -0,0 +1,85 @@
+package resource
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type LinksSuite struct {
+	suite.Suite
+}
+
+func (suite *LinksSuite) TestParseLinkHeader() {
+	base, _ := url.Parse("https://api.example.com/items?page=2")
+	header := make(http.Header)
+	header.Add("Link", `<https://api.example.com/items?page=3>; rel="next", </items?page=1>; rel=prev; title="a, b; c"`)
+	header.Add("Link", `<https://example.com/style.css>; rel=preload; as=style, <https://example.com/canonical>; rel="canonical" ; rel="ignored"`)
+
+	links := parseLinkHeader(base, header)
+	suite.Len(links, 4)
+	suite.Equal("https://api.example.com/items?page=3", links[0].URL.String())
+	suite.True(links[0].HasRel("next"))
+	suite.Equal("https://api.example.com/items?page=1", links[1].URL.String(), "Relative links should be resolved")
+	suite.Equal("a, b; c", links[1].Title, "Separators inside quotes should be kept")
+	suite.Equal("style", links[2].Params["as"])
+	suite.Equal("canonical", links[3].Rel, "Only the first rel parameter should be used")
+	suite.Equal(LinkSourceHTTPHeader, links[3].Source)
+}
+
+func (suite *LinksSuite) TestHeaderAndHTMLLinksAreMerged() {
+	ctx := context.Background()
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://www.netspective.com/", archivedResponse(200, http.Header{
+		"Content-Type": {"text/html"},
+		"Link":         {`<https://www.netspective.com/canonical>; rel="canonical"`},
+	}, `<html><head>
+		<link rel="alternate" type="application/rss+xml" title="Feed" href="/feed.xml">
+		<link rel="Canonical" href="https://www.netspective.com/html-canonical">
+	</head><body></body></html>`))
+
+	content, err := NewFactory(archive).PageFromURL(ctx, "https://www.netspective.com/")
+	suite.Nil(err, "Should not get an error")
+
+	canonical := content.LinksByRel("canonical")
+	suite.Len(canonical, 2)
+	suite.Equal(LinkSourceHTTPHeader, canonical[0].Source, "Header links should come first")
+	suite.Equal(LinkSourceHTML, canonical[1].Source)
+
+	page := content.(*Page)
+	url, ok := page.Canonical()
+	suite.True(ok)
+	suite.Equal("https://www.netspective.com/canonical", url.String())
+
+	alternate := content.LinksByRel("alternate")
+	suite.Len(alternate, 1)
+	suite.Equal("https://www.netspective.com/feed.xml", alternate[0].URL.String())
+	suite.Equal("application/rss+xml", alternate[0].Type)
+}
+
+func (suite *LinksSuite) TestFeedDiscovery() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://www.netspective.com/blog/", archivedResponse(200, http.Header{
+		"Content-Type": {"text/html"},
+		"Link":         {`</feed.json>; rel="alternate"; type="application/feed+json"`},
+	}, `<html><head>
+		<link rel="alternate" type="application/atom+xml" href="/atom.xml">
+		<link rel="alternate" type="text/html" hreflang="fr" href="/fr/blog/">
+		<link rel="stylesheet" type="application/rss+xml" href="/not-a-feed.xml">
+	</head><body></body></html>`))
+
+	content, err := NewFactory(archive).PageFromURL(context.Background(), "https://www.netspective.com/blog/")
+	suite.Nil(err, "Should not get an error")
+
+	feeds := content.(*Page).Feeds()
+	suite.Len(feeds, 2, "Only alternate links with feed types should be found")
+	suite.Equal("https://www.netspective.com/feed.json", feeds[0].URL.String(), "JSON Feed should be discovered")
+	suite.Equal("https://www.netspective.com/atom.xml", feeds[1].URL.String())
+}
+
+func TestLinksSuite(t *testing.T) {
+	suite.Run(t, new(LinksSuite))
+}
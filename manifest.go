@@ -0,0 +1,95 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/xerrors"
+)
+
+// FetchWebAppManifestPolicy is passed into options if we want the Web App Manifest linked by a page fetched
+type FetchWebAppManifestPolicy interface {
+	FetchWebAppManifest(context.Context, *url.URL) bool
+}
+
+// WebAppManifestIcon is an icon listed in a Web App Manifest, Src is resolved against the manifest's URL
+type WebAppManifestIcon struct {
+	Src     string `json:"src"`
+	Sizes   string `json:"sizes,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Purpose string `json:"purpose,omitempty"`
+}
+
+// WebAppManifest is the subset of a Web App Manifest (https://www.w3.org/TR/appmanifest/) useful for rich previews
+type WebAppManifest struct {
+	Name            string               `json:"name,omitempty"`
+	ShortName       string               `json:"short_name,omitempty"`
+	Description     string               `json:"description,omitempty"`
+	StartURL        string               `json:"start_url,omitempty"`
+	Display         string               `json:"display,omitempty"`
+	ThemeColor      string               `json:"theme_color,omitempty"`
+	BackgroundColor string               `json:"background_color,omitempty"`
+	Icons           []WebAppManifestIcon `json:"icons,omitempty"`
+}
+
+// ManifestURL returns the URL of the page's <link rel="manifest">
+func (p Page) ManifestURL() (*url.URL, bool) {
+	links := p.LinksByRel("manifest")
+	if len(links) == 0 {
+		return nil, false
+	}
+	return links[0].URL, true
+}
+
+// WebAppManifest fetches and decodes the Web App Manifest at manifestURL
+func (f *DefaultFactory) WebAppManifest(ctx context.Context, manifestURL string) (_ *WebAppManifest, err error) {
+	defer recoverPolicyPanic(&err)
+	resp, err := f.fetch(ctx, manifestURL, http.Header{"Accept": {"application/manifest+json, application/json"}})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := new(WebAppManifest)
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return nil, xerrors.Errorf("Unable to decode Web App Manifest %q: %w", manifestURL, err)
+	}
+
+	// relative URLs in a manifest are relative to the manifest itself, not the page
+	base := resp.Request.URL
+	for i, icon := range result.Icons {
+		if src, err := base.Parse(icon.Src); err == nil {
+			result.Icons[i].Src = src.String()
+		}
+	}
+	if len(result.StartURL) > 0 {
+		if startURL, err := base.Parse(result.StartURL); err == nil {
+			result.StartURL = startURL.String()
+		}
+	}
+	return result, nil
+}
+
+func (f *DefaultFactory) fetchWebAppManifest(ctx context.Context, url *url.URL) bool {
+	if f.FetchWebAppManifestPolicy != nil {
+		defer policyPanicked("FetchWebAppManifestPolicy")
+		return f.FetchWebAppManifestPolicy.FetchWebAppManifest(ctx, url)
+	}
+	return false
+}
+
+// discoverWebAppManifest fetches the linked manifest when the policy asks for it, failures are warnings
+func (f *DefaultFactory) discoverWebAppManifest(ctx context.Context, result *Page) {
+	manifestURL, ok := result.ManifestURL()
+	if !ok || !f.fetchWebAppManifest(ctx, result.TargetURL) {
+		return
+	}
+	manifest, err := f.WebAppManifest(ctx, manifestURL.String())
+	if err != nil {
+		result.Warnings = append(result.Warnings, PageWarning{Code: WarningRelatedFetchError, Message: err.Error()})
+		return
+	}
+	result.WebAppManifest = manifest
+}
@@ -0,0 +1,50 @@
+package resource
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type fetchWebAppManifest bool
+
+func (p fetchWebAppManifest) FetchWebAppManifest(context.Context, *url.URL) bool {
+	return bool(p)
+}
+
+type ManifestSuite struct {
+	suite.Suite
+}
+
+func (suite *ManifestSuite) TestManifestIsFetched() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://www.netspective.com/", archivedResponse(200, http.Header{"Content-Type": {"text/html"}},
+		`<html><head><link rel="manifest" href="/static/site.webmanifest"></head></html>`))
+	archive.Add("https://www.netspective.com/static/site.webmanifest", archivedResponse(200, http.Header{"Content-Type": {"application/manifest+json"}},
+		`{"name": "Netspective", "short_name": "NS", "start_url": "/?source=pwa", "theme_color": "#0b3d91",
+		"icons": [{"src": "icons/192.png", "sizes": "192x192", "type": "image/png"}]}`))
+
+	content, err := NewFactory(archive).PageFromURL(context.Background(), "https://www.netspective.com/")
+	suite.Nil(err, "Should not get an error")
+	manifestURL, ok := content.(*Page).ManifestURL()
+	suite.True(ok, "Manifest link should be found")
+	suite.Equal("https://www.netspective.com/static/site.webmanifest", manifestURL.String())
+	suite.Nil(content.(*Page).WebAppManifest, "Manifest should not be fetched without a policy")
+
+	content, err = NewFactory(archive, fetchWebAppManifest(true)).PageFromURL(context.Background(), "https://www.netspective.com/")
+	suite.Nil(err, "Should not get an error")
+	manifest := content.(*Page).WebAppManifest
+	suite.NotNil(manifest, "Manifest should be fetched")
+	suite.Equal("Netspective", manifest.Name)
+	suite.Equal("#0b3d91", manifest.ThemeColor)
+	suite.Equal("https://www.netspective.com/?source=pwa", manifest.StartURL)
+	suite.Len(manifest.Icons, 1)
+	suite.Equal("https://www.netspective.com/static/icons/192.png", manifest.Icons[0].Src, "Icons should be relative to the manifest")
+}
+
+func TestManifestSuite(t *testing.T) {
+	suite.Run(t, new(ManifestSuite))
+}
@@ -0,0 +1,157 @@
+package resource
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// isMarkdown returns true for text/markdown responses and .md files served with a generic type (e.g. raw GitHub files)
+func isMarkdown(url *url.URL, t Type) bool {
+	if t == nil {
+		return false
+	}
+	if DefaultTypeRegistry.Is(t, "text/markdown") {
+		return true
+	}
+	switch DefaultTypeRegistry.Canonical(t.MediaType()) {
+	case "text/plain", "application/octet-stream":
+		switch strings.ToLower(path.Ext(url.Path)) {
+		case ".md", ".markdown", ".mdown", ".mkd":
+			return true
+		}
+	}
+	return false
+}
+
+// parseMarkdown stores YAML (---) or TOML (+++) front matter as meta tags and, unless the front matter has a title,
+// records the first heading as the "title" meta tag
+func (p *Page) parseMarkdown(ctx context.Context, url *url.URL, resp *http.Response) {
+	defer resp.Body.Close()
+	body, readError := ioutil.ReadAll(resp.Body)
+	if readError != nil {
+		p.Warnings = append(p.Warnings, PageWarning{Code: WarningBodyReadError, Message: fmt.Sprintf("only %d bytes read: %v", len(body), readError)})
+	}
+	p.Fingerprint = fingerprint(body)
+
+	scanner := bufio.NewScanner(bytes.NewReader(bytes.TrimPrefix(body, []byte("\xef\xbb\xbf"))))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimRight(scanner.Text(), "\r"))
+	}
+
+	start := 0
+	if len(lines) > 0 && (lines[0] == "---" || lines[0] == "+++") {
+		delimiter := lines[0]
+		for i := 1; i < len(lines); i++ {
+			if lines[i] == delimiter || (delimiter == "---" && lines[i] == "...") {
+				p.parseFrontMatter(lines[1:i], delimiter == "+++")
+				start = i + 1
+				break
+			}
+		}
+	}
+
+	if _, ok := p.MetaPropertyTags["title"]; !ok {
+		if heading, ok := markdownHeading(lines[start:]); ok {
+			p.MetaPropertyTags["title"] = heading
+		}
+	}
+}
+
+// parseFrontMatter handles the flat key/value subset of YAML and TOML front matter that Markdown files use in
+// practice: scalars, inline lists ([a, b]) and, for YAML, block lists of "- item" lines. Nested tables are skipped.
+func (p *Page) parseFrontMatter(lines []string, isTOML bool) {
+	separator := ":"
+	if isTOML {
+		separator = "="
+	}
+
+	var listKey string
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if len(trimmed) == 0 || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if !isTOML && len(listKey) > 0 && strings.HasPrefix(trimmed, "- ") {
+			p.addMetaPropertyTag(listKey, frontMatterScalar(trimmed[2:]))
+			continue
+		}
+		listKey = ""
+		if line != strings.TrimLeft(line, " \t") || strings.HasPrefix(trimmed, "[") {
+			// nested value or TOML table
+			continue
+		}
+
+		index := strings.Index(line, separator)
+		if index <= 0 {
+			continue
+		}
+		key := strings.Trim(strings.TrimSpace(line[:index]), `"'`)
+		value := strings.TrimSpace(line[index+1:])
+		switch {
+		case len(value) == 0:
+			listKey = key
+		case strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]"):
+			for _, item := range strings.Split(value[1:len(value)-1], ",") {
+				if item = strings.TrimSpace(item); len(item) > 0 {
+					p.addMetaPropertyTag(key, frontMatterScalar(item))
+				}
+			}
+		default:
+			p.addMetaPropertyTag(key, frontMatterScalar(value))
+		}
+	}
+}
+
+// frontMatterScalar removes quotes and trailing comments from a front matter value
+func frontMatterScalar(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	if index := strings.Index(value, " #"); index >= 0 {
+		value = strings.TrimSpace(value[:index])
+	}
+	return value
+}
+
+// markdownHeading returns the text of the first ATX (# Title) or setext (Title followed by ===) heading, headings
+// inside fenced code blocks are ignored
+func markdownHeading(lines []string) (string, bool) {
+	inFence := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || len(trimmed) == 0 {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "#") {
+			text := strings.TrimLeft(trimmed, "#")
+			if level := len(trimmed) - len(text); level <= 6 && (len(text) == 0 || text[0] == ' ' || text[0] == '\t') {
+				text = strings.TrimSpace(strings.TrimRight(strings.TrimSpace(text), "#"))
+				if len(text) > 0 {
+					return text, true
+				}
+			}
+			continue
+		}
+		if i+1 < len(lines) {
+			underline := strings.TrimSpace(lines[i+1])
+			if len(underline) > 0 && (strings.Trim(underline, "=") == "" || strings.Trim(underline, "-") == "") {
+				return trimmed, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,60 @@
+package resource
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MarkdownSuite struct {
+	suite.Suite
+}
+
+func (suite *MarkdownSuite) page(urlText string, contentType string, body string) Content {
+	archive := NewMemoryResponseArchive()
+	archive.Add(urlText, archivedResponse(200, http.Header{"Content-Type": {contentType}}, body))
+	content, err := NewFactory(archive).PageFromURL(context.Background(), urlText)
+	suite.Nil(err, "Should not get an error")
+	return content
+}
+
+func (suite *MarkdownSuite) TestYAMLFrontMatter() {
+	content := suite.page("https://raw.githubusercontent.com/lectio/resource/master/README.md", "text/plain; charset=utf-8",
+		"---\ntitle: \"Lectio Resource\"\ndate: 2019-05-20\ntags:\n  - go\n  - harvester\nauthor:\n  name: Shahid\n---\n# Ignored Heading\n")
+
+	tags, err := content.MetaTags()
+	suite.Nil(err, "Should not get an error")
+	suite.Equal("Lectio Resource", tags["title"], "Front matter title takes precedence over the heading")
+	suite.Equal([]string{"go", "harvester"}, tags["tags"])
+	date, ok, _ := tags.GetTime("date")
+	suite.True(ok, "Date should be parsed")
+	suite.Equal(2019, date.Year())
+	_, ok = tags["name"]
+	suite.False(ok, "Nested values should be skipped")
+}
+
+func (suite *MarkdownSuite) TestTOMLFrontMatter() {
+	content := suite.page("https://www.netspective.com/post", "text/markdown",
+		"+++\ndraft = false\nkeywords = [\"privacy\", \"security\"]\n[params]\ntheme = \"dark\"\n+++\n\nSecurity First\n==============\n")
+
+	tags, _ := content.MetaTags()
+	suite.Equal("false", tags["draft"])
+	suite.Equal([]string{"privacy", "security"}, tags["keywords"])
+	suite.Equal("Security First", tags["title"], "Setext heading should be the title")
+}
+
+func (suite *MarkdownSuite) TestFirstHeading() {
+	content := suite.page("https://www.netspective.com/notes.md", "text/x-markdown",
+		"```\n# not a heading\n```\n\n## Release Notes ##\n\n# Later\n")
+
+	value, ok, err := content.MetaTag("title")
+	suite.Nil(err, "Should not get an error")
+	suite.True(ok, "Heading should be found")
+	suite.Equal("Release Notes", value)
+}
+
+func TestMarkdownSuite(t *testing.T) {
+	suite.Run(t, new(MarkdownSuite))
+}
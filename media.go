@@ -0,0 +1,126 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// MediaProfile describes a downloaded video or audio file, for display and filtering
+type MediaProfile struct {
+	Format     string        `json:"format"`               // the container, e.g. "mov,mp4,m4a,3gp,3g2,mj2"
+	Duration   time.Duration `json:"duration"`             // zero if unknown
+	BitRate    int64         `json:"bitRate,omitempty"`    // bits per second
+	VideoCodec string        `json:"videoCodec,omitempty"` // of the first video stream, e.g. "h264"
+	AudioCodec string        `json:"audioCodec,omitempty"` // of the first audio stream, e.g. "aac"
+	Width      int           `json:"width,omitempty"`      // of the first video stream, in pixels
+	Height     int           `json:"height,omitempty"`
+}
+
+// MediaProber is passed into options if we want video and audio attachments probed for their duration, codecs and
+// dimensions. It's only called for attachments whose sniffed or declared type is video or audio. Probing errors
+// don't invalidate the download.
+type MediaProber interface {
+	ProbeMedia(ctx context.Context, attachment *FileAttachment) (*MediaProfile, error)
+}
+
+// FFProbeMediaProber is a MediaProber which runs ffprobe. Attachments on the operating system's file system are
+// probed in place, others are piped to ffprobe, which can't probe formats that need seeking (such as MP4 files with
+// their index at the end) that way.
+type FFProbeMediaProber struct {
+	Path string // of the ffprobe executable, "ffprobe" (found on the PATH) if empty
+}
+
+// ProbeMedia satisfies MediaProber method
+func (p FFProbeMediaProber) ProbeMedia(ctx context.Context, attachment *FileAttachment) (*MediaProfile, error) {
+	executable := p.Path
+	if len(executable) == 0 {
+		executable = "ffprobe"
+	}
+	args := []string{"-v", "error", "-print_format", "json", "-show_format", "-show_streams"}
+
+	input, inPlace, err := osFilePath(attachment.DestFS, attachment.DestPath)
+	if err != nil {
+		return nil, err
+	}
+	var cmd *exec.Cmd
+	if inPlace {
+		cmd = exec.CommandContext(ctx, executable, append(args, input)...)
+	} else {
+		file, err := attachment.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		cmd = exec.CommandContext(ctx, executable, append(args, "pipe:0")...)
+		cmd.Stdin = file
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to probe %q with ffprobe (%s): %w", attachment.DestPath, strings.TrimSpace(stderr.String()), err)
+	}
+	return parseFFProbe(output)
+}
+
+// ffprobeOutput is the part of ffprobe's JSON output a MediaProfile is made from
+type ffprobeOutput struct {
+	Format struct {
+		FormatName string `json:"format_name"`
+		Duration   string `json:"duration"`
+		BitRate    string `json:"bit_rate"`
+	} `json:"format"`
+	Streams []struct {
+		CodecType string `json:"codec_type"`
+		CodecName string `json:"codec_name"`
+		Width     int    `json:"width"`
+		Height    int    `json:"height"`
+	} `json:"streams"`
+}
+
+func parseFFProbe(output []byte) (*MediaProfile, error) {
+	var probed ffprobeOutput
+	if err := json.Unmarshal(output, &probed); err != nil {
+		return nil, xerrors.Errorf("Unable to read ffprobe output: %w", err)
+	}
+	result := &MediaProfile{Format: probed.Format.FormatName}
+	if seconds, err := strconv.ParseFloat(probed.Format.Duration, 64); err == nil {
+		result.Duration = time.Duration(seconds * float64(time.Second))
+	}
+	result.BitRate, _ = strconv.ParseInt(probed.Format.BitRate, 10, 64)
+	for _, stream := range probed.Streams {
+		switch {
+		case stream.CodecType == "video" && len(result.VideoCodec) == 0:
+			result.VideoCodec = stream.CodecName
+			result.Width = stream.Width
+			result.Height = stream.Height
+		case stream.CodecType == "audio" && len(result.AudioCodec) == 0:
+			result.AudioCodec = stream.CodecName
+		}
+	}
+	return result, nil
+}
+
+// isMediaAttachment returns true if the attachment was sniffed or declared to be video or audio
+func isMediaAttachment(attachment *FileAttachment) bool {
+	if kind := attachment.FileType.MIME.Type; kind == "video" || kind == "audio" {
+		return true
+	}
+	if attachment.ContentType == nil {
+		return false
+	}
+	mediaType := attachment.ContentType.MediaType()
+	return strings.HasPrefix(mediaType, "video/") || strings.HasPrefix(mediaType, "audio/")
+}
+
+func mediaProber(creator FileAttachmentCreator, options []interface{}) MediaProber {
+	result, _ := OptionOf[MediaProber](append([]interface{}{creator}, options...))
+	return result
+}
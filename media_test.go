@@ -0,0 +1,86 @@
+package resource
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+const testFFProbeOutput = `{
+	"streams": [
+		{"index": 0, "codec_name": "h264", "codec_type": "video", "width": 1280, "height": 720},
+		{"index": 1, "codec_name": "aac", "codec_type": "audio", "sample_rate": "44100"}
+	],
+	"format": {"filename": "talk.mp4", "format_name": "mov,mp4,m4a,3gp,3g2,mj2", "duration": "62.500000", "bit_rate": "1205000"}
+}`
+
+type fixedMediaProber struct {
+	probed []string
+}
+
+func (p *fixedMediaProber) ProbeMedia(ctx context.Context, attachment *FileAttachment) (*MediaProfile, error) {
+	p.probed = append(p.probed, attachment.URL().String())
+	return parseFFProbe([]byte(testFFProbeOutput))
+}
+
+type MediaSuite struct {
+	suite.Suite
+}
+
+func (suite *MediaSuite) download(urlText string, contentType string, options ...interface{}) (bool, *FileAttachment, error) {
+	u, _ := url.Parse(urlText)
+	t, _ := NewPageType(contentType)
+	resp := &http.Response{Header: make(http.Header), Body: ioutil.NopCloser(strings.NewReader("not really media")), ContentLength: -1}
+	ok, attachment, err := DownloadFileFromHTTPResp(context.Background(), NewMemoryAttachmentCreator(nil), u, resp, t, options...)
+	return ok, attachment.(*FileAttachment), err
+}
+
+func (suite *MediaSuite) TestParseFFProbe() {
+	profile, err := parseFFProbe([]byte(testFFProbeOutput))
+	suite.Nil(err, "Should not get an error")
+	suite.Equal(&MediaProfile{
+		Format:     "mov,mp4,m4a,3gp,3g2,mj2",
+		Duration:   62500 * time.Millisecond,
+		BitRate:    1205000,
+		VideoCodec: "h264",
+		AudioCodec: "aac",
+		Width:      1280,
+		Height:     720,
+	}, profile)
+
+	_, err = parseFFProbe([]byte("Invalid data found when processing input"))
+	suite.NotNil(err, "Output which isn't JSON should be an error")
+}
+
+func (suite *MediaSuite) TestOnlyMediaIsProbed() {
+	prober := new(fixedMediaProber)
+	_, attachment, err := suite.download("https://www.netspective.com/talk.mp4", "video/mp4", prober)
+	suite.Nil(err, "Should not get an error")
+	suite.Require().NotNil(attachment.Media)
+	suite.Equal(720, attachment.Media.Height)
+
+	_, attachment, err = suite.download("https://www.netspective.com/notes.txt", "text/plain", prober)
+	suite.Nil(err, "Should not get an error")
+	suite.Nil(attachment.Media)
+	suite.Equal([]string{"https://www.netspective.com/talk.mp4"}, prober.probed)
+}
+
+func (suite *MediaSuite) TestProbeErrorsKeepDownload() {
+	ok, attachment, err := suite.download("https://www.netspective.com/episode.mp3", "audio/mpeg", FFProbeMediaProber{Path: "/nonexistent/ffprobe"})
+	suite.True(ok, "A file that can't be probed is still a good download")
+	suite.Nil(err, "Should not get an error")
+	suite.Nil(attachment.Media)
+
+	_, err = FFProbeMediaProber{Path: "/nonexistent/ffprobe"}.ProbeMedia(context.Background(), attachment)
+	suite.Contains(err.Error(), "Unable to probe")
+}
+
+func TestMediaSuite(t *testing.T) {
+	suite.Run(t, new(MediaSuite))
+}
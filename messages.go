@@ -0,0 +1,185 @@
+package resource
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/xerrors"
+)
+
+// DefaultMessageLanguage is the language messages fall back to when there's no translation
+const DefaultMessageLanguage = "en"
+
+// MessageCatalog holds the messages for error codes and warning codes, by language, so that they can be shown to
+// people in their own language. Errors are keyed as they're printed, e.g. "LECTIORES-200" (see ErrorMessageKey), and
+// warnings by their code, e.g. "missing-alt". Messages may refer to {url}, {status}, {detail}, {line}, and {column}.
+type MessageCatalog struct {
+	mutex    sync.RWMutex
+	messages map[string]map[string]string // lower case language tag to key to message
+}
+
+// DefaultMessageCatalog has the English messages, add translations to it with AddMessages
+var DefaultMessageCatalog = NewMessageCatalog()
+
+// NewMessageCatalog creates a catalog with the English messages
+func NewMessageCatalog() *MessageCatalog {
+	result := &MessageCatalog{messages: make(map[string]map[string]string)}
+	result.AddMessages(DefaultMessageLanguage, map[string]string{
+		ErrorMessageKey(ErrorCodeTargetURLBlank):        "No URL was given",
+		ErrorMessageKey(ErrorCodeTargetURLNil):          "No URL was given",
+		ErrorMessageKey(ErrorCodeUnknownOption):         "An option was given which isn't used ({detail})",
+		ErrorMessageKey(ErrorCodeInvalidConfiguration):  "The factory's options are invalid ({detail})",
+		ErrorMessageKey(ErrorCodeRequestBuild):          "{url} isn't a URL that can be requested",
+		ErrorMessageKey(ErrorCodeDNS):                   "The server of {url} couldn't be found",
+		ErrorMessageKey(ErrorCodeTLS):                   "A secure connection to {url} couldn't be made",
+		ErrorMessageKey(ErrorCodeTimeout):               "{url} took too long to respond",
+		ErrorMessageKey(ErrorCodeConnection):            "{url} couldn't be reached",
+		ErrorMessageKey(ErrorCodeHTTPStatus):            "{url} responded with status {status}",
+		ErrorMessageKey(ErrorCodeContentType):           "The type of {url} couldn't be determined",
+		ErrorMessageKey(ErrorCodeParse):                 "The content of {url} couldn't be read",
+		ErrorMessageKey(ErrorCodeTypeMismatch):          "{url} isn't the type of file it claims to be",
+		ErrorMessageKey(ErrorCodeChecksumMismatch):      "The file downloaded from {url} doesn't match its checksum",
+		ErrorMessageKey(ErrorCodeContentLengthMismatch): "The file downloaded from {url} is incomplete",
+		ErrorMessageKey(ErrorCodeDownload):              "The file at {url} couldn't be downloaded",
+		ErrorMessageKey(ErrorCodeInsufficientDiskSpace): "There isn't enough disk space to download {url}",
+		ErrorMessageKey(ErrorCodeNotArchived):           "{url} isn't available offline",
+		ErrorMessageKey(ErrorCodeBudgetExceeded):        "{url} took more time or data than allowed",
+		ErrorMessageKey(ErrorCodePolicyPanic):           "An option failed while {url} was being resolved ({detail})",
+		ErrorMessageKey(ErrorCodeTenantRefused):         "{url} isn't allowed, or the quota has been used up",
+		WarningBodyReadError:                            "The page couldn't be read completely",
+		WarningContentLengthMismatch:                    "The page is a different size than the server said",
+		WarningParseError:                               "The page's HTML couldn't be parsed",
+		WarningTokenizerError:                           "The page's HTML is malformed",
+		WarningUnclosedHead:                             "The page's <head> isn't closed",
+		WarningMetaAfterBodyStart:                       "A <meta> tag is in the page's body (line {line})",
+		WarningMetaMissingContent:                       "A <meta> tag has no content (line {line})",
+		WarningMetaMissingKey:                           "A <meta> tag has no name or property (line {line})",
+		WarningDuplicateAttribute:                       "An element has the same attribute twice (line {line})",
+		WarningRelatedFetchError:                        "A document the page links to couldn't be fetched",
+		WarningMissingAlt:                               "An image has no text alternative (line {line})",
+		WarningMissingLang:                              "The page doesn't say what language it's in",
+		WarningHeadingOrder:                             "A heading level is skipped (line {line})",
+		WarningIndexError:                               "The page couldn't be added to the search index",
+		WarningValidatorsError:                          "The page's cached validators couldn't be read, so it was fetched in full",
+		WarningCacheError:                               "The response cache couldn't be used, so the page may have been fetched again",
+	})
+	return result
+}
+
+// ErrorMessageKey returns the catalog key of an error code
+func ErrorMessageKey(code int) string {
+	return fmt.Sprintf("LECTIORES-%d", code)
+}
+
+// AddMessages adds (or replaces) the messages of a language, such as "de" or "pt-BR"
+func (c *MessageCatalog) AddMessages(language string, messages map[string]string) {
+	language = strings.ToLower(language)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.messages[language] == nil {
+		c.messages[language] = make(map[string]string)
+	}
+	for key, message := range messages {
+		c.messages[language][key] = message
+	}
+}
+
+// Message returns the message for key in language, falling back from a regional language ("pt-BR") to its base
+// language ("pt") and then to DefaultMessageLanguage
+func (c *MessageCatalog) Message(language string, key string) (string, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	for _, candidate := range messageLanguages(language) {
+		if message, ok := c.messages[candidate][key]; ok {
+			return message, true
+		}
+	}
+	return "", false
+}
+
+// ErrorMessage returns the message for err's code in language, or err.Error() if the catalog doesn't have one
+func (c *MessageCatalog) ErrorMessage(language string, err error) string {
+	if err == nil {
+		return ""
+	}
+	message, ok := c.Message(language, ErrorMessageKey(CodeOf(err)))
+	if !ok {
+		return err.Error()
+	}
+	return expandMessage(message, errorMessageArgs(err))
+}
+
+// WarningMessage returns the message for the warning's code in language, or its own Message if the catalog doesn't
+// have one. The warning's Message, which is in English, is available to translations as {detail}.
+func (c *MessageCatalog) WarningMessage(language string, warning PageWarning) string {
+	message, ok := c.Message(language, warning.Code)
+	if !ok {
+		return warning.Message
+	}
+	return expandMessage(message, map[string]string{
+		"detail": warning.Message,
+		"line":   strconv.Itoa(warning.Line),
+		"column": strconv.Itoa(warning.Column),
+	})
+}
+
+// messageLanguages returns the languages to look for a message in, most specific first
+func messageLanguages(language string) []string {
+	language = strings.ToLower(strings.Replace(strings.TrimSpace(language), "_", "-", -1))
+	var result []string
+	for len(language) > 0 {
+		result = append(result, language)
+		i := strings.LastIndex(language, "-")
+		if i < 0 {
+			break
+		}
+		language = language[:i]
+	}
+	return append(result, DefaultMessageLanguage)
+}
+
+// errorMessageArgs returns what messages may refer to from the first of this package's errors in err's chain
+func errorMessageArgs(err error) map[string]string {
+	result := map[string]string{"detail": err.Error()}
+	var coded *Error
+	var status *InvalidHTTPRespStatusCodeError
+	var checksum *ChecksumMismatchError
+	var notArchived *NotArchivedError
+	var length *ContentLengthMismatchError
+	var budget *BudgetExceededError
+	var tenant *TenantRefusedError
+	var diskSpace *InsufficientDiskSpaceError
+	switch {
+	case xerrors.As(err, &coded):
+		result["url"] = coded.URL
+		if coded.Err != nil {
+			result["detail"] = coded.Err.Error()
+		}
+	case xerrors.As(err, &status):
+		result["url"] = status.URL
+		result["status"] = strconv.Itoa(status.HTTPStatusCode)
+	case xerrors.As(err, &checksum):
+		result["url"] = checksum.URL
+	case xerrors.As(err, &notArchived):
+		result["url"] = notArchived.URL
+	case xerrors.As(err, &length):
+		result["url"] = length.URL
+	case xerrors.As(err, &budget):
+		result["url"] = budget.URL
+	case xerrors.As(err, &tenant):
+		result["url"] = tenant.URL
+	case xerrors.As(err, &diskSpace):
+		result["url"] = diskSpace.URL
+	}
+	return result
+}
+
+func expandMessage(message string, args map[string]string) string {
+	pairs := make([]string, 0, len(args)*2)
+	for key, value := range args {
+		pairs = append(pairs, "{"+key+"}", value)
+	}
+	return strings.NewReplacer(pairs...).Replace(message)
+}
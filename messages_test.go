@@ -0,0 +1,54 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/xerrors"
+)
+
+type MessagesSuite struct {
+	suite.Suite
+	catalog *MessageCatalog
+}
+
+func (suite *MessagesSuite) SetupTest() {
+	suite.catalog = NewMessageCatalog()
+	suite.catalog.AddMessages("de", map[string]string{
+		ErrorMessageKey(ErrorCodeHTTPStatus): "{url} antwortete mit Status {status}",
+		WarningMissingAlt:                    "Ein Bild hat keinen Alternativtext (Zeile {line})",
+	})
+	suite.catalog.AddMessages("pt-BR", map[string]string{
+		ErrorMessageKey(ErrorCodeTimeout): "{url} demorou demais para responder",
+	})
+}
+
+func (suite *MessagesSuite) TestErrorMessage() {
+	err := xerrors.Errorf("Unable to fetch: %w", &InvalidHTTPRespStatusCodeError{URL: "https://www.netspective.com/", HTTPStatusCode: 404})
+	suite.Equal("https://www.netspective.com/ antwortete mit Status 404", suite.catalog.ErrorMessage("de", err))
+	suite.Equal("https://www.netspective.com/ antwortete mit Status 404", suite.catalog.ErrorMessage("DE_at", err), "Regional languages should fall back to their base language")
+	suite.Equal("https://www.netspective.com/ responded with status 404", suite.catalog.ErrorMessage("fr", err), "Untranslated messages should be in English")
+
+	err = requestError("https://www.netspective.com/slow", "Unable to execute HTTP GET request", context.DeadlineExceeded, nil)
+	suite.Equal("https://www.netspective.com/slow demorou demais para responder", suite.catalog.ErrorMessage("pt-BR", err))
+	suite.Equal("https://www.netspective.com/slow took too long to respond", suite.catalog.ErrorMessage("pt", err), "pt shouldn't get pt-BR's messages")
+
+	suite.Equal("something else", suite.catalog.ErrorMessage("de", errors.New("something else")), "Uncoded errors should keep their text")
+	suite.Equal("", suite.catalog.ErrorMessage("de", nil))
+}
+
+func (suite *MessagesSuite) TestWarningMessage() {
+	warning := PageWarning{Code: WarningMissingAlt, Message: "<img> has no alt attribute", Line: 12, Column: 3}
+	suite.Equal("Ein Bild hat keinen Alternativtext (Zeile 12)", suite.catalog.WarningMessage("de", warning))
+	suite.Equal("An image has no text alternative (line 12)", suite.catalog.WarningMessage("", warning))
+
+	suite.catalog.AddMessages("en", map[string]string{"custom": "Custom: {detail}"})
+	suite.Equal("Custom: details", suite.catalog.WarningMessage("de", PageWarning{Code: "custom", Message: "details"}))
+	suite.Equal("unknown", suite.catalog.WarningMessage("de", PageWarning{Code: "not-in-catalog", Message: "unknown"}))
+}
+
+func TestMessagesSuite(t *testing.T) {
+	suite.Run(t, new(MessagesSuite))
+}
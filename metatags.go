@@ -0,0 +1,138 @@
+package resource
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// DefaultMetaTagTimeLayouts are tried, in order, by MetaTags.GetTime when no layouts are given
+var DefaultMetaTagTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+	"2006/01/02",
+}
+
+// Value returns the value of a tag, or its first value if the tag was repeated
+func (t MetaTags) Value(key string) (interface{}, bool) {
+	value, ok := t[key]
+	if values, isSlice := value.([]string); isSlice && len(values) > 0 {
+		return values[0], ok
+	}
+	return value, ok
+}
+
+// Values returns all the values of a tag in document order
+func (t MetaTags) Values(key string) ([]interface{}, bool) {
+	value, ok := t[key]
+	if !ok {
+		return nil, false
+	}
+	if values, isSlice := value.([]string); isSlice {
+		result := make([]interface{}, len(values))
+		for i, v := range values {
+			result[i] = v
+		}
+		return result, true
+	}
+	return []interface{}{value}, true
+}
+
+// Namespace returns the tags under a vocabulary prefix such as "og", "twitter", or "article", keyed without the
+// prefix (e.g. "og:image:width" is "image:width" in the "og" namespace). Prefixes are matched case-insensitively.
+func (t MetaTags) Namespace(namespace string) MetaTags {
+	prefix := namespace + ":"
+	result := make(MetaTags)
+	for key, value := range t {
+		if len(key) > len(prefix) && strings.EqualFold(key[:len(prefix)], prefix) {
+			result[key[len(prefix):]] = value
+		}
+	}
+	return result
+}
+
+// GetString returns the (first) value of a tag as a string
+func (t MetaTags) GetString(key string) (string, bool) {
+	value, ok := t.Value(key)
+	if !ok || value == nil {
+		return "", false
+	}
+	if text, isString := value.(string); isString {
+		return text, true
+	}
+	return fmt.Sprint(value), true
+}
+
+// GetInt returns the (first) value of a tag as an int, an error is returned if the value isn't a whole number
+func (t MetaTags) GetInt(key string) (int, bool, error) {
+	value, ok := t.Value(key)
+	if !ok {
+		return 0, false, nil
+	}
+	switch v := value.(type) {
+	case int:
+		return v, true, nil
+	case int64:
+		return int(v), true, nil
+	case float64:
+		if v == float64(int(v)) {
+			return int(v), true, nil
+		}
+	}
+
+	text, _ := t.GetString(key)
+	result, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil {
+		return 0, true, xerrors.Errorf("Meta tag %q value %q is not an integer: %w", key, text, err)
+	}
+	return result, true, nil
+}
+
+// GetTime returns the (first) value of a tag as a time, trying each layout in turn (DefaultMetaTagTimeLayouts if none
+// are given). An error is returned if none of the layouts match.
+func (t MetaTags) GetTime(key string, layouts ...string) (time.Time, bool, error) {
+	value, ok := t.Value(key)
+	if !ok {
+		return time.Time{}, false, nil
+	}
+	if result, isTime := value.(time.Time); isTime {
+		return result, true, nil
+	}
+
+	if len(layouts) == 0 {
+		layouts = DefaultMetaTagTimeLayouts
+	}
+	text, _ := t.GetString(key)
+	text = strings.TrimSpace(text)
+	for _, layout := range layouts {
+		if result, err := time.Parse(layout, text); err == nil {
+			return result, true, nil
+		}
+	}
+	return time.Time{}, true, fmt.Errorf("Meta tag %q value %q does not match any of the time layouts %q", key, text, layouts)
+}
+
+// GetURL returns the (first) value of a tag as a URL, relative URLs are resolved against base when it's not nil
+func (t MetaTags) GetURL(key string, base *url.URL) (*url.URL, bool, error) {
+	text, ok := t.GetString(key)
+	if !ok {
+		return nil, false, nil
+	}
+	result, err := url.Parse(strings.TrimSpace(text))
+	if err != nil {
+		return nil, true, xerrors.Errorf("Meta tag %q value %q is not a URL: %w", key, text, err)
+	}
+	if base != nil {
+		result = base.ResolveReference(result)
+	}
+	return result, true, nil
+}
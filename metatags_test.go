@@ -0,0 +1,111 @@
+package resource
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MetaTagsSuite struct {
+	suite.Suite
+	tags MetaTags
+}
+
+func (suite *MetaTagsSuite) SetupTest() {
+	suite.tags = MetaTags{
+		"og:title":               "Netspective",
+		"og:image":               []string{"/images/one.png", "/images/two.png"},
+		"og:image:width":         " 1200 ",
+		"og:image:height":        "tall",
+		"article:published_time": "2019-05-24T10:30:00Z",
+		"date":                   "24 May 2019",
+	}
+}
+
+func (suite *MetaTagsSuite) TestRepeatedMetaTags() {
+	page := &Page{MetaPropertyTags: make(map[string]interface{})}
+	page.addMetaPropertyTag("og:title", "Netspective")
+	page.addMetaPropertyTag("og:image", "https://www.netspective.com/one.png")
+	page.addMetaPropertyTag("og:image", "https://www.netspective.com/two.png")
+	page.addMetaPropertyTag("og:image", "https://www.netspective.com/three.png")
+
+	tags := MetaTags(page.MetaPropertyTags)
+	value, ok := tags.Value("og:image")
+	suite.True(ok)
+	suite.Equal("https://www.netspective.com/one.png", value, "First value should be returned")
+
+	values, ok := tags.Values("og:image")
+	suite.True(ok)
+	suite.Equal([]interface{}{"https://www.netspective.com/one.png", "https://www.netspective.com/two.png", "https://www.netspective.com/three.png"}, values)
+
+	values, ok = tags.Values("og:title")
+	suite.True(ok)
+	suite.Equal([]interface{}{"Netspective"}, values, "Single values should be a slice of one")
+
+	_, ok = tags.Values("og:description")
+	suite.False(ok)
+}
+
+func (suite *MetaTagsSuite) TestGetString() {
+	value, ok := suite.tags.GetString("og:title")
+	suite.True(ok)
+	suite.Equal("Netspective", value)
+
+	value, ok = suite.tags.GetString("og:image")
+	suite.True(ok)
+	suite.Equal("/images/one.png", value, "Repeated tags should return the first value")
+
+	_, ok = suite.tags.GetString("og:description")
+	suite.False(ok)
+}
+
+func (suite *MetaTagsSuite) TestGetInt() {
+	value, ok, err := suite.tags.GetInt("og:image:width")
+	suite.True(ok)
+	suite.Nil(err)
+	suite.Equal(1200, value)
+
+	_, ok, err = suite.tags.GetInt("og:image:height")
+	suite.True(ok)
+	suite.NotNil(err, "Non-numeric values should be an error")
+}
+
+func (suite *MetaTagsSuite) TestGetTime() {
+	value, ok, err := suite.tags.GetTime("article:published_time")
+	suite.True(ok)
+	suite.Nil(err)
+	suite.Equal(time.Date(2019, 5, 24, 10, 30, 0, 0, time.UTC), value)
+
+	_, _, err = suite.tags.GetTime("date")
+	suite.NotNil(err, "Unknown layouts should be an error")
+
+	value, _, err = suite.tags.GetTime("date", "2 January 2006")
+	suite.Nil(err)
+	suite.Equal(time.Date(2019, 5, 24, 0, 0, 0, 0, time.UTC), value)
+}
+
+func (suite *MetaTagsSuite) TestGetURL() {
+	base, _ := url.Parse("https://www.netspective.com/about/")
+	value, ok, err := suite.tags.GetURL("og:image", base)
+	suite.True(ok)
+	suite.Nil(err)
+	suite.Equal("https://www.netspective.com/images/one.png", value.String())
+}
+
+func (suite *MetaTagsSuite) TestNamespace() {
+	og := suite.tags.Namespace("og")
+	suite.Len(og, 4)
+	suite.Equal("Netspective", og["title"])
+	suite.Equal(" 1200 ", og["image:width"])
+
+	article := suite.tags.Namespace("ARTICLE")
+	suite.Equal(MetaTags{"published_time": "2019-05-24T10:30:00Z"}, article, "Namespaces should match case-insensitively")
+
+	suite.Empty(suite.tags.Namespace("twitter"))
+}
+
+func TestMetaTagsSuite(t *testing.T) {
+	suite.Run(t, new(MetaTagsSuite))
+}
@@ -0,0 +1,262 @@
+package resource
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/http"
+	"net/mail"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// maxMIMEPartDepth limits how deeply nested multipart bodies are walked
+const maxMIMEPartDepth = 8
+
+// mimePart is a leaf (non-multipart) part of a MIME body, with its transfer encoding already decoded
+type mimePart struct {
+	Header    textproto.MIMEHeader
+	MediaType string
+	Params    map[string]string
+	Data      []byte
+}
+
+// contentID returns the part's Content-ID without its angle brackets
+func (p mimePart) contentID() string {
+	return strings.Trim(strings.TrimSpace(p.Header.Get("Content-ID")), "<>")
+}
+
+// readMIMEParts flattens a (possibly nested) multipart body into its leaf parts, in document order
+func readMIMEParts(mediaType string, params map[string]string, body io.Reader, depth int) ([]mimePart, error) {
+	boundary := params["boundary"]
+	if len(boundary) == 0 {
+		return nil, fmt.Errorf("%s body has no boundary parameter", mediaType)
+	}
+
+	var result []mimePart
+	reader := multipart.NewReader(body, boundary)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return result, xerrors.Errorf("Unable to read %s part: %w", mediaType, err)
+		}
+
+		partContentType := part.Header.Get("Content-Type")
+		if len(partContentType) == 0 {
+			partContentType = "text/plain"
+		}
+		partMediaType, partParams, err := mime.ParseMediaType(partContentType)
+		if err != nil {
+			partMediaType, partParams = "application/octet-stream", map[string]string{}
+		}
+
+		// NextPart has already decoded (and removed the header of) quoted-printable parts
+		partBody := transferDecoder(part.Header.Get("Content-Transfer-Encoding"), part)
+
+		if strings.HasPrefix(partMediaType, "multipart/") && depth < maxMIMEPartDepth {
+			nested, err := readMIMEParts(partMediaType, partParams, partBody, depth+1)
+			result = append(result, nested...)
+			if err != nil {
+				return result, err
+			}
+			continue
+		}
+
+		data, err := ioutil.ReadAll(partBody)
+		if err != nil {
+			return result, xerrors.Errorf("Unable to read %s part: %w", partMediaType, err)
+		}
+		result = append(result, mimePart{Header: part.Header, MediaType: partMediaType, Params: partParams, Data: data})
+	}
+}
+
+// transferDecoder wraps r to undo a base64 or quoted-printable Content-Transfer-Encoding, other encodings are passed through
+func transferDecoder(encoding string, r io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, &whitespaceStripper{r})
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	}
+	return r
+}
+
+// whitespaceStripper removes the line breaks (and any other whitespace) base64 bodies are wrapped with
+type whitespaceStripper struct {
+	r io.Reader
+}
+
+func (w *whitespaceStripper) Read(p []byte) (int, error) {
+	for {
+		n, err := w.r.Read(p)
+		kept := 0
+		for _, b := range p[:n] {
+			if b != '\r' && b != '\n' && b != ' ' && b != '\t' {
+				p[kept] = b
+				kept++
+			}
+		}
+		if kept > 0 || err != nil {
+			return kept, err
+		}
+	}
+}
+
+// PartAttachment is a component of a multipart response (e.g. an image inside an MHTML archive), held in memory
+type PartAttachment struct {
+	ContentType     Type     `json:"type"`
+	ContentID       string   `json:"contentID,omitempty"`
+	ContentLocation *url.URL `json:"contentLocation,omitempty"`
+	FileName        string   `json:"fileName,omitempty"`
+	Data            []byte   `json:"-"`
+}
+
+// newPartAttachment creates a PartAttachment from a MIME part, relative Content-Locations are resolved against base
+func newPartAttachment(base *url.URL, part mimePart) *PartAttachment {
+	result := &PartAttachment{ContentID: part.contentID(), Data: part.Data}
+	result.ContentType, _ = NewPageType(mime.FormatMediaType(part.MediaType, part.Params))
+	if location := strings.TrimSpace(part.Header.Get("Content-Location")); len(location) > 0 {
+		if locationURL, err := url.Parse(location); err == nil {
+			if base != nil {
+				locationURL = base.ResolveReference(locationURL)
+			}
+			result.ContentLocation = locationURL
+		}
+	}
+	if _, params, err := mime.ParseMediaType(part.Header.Get("Content-Disposition")); err == nil {
+		result.FileName = params["filename"]
+	}
+	if len(result.FileName) == 0 {
+		result.FileName = part.Params["name"]
+	}
+	return result
+}
+
+// Type returns the part's declared Content-Type
+func (a PartAttachment) Type() Type {
+	return a.ContentType
+}
+
+// IsValid returns true if the part has a type
+func (a PartAttachment) IsValid() bool {
+	return a.ContentType != nil
+}
+
+// Open returns a reader for the part's content
+func (a PartAttachment) Open() (io.ReadCloser, error) {
+	return ioutil.NopCloser(bytes.NewReader(a.Data)), nil
+}
+
+// Stat returns the size and name of the part
+func (a PartAttachment) Stat() (os.FileInfo, error) {
+	name := a.FileName
+	if len(name) == 0 && a.ContentLocation != nil {
+		name = path.Base(a.ContentLocation.Path)
+	}
+	if len(name) == 0 {
+		name = a.ContentID
+	}
+	return partFileInfo{name: name, size: int64(len(a.Data))}, nil
+}
+
+// partFileInfo satisfies os.FileInfo for in-memory parts
+type partFileInfo struct {
+	name string
+	size int64
+}
+
+func (i partFileInfo) Name() string       { return i.name }
+func (i partFileInfo) Size() int64        { return i.size }
+func (i partFileInfo) Mode() os.FileMode  { return 0444 }
+func (i partFileInfo) ModTime() time.Time { return time.Time{} }
+func (i partFileInfo) IsDir() bool        { return false }
+func (i partFileInfo) Sys() interface{}   { return nil }
+
+// isMultipartRelated returns true for multipart/related responses and MHTML archives served with a generic type
+func isMultipartRelated(url *url.URL, t Type) bool {
+	if t == nil {
+		return false
+	}
+	if DefaultTypeRegistry.Is(t, "multipart/related") {
+		return true
+	}
+	switch DefaultTypeRegistry.Canonical(t.MediaType()) {
+	case "application/octet-stream", "message/rfc822", "text/plain":
+		ext := strings.ToLower(path.Ext(url.Path))
+		return ext == ".mhtml" || ext == ".mht"
+	}
+	return false
+}
+
+// parseMultipartRelated splits a multipart/related (or MHTML) response into its root HTML document, whose meta data
+// is parsed as usual, and its component parts which become ChildAttachments
+func (f *DefaultFactory) parseMultipartRelated(ctx context.Context, url *url.URL, resp *http.Response, result *Page) error {
+	defer resp.Body.Close()
+
+	mediaType, params := result.PageType.MediaType(), map[string]string(result.PageType.MediaTypeParams())
+	var body io.Reader = resp.Body
+	if mediaType != "multipart/related" {
+		// MHTML archives start with their own MIME headers, just like an email message
+		message, err := mail.ReadMessage(bufio.NewReader(resp.Body))
+		if err != nil {
+			return xerrors.Errorf("Unable to read MHTML headers: %w", err)
+		}
+		mediaType, params, err = mime.ParseMediaType(message.Header.Get("Content-Type"))
+		if err != nil {
+			return xerrors.Errorf("Unable to parse MHTML Content-Type: %w", err)
+		}
+		body = message.Body
+	}
+
+	parts, err := readMIMEParts(mediaType, params, body, 0)
+	if err != nil {
+		return err
+	}
+	if len(parts) == 0 {
+		return fmt.Errorf("%s body has no parts", mediaType)
+	}
+
+	// the root is identified by the start parameter, or is the first part
+	root := 0
+	if start := strings.Trim(params["start"], "<>"); len(start) > 0 {
+		for i, part := range parts {
+			if part.contentID() == start {
+				root = i
+				break
+			}
+		}
+	}
+
+	for i, part := range parts {
+		if i == root && DefaultTypeRegistry.Matches(part.MediaType, "text/html") {
+			if f.htmlContentPolicies(ctx, url, result) {
+				base := url
+				if location := newPartAttachment(url, part).ContentLocation; location != nil {
+					base = location
+				}
+				partResp := &http.Response{Header: http.Header(part.Header), Body: ioutil.NopCloser(bytes.NewReader(part.Data)), ContentLength: -1}
+				result.parsePageMetaData(ctx, base, partResp)
+				result.HTMLParsed = result.parseMetaData
+			}
+			continue
+		}
+		result.ChildAttachments = append(result.ChildAttachments, newPartAttachment(url, part))
+	}
+	return nil
+}
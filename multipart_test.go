@@ -0,0 +1,84 @@
+package resource
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+const testMHTMLArchive = "From: <Saved by Blink>\r\n" +
+	"Subject: Netspective\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/related; type=\"text/html\"; boundary=\"----MultipartBoundary--abc\"\r\n" +
+	"\r\n" +
+	"------MultipartBoundary--abc\r\n" +
+	"Content-Type: text/html\r\n" +
+	"Content-ID: <frame-1@mhtml.blink>\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n" +
+	"Content-Location: https://www.netspective.com/\r\n" +
+	"\r\n" +
+	"<html><head><meta property=3D\"og:site_name\" content=3D\"Netspective\" /></head><body></body></html>\r\n" +
+	"------MultipartBoundary--abc\r\n" +
+	"Content-Type: image/png\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"Content-Location: https://www.netspective.com/logo.png\r\n" +
+	"\r\n" +
+	"iVBORw0KGgo=\r\n" +
+	"------MultipartBoundary--abc--\r\n"
+
+type MultipartSuite struct {
+	suite.Suite
+}
+
+func (suite *MultipartSuite) TestMHTMLArchive() {
+	ctx := context.Background()
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://www.netspective.com/saved.mhtml", archivedResponse(200, http.Header{"Content-Type": {"application/octet-stream"}}, testMHTMLArchive))
+
+	content, err := NewFactory(archive).PageFromURL(ctx, "https://www.netspective.com/saved.mhtml")
+	suite.Nil(err, "Should not get an error")
+	suite.True(content.IsValid(), "MHTML archive should be valid")
+
+	value, ok, err := content.MetaTag("og:site_name")
+	suite.Nil(err, "Root HTML document should be parsed")
+	suite.True(ok, "og:site_name should be found in the root document")
+	suite.Equal("Netspective", value)
+
+	page := content.(*Page)
+	suite.Len(page.ChildAttachments, 1, "The image should be a child attachment")
+	part := page.ChildAttachments[0].(*PartAttachment)
+	suite.Equal("image/png", part.Type().MediaType())
+	suite.Equal("https://www.netspective.com/logo.png", part.ContentLocation.String())
+
+	reader, err := part.Open()
+	suite.Nil(err, "Should not get an error")
+	data, _ := ioutil.ReadAll(reader)
+	suite.Equal("\x89PNG\r\n\x1a\n", string(data), "base64 part should be decoded")
+	info, _ := part.Stat()
+	suite.Equal("logo.png", info.Name())
+}
+
+func (suite *MultipartSuite) TestMultipartRelatedStart() {
+	body := "--b\r\nContent-Type: text/css\r\nContent-ID: <style>\r\n\r\nh1 {}\r\n" +
+		"--b\r\nContent-Type: text/html\r\nContent-ID: <root>\r\n\r\n<html><head><meta name=\"description\" content=\"Root\"></head></html>\r\n--b--\r\n"
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://www.netspective.com/related", archivedResponse(200, http.Header{"Content-Type": {`multipart/related; boundary=b; start="<root>"`}}, body))
+
+	content, err := NewFactory(archive).PageFromURL(context.Background(), "https://www.netspective.com/related")
+	suite.Nil(err, "Should not get an error")
+	value, _, _ := content.MetaTag("description")
+	suite.Equal("Root", value, "start parameter should select the root part")
+
+	page := content.(*Page)
+	suite.Len(page.ChildAttachments, 1)
+	suite.Equal("style", page.ChildAttachments[0].(*PartAttachment).ContentID)
+	suite.True(strings.HasPrefix(page.ChildAttachments[0].Type().MediaType(), "text/css"))
+}
+
+func TestMultipartSuite(t *testing.T) {
+	suite.Run(t, new(MultipartSuite))
+}
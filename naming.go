@@ -0,0 +1,167 @@
+package resource
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+	"golang.org/x/xerrors"
+)
+
+// NamingStrategy decides where an attachment should be stored, relative to the root of a FileAttachmentCreator
+type NamingStrategy interface {
+	AttachmentName(context.Context, *url.URL, Type) (string, error)
+}
+
+// HashNamingStrategy names attachments using the SHA-1 of their URL, e.g. 2f/2fd4e1c67a2d28fced849ee1bb76e7391b93eb12.pdf
+type HashNamingStrategy struct {
+	FanOutDirs int // number of two-character directories to fan the files out into, 0 means a flat layout
+}
+
+// AttachmentName satisfies NamingStrategy method
+func (s HashNamingStrategy) AttachmentName(ctx context.Context, url *url.URL, t Type) (string, error) {
+	if url == nil {
+		return "", targetURLIsNilError(callerFrames(xErrorsFrameCaller))
+	}
+	sum := sha1.Sum([]byte(url.String()))
+	digest := hex.EncodeToString(sum[:])
+	return fanOutName(digest, s.FanOutDirs) + urlPathExtension(url), nil
+}
+
+// URLPathNamingStrategy names attachments after the host and path of their URL, e.g. www.example.com/papers/paper-05.pdf
+type URLPathNamingStrategy struct {
+	OmitHost      bool   // if true, the host is not used as the top level directory
+	IndexFileName string // used when the URL path ends in a slash, defaults to "index"
+}
+
+// AttachmentName satisfies NamingStrategy method
+func (s URLPathNamingStrategy) AttachmentName(ctx context.Context, url *url.URL, t Type) (string, error) {
+	if url == nil {
+		return "", targetURLIsNilError(callerFrames(xErrorsFrameCaller))
+	}
+
+	// path.Clean on a rooted path removes any ".." segments so names can't escape the creator's root
+	urlPath := path.Clean("/" + url.Path)
+	if strings.HasSuffix(url.Path, "/") || urlPath == "/" {
+		indexFileName := s.IndexFileName
+		if len(indexFileName) == 0 {
+			indexFileName = "index"
+		}
+		urlPath = path.Join(urlPath, indexFileName)
+	}
+	if s.OmitHost {
+		return strings.TrimPrefix(urlPath, "/"), nil
+	}
+	// the host isn't cleaned with the path, so it has to be a plain name too or it could escape the root (e.g. "..")
+	host := url.Hostname()
+	if !isPlainFileName(host) {
+		return "", xerrors.Errorf("Host %q of %q can't be used as a directory name in resource.URLPathNamingStrategy", host, url.String())
+	}
+	return path.Join(host, urlPath), nil
+}
+
+// DatePartitionedNamingStrategy places the names from another strategy into date-based directories, e.g. 2019/05/24/<name>
+type DatePartitionedNamingStrategy struct {
+	Base   NamingStrategy   // the strategy used within each partition, defaults to HashNamingStrategy
+	Layout string           // a time.Format layout for the partition directories, defaults to "2006/01/02"
+	Now    func() time.Time // the source of the partition date, defaults to time.Now
+}
+
+// AttachmentName satisfies NamingStrategy method
+func (s DatePartitionedNamingStrategy) AttachmentName(ctx context.Context, url *url.URL, t Type) (string, error) {
+	base := s.Base
+	if base == nil {
+		base = HashNamingStrategy{}
+	}
+	name, err := base.AttachmentName(ctx, url, t)
+	if err != nil {
+		return "", err
+	}
+
+	layout := s.Layout
+	if len(layout) == 0 {
+		layout = "2006/01/02"
+	}
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+	return path.Join(now().Format(layout), name), nil
+}
+
+// FileSystemAttachmentCreator is a FileAttachmentCreator which delegates all path logic to a NamingStrategy
+type FileSystemAttachmentCreator struct {
+	FS             afero.Fs
+	BasePath       string
+	NamingStrategy NamingStrategy
+	AutoAssignExt  bool
+}
+
+// NewFileSystemAttachmentCreator creates a FileAttachmentCreator which stores files in basePath using the given strategy
+func NewFileSystemAttachmentCreator(fs afero.Fs, basePath string, strategy NamingStrategy) *FileSystemAttachmentCreator {
+	return &FileSystemAttachmentCreator{
+		FS:             fs,
+		BasePath:       basePath,
+		NamingStrategy: strategy,
+		AutoAssignExt:  true,
+	}
+}
+
+// CreateFile satisfies FileAttachmentCreator method
+func (c *FileSystemAttachmentCreator) CreateFile(ctx context.Context, url *url.URL, t Type) (afero.Fs, afero.File, error) {
+	return c.CreateSniffedFile(ctx, url, t, SniffedType{})
+}
+
+// CreateSniffedFile satisfies SniffedFileAttachmentCreator method, replacing the named file's extension with the sniffed one
+func (c *FileSystemAttachmentCreator) CreateSniffedFile(ctx context.Context, url *url.URL, t Type, sniffed SniffedType) (afero.Fs, afero.File, error) {
+	strategy := c.NamingStrategy
+	if strategy == nil {
+		strategy = HashNamingStrategy{}
+	}
+	name, err := strategy.AttachmentName(ctx, url, t)
+	if err != nil {
+		return c.FS, nil, xerrors.Errorf("Unable to name attachment in resource.FileSystemAttachmentCreator: %w", err)
+	}
+	if len(sniffed.Extension) > 0 {
+		name = strings.TrimSuffix(name, path.Ext(name)) + "." + sniffed.Extension
+	}
+
+	destPath := filepath.Join(c.BasePath, filepath.FromSlash(name))
+	if err := c.FS.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return c.FS, nil, xerrors.Errorf("Unable to create directory %q in resource.FileSystemAttachmentCreator: %w", filepath.Dir(destPath), err)
+	}
+	destFile, err := c.FS.Create(destPath)
+	if err != nil {
+		return c.FS, nil, err
+	}
+	return c.FS, destFile, nil
+}
+
+// AutoAssignExtension satisfies FileAttachmentCreator method
+func (c *FileSystemAttachmentCreator) AutoAssignExtension(ctx context.Context, url *url.URL, t Type) bool {
+	return c.AutoAssignExt
+}
+
+// fanOutName splits the leading characters of name into two-character directories
+func fanOutName(name string, dirs int) string {
+	parts := make([]string, 0, dirs+1)
+	for i := 0; i < dirs && (i+1)*2 < len(name); i++ {
+		parts = append(parts, name[i*2:(i+1)*2])
+	}
+	return path.Join(append(parts, name)...)
+}
+
+// urlPathExtension returns the extension of the URL's path, if it has one
+func urlPathExtension(url *url.URL) string {
+	ext := path.Ext(url.Path)
+	if ext == "." {
+		return ""
+	}
+	return ext
+}
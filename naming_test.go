@@ -0,0 +1,89 @@
+package resource
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/suite"
+)
+
+type NamingSuite struct {
+	suite.Suite
+}
+
+func (suite *NamingSuite) parseURL(text string) *url.URL {
+	u, err := url.Parse(text)
+	suite.Nil(err, "URL should parse")
+	return u
+}
+
+func (suite *NamingSuite) TestHashNamingStrategy() {
+	ctx := context.Background()
+	u := suite.parseURL("http://ceur-ws.org/Vol-1401/paper-05.pdf")
+
+	name, err := HashNamingStrategy{}.AttachmentName(ctx, u, nil)
+	suite.Nil(err, "Should not get an error")
+	suite.Equal("25c09c1b2685d31204f0ff328b32d5cd76235f1c.pdf", name)
+
+	name, err = HashNamingStrategy{FanOutDirs: 2}.AttachmentName(ctx, u, nil)
+	suite.Nil(err, "Should not get an error")
+	suite.Equal("25/c0/25c09c1b2685d31204f0ff328b32d5cd76235f1c.pdf", name)
+
+	_, err = HashNamingStrategy{}.AttachmentName(ctx, nil, nil)
+	suite.NotNil(err, "Should get an error for a nil URL")
+}
+
+func (suite *NamingSuite) TestURLPathNamingStrategy() {
+	ctx := context.Background()
+
+	name, err := URLPathNamingStrategy{}.AttachmentName(ctx, suite.parseURL("http://ceur-ws.org/Vol-1401/paper-05.pdf"), nil)
+	suite.Nil(err, "Should not get an error")
+	suite.Equal("ceur-ws.org/Vol-1401/paper-05.pdf", name)
+
+	name, err = URLPathNamingStrategy{OmitHost: true}.AttachmentName(ctx, suite.parseURL("https://www.netspective.com/docs/"), nil)
+	suite.Nil(err, "Should not get an error")
+	suite.Equal("docs/index", name)
+
+	name, err = URLPathNamingStrategy{}.AttachmentName(ctx, suite.parseURL("https://www.netspective.com/../../etc/passwd"), nil)
+	suite.Nil(err, "Should not get an error")
+	suite.Equal("www.netspective.com/etc/passwd", name, "Names should not escape the root")
+
+	for _, urlText := range []string{"http://../etc/passwd", "http://./passwd", "mailto:someone@netspective.com", "file:///etc/passwd"} {
+		_, err = URLPathNamingStrategy{}.AttachmentName(ctx, suite.parseURL(urlText), nil)
+		suite.NotNil(err, "A host of %q can't be used as a directory", suite.parseURL(urlText).Hostname())
+	}
+}
+
+func (suite *NamingSuite) TestDatePartitionedNamingStrategy() {
+	ctx := context.Background()
+	strategy := DatePartitionedNamingStrategy{
+		Base: URLPathNamingStrategy{OmitHost: true},
+		Now:  func() time.Time { return time.Date(2019, 5, 24, 0, 0, 0, 0, time.UTC) },
+	}
+
+	name, err := strategy.AttachmentName(ctx, suite.parseURL("http://ceur-ws.org/Vol-1401/paper-05.pdf"), nil)
+	suite.Nil(err, "Should not get an error")
+	suite.Equal("2019/05/24/Vol-1401/paper-05.pdf", name)
+}
+
+func (suite *NamingSuite) TestFileSystemAttachmentCreator() {
+	ctx := context.Background()
+	fs := afero.NewMemMapFs()
+	creator := NewFileSystemAttachmentCreator(fs, "archive", URLPathNamingStrategy{})
+
+	destFS, file, err := creator.CreateFile(ctx, suite.parseURL("http://ceur-ws.org/Vol-1401/paper-05.pdf"), nil)
+	suite.Nil(err, "Should not get an error")
+	suite.Equal(fs, destFS)
+	suite.Equal("archive/ceur-ws.org/Vol-1401/paper-05.pdf", file.Name())
+	file.Close()
+
+	_, err = fs.Stat("archive/ceur-ws.org/Vol-1401/paper-05.pdf")
+	suite.Nil(err, "File should exist")
+}
+
+func TestNamingSuite(t *testing.T) {
+	suite.Run(t, new(NamingSuite))
+}
@@ -0,0 +1,114 @@
+package resource
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+
+	"golang.org/x/xerrors"
+)
+
+// maxArchivedRedirects is how many archived HTTP redirects will be followed before giving up
+const maxArchivedRedirects = 10
+
+// ResponseArchive is passed into options if PageFromURL should run offline, answering exclusively from previously
+// captured responses (e.g. a persistent cache or an imported WARC) and never touching the network
+type ResponseArchive interface {
+	ArchivedResponse(context.Context, *url.URL) (*http.Response, bool, error)
+}
+
+// MemoryResponseArchive is a ResponseArchive of responses held in memory, useful for reproducible and air-gapped tests
+type MemoryResponseArchive struct {
+	mu        sync.RWMutex
+	responses map[string][]byte
+}
+
+// NewMemoryResponseArchive creates an empty MemoryResponseArchive
+func NewMemoryResponseArchive() *MemoryResponseArchive {
+	return &MemoryResponseArchive{responses: make(map[string][]byte)}
+}
+
+// Add archives the response (including its body) under the given URL, the response body is consumed
+func (a *MemoryResponseArchive) Add(urlText string, resp *http.Response) error {
+	dump, err := httputil.DumpResponse(resp, true)
+	if err != nil {
+		return xerrors.Errorf("Unable to archive response for %q: %w", urlText, err)
+	}
+	a.AddRaw(urlText, dump)
+	return nil
+}
+
+// AddRaw archives a response, in HTTP/1.x wire format, under the given URL
+func (a *MemoryResponseArchive) AddRaw(urlText string, raw []byte) {
+	a.mu.Lock()
+	a.responses[urlText] = raw
+	a.mu.Unlock()
+}
+
+// ArchivedResponse satisfies ResponseArchive method
+func (a *MemoryResponseArchive) ArchivedResponse(ctx context.Context, url *url.URL) (*http.Response, bool, error) {
+	a.mu.RLock()
+	dump, ok := a.responses[url.String()]
+	a.mu.RUnlock()
+	if !ok {
+		return nil, false, nil
+	}
+
+	req := &http.Request{Method: http.MethodGet, URL: url}
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(dump)), req)
+	if err != nil {
+		return nil, false, xerrors.Errorf("Unable to read archived response for %q: %w", url.String(), err)
+	}
+	return resp, true, nil
+}
+
+// archivedResponse is the offline equivalent of an HTTP GET, following archived redirects but never the network
+func (f *DefaultFactory) archivedResponse(ctx context.Context, origURLtext string) (*http.Response, error) {
+	targetURL, err := url.Parse(origURLtext)
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to parse URL %q: %w", origURLtext, err)
+	}
+
+	for redirects := 0; ; redirects++ {
+		var resp *http.Response
+		var ok bool
+		guardPolicy("ResponseArchive", func() { resp, ok, err = f.ResponseArchive.ArchivedResponse(ctx, targetURL) })
+		if err != nil {
+			return nil, xerrors.Errorf("Unable to read from archive: %w", err)
+		}
+		if !ok {
+			return nil, &NotArchivedError{
+				URL:    targetURL.String(),
+				Frame:  xerrors.Caller(xErrorsFrameCaller),
+				Frames: callerFrames(xErrorsFrameCaller)}
+		}
+
+		location := resp.Header.Get("Location")
+		if resp.StatusCode >= 300 && resp.StatusCode < 400 && len(location) > 0 && redirects < maxArchivedRedirects {
+			resp.Body.Close()
+			next, err := targetURL.Parse(location)
+			if err != nil {
+				return nil, xerrors.Errorf("Unable to parse archived redirect %q: %w", location, err)
+			}
+			targetURL = next
+			continue
+		}
+
+		if resp.StatusCode != 200 {
+			resp.Body.Close()
+			return nil, &InvalidHTTPRespStatusCodeError{
+				URL:            targetURL.String(),
+				HTTPStatusCode: resp.StatusCode,
+				Header:         resp.Header,
+				Frame:          xerrors.Caller(xErrorsFrameCaller),
+				Frames:         callerFrames(xErrorsFrameCaller)}
+		}
+		// the response's request URL is where the redirects ended, just like a live response
+		resp.Request = &http.Request{Method: http.MethodGet, URL: targetURL}
+		return resp, nil
+	}
+}
@@ -0,0 +1,196 @@
+package resource
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/xerrors"
+)
+
+const testHTMLPage = `<!DOCTYPE html>
+<html>
+<head>
+	<meta property="og:site_name" content="Netspective" />
+	<meta property="og:title" content="Safety, privacy, and security focused technology consulting" />
+</head>
+<body><h1>Netspective</h1></body>
+</html>`
+
+type OfflineSuite struct {
+	suite.Suite
+	archive *MemoryResponseArchive
+	factory Factory
+}
+
+func archivedResponse(status int, header http.Header, body string) *http.Response {
+	return &http.Response{
+		StatusCode:    status,
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+	}
+}
+
+func (suite *OfflineSuite) SetupSuite() {
+	suite.archive = NewMemoryResponseArchive()
+	suite.archive.Add("https://www.netspective.com/", archivedResponse(200, http.Header{"Content-Type": {"text/html; charset=utf-8"}}, testHTMLPage))
+	suite.archive.Add("http://bit.ly/lectio_harvester_resource_test01", archivedResponse(301, http.Header{"Location": {"https://www.netspective.com/"}}, ""))
+	suite.factory = NewFactory(suite.archive)
+}
+
+func (suite *OfflineSuite) TestArchivedRedirectIsFollowed() {
+	ctx := context.Background()
+	page, err := suite.factory.PageFromURL(ctx, "http://bit.ly/lectio_harvester_resource_test01")
+	suite.Nil(err, "Should not get an error")
+	suite.Equal("https://www.netspective.com/", page.URL().String())
+
+	value, _, _ := page.MetaTag("og:site_name")
+	suite.Equal("Netspective", value)
+}
+
+func (suite *OfflineSuite) TestNotArchived() {
+	ctx := context.Background()
+	_, err := suite.factory.PageFromURL(ctx, "https://t.co/ELrZmo81wI")
+	suite.NotNil(err, "Should get an error")
+
+	var notArchived *NotArchivedError
+	suite.True(xerrors.As(err, &notArchived), "Error should be a NotArchivedError")
+}
+
+func (suite *OfflineSuite) TestRefreshHeaderRedirect() {
+	ctx := context.Background()
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://www.netspective.com/moved.pdf", archivedResponse(200, http.Header{
+		"Content-Type": {"application/pdf"},
+		"Refresh":      {"0;url=https://www.netspective.com/new.pdf"},
+	}, testPDFContent))
+
+	page, err := NewFactory(archive).PageFromURL(ctx, "https://www.netspective.com/moved.pdf")
+	suite.Nil(err, "Should not get an error")
+	isRedirect, urlText := page.Redirect()
+	suite.True(isRedirect, "Refresh header should be detected on non-HTML content")
+	suite.Equal("https://www.netspective.com/new.pdf", urlText)
+}
+
+func (suite *OfflineSuite) TestRedirectTarget() {
+	ctx := context.Background()
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://www.netspective.com/moved.pdf", archivedResponse(200, http.Header{
+		"Content-Type": {"application/pdf"},
+		"Refresh":      {"5;url=new.pdf"},
+	}, testPDFContent))
+	archive.Add("https://www.netspective.com/blog/old", archivedResponse(200, http.Header{"Content-Type": {"text/html"}},
+		`<html><head><meta http-equiv="refresh" content="0;url=/blog/new?from=old"></head></html>`))
+
+	page, err := NewFactory(archive).PageFromURL(ctx, "https://www.netspective.com/moved.pdf")
+	suite.Nil(err, "Should not get an error")
+	target, delay, ok := page.RedirectTarget()
+	suite.True(ok, "Refresh header should be detected")
+	suite.Equal("https://www.netspective.com/new.pdf", target.String(), "Relative URLs should be resolved against the page")
+	suite.Equal(5*time.Second, delay)
+
+	page, err = NewFactory(archive).PageFromURL(ctx, "https://www.netspective.com/blog/old")
+	suite.Nil(err, "Should not get an error")
+	target, delay, ok = page.RedirectTarget()
+	suite.True(ok, "Meta refresh should be detected")
+	suite.Equal("https://www.netspective.com/blog/new?from=old", target.String())
+	suite.Equal(time.Duration(0), delay)
+
+	_, _, ok = (&Page{}).RedirectTarget()
+	suite.False(ok, "There's no target without a redirect")
+}
+
+func (suite *OfflineSuite) TestBodyMetaDataIsExcluded() {
+	ctx := context.Background()
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://www.netspective.com/widget", archivedResponse(200, http.Header{"Content-Type": {"text/html"}},
+		`<html><head><meta property="og:title" content="Widget"></head>
+<body><div itemscope><meta name="author" content="Embedded Widget"><link rel="canonical" href="https://example.com/"></div></body></html>`))
+	archive.Add("https://www.netspective.com/headless", archivedResponse(200, http.Header{"Content-Type": {"text/html"}},
+		`<meta property="og:title" content="Headless"><p>Content</p><meta name="author" content="Body">`))
+
+	page, err := NewFactory(archive).PageFromURL(ctx, "https://www.netspective.com/widget")
+	suite.Nil(err, "Should not get an error")
+	value, _, _ := page.MetaTag("og:title")
+	suite.Equal("Widget", value)
+	_, ok, _ := page.MetaTag("author")
+	suite.False(ok, "<meta> in the body should not be collected by default")
+	_, ok = page.(*Page).Canonical()
+	suite.False(ok, "<link> in the body should not be collected by default")
+
+	page, err = NewFactory(archive, IncludeBodyMetaData(true)).PageFromURL(ctx, "https://www.netspective.com/widget")
+	suite.Nil(err, "Should not get an error")
+	value, ok, _ = page.MetaTag("author")
+	suite.True(ok, "<meta> in the body should be collected when asked for")
+	suite.Equal("Embedded Widget", value)
+
+	page, err = NewFactory(archive).PageFromURL(ctx, "https://www.netspective.com/headless")
+	suite.Nil(err, "Should not get an error")
+	value, ok, _ = page.MetaTag("og:title")
+	suite.True(ok, "<meta> before any content belongs to the implied <head>")
+	suite.Equal("Headless", value)
+	_, ok, _ = page.MetaTag("author")
+	suite.False(ok, "<meta> after content belongs to the implied <body>")
+}
+
+func (suite *OfflineSuite) TestHTMLContentPoliciesAreIndependent() {
+	ctx := context.Background()
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://www.netspective.com/old", archivedResponse(200, http.Header{"Content-Type": {"text/html"}},
+		`<html><head><meta http-equiv="refresh" content="0;url=/new"><meta property="og:title" content="Old"></head></html>`))
+
+	page, err := NewFactory(archive, ParseMetaDataInHTMLContent(false)).PageFromURL(ctx, "https://www.netspective.com/old")
+	suite.Nil(err, "Should not get an error")
+	isRedirect, urlText := page.Redirect()
+	suite.True(isRedirect, "Redirects should be detected without parsing meta data")
+	suite.Equal("/new", urlText)
+	_, _, err = page.MetaTag("og:title")
+	suite.NotNil(err, "Meta data should not be available when the policy says no")
+
+	page, err = NewFactory(archive, DetectRedirectsInHTMLContent(false)).PageFromURL(ctx, "https://www.netspective.com/old")
+	suite.Nil(err, "Should not get an error")
+	isRedirect, _ = page.Redirect()
+	suite.False(isRedirect, "Redirects should not be detected when the policy says no")
+	value, _, _ := page.MetaTag("og:title")
+	suite.Equal("Old", value)
+
+	page, err = NewFactory(archive, DetectRedirectsInHTMLContent(false), ParseMetaDataInHTMLContent(false)).PageFromURL(ctx, "https://www.netspective.com/old")
+	suite.Nil(err, "Should not get an error")
+	isRedirect, _ = page.Redirect()
+	suite.False(isRedirect)
+	suite.False(page.(*Page).HTMLParsed, "HTML should not be parsed when neither policy asks for it")
+}
+
+func (suite *OfflineSuite) TestTitleAndHTMLAttributes() {
+	ctx := context.Background()
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://www.netspective.com/ar", archivedResponse(200, http.Header{"Content-Type": {"text/html"}},
+		`<html lang=" ar " dir="RTL"><head><title>
+	Safety,   privacy
+</title></head><body><svg><title>Chart</title></svg><title>Late</title></body></html>`))
+	archive.Add("https://www.netspective.com/xhtml", archivedResponse(200, http.Header{"Content-Type": {"text/html"}},
+		`<html xml:lang="en-GB"><p>No title</p></html>`))
+
+	page, err := NewFactory(archive).PageFromURL(ctx, "https://www.netspective.com/ar")
+	suite.Nil(err, "Should not get an error")
+	suite.Equal("Safety, privacy", page.(*Page).Title, "Whitespace should be collapsed, later and SVG titles ignored")
+	suite.Equal("ar", page.(*Page).Language)
+	suite.Equal("rtl", page.(*Page).Direction)
+
+	page, err = NewFactory(archive).PageFromURL(ctx, "https://www.netspective.com/xhtml")
+	suite.Nil(err, "Should not get an error")
+	suite.Equal("", page.(*Page).Title)
+	suite.Equal("en-GB", page.(*Page).Language, "xml:lang should be used when there's no lang")
+	suite.Equal("", page.(*Page).Direction)
+}
+
+func TestOfflineSuite(t *testing.T) {
+	suite.Run(t, new(OfflineSuite))
+}
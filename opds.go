@@ -0,0 +1,108 @@
+package resource
+
+import (
+	"io"
+	"net/url"
+	"path"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// OPDS link relations and types, see https://specs.opds.io/opds-1.2
+const (
+	OPDSAcquisitionRel      = "http://opds-spec.org/acquisition"
+	OPDSAcquisitionFeedType = "application/atom+xml;profile=opds-catalog;kind=acquisition"
+)
+
+// Catalog describes an OPDS 1.2 acquisition catalog of the documents downloaded from pages, see WriteOPDS
+type Catalog struct {
+	Title   string
+	ID      string    // the catalog's permanent, unique identifier, Link if empty
+	Link    *url.URL  // where the catalog itself is served, its "self" link
+	Author  string    // catalogs need an author, for the catalog or every entry
+	Updated time.Time // when the catalog last changed, the latest of its entries' dates if zero
+}
+
+// catalogDocument returns the downloaded file of a page which can be put in a catalog
+func catalogDocument(page *Page) (*FileAttachment, bool) {
+	attachment, ok := page.DownloadedAttachment.(*FileAttachment)
+	if !ok || attachment == nil || !attachment.Valid || attachment.Preview || attachment.TargetURL == nil {
+		return nil, false
+	}
+	return attachment, true
+}
+
+// WriteOPDS writes the documents downloaded from pages, such as PDFs and e-books, as an OPDS acquisition feed that
+// e-reader apps can browse. Pages without a complete download are skipped. Entries are described by the page's
+// citation and meta data like NewFeedItem does, and are dated by the downloaded file when there's nothing better.
+func (c Catalog) WriteOPDS(w io.Writer, pages []*Page) error {
+	var entries []atomEntry
+	var dates []time.Time
+	updated := c.Updated
+	for _, page := range pages {
+		attachment, ok := catalogDocument(page)
+		if !ok {
+			continue
+		}
+		item := NewFeedItem(page)
+		link := atomLink{Href: attachment.TargetURL.String(), Rel: OPDSAcquisitionRel}
+		if attachment.ContentType != nil {
+			link.Type = attachment.ContentType.MediaType()
+		}
+		date := item.Updated
+		if date.IsZero() {
+			date = item.Published
+		}
+		if info, err := attachment.Stat(); err == nil {
+			link.Length = info.Size()
+			if date.IsZero() {
+				date = info.ModTime()
+			}
+		}
+		if c.Updated.IsZero() && date.After(updated) {
+			updated = date
+		}
+
+		entry := atomEntry{Title: item.Title, ID: attachment.TargetURL.String(), Links: []atomLink{link}, Summary: item.Description}
+		if len(entry.Title) == 0 {
+			entry.Title = path.Base(attachment.TargetURL.Path)
+		}
+		if !item.Published.IsZero() {
+			entry.Published = item.Published.Format(time.RFC3339)
+		}
+		for _, author := range item.Authors {
+			entry.Authors = append(entry.Authors, atomName{Name: author})
+		}
+		if len(entry.Authors) == 0 && len(c.Author) == 0 {
+			return xerrors.Errorf("OPDS entry %q has no author and the catalog has no Author", entry.ID)
+		}
+		for _, category := range item.Categories {
+			entry.Categories = append(entry.Categories, atomCategory{Term: category})
+		}
+		entries = append(entries, entry)
+		dates = append(dates, date)
+	}
+	if updated.IsZero() {
+		return xerrors.New("OPDS catalog needs an updated time, none of its documents has a date")
+	}
+
+	feed := atomFeed{Title: c.Title, ID: c.ID, Updated: updated.Format(time.RFC3339)}
+	if len(feed.ID) == 0 {
+		feed.ID = feedURL(c.Link)
+	}
+	if c.Link != nil {
+		feed.Links = []atomLink{{Href: c.Link.String(), Rel: "self", Type: OPDSAcquisitionFeedType}}
+	}
+	if len(c.Author) > 0 {
+		feed.Authors = []atomName{{Name: c.Author}}
+	}
+	for index, entry := range entries {
+		entry.Updated = feed.Updated
+		if !dates[index].IsZero() {
+			entry.Updated = dates[index].Format(time.RFC3339)
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return writeFeed(w, feed)
+}
@@ -0,0 +1,96 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type OPDSSuite struct {
+	suite.Suite
+	pages []*Page
+}
+
+func (suite *OPDSSuite) SetupSuite() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://journal.example.com/article/5678", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, `<html><head>
+<meta name="citation_title" content="An Example">
+<meta name="citation_author" content="Vaswani, Ashish">
+<meta name="citation_publication_date" content="2017/06/12">
+<meta name="citation_pdf_url" content="/article/5678.pdf"></head></html>`))
+	archive.Add("https://journal.example.com/article/5678.pdf", archivedResponse(200, http.Header{"Content-Type": {"application/pdf"}}, testPDF))
+	archive.Add("https://www.netspective.com/reports/annual.pdf", archivedResponse(200, http.Header{"Content-Type": {"application/pdf"}}, testPDFContent))
+	archive.Add("https://www.netspective.com/about", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, testFeedUndated))
+	factory := NewFactory(archive, NewMemoryAttachmentCreator(nil), resolvePDF(true))
+	for _, target := range []string{"https://journal.example.com/article/5678", "https://www.netspective.com/reports/annual.pdf", "https://www.netspective.com/about"} {
+		content, err := factory.PageFromURL(context.Background(), target)
+		suite.Require().Nil(err, "Should not get an error")
+		suite.pages = append(suite.pages, content.(*Page))
+	}
+}
+
+func (suite *OPDSSuite) TestWriteOPDS() {
+	link, _ := url.Parse("https://www.netspective.com/catalog.xml")
+	var buffer bytes.Buffer
+	suite.Require().Nil(Catalog{Title: "Netspective documents", Link: link, Author: "Netspective"}.WriteOPDS(&buffer, suite.pages))
+
+	var feed struct {
+		XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+		ID      string   `xml:"id"`
+		Updated string   `xml:"updated"`
+		Links   []struct {
+			Rel  string `xml:"rel,attr"`
+			Type string `xml:"type,attr"`
+		} `xml:"link"`
+		Entries []struct {
+			Title     string `xml:"title"`
+			ID        string `xml:"id"`
+			Updated   string `xml:"updated"`
+			Published string `xml:"published"`
+			Author    []struct {
+				Name string `xml:"name"`
+			} `xml:"author"`
+			Links []struct {
+				Href   string `xml:"href,attr"`
+				Rel    string `xml:"rel,attr"`
+				Type   string `xml:"type,attr"`
+				Length int64  `xml:"length,attr"`
+			} `xml:"link"`
+		} `xml:"entry"`
+	}
+	suite.Require().Nil(xml.Unmarshal(buffer.Bytes(), &feed), buffer.String())
+	suite.Equal("https://www.netspective.com/catalog.xml", feed.ID)
+	suite.NotEmpty(feed.Updated)
+	suite.Equal(OPDSAcquisitionFeedType, feed.Links[0].Type)
+	suite.Require().Len(feed.Entries, 2, "Pages without downloads should be skipped")
+
+	article := feed.Entries[0]
+	suite.Equal("An Example", article.Title)
+	suite.Equal("https://journal.example.com/article/5678.pdf", article.ID)
+	suite.Equal("2017-06-12T00:00:00Z", article.Published)
+	suite.Equal("2017-06-12T00:00:00Z", article.Updated)
+	suite.Equal("Vaswani, Ashish", article.Author[0].Name)
+	suite.Equal(OPDSAcquisitionRel, article.Links[0].Rel)
+	suite.Equal("application/pdf", article.Links[0].Type)
+	suite.Equal(int64(len(testPDF)), article.Links[0].Length)
+
+	report := feed.Entries[1]
+	suite.Equal("annual.pdf", report.Title, "The file name should be used when there's no title")
+	suite.NotEmpty(report.Updated, "The downloaded file should date the entry")
+	suite.Equal(int64(len(testPDFContent)), report.Links[0].Length)
+}
+
+func (suite *OPDSSuite) TestCatalogNeedsAuthors() {
+	var buffer bytes.Buffer
+	suite.NotNil(Catalog{Title: "Anonymous"}.WriteOPDS(&buffer, suite.pages[1:2]), "Entries need an author")
+	suite.Nil(Catalog{Title: "Cited"}.WriteOPDS(&buffer, suite.pages[:1]), "Cited documents have their own authors")
+}
+
+func TestOPDSSuite(t *testing.T) {
+	suite.Run(t, new(OPDSSuite))
+}
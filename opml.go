@@ -0,0 +1,92 @@
+package resource
+
+import (
+	"encoding/xml"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// FeedOutline is a feed discovered on a page, see FeedOutlines
+type FeedOutline struct {
+	Title   string   // the feed link's title, or the title of the page which advertised it
+	FeedURL *url.URL // the feed itself
+	SiteURL *url.URL // the page which advertised the feed
+	Type    string   // the media type of the feed, e.g. application/atom+xml
+}
+
+// FeedOutlines returns the RSS, Atom, and JSON Feed links advertised by pages, each feed only once and in the order
+// they were found
+func FeedOutlines(pages []*Page) []FeedOutline {
+	var result []FeedOutline
+	seen := make(map[string]bool)
+	for _, page := range pages {
+		for _, link := range page.Feeds() {
+			if link.URL == nil || seen[link.URL.String()] {
+				continue
+			}
+			seen[link.URL.String()] = true
+			outline := FeedOutline{Title: strings.TrimSpace(link.Title), FeedURL: link.URL, SiteURL: pageLink(page), Type: link.Type}
+			if len(outline.Title) == 0 {
+				outline.Title = page.Title
+			}
+			if len(outline.Title) == 0 {
+				outline.Title = link.URL.String()
+			}
+			result = append(result, outline)
+		}
+	}
+	return result
+}
+
+// FeedList describes an OPML subscription list of the feeds discovered across pages, see WriteOPML
+type FeedList struct {
+	Title     string
+	OwnerName string
+	Created   time.Time // omitted if zero
+}
+
+type opmlDocument struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    opmlHead `xml:"head"`
+	Body    opmlBody `xml:"body"`
+}
+
+type opmlBody struct {
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+type opmlHead struct {
+	Title       string `xml:"title"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+	OwnerName   string `xml:"ownerName,omitempty"`
+}
+
+type opmlOutline struct {
+	Type    string `xml:"type,attr"`
+	Text    string `xml:"text,attr"`
+	Title   string `xml:"title,attr,omitempty"`
+	XMLURL  string `xml:"xmlUrl,attr"`
+	HTMLURL string `xml:"htmlUrl,attr,omitempty"`
+}
+
+// WriteOPML writes the feeds discovered across pages as an OPML 2.0 subscription list that feed readers can import.
+// Every outline has type "rss", which is what OPML uses for Atom and JSON feeds too.
+func (l FeedList) WriteOPML(w io.Writer, pages []*Page) error {
+	document := opmlDocument{Version: "2.0", Head: opmlHead{Title: l.Title, OwnerName: l.OwnerName}}
+	if !l.Created.IsZero() {
+		document.Head.DateCreated = l.Created.Format(time.RFC1123Z)
+	}
+	for _, outline := range FeedOutlines(pages) {
+		document.Body.Outlines = append(document.Body.Outlines, opmlOutline{
+			Type:    "rss",
+			Text:    outline.Title,
+			Title:   outline.Title,
+			XMLURL:  outline.FeedURL.String(),
+			HTMLURL: feedURL(outline.SiteURL),
+		})
+	}
+	return writeFeed(w, document)
+}
@@ -0,0 +1,97 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type OPMLSuite struct {
+	suite.Suite
+	pages []*Page
+}
+
+func (suite *OPMLSuite) SetupSuite() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://www.netspective.com/blog", archivedResponse(200, http.Header{
+		"Content-Type": {"text/html"},
+		"Link":         {`</blog/comments.xml>; rel="alternate"; type="application/rss+xml"; title="Comments"`},
+	}, `<html><head><title>Netspective Blog</title>
+<link rel="alternate" type="application/atom+xml" href="/blog/atom.xml">
+<link rel="alternate" type="text/html" hreflang="fr" href="/fr/blog">
+</head></html>`))
+	archive.Add("https://www.netspective.com/blog/post", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, `<html><head><title>A Post</title>
+<link rel="alternate" type="application/atom+xml" href="/blog/atom.xml">
+<link rel="alternate" type="application/feed+json" title="JSON Feed" href="https://www.netspective.com/blog/feed.json">
+</head></html>`))
+	factory := NewFactory(archive)
+	for _, target := range []string{"https://www.netspective.com/blog", "https://www.netspective.com/blog/post"} {
+		content, err := factory.PageFromURL(context.Background(), target)
+		suite.Require().Nil(err, "Should not get an error")
+		suite.pages = append(suite.pages, content.(*Page))
+	}
+}
+
+func (suite *OPMLSuite) TestFeedOutlines() {
+	outlines := FeedOutlines(suite.pages)
+	suite.Require().Len(outlines, 3, "Feeds should only be listed once")
+	urls := []string{outlines[0].FeedURL.String(), outlines[1].FeedURL.String(), outlines[2].FeedURL.String()}
+	suite.ElementsMatch([]string{"https://www.netspective.com/blog/comments.xml", "https://www.netspective.com/blog/atom.xml", "https://www.netspective.com/blog/feed.json"}, urls)
+	for _, outline := range outlines {
+		switch outline.FeedURL.Path {
+		case "/blog/comments.xml":
+			suite.Equal("Comments", outline.Title)
+		case "/blog/atom.xml":
+			suite.Equal("Netspective Blog", outline.Title, "The page title should be used when the link has none")
+			suite.Equal("https://www.netspective.com/blog", outline.SiteURL.String())
+			suite.Equal("application/atom+xml", outline.Type)
+		case "/blog/feed.json":
+			suite.Equal("JSON Feed", outline.Title)
+			suite.Equal("https://www.netspective.com/blog/post", outline.SiteURL.String())
+		}
+	}
+}
+
+func (suite *OPMLSuite) TestWriteOPML() {
+	var buffer bytes.Buffer
+	list := FeedList{Title: "Netspective feeds", OwnerName: "Netspective", Created: time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)}
+	suite.Require().Nil(list.WriteOPML(&buffer, suite.pages))
+
+	var document struct {
+		Version string `xml:"version,attr"`
+		Head    struct {
+			Title       string `xml:"title"`
+			DateCreated string `xml:"dateCreated"`
+		} `xml:"head"`
+		Outlines []struct {
+			Type    string `xml:"type,attr"`
+			Text    string `xml:"text,attr"`
+			XMLURL  string `xml:"xmlUrl,attr"`
+			HTMLURL string `xml:"htmlUrl,attr"`
+		} `xml:"body>outline"`
+	}
+	suite.Require().Nil(xml.Unmarshal(buffer.Bytes(), &document), buffer.String())
+	suite.Equal("2.0", document.Version)
+	suite.Equal("Netspective feeds", document.Head.Title)
+	suite.Equal("Sat, 01 Jun 2019 12:00:00 +0000", document.Head.DateCreated)
+	suite.Require().Len(document.Outlines, 3)
+	for _, outline := range document.Outlines {
+		suite.Equal("rss", outline.Type)
+		suite.NotEmpty(outline.Text)
+		suite.NotEmpty(outline.XMLURL)
+		suite.NotEmpty(outline.HTMLURL)
+	}
+
+	buffer.Reset()
+	suite.Require().Nil(FeedList{Title: "Empty"}.WriteOPML(&buffer, nil))
+	suite.Contains(buffer.String(), "<body></body>", "OPML needs a body even without outlines")
+}
+
+func TestOPMLSuite(t *testing.T) {
+	suite.Run(t, new(OPMLSuite))
+}
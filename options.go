@@ -0,0 +1,144 @@
+package resource
+
+import (
+	"context"
+	"net/http"
+)
+
+// OptionOf returns the last option of type T, so that later options override earlier ones as they do everywhere else,
+// e.g. OptionOf[AttachmentDownloadPolicy](options). It's false if there's no such option.
+func OptionOf[T any](options []interface{}) (T, bool) {
+	var result T
+	found := false
+	for _, option := range options {
+		if instance, ok := option.(T); ok {
+			result = instance
+			found = true
+		}
+	}
+	return result, found
+}
+
+// OptionsOf returns every option of type T, in the order they were given
+func OptionsOf[T any](options []interface{}) []T {
+	var result []T
+	for _, option := range options {
+		if instance, ok := option.(T); ok {
+			result = append(result, instance)
+		}
+	}
+	return result
+}
+
+// isOption returns true if option is a T, it's used to list the options a method understands
+func isOption[T any](option interface{}) bool {
+	_, ok := option.(T)
+	return ok
+}
+
+// pageOptions are the kinds of options PageFromURL understands, anything else passed to it is a mistake which would
+// otherwise be silently ignored. Factory-wide policies (EventSink, Clock, ...) only work when passed to NewFactory.
+var pageOptions = []func(interface{}) bool{
+	isOption[Annotations],
+	isOption[FetchBudget],
+	isOption[*FetchBudget],
+	isOption[FileAttachmentCreator],
+	isOption[AttachmentExtensions],
+	isOption[AttachmentPreviewPolicy],
+	isOption[AttachmentDownloadPolicy],
+	isOption[PreserveOriginalFileNamePolicy],
+	isOption[AttachmentTypeMismatchPolicy],
+	isOption[AttachmentProfiler],
+	isOption[DownloadSinkChain],
+	isOption[Checksum],
+	isOption[ExpectedChecksumProvider],
+	isOption[*CleanupManager],
+	isOption[DiskSpaceChecker],
+	isOption[AttachmentTransformer],
+	isOption[MediaProber],
+	isOption[Validators],
+	isOption[ValidatorProvider],
+}
+
+// factoryOptions are the kinds of factory-wide options NewFactory understands on top of pageOptions
+var factoryOptions = []func(interface{}) bool{
+	isOption[HTTPClientProvider],
+	isOption[func(ctx context.Context) *http.Client],
+	isOption[HTTPRequestPreparer],
+	isOption[func(ctx context.Context, client *http.Client, req *http.Request)],
+	isOption[RedirectPolicy],
+	isOption[RoundTripperDecorator],
+	isOption[func(next http.RoundTripper) http.RoundTripper],
+	isOption[*ConnectionPool],
+	isOption[*DialConfig],
+	isOption[UnreadBodyPolicy],
+	isOption[ContentTTLPolicy],
+	isOption[EventSink],
+	isOption[ContentScorer],
+	isOption[Indexer],
+	isOption[DuplicateResolutionPolicy],
+	isOption[Shorteners],
+	isOption[DetectRedirectsPolicy],
+	isOption[ParseMetaDataInHTMLContentPolicy],
+	isOption[ContentDownloaderErrorPolicy],
+	isOption[ResponseArchive],
+	isOption[FetchActivityPubActorPolicy],
+	isOption[FetchWebAppManifestPolicy],
+	isOption[FetchOEmbedPolicy],
+	isOption[ResolvePDFPolicy],
+	isOption[PDFURLPatterns],
+	isOption[CheckAccessibilityPolicy],
+	isOption[ScanAssetsPolicy],
+	isOption[DetectTrackersPolicy],
+	isOption[TrackerDomains],
+	isOption[IncludeBodyMetaDataPolicy],
+	isOption[ExcerptPolicy],
+	isOption[HostStatsStore],
+	isOption[RequestTimeoutPolicy],
+	isOption[HedgingPolicy],
+	isOption[RetryPolicy],
+	isOption[CacheProvider],
+	isOption[CacheObserver],
+	isOption[HTMLBodyLimitPolicy],
+	isOption[HTMLHeadOnlyPolicy],
+	isOption[RetainBodyPolicy],
+	isOption[ResponseRecorder],
+	isOption[*DomainPolicyRouter],
+	isOption[*TenantRouter],
+	isOption[AuditSink],
+	isOption[Clock],
+	isOption[RandomSource],
+}
+
+// batchOptions are the kinds of options PagesFromURLs understands on top of pageOptions
+var batchOptions = []func(interface{}) bool{
+	isOption[BatchConcurrency],
+	isOption[DuplicateResolutionPolicy],
+}
+
+// ValidateOptions returns an ErrorCodeUnknownOption error for the first of options PageFromURL wouldn't use, the
+// options of PolicyBundles are checked one by one. PageFromURL, PagesFromURLs and PagesFromWARC validate their options with it.
+func ValidateOptions(options ...interface{}) error {
+	return validateOptions(flattenOptions(options), pageOptions)
+}
+
+func validateOptions(options []interface{}, kinds ...[]func(interface{}) bool) error {
+	for _, option := range options {
+		if option == nil || isKnownOption(option, kinds...) {
+			continue
+		}
+		return unknownOptionError(option, callerFrames(xErrorsFrameCaller+1))
+	}
+	return nil
+}
+
+func isKnownOption(option interface{}, kinds ...[]func(interface{}) bool) bool {
+	for _, list := range kinds {
+		for _, is := range list {
+			if is(option) {
+				return true
+			}
+		}
+	}
+	return false
+}
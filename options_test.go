@@ -0,0 +1,71 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type OptionsSuite struct {
+	suite.Suite
+}
+
+func (suite *OptionsSuite) TestOptionOf() {
+	options := []interface{}{AttachmentExtensions{"application/pdf": ".pdf"}, "unrelated", AttachmentExtensions{"text/plain": ".txt"}}
+	extensions, ok := OptionOf[AttachmentExtensions](options)
+	suite.True(ok)
+	suite.Equal(AttachmentExtensions{"text/plain": ".txt"}, extensions, "The last option should win")
+
+	budget, ok := OptionOf[*FetchBudget](options)
+	suite.False(ok)
+	suite.Nil(budget)
+
+	suite.Len(OptionsOf[AttachmentExtensions](options), 2)
+	suite.Len(OptionsOf[string](options), 1)
+	suite.Empty(OptionsOf[Annotations](options))
+}
+
+func (suite *OptionsSuite) TestValidateOptions() {
+	suite.Nil(ValidateOptions())
+	suite.Nil(ValidateOptions(Annotations{"id": 1}, FetchBudget{MaxBytes: 1024}, NewMemoryAttachmentCreator(nil), nil))
+	suite.Nil(ValidateOptions(NewPolicyBundle("downloads", NewMemoryAttachmentCreator(nil))), "Bundled options should be checked one by one")
+
+	err := ValidateOptions(Annotations{"id": 1}, NewManualClock(time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)))
+	suite.Equal(ErrorCodeUnknownOption, CodeOf(err), "Factory-wide options aren't used per call")
+	suite.Contains(err.Error(), "*resource.ManualClock")
+
+	err = ValidateOptions(NewPolicyBundle("typo", map[string]string{"id": "1"}))
+	suite.Equal(ErrorCodeUnknownOption, CodeOf(err), "Mistyped options should be detected inside bundles")
+	suite.Equal(ErrorCategoryInput, CategoryOf(err))
+}
+
+func (suite *OptionsSuite) TestUnknownOptionsAreRefused() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://www.netspective.com/", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, testHTMLPage))
+	factory := NewFactory(archive)
+	ctx := context.Background()
+
+	_, err := factory.PageFromURL(ctx, "https://www.netspective.com/", map[string]interface{}{"id": 1})
+	suite.Equal(ErrorCodeUnknownOption, CodeOf(err), "A map isn't Annotations")
+
+	content, err := factory.PageFromURL(ctx, "https://www.netspective.com/", Annotations{"id": 1})
+	suite.Nil(err, "Should not get an error")
+	suite.NotNil(content)
+
+	results := factory.PagesFromURLs(ctx, []string{"https://www.netspective.com/"}, BatchConcurrency(1), Annotations{"id": 1})
+	suite.Nil(results[0].Err, "Batch options shouldn't be passed on to each page")
+
+	results = factory.PagesFromURLs(ctx, []string{"https://www.netspective.com/"}, HedgeAfter(0))
+	suite.Equal(ErrorCodeUnknownOption, CodeOf(results[0].Err))
+
+	err = factory.PagesFromWARC(ctx, new(bytes.Buffer), func(context.Context, Content, error) error { return nil }, make(ChannelEventSink))
+	suite.Equal(ErrorCodeUnknownOption, CodeOf(err))
+}
+
+func TestOptionsSuite(t *testing.T) {
+	suite.Run(t, new(OptionsSuite))
+}
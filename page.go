@@ -1,20 +1,18 @@
 package resource
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/url"
-	"regexp"
 	"strings"
+	"time"
 
 	"golang.org/x/net/html"
 )
 
-// metaRefreshContentRegEx is used to match the 'content' attribute in a tag like this:
-//   <meta http-equiv="refresh" content="2;url=https://www.google.com">
-var metaRefreshContentRegEx = regexp.MustCompile(`^(\d?)\s?;\s?url=(.*)$`)
-
 // Page manages the content of a URL target
 type Page struct {
 	TargetURL                    *url.URL               `json:"url"`
@@ -22,58 +20,201 @@ type Page struct {
 	HTMLParsed                   bool                   `json:"htmlParsed"`
 	IsHTMLRedirect               bool                   `json:"isHTMLRedirect"`
 	MetaRefreshTagContentURLText string                 `json:"metaRefreshTagContentURLText"` // if IsHTMLRedirect is true, then this is the value after url= in something like <meta http-equiv='refresh' content='delay;url='>
-	MetaPropertyTags             map[string]interface{} `json:"metaPropertyTags"`             // if IsHTML() is true, a collection of all meta data like <meta property="og:site_name" content="Netspective" /> or <meta name="twitter:title" content="text" />
+	IsHeaderRedirect             bool                   `json:"isHeaderRedirect"`
+	RefreshHeaderURLText         string                 `json:"refreshHeaderURLText"`         // if IsHeaderRedirect is true, then this is the value after url= in a response header like Refresh: delay;url=
+	MetaRefreshDelay             time.Duration          `json:"metaRefreshDelay,omitempty"`   // if IsHTMLRedirect is true, how long the page asks to be shown before redirecting
+	RefreshHeaderDelay           time.Duration          `json:"refreshHeaderDelay,omitempty"` // if IsHeaderRedirect is true, how long the response asks to be shown before redirecting
+	MetaPropertyTags             map[string]interface{} `json:"metaPropertyTags"`             // if IsHTML() is true, a collection of all meta data like <meta property="og:site_name" content="Netspective" /> or <meta name="twitter:title" content="text" />, repeated tags are kept in order as []string
+	Links                        []Link                 `json:"links,omitempty"`              // from the Link response header followed by any HTML <link> elements
 	DownloadedAttachment         Attachment             `json:"attachment"`
+	ChildAttachments             []Attachment           `json:"childAttachments,omitempty"` // component parts of multipart content, such as the images inside an MHTML archive
+	Warnings                     []PageWarning          `json:"warnings,omitempty"`         // non-fatal anomalies found while processing the content
+	ActivityPubActor             *ActivityPubActor      `json:"activityPubActor,omitempty"` // only fetched if FetchActivityPubActorPolicy asks for it
+	WebAppManifest               *WebAppManifest        `json:"webAppManifest,omitempty"`   // only fetched if FetchWebAppManifestPolicy asks for it
+	Embed                        *EmbedTarget           `json:"embed,omitempty"`            // set if the page is a known service's post, video, or track
+	Citation                     *Citation              `json:"citation,omitempty"`         // from the citation_* meta tags of scholarly pages
+	DublinCore                   *DublinCore            `json:"dublinCore,omitempty"`       // from the DC.* and dcterms.* meta tags
+	LicenseHints                 []License              `json:"licenseHints,omitempty"`     // licenses named in the HTML <body>, see Licenses() for all of them
+	Assets                       []Asset                `json:"assets,omitempty"`           // only listed if ScanAssetsPolicy asks for it
+	Weight                       *PageWeight            `json:"weight,omitempty"`           // only estimated if ScanAssetsPolicy asks for it
+	Trackers                     []string               `json:"trackers,omitempty"`         // the ad and tracking domains referenced, only detected if DetectTrackersPolicy asks for it
+	PDFURL                       *url.URL               `json:"pdfURL,omitempty"`           // the PDF of a scholarly landing page, only located if ResolvePDFPolicy asks for it
+	Body                         []byte                 `json:"-"`                          // the HTML as read, only kept if RetainBodyPolicy asks for it
+	BodyEncoding                 string                 `json:"bodyEncoding,omitempty"`     // how Body is compressed, e.g. "gzip", only set on pages stored by a CompressedContentStore
+	BudgetUsage                  *BudgetUsage           `json:"budgetUsage,omitempty"`      // only tracked if there's a FetchBudget
+	Expires                      time.Time              `json:"expires"`                    // from the cache headers or a ContentTTLPolicy, zero if unknown
+	ETag                         string                 `json:"etag,omitempty"`             // validator for conditional requests
+	LastModified                 string                 `json:"lastModified,omitempty"`     // validator for conditional requests, as sent by the server
+	NotModified                  bool                   `json:"notModified,omitempty"`      // set if a conditional GET found the page unchanged, only the URL, validators, expiry and annotations are then known
+	CacheStatus                  string                 `json:"cacheStatus,omitempty"`      // how the CacheProvider answered, e.g. CacheStatusHit, empty without one
+	Fingerprint                  string                 `json:"fingerprint,omitempty"`      // hex SHA-256 of the parsed body, for spotting changes
+	Annotations                  Annotations            `json:"annotations,omitempty"`      // the caller's own metadata about the URL, passed in with it
+	TLS                          *HostTLSInfo           `json:"tls,omitempty"`              // the connection the content was received on, nil for plain HTTP
+	WordCount                    int                    `json:"wordCount,omitempty"`        // of the text in the HTML <body>
+	ExcerptText                  string                 `json:"excerpt,omitempty"`          // the start of the HTML <body> text, see Excerpt()
+	Score                        float64                `json:"score,omitempty"`            // from the ContentScorer, if there is one
+	ScoreFactors                 map[string]float64     `json:"scoreFactors,omitempty"`     // what the ContentScorer based Score on
+	ShortenedURL                 *ShortenedURL          `json:"shortenedURL,omitempty"`     // set if the URL asked for was a known shortener's
+	Title                        string                 `json:"title,omitempty"`            // the text of the HTML <title>, with its whitespace collapsed
+	Language                     string                 `json:"language,omitempty"`         // the lang (or xml:lang) attribute of <html>
+	Direction                    string                 `json:"direction,omitempty"`        // the dir attribute of <html>: "ltr", "rtl", or "auto"
 
-	valid bool
+	valid               bool
+	detectRedirects     bool
+	parseMetaData       bool
+	retainBody          bool
+	checkAccessibility  bool
+	scanAssets          bool
+	detectTrackers      bool
+	includeBodyMetaData bool
+	indexText           bool
+	excerptLength       int    // the most characters of ExcerptText, see ExcerptPolicy
+	text                string // the text of the <body> for the Indexer, only kept if indexText
+	referencedURLs      []*url.URL
 }
 
-func (p *Page) parsePageMetaData(ctx context.Context, url *url.URL, resp *http.Response) error {
-	doc, parseError := html.Parse(resp.Body)
+// parsePageMetaData never fails outright, problems with the content are recorded as Warnings instead
+func (p *Page) parsePageMetaData(ctx context.Context, url *url.URL, resp *http.Response) {
+	defer resp.Body.Close()
+	body, readError := ioutil.ReadAll(resp.Body)
+	if readError != nil {
+		p.Warnings = append(p.Warnings, PageWarning{Code: WarningBodyReadError, Message: fmt.Sprintf("only %d bytes read: %v", len(body), readError)})
+	}
+
+	if resp.ContentLength > 0 && int64(len(body)) != resp.ContentLength {
+		p.Warnings = append(p.Warnings, PageWarning{Code: WarningContentLengthMismatch, Message: fmt.Sprintf("received %d bytes but Content-Length declared %d", len(body), resp.ContentLength)})
+	}
+
+	if p.retainBody {
+		p.Body = body
+	}
+	p.Fingerprint = fingerprint(body)
+	if p.scanAssets {
+		p.Weight = &PageWeight{HTMLBytes: int64(len(body))}
+	}
+
+	p.Warnings = append(p.Warnings, scanHTMLAnomalies(body)...)
+	if p.checkAccessibility {
+		p.Warnings = append(p.Warnings, scanAccessibility(body)...)
+	}
+	doc, parseError := html.Parse(bytes.NewReader(body))
 	if parseError != nil {
-		return parseError
+		p.Warnings = append(p.Warnings, PageWarning{Code: WarningParseError, Message: parseError.Error()})
+		return
 	}
-	defer resp.Body.Close()
 
-	var inHead bool
-	var f func(*html.Node)
-	f = func(n *html.Node) {
+	// the parser puts the <meta> and <link> elements before the content into <head> even when the page doesn't have
+	// one, so only what's inside it is the page's own metadata
+	var f func(n *html.Node, inHead bool)
+	f = func(n *html.Node, inHead bool) {
 		if n.Type == html.ElementNode && strings.EqualFold(n.Data, "head") {
 			inHead = true
 		}
-		if inHead && n.Type == html.ElementNode && strings.EqualFold(n.Data, "meta") {
+		collect := inHead || p.includeBodyMetaData
+		if p.parseMetaData && n.Type == html.ElementNode && n.Namespace == "" {
+			switch strings.ToLower(n.Data) {
+			case "html":
+				p.addHTMLAttributes(n)
+			case "title":
+				if collect && len(p.Title) == 0 {
+					var text strings.Builder
+					for c := n.FirstChild; c != nil; c = c.NextSibling {
+						if c.Type == html.TextNode {
+							text.WriteString(c.Data)
+						}
+					}
+					p.Title = strings.Join(strings.Fields(text.String()), " ")
+				}
+			}
+		}
+		if collect && p.parseMetaData && n.Type == html.ElementNode && strings.EqualFold(n.Data, "link") {
+			if link, ok := linkFromHTMLNode(url, n); ok {
+				p.Links = append(p.Links, link)
+			}
+		}
+		if collect && n.Type == html.ElementNode && strings.EqualFold(n.Data, "meta") {
 			for _, attr := range n.Attr {
-				if strings.EqualFold(attr.Key, "http-equiv") && strings.EqualFold(strings.TrimSpace(attr.Val), "refresh") {
+				if p.detectRedirects && strings.EqualFold(attr.Key, "http-equiv") && strings.EqualFold(strings.TrimSpace(attr.Val), "refresh") {
 					for _, attr := range n.Attr {
 						if strings.EqualFold(attr.Key, "content") {
-							contentValue := strings.TrimSpace(attr.Val)
-							parts := metaRefreshContentRegEx.FindStringSubmatch(contentValue)
-							if parts != nil && len(parts) == 3 {
-								// the first part is the entire match
-								// the second and third parts are the delay and URL
-								// See for explanation: http://redirectdetective.com/redirection-types.html
+							if delayText, urlText, ok := parseRefreshContent(attr.Val); ok {
 								p.IsHTMLRedirect = true
-								p.MetaRefreshTagContentURLText = parts[2]
+								p.MetaRefreshTagContentURLText = urlText
+								p.MetaRefreshDelay = refreshDelay(delayText)
 							}
 						}
 					}
 				}
-				if strings.EqualFold(attr.Key, "property") || strings.EqualFold(attr.Key, "name") {
+				if p.parseMetaData && (strings.EqualFold(attr.Key, "property") || strings.EqualFold(attr.Key, "name")) {
 					propertyName := attr.Val
 					for _, attr := range n.Attr {
 						if strings.EqualFold(attr.Key, "content") {
-							p.MetaPropertyTags[propertyName] = attr.Val
+							p.addMetaPropertyTag(propertyName, attr.Val)
 						}
 					}
 				}
 			}
 		}
+		if n.Type == html.ElementNode {
+			if license, ok := licenseHintFromHTMLNode(url, n); ok && p.parseMetaData {
+				p.LicenseHints = append(p.LicenseHints, license)
+			}
+			if p.scanAssets {
+				if asset, ok := assetFromHTMLNode(url, n); ok {
+					p.addAsset(asset)
+				}
+				if asset, ok := videoPosterAsset(url, n); ok {
+					p.addAsset(asset)
+				}
+			}
+		}
+		if p.detectTrackers {
+			p.addReferencedURLs(url, n)
+		}
 		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			f(c)
+			f(c, inHead)
 		}
 	}
-	f(doc)
-	return nil
+	f(doc, false)
+	p.WordCount = countWords(doc)
+	if p.indexText {
+		p.text = bodyText(doc)
+	}
+	if p.parseMetaData {
+		p.LicenseHints = append(p.LicenseHints, spdxLicenseHints(body)...)
+		p.Citation, _ = CitationFromMetaTags(p.MetaPropertyTags, url)
+		p.DublinCore, _ = DublinCoreFromMetaTags(p.MetaPropertyTags)
+	}
+	p.ExcerptText = p.pageExcerpt(doc, p.excerptLength)
+}
+
+// addHTMLAttributes records the language and direction given by the <html> element
+func (p *Page) addHTMLAttributes(n *html.Node) {
+	for _, attr := range n.Attr {
+		value := strings.TrimSpace(attr.Val)
+		switch strings.ToLower(attr.Key) {
+		case "lang":
+			p.Language = value
+		case "xml:lang":
+			if len(p.Language) == 0 {
+				p.Language = value
+			}
+		case "dir":
+			p.Direction = strings.ToLower(value)
+		}
+	}
+}
+
+// addMetaPropertyTag records a meta tag value, keeping every value of repeated tags such as og:image or article:tag
+func (p *Page) addMetaPropertyTag(name string, value string) {
+	switch existing := p.MetaPropertyTags[name].(type) {
+	case string:
+		p.MetaPropertyTags[name] = []string{existing, value}
+	case []string:
+		p.MetaPropertyTags[name] = append(existing, value)
+	default:
+		p.MetaPropertyTags[name] = value
+	}
 }
 
 // URL is the resource locator for this content
@@ -93,7 +234,7 @@ func (p Page) Type() Type {
 
 // IsHTML returns true if this is HTML content
 func (p Page) IsHTML() bool {
-	return p.Type().MediaType() == "text/html"
+	return DefaultTypeRegistry.Is(p.PageType, "text/html")
 }
 
 // TargetURLText returns the text version of the TargetURL
@@ -104,31 +245,114 @@ func (p Page) TargetURLText() string {
 	return p.TargetURL.String()
 }
 
-// MetaTags returns tags that were parsed
+// MetaTags returns tags that were parsed, from the page itself or the root HTML document of multipart content
 func (p Page) MetaTags() (MetaTags, error) {
-	if !p.IsHTML() {
-		return nil, fmt.Errorf("Meta tags not available in non-HTML content")
-	}
 	if !p.HTMLParsed {
+		if !p.IsHTML() {
+			return nil, fmt.Errorf("Meta tags not available in non-HTML content")
+		}
 		return nil, fmt.Errorf("Meta tags not available in unparsed HTML (error or policy didn't request parsing)")
 	}
 	return p.MetaPropertyTags, nil
 }
 
-// MetaTag returns a specific parsed meta tag
+// MetaTag returns a specific parsed meta tag, the first value is returned if the tag was repeated
 func (p Page) MetaTag(key string) (interface{}, bool, error) {
 	tags, issue := p.MetaTags()
 	if issue != nil {
 		return nil, false, issue
 	}
-	result, ok := tags[key]
+	result, ok := tags.Value(key)
 	return result, ok, nil
 }
 
-// Redirect returns true if redirect was requested through via <meta http-equiv='refresh' content='delay;url='>
+// MetaTagAll returns every value of a specific parsed meta tag, in document order
+func (p Page) MetaTagAll(key string) ([]interface{}, bool, error) {
+	tags, issue := p.MetaTags()
+	if issue != nil {
+		return nil, false, issue
+	}
+	result, ok := tags.Values(key)
+	return result, ok, nil
+}
+
+// MetaNamespace returns the parsed meta tags under a vocabulary prefix such as "og", "twitter", or "article"
+func (p Page) MetaNamespace(namespace string) (MetaTags, error) {
+	tags, issue := p.MetaTags()
+	if issue != nil {
+		return nil, issue
+	}
+	return tags.Namespace(namespace), nil
+}
+
+// LinksByRel returns the links, from the Link header and HTML, with the given relation type such as "alternate" or "next"
+func (p Page) LinksByRel(rel string) []Link {
+	var result []Link
+	for _, link := range p.Links {
+		if link.HasRel(rel) {
+			result = append(result, link)
+		}
+	}
+	return result
+}
+
+// Feeds returns the RSS, Atom, and JSON Feed links advertised by the Link header and HTML, for feed autodiscovery
+func (p Page) Feeds() []Link {
+	var result []Link
+	for _, link := range p.Links {
+		if link.IsFeed() {
+			result = append(result, link)
+		}
+	}
+	return result
+}
+
+// Canonical returns the URL of the first rel="canonical" link
+func (p Page) Canonical() (*url.URL, bool) {
+	links := p.LinksByRel("canonical")
+	if len(links) == 0 {
+		return nil, false
+	}
+	return links[0].URL, true
+}
+
+// Redirect returns true if redirect was requested through via <meta http-equiv='refresh' content='delay;url='> or,
+// failing that, a Refresh: delay;url= response header.
 // For an explanation, please see http://redirectdetective.com/redirection-types.html
 func (p Page) Redirect() (bool, string) {
-	return p.IsHTMLRedirect, p.MetaRefreshTagContentURLText
+	if p.IsHTMLRedirect {
+		return true, p.MetaRefreshTagContentURLText
+	}
+	return p.IsHeaderRedirect, p.RefreshHeaderURLText
+}
+
+// RedirectTarget returns where the redirect requested by a meta refresh tag or Refresh header (see Redirect) leads,
+// resolved against the page's URL, and how long it asks to wait first. False is returned if there's no redirect or
+// its URL can't be parsed.
+func (p Page) RedirectTarget() (*url.URL, time.Duration, bool) {
+	redirect, urlText := p.Redirect()
+	if !redirect {
+		return nil, 0, false
+	}
+	delay := p.RefreshHeaderDelay
+	if p.IsHTMLRedirect {
+		delay = p.MetaRefreshDelay
+	}
+
+	target, err := url.Parse(strings.TrimSpace(urlText))
+	if err != nil {
+		return nil, delay, false
+	}
+	if p.TargetURL != nil {
+		target = p.TargetURL.ResolveReference(target)
+	}
+	return target, delay, true
+}
+
+// IsStale returns true if the content has expired at now, and so should be harvested again. Content without any
+// expiry information is always stale.
+func (p Page) IsStale(now time.Time) bool {
+	return p.Expires.IsZero() || !now.Before(p.Expires)
 }
 
 // Attachment returns the any downloaded file
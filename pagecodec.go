@@ -0,0 +1,65 @@
+package resource
+
+import (
+	"encoding/json"
+
+	"golang.org/x/xerrors"
+)
+
+// storedPage is the JSON of a Page as it's kept by persistent ContentStores. Attachments aren't stored (their files
+// are kept by their creators), nor is Body, which stores keep separately.
+type storedPage struct {
+	*storedPageFields
+	PageType             *PageType       `json:"type"`
+	DownloadedAttachment json.RawMessage `json:"attachment,omitempty"`
+	ChildAttachments     json.RawMessage `json:"childAttachments,omitempty"`
+	Valid                bool            `json:"valid"`
+}
+
+// storedPageFields is Page without its methods, so that embedding it in storedPage doesn't promote them
+type storedPageFields Page
+
+// MarshalStoredPage encodes page for a persistent ContentStore, UnmarshalStoredPage decodes it again. Everything but
+// Body, DownloadedAttachment and ChildAttachments is kept.
+func MarshalStoredPage(page *Page) ([]byte, error) {
+	fields := storedPageFields(*page)
+	fields.DownloadedAttachment = nil
+	fields.ChildAttachments = nil
+	stored := storedPage{storedPageFields: &fields, Valid: page.valid}
+	if pageType, ok := page.PageType.(*PageType); ok {
+		stored.PageType = pageType
+	} else if page.PageType != nil {
+		stored.PageType = &PageType{ContType: page.PageType.ContentType(), MedType: page.PageType.MediaType(), MedTypeParams: page.PageType.MediaTypeParams()}
+	}
+	result, err := json.Marshal(stored)
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to encode page %q: %w", page.TargetURLText(), err)
+	}
+	return result, nil
+}
+
+// UnmarshalStoredPage decodes a page encoded by MarshalStoredPage
+func UnmarshalStoredPage(data []byte) (*Page, error) {
+	fields := new(storedPageFields)
+	stored := storedPage{storedPageFields: fields}
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, xerrors.Errorf("Unable to decode stored page: %w", err)
+	}
+	result := (*Page)(fields)
+	if stored.PageType != nil {
+		result.PageType = stored.PageType
+	}
+	result.valid = stored.Valid
+	for key, value := range result.MetaPropertyTags {
+		// repeated meta tags are []string, which JSON gives back as []interface{}
+		if values, ok := value.([]interface{}); ok {
+			texts := make([]string, 0, len(values))
+			for _, value := range values {
+				text, _ := value.(string)
+				texts = append(texts, text)
+			}
+			result.MetaPropertyTags[key] = texts
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,65 @@
+package resource
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+const testStoredPage = `<html lang="en"><head><title>Stored</title>
+<meta property="og:site_name" content="Netspective">
+<meta property="article:tag" content="health"><meta property="article:tag" content="technology">
+<meta name="citation_title" content="An Example"><meta name="citation_publication_date" content="2019/06/01">
+<link rel="alternate" type="application/rss+xml" href="/feed.xml"></head>
+<body><p>Licensed under CC BY 4.0</p></body></html>`
+
+type PageCodecSuite struct {
+	suite.Suite
+}
+
+func (suite *PageCodecSuite) TestRoundTrip() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://www.netspective.com/stored", archivedResponse(200, http.Header{"Content-Type": {"text/html; charset=utf-8"}, "Etag": {`"v1"`}}, testStoredPage))
+	content, err := NewFactory(archive, RetainBody(true)).PageFromURL(context.Background(), "https://www.netspective.com/stored", Annotations{"collection": "guides"})
+	suite.Require().Nil(err, "Should not get an error")
+	page := content.(*Page)
+
+	data, err := MarshalStoredPage(page)
+	suite.Require().Nil(err, "Should not get an error")
+	decoded, err := UnmarshalStoredPage(data)
+	suite.Require().Nil(err, "Should not get an error")
+
+	suite.True(decoded.IsValid())
+	suite.Equal("text/html", decoded.PageType.MediaType())
+	suite.Equal("https://www.netspective.com/stored", decoded.TargetURLText())
+	suite.Equal(page.MetaPropertyTags, decoded.MetaPropertyTags, "Repeated meta tags should still be []string")
+	values, _, _ := decoded.MetaTagAll("article:tag")
+	suite.Equal([]interface{}{"health", "technology"}, values)
+	suite.Equal(page.Links, decoded.Links)
+	suite.Equal(page.Citation, decoded.Citation)
+	suite.Equal(page.LicenseHints, decoded.LicenseHints)
+	suite.Equal(page.ETag, decoded.ETag)
+	suite.Equal(page.Fingerprint, decoded.Fingerprint)
+	suite.Equal(page.Annotations, decoded.Annotations)
+	suite.Nil(decoded.Body, "Bodies are kept by the store")
+}
+
+func (suite *PageCodecSuite) TestAttachmentsAreNotStored() {
+	page := &Page{DownloadedAttachment: &FileAttachment{DestPath: "paper.pdf"}}
+	data, err := MarshalStoredPage(page)
+	suite.Nil(err, "Should not get an error")
+	decoded, err := UnmarshalStoredPage(data)
+	suite.Nil(err, "Should not get an error")
+	suite.Nil(decoded.DownloadedAttachment)
+	suite.False(decoded.IsValid())
+	suite.NotNil(page.DownloadedAttachment, "The page passed in shouldn't change")
+
+	_, err = UnmarshalStoredPage([]byte("not json"))
+	suite.NotNil(err, "Should get an error")
+}
+
+func TestPageCodecSuite(t *testing.T) {
+	suite.Run(t, new(PageCodecSuite))
+}
@@ -0,0 +1,92 @@
+package resource
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"golang.org/x/xerrors"
+)
+
+// PolicyPanicError is returned instead of crashing when a policy, creator, sink, or other option supplied by the
+// caller panics. Policy names the option's interface, e.g. "AttachmentDownloadPolicy", Value is what it panicked with
+// and Stack is where.
+type PolicyPanicError struct {
+	Policy string
+	Value  interface{}
+	Stack  []byte
+}
+
+// FormatError will print a simple message to the Printer object. This will be what you see when you Println or use %s/%v in a formatted print statement.
+func (e PolicyPanicError) FormatError(p xerrors.Printer) error {
+	p.Printf("LECTIORES-502 %s panicked: %v", e.Policy, e.Value)
+	if p.Detail() {
+		p.Printf("%s", e.Stack)
+	}
+	return nil
+}
+
+// Format provide backwards compatibility with pre-xerrors package
+func (e PolicyPanicError) Format(f fmt.State, c rune) {
+	xerrors.FormatError(e, f, c)
+}
+
+// Error returns the policy and what it panicked with
+func (e PolicyPanicError) Error() string {
+	return fmt.Sprint(e)
+}
+
+// Unwrap returns what the policy panicked with, if it's an error
+func (e PolicyPanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// ErrorCode satisfies the interface CodeOf uses
+func (e PolicyPanicError) ErrorCode() int {
+	return ErrorCodePolicyPanic
+}
+
+// policyPanic is what a policy's panic is turned into on its way up to recoverPolicyPanic, it's an error so that
+// panics which aren't recovered still name the policy
+type policyPanic struct {
+	err *PolicyPanicError
+}
+
+func (p policyPanic) Error() string {
+	return p.err.Error()
+}
+
+// policyPanicked is deferred around calls into options supplied by the caller, it names the policy in any panic
+func policyPanicked(policy string) {
+	if r := recover(); r != nil {
+		if _, ok := r.(policyPanic); ok {
+			// a policy which called back into the factory, the innermost policy is the one to blame
+			panic(r)
+		}
+		panic(policyPanic{&PolicyPanicError{Policy: policy, Value: r, Stack: debug.Stack()}})
+	}
+}
+
+// callPolicy returns the result of fn, which calls into the named policy
+func callPolicy[T any](policy string, fn func() T) T {
+	defer policyPanicked(policy)
+	return fn()
+}
+
+// guardPolicy calls fn, which calls into the named policy
+func guardPolicy(policy string, fn func()) {
+	defer policyPanicked(policy)
+	fn()
+}
+
+// recoverPolicyPanic is deferred by the entry points of the factory to return a PolicyPanicError in err instead of
+// crashing. Panics which didn't come from a policy are the package's own bugs and aren't recovered.
+func recoverPolicyPanic(err *error) {
+	if r := recover(); r != nil {
+		panicked, ok := r.(policyPanic)
+		if !ok {
+			panic(r)
+		}
+		*err = panicked.err
+	}
+}
@@ -0,0 +1,160 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/xerrors"
+)
+
+var errPanickyPolicy = errors.New("policy bug")
+
+type panickyScorer struct{}
+
+func (panickyScorer) ScoreContent(ctx context.Context, page *Page) (float64, map[string]float64) {
+	panic("scorer bug")
+}
+
+type panickyDuplicateKeys struct{}
+
+func (panickyDuplicateKeys) DuplicateKey(ctx context.Context, page *Page) string {
+	panic(errPanickyPolicy)
+}
+
+type panickyDownloads struct{}
+
+func (panickyDownloads) ShouldDownload(ctx context.Context, url *url.URL, t Type, contentLength int64, headers http.Header) bool {
+	panic(errPanickyPolicy)
+}
+
+type panickyAudit struct{}
+
+func (panickyAudit) Audit(ctx context.Context, record AuditRecord) error {
+	panic("audit bug")
+}
+
+type PanicsSuite struct {
+	suite.Suite
+	archive *MemoryResponseArchive
+}
+
+func (suite *PanicsSuite) SetupTest() {
+	suite.archive = NewMemoryResponseArchive()
+	suite.archive.Add("https://www.netspective.com/", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, testHTMLPage))
+	suite.archive.Add("https://www.netspective.com/about", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, testHTMLPage))
+}
+
+func (suite *PanicsSuite) TestPolicyPanicIsReturned() {
+	factory := NewFactory(suite.archive, panickyScorer{})
+	_, err := factory.PageFromURL(context.Background(), "https://www.netspective.com/")
+
+	var panicked *PolicyPanicError
+	suite.True(xerrors.As(err, &panicked), "Panic should be returned as an error")
+	suite.Equal("ContentScorer", panicked.Policy)
+	suite.Equal("scorer bug", panicked.Value)
+	suite.Contains(string(panicked.Stack), "ScoreContent", "The stack should show where the policy panicked")
+	suite.Equal("LECTIORES-502 ContentScorer panicked: scorer bug", err.Error())
+	suite.Equal(ErrorCategoryPolicy, CategoryOf(err))
+}
+
+func (suite *PanicsSuite) TestBatchSurvivesPanics() {
+	factory := NewFactory(suite.archive, panickyScorer{})
+	results := factory.PagesFromURLs(context.Background(), []string{"https://www.netspective.com/", "https://www.netspective.com/about"})
+	suite.Len(results, 2)
+	for _, result := range results {
+		suite.Equal(ErrorCodePolicyPanic, CodeOf(result.Err), "Every worker should survive")
+	}
+
+	results = NewFactory(suite.archive).PagesFromURLs(context.Background(), []string{"https://www.netspective.com/"}, panickyDuplicateKeys{})
+	suite.Len(results, 1)
+	suite.True(xerrors.Is(results[0].Err, errPanickyPolicy), "Errors the policy panicked with should be unwrapped")
+	suite.True(results[0].Issues.HasErrors())
+}
+
+func (suite *PanicsSuite) TestDownloadPolicyPanic() {
+	target, _ := url.Parse("https://www.netspective.com/paper.pdf")
+	resp := archivedResponse(200, http.Header{"Content-Type": {"application/pdf"}}, testPDFContent)
+	ok, _, err := DownloadFileFromHTTPResp(context.Background(), NewMemoryAttachmentCreator(nil), target, resp, nil, panickyDownloads{})
+	suite.False(ok)
+	var panicked *PolicyPanicError
+	suite.True(xerrors.As(err, &panicked), "Should get the panic as an error")
+	suite.Equal("AttachmentDownloadPolicy", panicked.Policy)
+}
+
+type panickyFinalizer struct {
+	*FileSystemAttachmentCreator
+}
+
+func (panickyFinalizer) FinalizeFile(ctx context.Context, fs afero.Fs, path string, url *url.URL, t Type) (string, error) {
+	panic("finalizer bug")
+}
+
+func (suite *PanicsSuite) TestFinalizerPanic() {
+	target, _ := url.Parse("https://www.netspective.com/paper.pdf")
+	resp := archivedResponse(200, http.Header{"Content-Type": {"application/pdf"}}, testPDFContent)
+	ok, _, err := DownloadFileFromHTTPResp(context.Background(), panickyFinalizer{NewMemoryAttachmentCreator(nil)}, target, resp, nil)
+	suite.False(ok)
+	var panicked *PolicyPanicError
+	suite.True(xerrors.As(err, &panicked), "Should get the panic as an error")
+	suite.Equal("FileAttachmentFinalizer", panicked.Policy)
+}
+
+func (suite *PanicsSuite) TestFetchingMethodsReturnPolicyPanics() {
+	ctx := context.Background()
+	factory := NewFactory(suite.archive, panickyAudit{})
+	store := NewMemoryContentStore()
+	target, _ := url.Parse("https://www.netspective.com/")
+	store.StorePage(ctx, &Page{TargetURL: target})
+
+	methods := map[string]func() error{
+		"PageFromURL":      func() error { _, err := factory.PageFromURL(ctx, "https://www.netspective.com/"); return err },
+		"SecurityTxt":      func() error { _, err := factory.SecurityTxt(ctx, "www.netspective.com"); return err },
+		"HumansTxt":        func() error { _, err := factory.HumansTxt(ctx, "www.netspective.com"); return err },
+		"ActivityPubActor": func() error { _, err := factory.ActivityPubActor(ctx, "https://www.netspective.com/actor"); return err },
+		"OEmbed":           func() error { _, err := factory.OEmbed(ctx, "https://www.netspective.com/oembed"); return err },
+		"WebAppManifest": func() error {
+			_, err := factory.WebAppManifest(ctx, "https://www.netspective.com/manifest.json")
+			return err
+		},
+		"HostProfile":     func() error { _, err := factory.HostProfile(ctx, "www.netspective.com"); return err },
+		"RevalidateStore": func() error { _, err := factory.RevalidateStore(ctx, store, RevalidationBatch{}); return err },
+	}
+	for name, method := range methods {
+		var err error
+		suite.NotPanics(func() { err = method() }, "%s should return the panic", name)
+		var panicked *PolicyPanicError
+		if suite.True(xerrors.As(err, &panicked), "%s should return a PolicyPanicError, got %v", name, err) {
+			suite.Equal("AuditSink", panicked.Policy, name)
+		}
+	}
+}
+
+func (suite *PanicsSuite) TestOtherPanicsAreNotRecovered() {
+	suite.PanicsWithValue("package bug", func() {
+		var err error
+		defer recoverPolicyPanic(&err)
+		panic("package bug")
+	})
+
+	err := func() (err error) {
+		defer recoverPolicyPanic(&err)
+		guardPolicy("EventSink", func() { panic(fmt.Sprintf("sink %d", 1)) })
+		return nil
+	}()
+	suite.Equal("LECTIORES-502 EventSink panicked: sink 1", err.Error())
+
+	panicky := func(ctx context.Context) *http.Client { panic("provider bug") }
+	var invalid *InvalidConfigurationError
+	suite.True(xerrors.As(NewFactory(panicky).Validate(), &invalid), "NewFactory should survive a panicking provider")
+	suite.Equal([]ConfigurationProblem{{Option: "ProvideClientFunc", Problem: "panicked: provider bug"}}, invalid.Problems)
+}
+
+func TestPanicsSuite(t *testing.T) {
+	suite.Run(t, new(PanicsSuite))
+}
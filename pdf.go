@@ -0,0 +1,123 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// ResolvePDFPolicy is passed into options if we want the PDF behind a scholarly landing page (such as the one a DOI
+// or arXiv abstract URL leads to) located and, when there's a FileAttachmentCreator, downloaded as its attachment
+type ResolvePDFPolicy interface {
+	ResolvePDF(context.Context, *url.URL) bool
+}
+
+// PDFURLPattern rewrites a landing page's URL into the URL of its PDF, in the manner of Unpaywall's publisher rules
+type PDFURLPattern struct {
+	Match   *regexp.Regexp
+	Replace string // expanded as by regexp.ReplaceAllString
+}
+
+// PDFURLPatterns is passed into options to recognize more publishers' landing pages, they're tried before
+// DefaultPDFURLPatterns
+type PDFURLPatterns []PDFURLPattern
+
+// DefaultPDFURLPatterns are used for landing pages which don't have a citation_pdf_url meta tag
+var DefaultPDFURLPatterns = PDFURLPatterns{
+	{regexp.MustCompile(`^https?://(?:www\.|export\.)?arxiv\.org/abs/([^?#]+?)/?$`), "https://arxiv.org/pdf/$1"},
+	{regexp.MustCompile(`^(https?://(?:www\.)?(?:bio|med)rxiv\.org/content/10\.1101/[^?#]+?)(?:\.full|\.abstract)?/?$`), "$1.full.pdf"},
+	{regexp.MustCompile(`^https?://openreview\.net/forum\?id=([^&#]+)$`), "https://openreview.net/pdf?id=$1"},
+	{regexp.MustCompile(`^(https?://journals\.plos\.org/[a-z]+)/article\?id=([^&#]+)$`), "$1/article/file?id=$2&type=printable"},
+	{regexp.MustCompile(`^https?://link\.springer\.com/article/(10\.[^?#]+)$`), "https://link.springer.com/content/pdf/$1.pdf"},
+	{regexp.MustCompile(`^(https?://(?:www\.)?mdpi\.com/\d{4}-\d{3}[\dX]/\d+/\d+/\d+)/?$`), "$1/pdf"},
+	{regexp.MustCompile(`^(https?://(?:www\.)?aclanthology\.org/[A-Z0-9][\w.-]*\d)/?$`), "$1.pdf"},
+}
+
+// locate returns the PDF URL of the first pattern matching landing
+func (patterns PDFURLPatterns) locate(landing *url.URL) (*url.URL, bool) {
+	landingText := landing.String()
+	for _, pattern := range patterns {
+		if pattern.Match == nil || !pattern.Match.MatchString(landingText) {
+			continue
+		}
+		if result, err := url.Parse(pattern.Match.ReplaceAllString(landingText, pattern.Replace)); err == nil {
+			return result, true
+		}
+	}
+	return nil, false
+}
+
+func (f *DefaultFactory) resolvePDF(ctx context.Context, url *url.URL) bool {
+	if f.ResolvePDFPolicy != nil {
+		defer policyPanicked("ResolvePDFPolicy")
+		return f.ResolvePDFPolicy.ResolvePDF(ctx, url)
+	}
+	return false
+}
+
+// locatePDF finds the PDF of a landing page from, in order of preference, its citation_pdf_url meta tag, a
+// <link rel="alternate" type="application/pdf">, and the URL patterns
+func (f *DefaultFactory) locatePDF(result *Page) (*url.URL, bool) {
+	if result.Citation != nil && result.Citation.PDFURL != nil {
+		return result.Citation.PDFURL, true
+	}
+	for _, link := range result.LinksByRel("alternate") {
+		if strings.EqualFold(link.Type, "application/pdf") {
+			return link.URL, true
+		}
+	}
+	if pdfURL, ok := f.PDFURLPatterns.locate(result.TargetURL); ok {
+		return pdfURL, true
+	}
+	return DefaultPDFURLPatterns.locate(result.TargetURL)
+}
+
+// discoverPDF locates the PDF of a scholarly landing page when the policy asks for it and downloads it as the page's
+// attachment. Failures are warnings unless the ContentDownloaderErrorPolicy says to stop.
+func (f *DefaultFactory) discoverPDF(ctx context.Context, result *Page, options []interface{}) error {
+	if !f.resolvePDF(ctx, result.TargetURL) {
+		return nil
+	}
+	pdfURL, ok := f.locatePDF(result)
+	if !ok {
+		return nil
+	}
+	result.PDFURL = pdfURL
+	attachmentCreator := f.attachmentCreator(options)
+	if attachmentCreator == nil {
+		return nil
+	}
+
+	resp, err := f.fetch(ctx, pdfURL.String(), http.Header{"Accept": {"application/pdf"}})
+	if err != nil {
+		return f.pdfDownloadError(ctx, result, nil, err)
+	}
+	typ, err := NewPageType(resp.Header.Get("Content-Type"))
+	if err != nil || !DefaultTypeRegistry.Is(typ, "application/pdf") {
+		// publishers often answer with a login or paywall page instead
+		resp.Body.Close()
+		result.Warnings = append(result.Warnings, PageWarning{Code: WarningRelatedFetchError,
+			Message: fmt.Sprintf("%s is %q rather than a PDF", resp.Request.URL, resp.Header.Get("Content-Type"))})
+		return nil
+	}
+
+	ok, attachment, err := DownloadFileFromHTTPResp(ctx, attachmentCreator, resp.Request.URL, resp, typ, f.downloadOptions(options)...)
+	if err != nil {
+		return f.pdfDownloadError(ctx, result, typ, err)
+	}
+	if ok && attachment != nil {
+		result.DownloadedAttachment = attachment
+	}
+	return nil
+}
+
+func (f *DefaultFactory) pdfDownloadError(ctx context.Context, result *Page, typ Type, err error) error {
+	if f.stopOnDownloadError(ctx, result.PDFURL, typ, err) {
+		return err
+	}
+	result.Warnings = append(result.Warnings, PageWarning{Code: WarningRelatedFetchError, Message: err.Error()})
+	return nil
+}
@@ -0,0 +1,91 @@
+package resource
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type resolvePDF bool
+
+func (p resolvePDF) ResolvePDF(context.Context, *url.URL) bool {
+	return bool(p)
+}
+
+const testPDF = "%PDF-1.4\n1 0 obj << /Type /Catalog >> endobj\ntrailer << /Root 1 0 R >>\n%%EOF\n"
+
+type PDFSuite struct {
+	suite.Suite
+	archive *MemoryResponseArchive
+	creator *FileSystemAttachmentCreator
+}
+
+func (suite *PDFSuite) SetupTest() {
+	suite.archive = NewMemoryResponseArchive()
+	suite.archive.Add("https://doi.org/10.1234/example.5678", archivedResponse(302, http.Header{"Location": {"https://journal.example.com/article/5678"}}, ""))
+	suite.archive.Add("https://journal.example.com/article/5678", archivedResponse(200, http.Header{"Content-Type": {"text/html"}},
+		`<html><head><meta name="citation_title" content="An Example"><meta name="citation_pdf_url" content="/article/5678.pdf"></head></html>`))
+	suite.archive.Add("https://journal.example.com/article/5678.pdf", archivedResponse(200, http.Header{"Content-Type": {"application/pdf"}}, testPDF))
+	suite.creator = NewMemoryAttachmentCreator(nil)
+}
+
+func (suite *PDFSuite) TestCitationPDFURL() {
+	content, err := NewFactory(suite.archive, suite.creator).PageFromURL(context.Background(), "https://doi.org/10.1234/example.5678")
+	suite.Nil(err, "Should not get an error")
+	suite.Nil(content.(*Page).PDFURL, "The PDF should not be resolved without a policy")
+	suite.Nil(content.(*Page).DownloadedAttachment)
+
+	content, err = NewFactory(suite.archive, suite.creator, resolvePDF(true)).PageFromURL(context.Background(), "https://doi.org/10.1234/example.5678")
+	suite.Nil(err, "Should not get an error")
+	page := content.(*Page)
+	suite.Equal("https://journal.example.com/article/5678", page.TargetURLText(), "The landing page should still be the page")
+	suite.Require().NotNil(page.PDFURL)
+	suite.Equal("https://journal.example.com/article/5678.pdf", page.PDFURL.String())
+	suite.Require().NotNil(page.DownloadedAttachment, "The PDF should be downloaded")
+	suite.Equal("application/pdf", page.DownloadedAttachment.Type().MediaType())
+	file, err := page.DownloadedAttachment.(*FileAttachment).Open()
+	suite.Require().Nil(err, "Should not get an error")
+	defer file.Close()
+	body, _ := ioutil.ReadAll(file)
+	suite.Equal(testPDF, string(body))
+}
+
+func (suite *PDFSuite) TestURLPatterns() {
+	suite.archive.Add("https://arxiv.org/abs/1706.03762", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, testHTMLPage))
+	suite.archive.Add("https://arxiv.org/pdf/1706.03762", archivedResponse(200, http.Header{"Content-Type": {"application/pdf"}}, testPDF))
+
+	content, err := NewFactory(suite.archive, suite.creator, resolvePDF(true)).PageFromURL(context.Background(), "https://arxiv.org/abs/1706.03762")
+	suite.Nil(err, "Should not get an error")
+	page := content.(*Page)
+	suite.Require().NotNil(page.PDFURL)
+	suite.Equal("https://arxiv.org/pdf/1706.03762", page.PDFURL.String())
+	suite.NotNil(page.DownloadedAttachment)
+
+	suite.archive.Add("https://papers.example.org/view/42", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, testHTMLPage))
+	patterns := PDFURLPatterns{{regexp.MustCompile(`^https://papers\.example\.org/view/(\d+)$`), "https://papers.example.org/download/$1.pdf"}}
+	content, err = NewFactory(suite.archive, resolvePDF(true), patterns).PageFromURL(context.Background(), "https://papers.example.org/view/42")
+	suite.Nil(err, "Should not get an error")
+	suite.Require().NotNil(content.(*Page).PDFURL)
+	suite.Equal("https://papers.example.org/download/42.pdf", content.(*Page).PDFURL.String(), "The PDF should be located without downloading it")
+}
+
+func (suite *PDFSuite) TestPaywall() {
+	suite.archive.Add("https://journal.example.com/article/5678.pdf", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, testHTMLPage))
+
+	content, err := NewFactory(suite.archive, suite.creator, resolvePDF(true)).PageFromURL(context.Background(), "https://doi.org/10.1234/example.5678")
+	suite.Nil(err, "Should not get an error")
+	page := content.(*Page)
+	suite.NotNil(page.PDFURL)
+	suite.Nil(page.DownloadedAttachment, "HTML should not be downloaded as the PDF")
+	suite.Require().NotEmpty(page.Warnings)
+	suite.Equal(WarningRelatedFetchError, page.Warnings[len(page.Warnings)-1].Code)
+}
+
+func TestPDFSuite(t *testing.T) {
+	suite.Run(t, new(PDFSuite))
+}
@@ -0,0 +1,314 @@
+package resource
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// PDFTextTransformer is an AttachmentTransformer which extracts the plain text of PDF attachments into a sibling
+// ".txt" attachment, with its WordCount, for search indexing. It reads the text operators of uncompressed and
+// FlateDecode content streams, which covers what most authoring tools produce; text drawn with fonts that need a
+// ToUnicode map (common for CJK) or that's only in images comes out garbled or not at all.
+type PDFTextTransformer struct{}
+
+// TransformAttachment satisfies AttachmentTransformer method
+func (t PDFTextTransformer) TransformAttachment(ctx context.Context, source *FileAttachment) ([]*FileAttachment, error) {
+	if !isPDFAttachment(source) {
+		return nil, nil
+	}
+	var words int
+	stream := StreamTransformer{
+		Name:        "pdf-text",
+		Extension:   "txt",
+		ContentType: "text/plain; charset=utf-8",
+		Transform: func(ctx context.Context, src io.Reader, dst io.Writer) error {
+			// PDFs can't be read front to back, their cross-references point all over the file
+			content, err := ioutil.ReadAll(src)
+			if err != nil {
+				return err
+			}
+			text := extractPDFText(content)
+			words = len(strings.Fields(text))
+			_, err = io.WriteString(dst, text)
+			return err
+		}}
+	derived, err := stream.TransformAttachment(ctx, source)
+	for _, attachment := range derived {
+		attachment.WordCount = words
+	}
+	return derived, err
+}
+
+func isPDFAttachment(attachment *FileAttachment) bool {
+	if attachment.FileType.MIME.Value == "application/pdf" {
+		return true
+	}
+	return attachment.ContentType != nil && attachment.ContentType.MediaType() == "application/pdf"
+}
+
+// extractPDFText returns the text shown by the content streams of a PDF, a line per line of text
+func extractPDFText(content []byte) string {
+	var text strings.Builder
+	for _, stream := range pdfStreams(content) {
+		extractStreamText(stream, &text)
+	}
+
+	var lines []string
+	for _, line := range strings.Split(text.String(), "\n") {
+		if line = strings.Join(strings.Fields(line), " "); len(line) > 0 {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// pdfStreams returns the decoded data of the PDF's streams, streams with filters other than FlateDecode (such as
+// images) are skipped
+func pdfStreams(content []byte) [][]byte {
+	var result [][]byte
+	for offset := 0; ; {
+		start := bytes.Index(content[offset:], []byte("stream"))
+		if start < 0 {
+			return result
+		}
+		start += offset
+		offset = start + len("stream")
+		if start >= 3 && string(content[start-3:start]) == "end" {
+			continue
+		}
+		dataStart := offset
+		if dataStart < len(content) && content[dataStart] == '\r' {
+			dataStart++
+		}
+		if dataStart < len(content) && content[dataStart] == '\n' {
+			dataStart++
+		}
+		end := bytes.Index(content[dataStart:], []byte("endstream"))
+		if end < 0 {
+			return result
+		}
+		data := content[dataStart : dataStart+end]
+		offset = dataStart + end + len("endstream")
+
+		dictionary := content[:start]
+		if obj := bytes.LastIndex(dictionary, []byte(" obj")); obj >= 0 {
+			dictionary = dictionary[obj:]
+		}
+		switch {
+		case bytes.Contains(dictionary, []byte("/FlateDecode")):
+			reader, err := zlib.NewReader(bytes.NewReader(data))
+			if err != nil {
+				continue
+			}
+			// a truncated stream still has text worth keeping
+			data, _ = ioutil.ReadAll(reader)
+		case bytes.Contains(dictionary, []byte("/Filter")):
+			continue
+		}
+		result = append(result, data)
+	}
+}
+
+// pdfOperand is a string or number operand of a content stream operator, or the start of an array
+type pdfOperand struct {
+	text     string
+	number   float64
+	isNumber bool
+}
+
+// extractStreamText writes the text shown between BT and ET in a content stream
+func extractStreamText(stream []byte, text *strings.Builder) {
+	var operands []pdfOperand
+	inText := false
+	for i := 0; i < len(stream); {
+		c := stream[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == 0 || c == ']' || c == '[' || c == '>' || c == '{' || c == '}':
+			i++
+		case c == '%':
+			for i < len(stream) && stream[i] != '\n' && stream[i] != '\r' {
+				i++
+			}
+		case c == '(':
+			var value string
+			value, i = pdfLiteralString(stream, i+1)
+			operands = append(operands, pdfOperand{text: value})
+		case c == '<' && i+1 < len(stream) && stream[i+1] == '<':
+			i += 2
+		case c == '<':
+			end := bytes.IndexByte(stream[i:], '>')
+			if end < 0 {
+				end = len(stream) - i
+			}
+			operands = append(operands, pdfOperand{text: pdfHexString(stream[i+1 : i+end])})
+			i += end + 1
+		case c == '/':
+			i++
+			for i < len(stream) && !isPDFDelimiter(stream[i]) {
+				i++
+			}
+		default:
+			start := i
+			for i < len(stream) && !isPDFDelimiter(stream[i]) {
+				i++
+			}
+			if i == start {
+				i++
+				continue
+			}
+			token := string(stream[start:i])
+			if number, err := strconv.ParseFloat(token, 64); err == nil {
+				operands = append(operands, pdfOperand{number: number, isNumber: true})
+				continue
+			}
+			inText = showPDFText(token, operands, inText, text)
+			operands = operands[:0]
+		}
+	}
+}
+
+// showPDFText writes what operator shows, it returns whether a text object is still open
+func showPDFText(operator string, operands []pdfOperand, inText bool, text *strings.Builder) bool {
+	switch operator {
+	case "BT":
+		return true
+	case "ET":
+		text.WriteString("\n")
+		return false
+	}
+	if !inText {
+		return false
+	}
+	switch operator {
+	case "Tj", "TJ":
+		for _, operand := range operands {
+			if !operand.isNumber {
+				text.WriteString(operand.text)
+			} else if operand.number < -200 {
+				// a large negative adjustment within TJ is how most generators space words
+				text.WriteString(" ")
+			}
+		}
+	case "'", "\"":
+		text.WriteString("\n")
+		if len(operands) > 0 {
+			text.WriteString(operands[len(operands)-1].text)
+		}
+	case "T*", "Tm":
+		text.WriteString("\n")
+	case "Td", "TD":
+		if len(operands) == 2 && operands[1].isNumber && operands[1].number != 0 {
+			text.WriteString("\n")
+		} else {
+			text.WriteString(" ")
+		}
+	}
+	return true
+}
+
+func isPDFDelimiter(c byte) bool {
+	return strings.IndexByte(" \t\r\n\f\x00()<>[]{}/%", c) >= 0
+}
+
+// pdfLiteralString returns the (possibly nested) literal string starting at i and the index after its closing bracket
+func pdfLiteralString(stream []byte, i int) (string, int) {
+	var value []byte
+	for depth := 1; i < len(stream); i++ {
+		c := stream[i]
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth == 0 {
+				return decodePDFText(value), i + 1
+			}
+		case '\\':
+			if i++; i >= len(stream) {
+				break
+			}
+			switch e := stream[i]; e {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r', '\n':
+				// a line continuation
+				if e == '\r' && i+1 < len(stream) && stream[i+1] == '\n' {
+					i++
+				}
+				continue
+			default:
+				if e < '0' || e > '7' {
+					c = e
+					break
+				}
+				octal := int(e - '0')
+				for digits := 1; digits < 3 && i+1 < len(stream) && stream[i+1] >= '0' && stream[i+1] <= '7'; digits++ {
+					i++
+					octal = octal*8 + int(stream[i]-'0')
+				}
+				c = byte(octal)
+			}
+		}
+		value = append(value, c)
+	}
+	return decodePDFText(value), i
+}
+
+func pdfHexString(hex []byte) string {
+	var digits []byte
+	for _, c := range hex {
+		if unicode.Is(unicode.ASCII_Hex_Digit, rune(c)) {
+			digits = append(digits, c)
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	value := make([]byte, len(digits)/2)
+	for i := range value {
+		b, _ := strconv.ParseUint(string(digits[i*2:i*2+2]), 16, 8)
+		value[i] = byte(b)
+	}
+	return decodePDFText(value)
+}
+
+// decodePDFText decodes UTF-16BE strings (which start with a byte order mark) and treats anything else as Latin-1,
+// which PDFDocEncoding mostly agrees with, dropping control characters
+func decodePDFText(value []byte) string {
+	var runes []rune
+	if len(value) >= 2 && value[0] == 0xFE && value[1] == 0xFF {
+		units := make([]uint16, 0, len(value)/2)
+		for i := 2; i+1 < len(value); i += 2 {
+			units = append(units, uint16(value[i])<<8|uint16(value[i+1]))
+		}
+		runes = utf16.Decode(units)
+	} else {
+		for _, b := range value {
+			runes = append(runes, rune(b))
+		}
+	}
+	result := make([]rune, 0, len(runes))
+	for _, r := range runes {
+		if r == '\t' || !unicode.IsControl(r) {
+			result = append(result, r)
+		}
+	}
+	return string(result)
+}
@@ -0,0 +1,75 @@
+package resource
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PDFTextSuite struct {
+	suite.Suite
+}
+
+// testTextPDF returns a PDF with an uncompressed page and a FlateDecode compressed one
+func (suite *PDFTextSuite) testTextPDF() string {
+	var compressed bytes.Buffer
+	writer := zlib.NewWriter(&compressed)
+	writer.Write([]byte("BT /F1 12 Tf 72 700 Td [(Second)-250(page)] TJ 0 -14 Td (with \\(escaped\\) text) Tj ET"))
+	writer.Close()
+
+	return "%PDF-1.4\n" +
+		"1 0 obj << /Type /Catalog /Pages 2 0 R >> endobj\n" +
+		"4 0 obj << /Length 44 >>\nstream\nBT /F1 12 Tf 72 720 Td (Attention Is All) Tj ( You Need) Tj ET\nendstream\nendobj\n" +
+		"5 0 obj << /Length " + strconv.Itoa(compressed.Len()) + " /Filter /FlateDecode >>\nstream\n" + compressed.String() + "\nendstream\nendobj\n" +
+		"6 0 obj << /Subtype /Image /Filter /DCTDecode >>\nstream\n(Not text) Tj\nendstream\nendobj\n" +
+		"trailer << /Root 1 0 R >>\n%%EOF\n"
+}
+
+func (suite *PDFTextSuite) download(content string, contentType string) (*FileAttachment, error) {
+	u, _ := url.Parse("https://arxiv.org/pdf/1706.03762")
+	t, _ := NewPageType(contentType)
+	resp := &http.Response{Header: make(http.Header), Body: ioutil.NopCloser(strings.NewReader(content)), ContentLength: -1}
+	_, attachment, err := DownloadFileFromHTTPResp(context.Background(), NewMemoryAttachmentCreator(nil), u, resp, t, PDFTextTransformer{})
+	return attachment.(*FileAttachment), err
+}
+
+func (suite *PDFTextSuite) TestExtractText() {
+	suite.Equal("Attention Is All You Need\nSecond page\nwith (escaped) text\n", extractPDFText([]byte(suite.testTextPDF())))
+	suite.Equal("", extractPDFText([]byte(testPDFContent)), "A PDF without content streams has no text")
+	suite.Equal("Hi\né\n", extractPDFText([]byte("stream\nBT <FEFF00480069> Tj (\\351) ' ET\nendstream")), "UTF-16 and octal escapes should be decoded")
+}
+
+func (suite *PDFTextSuite) TestDerivedTextAttachment() {
+	attachment, err := suite.download(suite.testTextPDF(), "application/pdf")
+	suite.Nil(err, "Should not get an error")
+	suite.Require().Len(attachment.Derived, 1)
+
+	text := attachment.Derived[0]
+	suite.Equal("pdf-text", text.Transform)
+	suite.Equal(attachment.DestPath+".txt", text.DestPath)
+	suite.Equal("text/plain", text.Type().MediaType())
+	suite.Equal(10, text.WordCount)
+	reader, err := text.Open()
+	suite.Require().Nil(err)
+	defer reader.Close()
+	content, _ := ioutil.ReadAll(reader)
+	suite.Equal("Attention Is All You Need\nSecond page\nwith (escaped) text\n", string(content))
+}
+
+func (suite *PDFTextSuite) TestOtherAttachmentsAreSkipped() {
+	attachment, err := suite.download("name,value\n", "text/csv")
+	suite.Nil(err, "Should not get an error")
+	suite.Empty(attachment.Derived)
+}
+
+func TestPDFTextSuite(t *testing.T) {
+	suite.Run(t, new(PDFTextSuite))
+}
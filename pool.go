@@ -0,0 +1,56 @@
+package resource
+
+import (
+	"net/http"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+// ConnectionPool is passed into options to tune the connections kept by the factory's built-in HTTP client, it has
+// no effect when an HTTPClientProvider (or client function) supplies the client. The built-in client always shares
+// one transport across requests, using DefaultConnectionPool if no ConnectionPool is passed.
+type ConnectionPool struct {
+	MaxIdleConns        int           // across all hosts, zero means no limit
+	MaxIdleConnsPerHost int           // Go's default of 2 causes connection churn when a batch hits the same host
+	MaxConnsPerHost     int           // including connections in use, zero means no limit
+	IdleConnTimeout     time.Duration // how long an idle connection is kept, zero means forever
+	KeepAlive           time.Duration // TCP keep-alive period, negative disables TCP keep-alives
+	DisableKeepAlives   bool          // if true, connections aren't reused at all
+}
+
+// DefaultConnectionPool returns the pool settings of the built-in HTTP client
+func DefaultConnectionPool() *ConnectionPool {
+	return &ConnectionPool{
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 16,
+		IdleConnTimeout:     90 * time.Second,
+		KeepAlive:           30 * time.Second,
+	}
+}
+
+// NewTransport creates an HTTP/2-capable transport using the pool settings, dialing as dial says (nil means Go's
+// defaults), and otherwise the same defaults as http.DefaultTransport, for anyone building their own http.Client
+func (p *ConnectionPool) NewTransport(dial *DialConfig) *http.Transport {
+	return p.newTransport(dial, SystemClock{})
+}
+
+func (p *ConnectionPool) newTransport(dial *DialConfig, clock Clock) *http.Transport {
+	if dial == nil {
+		dial = new(DialConfig)
+	}
+	result := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dial.dialContext(p.KeepAlive, clock),
+		MaxIdleConns:          p.MaxIdleConns,
+		MaxIdleConnsPerHost:   p.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       p.MaxConnsPerHost,
+		IdleConnTimeout:       p.IdleConnTimeout,
+		DisableKeepAlives:     p.DisableKeepAlives,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+	// a custom DialContext turns off Go's automatic HTTP/2 support so it's configured explicitly
+	http2.ConfigureTransport(result)
+	return result
+}
@@ -0,0 +1,67 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PoolSuite struct {
+	suite.Suite
+}
+
+func (suite *PoolSuite) connectionsFor(options ...interface{}) int32 {
+	var connections int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, testHTMLPage)
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&connections, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	factory := NewFactory(options...)
+	for i := 0; i < 5; i++ {
+		_, err := factory.PageFromURL(context.Background(), server.URL)
+		suite.Nil(err, "Should not get an error")
+	}
+	return atomic.LoadInt32(&connections)
+}
+
+func (suite *PoolSuite) TestConnectionsAreReused() {
+	suite.Equal(int32(1), suite.connectionsFor())
+}
+
+func (suite *PoolSuite) TestDisableKeepAlives() {
+	pool := DefaultConnectionPool()
+	pool.DisableKeepAlives = true
+	suite.Equal(int32(5), suite.connectionsFor(pool))
+}
+
+func (suite *PoolSuite) TestConfiguredPool() {
+	config := FactoryConfig{MaxConnsPerHost: 4}
+	suite.Nil(config.ApplyEnv(func(name string) (string, bool) {
+		if name == ConfigEnvPrefix+"MAX_IDLE_CONNS_PER_HOST" {
+			return "32", true
+		}
+		return "", false
+	}))
+	pool := config.ConnectionPool()
+	suite.Equal(4, pool.MaxConnsPerHost)
+	suite.Equal(32, pool.MaxIdleConnsPerHost)
+	suite.Equal(DefaultConnectionPool().IdleConnTimeout, pool.IdleConnTimeout)
+}
+
+func TestPoolSuite(t *testing.T) {
+	suite.Run(t, new(PoolSuite))
+}
@@ -0,0 +1,23 @@
+package resource
+
+import "time"
+
+// PreviewProfile returns the options of a factory optimized for latency, such as a chat or CMS link unfurler:
+// only the <head> of the first 256 KiB of HTML is read, requests time out after 1 to 5 seconds and are hedged after
+// 1 second, and attachments are previewed (their first 64 KiB) into memory. A CacheProvider only keeps responses
+// which were read to the end, so it won't help with the HTML previews.
+func PreviewProfile() []interface{} {
+	return []interface{}{
+		HTMLBodyLimit(256 * 1024),
+		HTMLHeadOnly(true),
+		NewAdaptiveTimeouts(time.Second, 5*time.Second),
+		HedgeAfter(time.Second),
+		NewMemoryAttachmentCreator(nil),
+		AttachmentPreviewSize(64 * 1024),
+	}
+}
+
+// NewPreviewFactory creates a factory with the PreviewProfile, options override the profile's choices
+func NewPreviewFactory(options ...interface{}) *DefaultFactory {
+	return NewFactory(append(PreviewProfile(), options...)...)
+}
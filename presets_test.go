@@ -0,0 +1,58 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PresetsSuite struct {
+	suite.Suite
+}
+
+func (suite *PresetsSuite) TestHeadOnlyReader() {
+	page := `<html><head><title>x</title></HEAD><body>` + strings.Repeat("body ", 1000) + `</body></html>`
+	data, err := ioutil.ReadAll(&headOnlyReader{r: iotest.OneByteReader(strings.NewReader(page))})
+	suite.Nil(err, "Should not get an error")
+	suite.Equal(`<html><head><title>x</title></HEAD>`, string(data), "Tag split across reads should be found")
+}
+
+func (suite *PresetsSuite) TestPreviewFactory() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/paper.pdf" {
+			w.Header().Set("Content-Type", "application/pdf")
+			io.WriteString(w, testPDFContent)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><head><meta property="og:title" content="Preview"></head><body>`)
+		fmt.Fprint(w, strings.Repeat(`<meta property="og:title" content="Body">`, 10000))
+		fmt.Fprint(w, `</body></html>`)
+	}))
+	defer server.Close()
+
+	factory := NewPreviewFactory()
+	content, err := factory.PageFromURL(context.Background(), server.URL)
+	suite.Nil(err, "Should not get an error")
+	values, _, _ := content.MetaTagAll("og:title")
+	suite.Equal([]interface{}{"Preview"}, values, "Reading should stop at </head>")
+
+	content, err = factory.PageFromURL(context.Background(), server.URL+"/paper.pdf")
+	suite.Nil(err, "Should not get an error")
+	attachment := content.Attachment().(*FileAttachment)
+	suite.True(attachment.Preview, "Attachments should be previewed")
+	_, isMemory := attachment.DestFS.(interface{ List() })
+	suite.True(isMemory, "Attachments should be kept in memory")
+}
+
+func TestPresetsSuite(t *testing.T) {
+	suite.Run(t, new(PresetsSuite))
+}
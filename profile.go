@@ -0,0 +1,268 @@
+package resource
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// DatasetProfile is a lightweight schema hint for a tabular attachment such as a CSV or XLSX file
+type DatasetProfile struct {
+	Format    string   `json:"format"`              // "csv" or "xlsx"
+	Columns   []string `json:"columns"`             // the values of the first (header) row
+	RowCount  int      `json:"rowCount"`            // number of rows after the header row
+	Delimiter string   `json:"delimiter,omitempty"` // the field separator of CSV files
+	Sheet     string   `json:"sheet,omitempty"`     // the name of the profiled (first) worksheet of XLSX files
+}
+
+// AttachmentProfiler is passed into options if we want downloaded attachments profiled. A nil profile means the
+// profiler doesn't handle the attachment's type. Profiling errors don't invalidate the download.
+type AttachmentProfiler interface {
+	ProfileAttachment(ctx context.Context, url *url.URL, attachment *FileAttachment) (*DatasetProfile, error)
+}
+
+// TabularAttachmentProfiler is an AttachmentProfiler for CSV, TSV and XLSX attachments
+type TabularAttachmentProfiler struct{}
+
+// ProfileAttachment satisfies AttachmentProfiler method
+func (p TabularAttachmentProfiler) ProfileAttachment(ctx context.Context, url *url.URL, attachment *FileAttachment) (*DatasetProfile, error) {
+	var mediaType string
+	if attachment.ContentType != nil {
+		mediaType = attachment.ContentType.MediaType()
+	}
+	ext := strings.ToLower(path.Ext(attachment.DestPath))
+
+	switch {
+	case attachment.FileType.Extension == "xlsx" || ext == ".xlsx" || mediaType == "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":
+		return profileXLSX(attachment)
+	case ext == ".tsv" || mediaType == "text/tab-separated-values":
+		return profileCSV(attachment, '\t')
+	case ext == ".csv" || mediaType == "text/csv" || mediaType == "application/csv":
+		return profileCSV(attachment, 0)
+	}
+	return nil, nil
+}
+
+// csvDelimiters are the candidates tried when a CSV file's delimiter isn't known
+var csvDelimiters = []rune{',', ';', '\t', '|'}
+
+// profileCSV counts the rows of a delimited file, a zero delimiter is sniffed from the header row
+func profileCSV(attachment *FileAttachment, delimiter rune) (*DatasetProfile, error) {
+	file, err := attachment.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	if delimiter == 0 {
+		header, _ := reader.Peek(4096)
+		delimiter = sniffCSVDelimiter(string(header))
+	}
+
+	records := csv.NewReader(reader)
+	records.Comma = delimiter
+	records.FieldsPerRecord = -1
+	records.LazyQuotes = true
+	result := &DatasetProfile{Format: "csv", Delimiter: string(delimiter)}
+	for {
+		record, err := records.Read()
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return result, xerrors.Errorf("Unable to profile CSV attachment %q: %w", attachment.DestPath, err)
+		}
+		if result.Columns == nil {
+			result.Columns = record
+			continue
+		}
+		result.RowCount++
+	}
+}
+
+// sniffCSVDelimiter returns the candidate delimiter which occurs most often, outside of quotes, in the first line
+func sniffCSVDelimiter(sample string) rune {
+	if index := strings.IndexAny(sample, "\r\n"); index >= 0 {
+		sample = sample[:index]
+	}
+	counts := make(map[rune]int)
+	inQuotes := false
+	for _, r := range sample {
+		if r == '"' {
+			inQuotes = !inQuotes
+		} else if !inQuotes {
+			counts[r]++
+		}
+	}
+	result := csvDelimiters[0]
+	for _, candidate := range csvDelimiters[1:] {
+		if counts[candidate] > counts[result] {
+			result = candidate
+		}
+	}
+	return result
+}
+
+// profileXLSX reads the header row and counts the rows of the first worksheet of an Office Open XML workbook
+func profileXLSX(attachment *FileAttachment) (*DatasetProfile, error) {
+	file, err := attachment.DestFS.Open(attachment.DestPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	archive, err := zip.NewReader(file, info.Size())
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to open XLSX attachment %q: %w", attachment.DestPath, err)
+	}
+	files := make(map[string]*zip.File)
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+
+	var workbook struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			ID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := decodeZipXML(files, "xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	}
+	if len(workbook.Sheets) == 0 {
+		return nil, fmt.Errorf("XLSX attachment %q has no worksheets", attachment.DestPath)
+	}
+
+	var relationships struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	sheetPath := "xl/worksheets/sheet1.xml"
+	if decodeZipXML(files, "xl/_rels/workbook.xml.rels", &relationships) == nil {
+		for _, relationship := range relationships.Relationships {
+			if relationship.ID == workbook.Sheets[0].ID {
+				if strings.HasPrefix(relationship.Target, "/") {
+					sheetPath = strings.TrimPrefix(relationship.Target, "/")
+				} else {
+					sheetPath = path.Join("xl", relationship.Target)
+				}
+			}
+		}
+	}
+
+	var sharedStrings struct {
+		Items []struct {
+			Text string `xml:"t"`
+			Runs []struct {
+				Text string `xml:"t"`
+			} `xml:"r"`
+		} `xml:"si"`
+	}
+	decodeZipXML(files, "xl/sharedStrings.xml", &sharedStrings)
+	stringAt := func(index string) string {
+		i, err := strconv.Atoi(index)
+		if err != nil || i < 0 || i >= len(sharedStrings.Items) {
+			return ""
+		}
+		item := sharedStrings.Items[i]
+		if len(item.Runs) == 0 {
+			return item.Text
+		}
+		var text strings.Builder
+		for _, run := range item.Runs {
+			text.WriteString(run.Text)
+		}
+		return text.String()
+	}
+
+	sheet, ok := files[sheetPath]
+	if !ok {
+		return nil, fmt.Errorf("XLSX attachment %q is missing worksheet %q", attachment.DestPath, sheetPath)
+	}
+	reader, err := sheet.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	// worksheets can be large so they're streamed, only the cells of the first row are decoded
+	result := &DatasetProfile{Format: "xlsx", Sheet: workbook.Sheets[0].Name}
+	rows := 0
+	decoder := xml.NewDecoder(reader)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return result, xerrors.Errorf("Unable to profile XLSX attachment %q: %w", attachment.DestPath, err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+		rows++
+		if rows > 1 {
+			continue
+		}
+		var row struct {
+			Cells []struct {
+				Type   string `xml:"t,attr"`
+				Value  string `xml:"v"`
+				Inline string `xml:"is>t"`
+			} `xml:"c"`
+		}
+		if err := decoder.DecodeElement(&row, &start); err != nil {
+			return result, xerrors.Errorf("Unable to profile XLSX attachment %q: %w", attachment.DestPath, err)
+		}
+		result.Columns = []string{}
+		for _, cell := range row.Cells {
+			switch cell.Type {
+			case "s":
+				result.Columns = append(result.Columns, stringAt(cell.Value))
+			case "inlineStr":
+				result.Columns = append(result.Columns, cell.Inline)
+			default:
+				result.Columns = append(result.Columns, cell.Value)
+			}
+		}
+	}
+	if rows > 0 {
+		result.RowCount = rows - 1
+	}
+	return result, nil
+}
+
+func decodeZipXML(files map[string]*zip.File, name string, v interface{}) error {
+	f, ok := files[name]
+	if !ok {
+		return fmt.Errorf("%q not found in archive", name)
+	}
+	reader, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	return xml.NewDecoder(reader).Decode(v)
+}
+
+func attachmentProfiler(creator FileAttachmentCreator, options []interface{}) AttachmentProfiler {
+	result, _ := OptionOf[AttachmentProfiler](append([]interface{}{creator}, options...))
+	return result
+}
@@ -0,0 +1,73 @@
+package resource
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ProfileSuite struct {
+	suite.Suite
+}
+
+func (suite *ProfileSuite) download(urlText string, contentType string, body []byte) *FileAttachment {
+	u, _ := url.Parse(urlText)
+	t, _ := NewPageType(contentType)
+	resp := &http.Response{Header: make(http.Header), Body: ioutil.NopCloser(bytes.NewReader(body)), ContentLength: -1}
+	_, attachment, err := DownloadFileFromHTTPResp(context.Background(), NewMemoryAttachmentCreator(URLPathNamingStrategy{}), u, resp, t, TabularAttachmentProfiler{})
+	suite.Nil(err, "Should not get an error")
+	return attachment.(*FileAttachment)
+}
+
+func (suite *ProfileSuite) TestCSV() {
+	attachment := suite.download("https://data.example.com/cities.csv", "text/csv",
+		[]byte("name;\"population; 2019\";country\nParis;2148000;FR\nBerlin;3645000;DE\n"))
+	suite.NotNil(attachment.Profile, "CSV should be profiled")
+	suite.Equal("csv", attachment.Profile.Format)
+	suite.Equal(";", attachment.Profile.Delimiter, "Delimiter should be sniffed outside of quotes")
+	suite.Equal([]string{"name", "population; 2019", "country"}, attachment.Profile.Columns)
+	suite.Equal(2, attachment.Profile.RowCount)
+}
+
+func (suite *ProfileSuite) TestXLSX() {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, content := range map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Cities" sheetId="1" r:id="rId7"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId7" Type="worksheet" Target="worksheets/data.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<sst><si><t>name</t></si><si><r><t>popu</t></r><r><t>lation</t></r></si></sst>`,
+		"xl/worksheets/data.xml": `<worksheet><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="inlineStr"><is><t>country</t></is></c></row>` +
+			`<row r="2"><c r="A2" t="inlineStr"><is><t>Paris</t></is></c><c r="B2"><v>2148000</v></c></row>` +
+			`</sheetData></worksheet>`,
+	} {
+		w, _ := archive.Create(name)
+		w.Write([]byte(content))
+	}
+	archive.Close()
+
+	attachment := suite.download("https://data.example.com/cities.xlsx", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", buf.Bytes())
+	suite.NotNil(attachment.Profile, "XLSX should be profiled")
+	suite.Equal("xlsx", attachment.Profile.Format)
+	suite.Equal("Cities", attachment.Profile.Sheet)
+	suite.Equal([]string{"name", "population", "country"}, attachment.Profile.Columns)
+	suite.Equal(1, attachment.Profile.RowCount)
+}
+
+func (suite *ProfileSuite) TestOtherTypesAreNotProfiled() {
+	attachment := suite.download("http://ceur-ws.org/Vol-1401/paper-05.pdf", "application/pdf", []byte(testPDFContent))
+	suite.True(attachment.Valid, "Download should be valid")
+	suite.Nil(attachment.Profile, "PDF should not be profiled")
+}
+
+func TestProfileSuite(t *testing.T) {
+	suite.Run(t, new(ProfileSuite))
+}
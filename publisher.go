@@ -0,0 +1,61 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+
+	"golang.org/x/xerrors"
+)
+
+// Publisher is the part of a message broker client needed to publish harvesting results, e.g. a NATS connection or
+// a Kafka producer. This package doesn't depend on any broker, a few lines adapt the client:
+//
+//	resource.PublisherFunc(func(ctx context.Context, topic, key string, value []byte) error {
+//	    return natsConn.Publish(topic, value)
+//	})
+//
+// The key is the URL that was asked for, so Kafka keeps the messages about a URL in one partition.
+type Publisher interface {
+	Publish(ctx context.Context, topic string, key string, value []byte) error
+}
+
+// PublisherFunc is a function which satisfies Publisher
+type PublisherFunc func(ctx context.Context, topic string, key string, value []byte) error
+
+// Publish satisfies Publisher method
+func (fn PublisherFunc) Publish(ctx context.Context, topic string, key string, value []byte) error {
+	return fn(ctx, topic, key, value)
+}
+
+// PublishingEventSink is an EventSink which publishes each event, with its Page snapshot, as JSON. The topic is
+// TopicPrefix followed by the event type, e.g. "lectio.page-resolved", unless Topics maps the type to another topic.
+// Event types with an empty topic in Topics aren't published.
+type PublishingEventSink struct {
+	Publisher   Publisher
+	TopicPrefix string
+	Topics      map[string]string
+}
+
+// NewPublishingEventSink creates an EventSink which publishes to topics named topicPrefix + event type
+func NewPublishingEventSink(publisher Publisher, topicPrefix string) *PublishingEventSink {
+	return &PublishingEventSink{Publisher: publisher, TopicPrefix: topicPrefix}
+}
+
+// HandleEvent satisfies EventSink method
+func (s *PublishingEventSink) HandleEvent(ctx context.Context, event Event) error {
+	topic, ok := s.Topics[event.Type]
+	if !ok {
+		topic = s.TopicPrefix + event.Type
+	}
+	if len(topic) == 0 {
+		return nil
+	}
+	value, err := json.Marshal(event)
+	if err != nil {
+		return xerrors.Errorf("Unable to encode %s event for %q: %w", event.Type, event.URL, err)
+	}
+	if err := s.Publisher.Publish(ctx, topic, event.URL, value); err != nil {
+		return xerrors.Errorf("Unable to publish %s event for %q to %q: %w", event.Type, event.URL, topic, err)
+	}
+	return nil
+}
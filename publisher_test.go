@@ -0,0 +1,51 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PublisherSuite struct {
+	suite.Suite
+}
+
+type publishedMessage struct {
+	topic string
+	key   string
+	value []byte
+}
+
+func (suite *PublisherSuite) TestPublishingEventSink() {
+	var messages []publishedMessage
+	publisher := PublisherFunc(func(ctx context.Context, topic string, key string, value []byte) error {
+		messages = append(messages, publishedMessage{topic, key, value})
+		return nil
+	})
+	sink := NewPublishingEventSink(publisher, "lectio.")
+	sink.Topics = map[string]string{EventRedirectDetected: ""}
+
+	archive := NewMemoryResponseArchive()
+	archive.Add("http://example.com/old", archivedResponse(301, http.Header{"Location": {"/"}}, ""))
+	archive.Add("http://example.com/", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, testHTMLPage))
+	_, err := NewFactory(archive, sink).PageFromURL(context.Background(), "http://example.com/old")
+	suite.Nil(err, "Should not get an error")
+
+	suite.Len(messages, 1, "Redirect events should not be published")
+	suite.Equal("lectio.page-resolved", messages[0].topic)
+	suite.Equal("http://example.com/old", messages[0].key)
+	var event struct {
+		Page struct {
+			MetaPropertyTags map[string]interface{} `json:"metaPropertyTags"`
+		} `json:"page"`
+	}
+	suite.Nil(json.Unmarshal(messages[0].value, &event))
+	suite.Equal("Netspective", event.Page.MetaPropertyTags["og:site_name"])
+}
+
+func TestPublisherSuite(t *testing.T) {
+	suite.Run(t, new(PublisherSuite))
+}
@@ -0,0 +1,65 @@
+package resource
+
+import (
+	"context"
+	"net/http"
+
+	"golang.org/x/xerrors"
+)
+
+// RedirectPolicy is passed into options if HTTP redirects should be checked before they're followed, it's given the
+// request for the redirect and the requests made so far, oldest first, and returns an error to stop following them.
+// The HTTP client's own CheckRedirect, if it has one, is still called after the policy; if it has none, the policy
+// replaces Go's default limit of 10 redirects.
+type RedirectPolicy interface {
+	CheckRedirect(ctx context.Context, req *http.Request, via []*http.Request) error
+}
+
+// RedirectPolicyFunc is a function which satisfies RedirectPolicy
+type RedirectPolicyFunc func(ctx context.Context, req *http.Request, via []*http.Request) error
+
+// CheckRedirect satisfies RedirectPolicy by calling fn
+func (fn RedirectPolicyFunc) CheckRedirect(ctx context.Context, req *http.Request, via []*http.Request) error {
+	return fn(ctx, req, via)
+}
+
+// MaxRedirects is a RedirectPolicy which follows at most that many redirects, zero means none are followed
+type MaxRedirects int
+
+// CheckRedirect satisfies RedirectPolicy
+func (m MaxRedirects) CheckRedirect(ctx context.Context, req *http.Request, via []*http.Request) error {
+	if len(via) > int(m) {
+		return xerrors.Errorf("stopped after %d redirects", m)
+	}
+	return nil
+}
+
+// SameHostRedirects is a RedirectPolicy which, when true, only follows redirects to the host of the original request
+type SameHostRedirects bool
+
+// CheckRedirect satisfies RedirectPolicy
+func (s SameHostRedirects) CheckRedirect(ctx context.Context, req *http.Request, via []*http.Request) error {
+	if bool(s) && len(via) > 0 && req.URL.Host != via[0].URL.Host {
+		return xerrors.Errorf("redirect from %s to another host %s isn't allowed", via[0].URL.Host, req.URL.Host)
+	}
+	return nil
+}
+
+// redirectingClient returns a copy of client which checks redirects with the factory's RedirectPolicy
+func (f *DefaultFactory) redirectingClient(ctx context.Context, client *http.Client) *http.Client {
+	if f.RedirectPolicy == nil || client == nil {
+		return client
+	}
+	next := client.CheckRedirect
+	result := *client
+	result.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if err := callPolicy("RedirectPolicy", func() error { return f.RedirectPolicy.CheckRedirect(ctx, req, via) }); err != nil {
+			return err
+		}
+		if next != nil {
+			return next(req, via)
+		}
+		return nil
+	}
+	return &result
+}
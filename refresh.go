@@ -0,0 +1,72 @@
+package resource
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseRefreshContent parses the value of a <meta http-equiv="refresh"> content attribute or a Refresh response
+// header, such as "2;url=https://www.google.com". It follows the HTML standard's declarative refresh steps but is more
+// tolerant of what's actually published: a missing delay, "," instead of ";", any case of "url", whitespace around any
+// of the parts, quoted URLs, and the URL given without "url=". False is returned when there's no URL, since that
+// refreshes the page itself rather than redirecting.
+func parseRefreshContent(value string) (delayText string, urlText string, ok bool) {
+	rest := strings.TrimLeft(value, " \t\n\f\r")
+
+	// the delay is whole or fractional seconds, browsers ignore what's after the point
+	end := 0
+	for end < len(rest) && (rest[end] >= '0' && rest[end] <= '9' || rest[end] == '.') {
+		end++
+	}
+	delayText, rest = rest[:end], rest[end:]
+
+	rest = strings.TrimLeft(rest, " \t\n\f\r")
+	if len(rest) > 0 && (rest[0] == ';' || rest[0] == ',') {
+		rest = strings.TrimLeft(rest[1:], " \t\n\f\r")
+	} else if len(delayText) > 0 && len(rest) > 0 && !hasURLKey(rest) {
+		// anything but a separator after the delay, like "5 seconds", isn't a refresh browsers follow
+		return "", "", false
+	}
+
+	if hasURLKey(rest) {
+		afterKey := strings.TrimLeft(rest[3:], " \t\n\f\r")
+		if len(afterKey) > 0 && afterKey[0] == '=' {
+			rest = strings.TrimLeft(afterKey[1:], " \t\n\f\r")
+		}
+	}
+	if len(rest) > 0 && (rest[0] == '\'' || rest[0] == '"') {
+		quote := rest[0]
+		rest = rest[1:]
+		if closing := strings.IndexByte(rest, quote); closing >= 0 {
+			rest = rest[:closing]
+		}
+	}
+
+	urlText = strings.TrimSpace(rest)
+	if len(urlText) == 0 {
+		return delayText, "", false
+	}
+	if len(delayText) == 0 {
+		delayText = "0"
+	}
+	return delayText, urlText, true
+}
+
+// hasURLKey returns true if text starts with "url", in any case, followed by "="
+func hasURLKey(text string) bool {
+	if len(text) < 3 || !strings.EqualFold(text[:3], "url") {
+		return false
+	}
+	afterKey := strings.TrimLeft(text[3:], " \t\n\f\r")
+	return len(afterKey) > 0 && afterKey[0] == '='
+}
+
+// refreshDelay converts the delay of a refresh into a duration, it's in seconds and may be fractional
+func refreshDelay(delayText string) time.Duration {
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(delayText), 64)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
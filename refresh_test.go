@@ -0,0 +1,58 @@
+package resource
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RefreshSuite struct {
+	suite.Suite
+}
+
+func (suite *RefreshSuite) TestObservedRefreshValues() {
+	tests := []struct {
+		value   string
+		delay   string
+		urlText string
+	}{
+		{"2;url=https://www.google.com", "2", "https://www.google.com"},
+		{"0;URL=https://example.com/", "0", "https://example.com/"},
+		{"10; url=https://example.com/", "10", "https://example.com/"},
+		{"300 ; URL = https://example.com/a?b=c", "300", "https://example.com/a?b=c"},
+		{"0;url='https://example.com/quoted'", "0", "https://example.com/quoted"},
+		{`0; url="https://example.com/double"`, "0", "https://example.com/double"},
+		{"0; url='https://example.com/unterminated", "0", "https://example.com/unterminated"},
+		{"  5,url=/relative/path  ", "5", "/relative/path"},
+		{"0; https://example.com/no-key", "0", "https://example.com/no-key"},
+		{"0.5;url=https://example.com/fraction", "0.5", "https://example.com/fraction"},
+		{"1\n;\turl=https://example.com/whitespace", "1", "https://example.com/whitespace"},
+		{"url=https://example.com/no-delay", "0", "https://example.com/no-delay"},
+		{"0;Url=https://example.com/?url=nested", "0", "https://example.com/?url=nested"},
+		{"0 url=https://example.com/no-separator", "0", "https://example.com/no-separator"},
+	}
+	for _, test := range tests {
+		delay, urlText, ok := parseRefreshContent(test.value)
+		suite.True(ok, test.value)
+		suite.Equal(test.delay, delay, test.value)
+		suite.Equal(test.urlText, urlText, test.value)
+	}
+}
+
+func (suite *RefreshSuite) TestNotRedirects() {
+	for _, value := range []string{"", "30", "30;", "0; url=", "5 seconds then https://example.com/", "0; url=''"} {
+		_, _, ok := parseRefreshContent(value)
+		suite.False(ok, value)
+	}
+}
+
+func (suite *RefreshSuite) TestRefreshDelay() {
+	suite.Equal(2*time.Second, refreshDelay("2"))
+	suite.Equal(1500*time.Millisecond, refreshDelay("1.5"))
+	suite.Equal(time.Duration(0), refreshDelay("1.2.3"))
+}
+
+func TestRefreshSuite(t *testing.T) {
+	suite.Run(t, new(RefreshSuite))
+}
@@ -0,0 +1,141 @@
+package resource
+
+import (
+	"context"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/spf13/afero"
+	"golang.org/x/xerrors"
+)
+
+// RetentionPolicy limits what's kept in an attachment store so long-running harvesters don't fill the disk, zero
+// values don't limit
+type RetentionPolicy struct {
+	MaxAge       time.Duration // files last used longer ago than this are removed
+	MaxTotalSize int64         // the least recently used files are removed until the rest add up to no more than this
+	MaxFiles     int           // the least recently used files are removed until there are no more than this
+}
+
+// CollectedFile is a file removed by an AttachmentCollector
+type CollectedFile struct {
+	Path     string    `json:"path"`
+	Size     int64     `json:"size"`
+	LastUsed time.Time `json:"lastUsed"`
+	Reason   string    `json:"reason"` // "max age", "max total size" or "max files"
+}
+
+// AttachmentCollector garbage collects the files of an attachment store according to its RetentionPolicy. A file was
+// last used when it was last modified (i.e. downloaded) or Touched, whichever is later, so readers should Touch the
+// attachments they use to keep them from being collected first.
+type AttachmentCollector struct {
+	Policy RetentionPolicy
+	Clock  Clock // the system clock if nil
+
+	fs    afero.Fs
+	dir   string
+	mutex sync.Mutex
+	used  map[string]time.Time
+}
+
+// NewAttachmentCollector creates a collector for the files under where location (e.g. a
+// FileSystemAttachmentCreator) creates them
+func NewAttachmentCollector(location AttachmentLocation, policy RetentionPolicy) *AttachmentCollector {
+	fs, dir := location.AttachmentLocation()
+	return &AttachmentCollector{Policy: policy, fs: fs, dir: dir, used: make(map[string]time.Time)}
+}
+
+// Touch records that the file at path, e.g. a FileAttachment's DestPath, was just used
+func (c *AttachmentCollector) Touch(path string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.used[path] = c.clock().Now()
+}
+
+// Collect removes the files which are too old, then the least recently used until the store is within its limits,
+// and returns what was removed. Files which can't be removed are left in place and the first such error is returned.
+func (c *AttachmentCollector) Collect(ctx context.Context) ([]CollectedFile, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var files []CollectedFile
+	var totalSize int64
+	err := afero.Walk(c.fs, c.dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if info.IsDir() {
+			return nil
+		}
+		lastUsed := info.ModTime()
+		if touched, ok := c.used[path]; ok && touched.After(lastUsed) {
+			lastUsed = touched
+		}
+		files = append(files, CollectedFile{Path: path, Size: info.Size(), LastUsed: lastUsed})
+		totalSize += info.Size()
+		return nil
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to walk attachment store %q in resource.AttachmentCollector: %w", c.dir, err)
+	}
+	sort.SliceStable(files, func(i, j int) bool { return files[i].LastUsed.Before(files[j].LastUsed) })
+
+	now := c.clock().Now()
+	var removed []CollectedFile
+	var result error
+	count := len(files)
+	for _, file := range files {
+		switch {
+		case c.Policy.MaxAge > 0 && now.Sub(file.LastUsed) > c.Policy.MaxAge:
+			file.Reason = "max age"
+		case c.Policy.MaxTotalSize > 0 && totalSize > c.Policy.MaxTotalSize:
+			file.Reason = "max total size"
+		case c.Policy.MaxFiles > 0 && count > c.Policy.MaxFiles:
+			file.Reason = "max files"
+		default:
+			// files are sorted least recently used first so the rest are within every limit
+			return removed, result
+		}
+		if err := removeTrackedFile(c.fs, file.Path); err != nil {
+			if result == nil {
+				result = xerrors.Errorf("Unable to remove %q in resource.AttachmentCollector: %w", file.Path, err)
+			}
+			continue
+		}
+		delete(c.used, file.Path)
+		totalSize -= file.Size
+		count--
+		removed = append(removed, file)
+	}
+	return removed, result
+}
+
+// Run collects every interval until ctx is done, calling onCollect (if it isn't nil) with the results of each
+// collection. It's meant to be run in its own goroutine.
+func (c *AttachmentCollector) Run(ctx context.Context, interval time.Duration, onCollect func([]CollectedFile, error)) {
+	for {
+		timer := c.clock().NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C():
+		}
+		removed, err := c.Collect(ctx)
+		if onCollect != nil {
+			onCollect(removed, err)
+		}
+	}
+}
+
+func (c *AttachmentCollector) clock() Clock {
+	if c.Clock != nil {
+		return c.Clock
+	}
+	return SystemClock{}
+}
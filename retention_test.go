@@ -0,0 +1,108 @@
+package resource
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/suite"
+)
+
+type RetentionSuite struct {
+	suite.Suite
+	clock   *ManualClock
+	creator *FileSystemAttachmentCreator
+}
+
+func (suite *RetentionSuite) SetupTest() {
+	suite.clock = NewManualClock(time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC))
+	suite.creator = NewMemoryAttachmentCreator(nil)
+}
+
+// store writes a file of size bytes which was downloaded age ago
+func (suite *RetentionSuite) store(path string, size int, age time.Duration) {
+	afero.WriteFile(suite.creator.FS, path, []byte(strings.Repeat("x", size)), 0644)
+	modified := suite.clock.Now().Add(-age)
+	suite.creator.FS.Chtimes(path, modified, modified)
+}
+
+func (suite *RetentionSuite) collector(policy RetentionPolicy) *AttachmentCollector {
+	collector := NewAttachmentCollector(suite.creator, policy)
+	collector.Clock = suite.clock
+	return collector
+}
+
+func (suite *RetentionSuite) paths(files []CollectedFile) []string {
+	var result []string
+	for _, file := range files {
+		result = append(result, file.Path+" "+file.Reason)
+	}
+	return result
+}
+
+func (suite *RetentionSuite) exists(path string) bool {
+	exists, _ := afero.Exists(suite.creator.FS, path)
+	return exists
+}
+
+func (suite *RetentionSuite) TestMaxAge() {
+	suite.store("old.pdf", 10, 48*time.Hour)
+	suite.store("new.pdf", 10, time.Hour)
+	removed, err := suite.collector(RetentionPolicy{MaxAge: 24 * time.Hour}).Collect(context.Background())
+	suite.Nil(err, "Should not get an error")
+	suite.Equal([]string{"old.pdf max age"}, suite.paths(removed))
+	suite.Equal(int64(10), removed[0].Size)
+	suite.False(suite.exists("old.pdf"))
+	suite.True(suite.exists("new.pdf"))
+}
+
+func (suite *RetentionSuite) TestLeastRecentlyUsedAreRemovedFirst() {
+	suite.store("a.pdf", 100, 3*time.Hour)
+	suite.store("b.pdf", 100, 2*time.Hour)
+	suite.store("c.pdf", 100, time.Hour)
+	collector := suite.collector(RetentionPolicy{MaxTotalSize: 250})
+	collector.Touch("a.pdf")
+
+	removed, err := collector.Collect(context.Background())
+	suite.Nil(err, "Should not get an error")
+	suite.Equal([]string{"b.pdf max total size"}, suite.paths(removed), "a.pdf was used more recently than b.pdf")
+
+	collector.Policy = RetentionPolicy{MaxFiles: 1}
+	removed, _ = collector.Collect(context.Background())
+	suite.Equal([]string{"c.pdf max files"}, suite.paths(removed))
+	suite.True(suite.exists("a.pdf"))
+}
+
+func (suite *RetentionSuite) TestNoPolicyKeepsEverything() {
+	suite.store("a.pdf", 100, 365*24*time.Hour)
+	removed, err := suite.collector(RetentionPolicy{}).Collect(context.Background())
+	suite.Nil(err, "Should not get an error")
+	suite.Empty(removed)
+}
+
+func (suite *RetentionSuite) TestRun() {
+	suite.store("old.pdf", 10, 48*time.Hour)
+	collector := suite.collector(RetentionPolicy{MaxAge: 24 * time.Hour})
+	ctx, cancel := context.WithCancel(context.Background())
+	collected := make(chan []CollectedFile)
+	done := make(chan struct{})
+	go func() {
+		collector.Run(ctx, time.Hour, func(removed []CollectedFile, err error) { collected <- removed })
+		close(done)
+	}()
+
+	for suite.clock.Timers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	suite.clock.Advance(time.Hour)
+	suite.Equal([]string{"old.pdf max age"}, suite.paths(<-collected))
+
+	cancel()
+	<-done
+}
+
+func TestRetentionSuite(t *testing.T) {
+	suite.Run(t, new(RetentionSuite))
+}
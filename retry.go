@@ -0,0 +1,191 @@
+package resource
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// RetryPolicy is passed into options if failed fetches should be tried again. RetryDelay is called after each failed
+// attempt, attempt is 1 after the first, and returns how long to wait before the next attempt or false to give up
+// and return err. The status of a failed response is in err as an InvalidHTTPRespStatusCodeError.
+type RetryPolicy interface {
+	RetryDelay(ctx context.Context, url *url.URL, attempt int, err error) (time.Duration, bool)
+}
+
+// DefaultRetryableStatusCodes are the HTTP statuses ExponentialBackoff retries if it has no RetryableStatusCodes
+var DefaultRetryableStatusCodes = []int{
+	http.StatusRequestTimeout,
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// DefaultRetryableErrorCodes are the error codes ExponentialBackoff retries if it has no RetryableErrorCodes
+var DefaultRetryableErrorCodes = []int{ErrorCodeTimeout, ErrorCodeConnection}
+
+// ExponentialBackoff is a RetryPolicy which waits Initial after the first failure and Multiplier times longer after
+// each one after that, up to Max. Each delay is randomly shortened by up to Jitter of itself so that clients which
+// failed together don't retry together. A Retry-After header on a retried status is obeyed, unless it's longer than
+// Max. Zero fields have the defaults given below.
+type ExponentialBackoff struct {
+	MaxAttempts          int           // including the first, 3 by default
+	Initial              time.Duration // 500ms by default
+	Max                  time.Duration // 30s by default
+	Multiplier           float64       // 2 by default
+	Jitter               float64       // the fraction of each delay which is random, from 0 (the default) to 1
+	RetryableStatusCodes []int         // DefaultRetryableStatusCodes by default
+	RetryableErrorCodes  []int         // error codes (see CodeOf) of failed requests, DefaultRetryableErrorCodes by default
+	Random               RandomSource  // for the jitter, the factory's RandomSource (or SystemRandom) by default
+	Clock                Clock         // for Retry-After dates, the factory's Clock (or SystemClock) by default
+}
+
+// RetryDelay satisfies RetryPolicy method
+func (b ExponentialBackoff) RetryDelay(ctx context.Context, url *url.URL, attempt int, err error) (time.Duration, bool) {
+	maxAttempts := b.MaxAttempts
+	if maxAttempts == 0 {
+		maxAttempts = 3
+	}
+	if attempt >= maxAttempts || !b.Retryable(err) {
+		return 0, false
+	}
+
+	initial, max, multiplier := b.Initial, b.Max, b.Multiplier
+	if initial <= 0 {
+		initial = 500 * time.Millisecond
+	}
+	if max <= 0 {
+		max = 30 * time.Second
+	}
+	if multiplier < 1 {
+		multiplier = 2
+	}
+	delay := max
+	if backoff := float64(initial) * math.Pow(multiplier, float64(attempt-1)); backoff < float64(max) {
+		delay = time.Duration(backoff)
+	}
+	if jitter := math.Min(b.Jitter, 1); jitter > 0 && delay > 0 {
+		random := b.Random
+		if random == nil {
+			random = SystemRandom{}
+		}
+		delay -= time.Duration(random.Int63n(int64(float64(delay)*jitter) + 1))
+	}
+
+	var statusErr *InvalidHTTPRespStatusCodeError
+	if xerrors.As(err, &statusErr) {
+		clock := b.Clock
+		if clock == nil {
+			clock = SystemClock{}
+		}
+		if after, ok := retryAfter(statusErr.Header.Get("Retry-After"), clock.Now()); ok {
+			if after > max {
+				return 0, false
+			}
+			if after > delay {
+				delay = after
+			}
+		}
+	}
+	return delay, true
+}
+
+// Retryable returns true if err is a failed response with one of the RetryableStatusCodes or a failed request with
+// one of the RetryableErrorCodes
+func (b ExponentialBackoff) Retryable(err error) bool {
+	var statusErr *InvalidHTTPRespStatusCodeError
+	if xerrors.As(err, &statusErr) {
+		codes := b.RetryableStatusCodes
+		if codes == nil {
+			codes = DefaultRetryableStatusCodes
+		}
+		return containsInt(codes, statusErr.HTTPStatusCode)
+	}
+	codes := b.RetryableErrorCodes
+	if codes == nil {
+		codes = DefaultRetryableErrorCodes
+	}
+	return containsInt(codes, CodeOf(err))
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// retryAfter parses a Retry-After header, which is either a number of seconds or an HTTP date
+func retryAfter(header string, now time.Time) (time.Duration, bool) {
+	header = strings.TrimSpace(header)
+	if len(header) == 0 {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		if delay := at.Sub(now); delay > 0 {
+			return delay, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// retriedFetch fetches urlText until it succeeds or the RetryPolicy gives up, waiting between attempts on the
+// factory's clock. Responses from a ResponseArchive aren't retried because they would fail the same way again.
+func (f *DefaultFactory) retriedFetch(ctx context.Context, urlText string, header http.Header) (*http.Response, error) {
+	policy := f.retryPolicy()
+	for attempt := 1; ; attempt++ {
+		resp, err := f.auditedFetch(ctx, urlText, header)
+		if err == nil || f.RetryPolicy == nil || f.ResponseArchive != nil || ctx.Err() != nil {
+			return resp, err
+		}
+		target, parseErr := url.Parse(urlText)
+		if parseErr != nil {
+			return resp, err
+		}
+		var delay time.Duration
+		var retry bool
+		guardPolicy("RetryPolicy", func() { delay, retry = policy.RetryDelay(ctx, target, attempt, err) })
+		if !retry {
+			return resp, err
+		}
+		timer := f.clock().NewTimer(delay)
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+			timer.Stop()
+			return resp, err
+		}
+	}
+}
+
+// retryPolicy returns the RetryPolicy, an ExponentialBackoff without its own Random or Clock takes the factory's
+func (f *DefaultFactory) retryPolicy() RetryPolicy {
+	backoff, ok := f.RetryPolicy.(ExponentialBackoff)
+	if pointer, isPointer := f.RetryPolicy.(*ExponentialBackoff); isPointer && pointer != nil {
+		backoff, ok = *pointer, true
+	}
+	if !ok {
+		return f.RetryPolicy
+	}
+	if backoff.Random == nil {
+		backoff.Random = f.random()
+	}
+	if backoff.Clock == nil {
+		backoff.Clock = f.clock()
+	}
+	return backoff
+}
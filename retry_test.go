@@ -0,0 +1,227 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// highestRandom is a RandomSource which always returns the largest number it can
+type highestRandom struct{ SystemRandom }
+
+func (highestRandom) Int63n(n int64) int64 {
+	return n - 1
+}
+
+type RetrySuite struct {
+	suite.Suite
+	target *url.URL
+}
+
+func (suite *RetrySuite) SetupSuite() {
+	suite.target, _ = url.Parse("https://www.netspective.com/")
+}
+
+func (suite *RetrySuite) statusError(status int, header http.Header) error {
+	return &InvalidHTTPRespStatusCodeError{URL: suite.target.String(), HTTPStatusCode: status, Header: header}
+}
+
+func (suite *RetrySuite) TestBackoffDelays() {
+	backoff := ExponentialBackoff{MaxAttempts: 5, Initial: 100 * time.Millisecond, Max: time.Second}
+	err := suite.statusError(http.StatusServiceUnavailable, nil)
+	for attempt, expected := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond} {
+		delay, retry := backoff.RetryDelay(context.Background(), suite.target, attempt+1, err)
+		suite.True(retry)
+		suite.Equal(expected, delay)
+	}
+	_, retry := backoff.RetryDelay(context.Background(), suite.target, 5, err)
+	suite.False(retry, "Should give up after MaxAttempts")
+
+	backoff = ExponentialBackoff{MaxAttempts: 10, Initial: time.Second, Multiplier: 3, Max: 5 * time.Second}
+	delay, _ := backoff.RetryDelay(context.Background(), suite.target, 3, err)
+	suite.Equal(5*time.Second, delay, "Delays shouldn't be longer than Max")
+
+	delay, retry = ExponentialBackoff{}.RetryDelay(context.Background(), suite.target, 2, err)
+	suite.True(retry)
+	suite.Equal(time.Second, delay, "Defaults should be 500ms doubling")
+	_, retry = ExponentialBackoff{}.RetryDelay(context.Background(), suite.target, 3, err)
+	suite.False(retry, "Default should be 3 attempts")
+}
+
+func (suite *RetrySuite) TestJitter() {
+	backoff := ExponentialBackoff{Initial: time.Second, Jitter: 0.5, Random: highestRandom{}}
+	delay, _ := backoff.RetryDelay(context.Background(), suite.target, 1, suite.statusError(http.StatusBadGateway, nil))
+	suite.Equal(500*time.Millisecond, delay, "Jitter should take off up to half the delay")
+}
+
+func (suite *RetrySuite) TestFactoryRandomSource() {
+	err := suite.statusError(http.StatusBadGateway, nil)
+	for _, policy := range []RetryPolicy{ExponentialBackoff{Initial: time.Second, Jitter: 0.5}, &ExponentialBackoff{Initial: time.Second, Jitter: 0.5}} {
+		delay, _ := NewFactory(highestRandom{}, policy).retryPolicy().RetryDelay(context.Background(), suite.target, 1, err)
+		suite.Equal(500*time.Millisecond, delay, "The jitter should come from the factory's RandomSource")
+	}
+
+	own := ExponentialBackoff{Initial: time.Second, Jitter: 0.5, Random: highestRandom{}, Clock: SystemClock{}}
+	suite.Equal(own, NewFactory(SystemRandom{}, own).retryPolicy(), "The policy's own Random and Clock should be kept")
+}
+
+func (suite *RetrySuite) TestRetryable() {
+	backoff := ExponentialBackoff{}
+	suite.True(backoff.Retryable(suite.statusError(http.StatusServiceUnavailable, nil)))
+	suite.True(backoff.Retryable(suite.statusError(http.StatusTooManyRequests, nil)))
+	suite.False(backoff.Retryable(suite.statusError(http.StatusNotFound, nil)))
+	suite.True(backoff.Retryable(requestError(suite.target.String(), "Unable to execute HTTP GET request", &url.Error{Op: "Get", URL: suite.target.String(), Err: fmt.Errorf("connection reset")}, nil)))
+	suite.False(backoff.Retryable(requestBuildError(suite.target.String(), fmt.Errorf("bad URL"), nil)))
+
+	backoff = ExponentialBackoff{RetryableStatusCodes: []int{http.StatusNotFound}}
+	suite.True(backoff.Retryable(suite.statusError(http.StatusNotFound, nil)))
+	suite.False(backoff.Retryable(suite.statusError(http.StatusServiceUnavailable, nil)))
+}
+
+func (suite *RetrySuite) TestRetryAfter() {
+	clock := NewManualClock(time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC))
+	backoff := ExponentialBackoff{Initial: time.Second, Max: time.Minute, Clock: clock}
+	delay, retry := backoff.RetryDelay(context.Background(), suite.target, 1, suite.statusError(http.StatusServiceUnavailable, http.Header{"Retry-After": {"10"}}))
+	suite.True(retry)
+	suite.Equal(10*time.Second, delay)
+
+	date := clock.Now().Add(30 * time.Second).Format(http.TimeFormat)
+	delay, _ = backoff.RetryDelay(context.Background(), suite.target, 1, suite.statusError(http.StatusTooManyRequests, http.Header{"Retry-After": {date}}))
+	suite.Equal(30*time.Second, delay)
+
+	_, retry = backoff.RetryDelay(context.Background(), suite.target, 1, suite.statusError(http.StatusServiceUnavailable, http.Header{"Retry-After": {"3600"}}))
+	suite.False(retry, "Shouldn't wait longer than Max")
+}
+
+// flakyServer fails the first failures requests with status and then serves a page
+func (suite *RetrySuite) flakyServer(failures int32, status int, requests *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(requests, 1) <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, testHTMLPage)
+	}))
+}
+
+// advance moves clock past every timer the factory waits on until done is closed
+func (suite *RetrySuite) advance(clock *ManualClock, done chan struct{}) {
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		if clock.Timers() > 0 {
+			clock.Advance(time.Minute)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func (suite *RetrySuite) TestFactoryRetries() {
+	var requests int32
+	server := suite.flakyServer(2, http.StatusServiceUnavailable, &requests)
+	defer server.Close()
+
+	clock := NewManualClock(time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC))
+	done := make(chan struct{})
+	go suite.advance(clock, done)
+	content, err := NewFactory(clock, ExponentialBackoff{Initial: time.Second}).PageFromURL(context.Background(), server.URL)
+	close(done)
+	suite.Nil(err, "Should not get an error")
+	suite.NotNil(content)
+	suite.Equal(int32(3), atomic.LoadInt32(&requests))
+}
+
+func (suite *RetrySuite) TestFactoryGivesUp() {
+	var requests int32
+	server := suite.flakyServer(10, http.StatusBadGateway, &requests)
+	defer server.Close()
+
+	clock := NewManualClock(time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC))
+	done := make(chan struct{})
+	go suite.advance(clock, done)
+	_, err := NewFactory(clock, ExponentialBackoff{MaxAttempts: 2}).PageFromURL(context.Background(), server.URL)
+	close(done)
+	suite.Equal(ErrorCodeHTTPStatus, CodeOf(err))
+	suite.Equal(int32(2), atomic.LoadInt32(&requests))
+
+	requests = 0
+	_, err = NewFactory(clock, ExponentialBackoff{RetryableStatusCodes: []int{http.StatusServiceUnavailable}}).PageFromURL(context.Background(), server.URL)
+	suite.Equal(ErrorCodeHTTPStatus, CodeOf(err))
+	suite.Equal(int32(1), atomic.LoadInt32(&requests), "Other statuses shouldn't be retried")
+
+	requests = 0
+	_, err = NewFactory(clock).PageFromURL(context.Background(), server.URL)
+	suite.Equal(int32(1), atomic.LoadInt32(&requests), "Nothing should be retried without a policy")
+}
+
+func (suite *RetrySuite) TestRetryAfterDateOnFactoryClock() {
+	clock := NewManualClock(time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC))
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.Header().Set("Retry-After", clock.Now().Add(30*time.Second).Format(http.TimeFormat))
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, testHTMLPage)
+	}))
+	defer server.Close()
+
+	done := make(chan error)
+	go func() {
+		_, err := NewFactory(clock, ExponentialBackoff{Initial: time.Second, Max: time.Minute}).PageFromURL(context.Background(), server.URL)
+		done <- err
+	}()
+	for clock.Timers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	clock.Advance(29 * time.Second)
+	time.Sleep(20 * time.Millisecond)
+	suite.Equal(int32(1), atomic.LoadInt32(&requests), "The Retry-After date should be measured on the factory's clock")
+	clock.Advance(time.Second)
+	suite.Nil(<-done, "Should not get an error")
+	suite.Equal(int32(2), atomic.LoadInt32(&requests))
+}
+
+func (suite *RetrySuite) TestCancelWhileWaiting() {
+	var requests int32
+	server := suite.flakyServer(10, http.StatusServiceUnavailable, &requests)
+	defer server.Close()
+
+	clock := NewManualClock(time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC))
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		for clock.Timers() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+	}()
+	_, err := NewFactory(clock, ExponentialBackoff{Initial: time.Hour, Max: 2 * time.Hour}).PageFromURL(ctx, server.URL)
+	suite.Equal(ErrorCodeHTTPStatus, CodeOf(err), "The last attempt's error should be returned")
+	suite.Equal(int32(1), atomic.LoadInt32(&requests))
+}
+
+func (suite *RetrySuite) TestValidate() {
+	err := NewFactory(ExponentialBackoff{MaxAttempts: -1}).Validate()
+	suite.NotNil(err)
+	suite.Contains(err.Error(), "RetryPolicy has a negative setting")
+
+	_, err = NewFactoryWithOptions(WithRetryPolicy(&ExponentialBackoff{}), WithResponseArchive(NewMemoryResponseArchive()))
+	suite.Contains(err.Error(), "WithRetryPolicy is unused because WithResponseArchive replays responses")
+}
+
+func TestRetrySuite(t *testing.T) {
+	suite.Run(t, new(RetrySuite))
+}
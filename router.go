@@ -0,0 +1,93 @@
+package resource
+
+import (
+	"net/url"
+	"path"
+	"strings"
+)
+
+// DomainPolicyRoute applies a bundle to the hosts matching Pattern, which is a host name ("www.nytimes.com"), a
+// subdomain wildcard ("*.nytimes.com" matches www.nytimes.com but not nytimes.com), or a path.Match glob ("intranet-*")
+type DomainPolicyRoute struct {
+	Pattern string
+	Bundle  PolicyBundle
+}
+
+// Matches returns true if the route applies to host
+func (r DomainPolicyRoute) Matches(host string) bool {
+	host = strings.ToLower(host)
+	pattern := strings.ToLower(r.Pattern)
+	if strings.HasPrefix(pattern, "*.") {
+		return strings.HasSuffix(host, pattern[1:])
+	}
+	if host == pattern {
+		return true
+	}
+	matched, _ := path.Match(pattern, host)
+	return matched
+}
+
+// DomainPolicyRouter is passed into options to apply different policy bundles (e.g. auth headers for internal hosts,
+// aggressive caching for news sites) depending on the host of each requested URL. The first matching route wins and
+// its bundle overrides the factory's own options for that request.
+type DomainPolicyRouter struct {
+	Routes []DomainPolicyRoute
+}
+
+// NewDomainPolicyRouter creates a router without routes
+func NewDomainPolicyRouter() *DomainPolicyRouter {
+	return &DomainPolicyRouter{}
+}
+
+// Route adds a route, it returns the router so routes can be chained
+func (r *DomainPolicyRouter) Route(pattern string, bundle PolicyBundle) *DomainPolicyRouter {
+	r.Routes = append(r.Routes, DomainPolicyRoute{Pattern: pattern, Bundle: bundle})
+	return r
+}
+
+// BundleFor returns the bundle of the first route matching the URL's host
+func (r *DomainPolicyRouter) BundleFor(url *url.URL) (PolicyBundle, bool) {
+	if url == nil {
+		return PolicyBundle{}, false
+	}
+	host := url.Hostname()
+	for _, route := range r.Routes {
+		if route.Matches(host) {
+			return route.Bundle, true
+		}
+	}
+	return PolicyBundle{}, false
+}
+
+// routed returns the factory to use for url, a copy of f with the routed bundle's options applied if a route matches
+func (f *DefaultFactory) routed(urlText string) *DefaultFactory {
+	if f.DomainPolicyRouter == nil {
+		return f
+	}
+	target, err := url.Parse(urlText)
+	if err != nil {
+		return f
+	}
+	bundle, ok := f.DomainPolicyRouter.BundleFor(target)
+	if !ok {
+		return f
+	}
+
+	result := f.requestCopy()
+	result.initOptions(bundle)
+	// the routed factory is only used for this request so it mustn't route again
+	result.DomainPolicyRouter = nil
+	return result
+}
+
+// requestCopy returns a copy of f for a single request, whose options may then be added to without changing f. The
+// fields options append to are copied, since requests are resolved concurrently.
+func (f *DefaultFactory) requestCopy() *DefaultFactory {
+	result := *f
+	result.options = append([]interface{}(nil), f.options...)
+	result.policyNames = append([]string(nil), f.policyNames...)
+	result.RoundTripperDecorators = append([]RoundTripperDecorator(nil), f.RoundTripperDecorators...)
+	result.PDFURLPatterns = append(PDFURLPatterns(nil), f.PDFURLPatterns...)
+	result.TrackerDomains = append(TrackerDomains(nil), f.TrackerDomains...)
+	return &result
+}
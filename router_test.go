@@ -0,0 +1,97 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RouterSuite struct {
+	suite.Suite
+}
+
+func (suite *RouterSuite) TestMatches() {
+	suite.True(DomainPolicyRoute{Pattern: "*.nytimes.com"}.Matches("www.NYTimes.com"))
+	suite.False(DomainPolicyRoute{Pattern: "*.nytimes.com"}.Matches("nytimes.com"), "Subdomain wildcards should not match the apex")
+	suite.False(DomainPolicyRoute{Pattern: "*.nytimes.com"}.Matches("notnytimes.com"))
+	suite.True(DomainPolicyRoute{Pattern: "intranet-*"}.Matches("intranet-wiki"))
+	suite.True(DomainPolicyRoute{Pattern: "ceur-ws.org"}.Matches("ceur-ws.org"))
+}
+
+func (suite *RouterSuite) TestRoutedBundles() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("http://docs.example.com/paper.pdf", archivedResponse(200, http.Header{"Content-Type": {"application/pdf"}}, testPDFContent))
+	archive.Add("http://example.org/paper.pdf", archivedResponse(200, http.Header{"Content-Type": {"application/pdf"}}, testPDFContent))
+
+	router := NewDomainPolicyRouter().
+		Route("*.example.com", NewPolicyBundle("downloads", NewMemoryAttachmentCreator(nil)))
+	factory := NewFactory(archive, router)
+
+	content, err := factory.PageFromURL(context.Background(), "http://docs.example.com/paper.pdf")
+	suite.Nil(err, "Should not get an error")
+	suite.NotNil(content.Attachment(), "Routed bundle should enable downloads")
+
+	content, err = factory.PageFromURL(context.Background(), "http://example.org/paper.pdf")
+	suite.Nil(err, "Should not get an error")
+	suite.Nil(content.Attachment(), "Unrouted host should use the factory's own options")
+	suite.Nil(factory.FileAttachmentCreator, "Routing should not change the factory")
+}
+
+func (suite *RouterSuite) TestRoutedRequestPreparer() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer internal" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, testHTMLPage)
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+	auth := func(ctx context.Context, client *http.Client, req *http.Request) {
+		req.Header.Set("Authorization", "Bearer internal")
+	}
+	router := NewDomainPolicyRouter().Route(u.Hostname(), NewPolicyBundle("internal-auth", auth))
+
+	_, err := NewFactory().PageFromURL(context.Background(), server.URL)
+	suite.NotNil(err, "Request without auth should fail")
+	_, err = NewFactory(router).PageFromURL(context.Background(), server.URL)
+	suite.Nil(err, "Routed bundle should add the auth header")
+}
+
+func (suite *RouterSuite) TestRoutedBundlesDontChangeFactory() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("http://docs.example.com/paper.pdf", archivedResponse(200, http.Header{"Content-Type": {"application/pdf"}}, testPDFContent))
+	basePatterns := PDFURLPatterns{{regexp.MustCompile(`^https://base\.example\.org/(\w+)$`), "https://base.example.org/$1.pdf"}}
+	router := NewDomainPolicyRouter().Route("*.example.com", NewPolicyBundle("routed",
+		Shorteners{"ex.am": "Example"},
+		PDFURLPatterns{{regexp.MustCompile(`^https://docs\.example\.com/(\w+)$`), "https://docs.example.com/$1.pdf"}},
+		TrackerDomains{"tracker.example.com"}))
+	factory := NewFactory(archive, router, Shorteners{"b.ex": "Base"}, basePatterns, TrackerDomains{"tracker.example.org"})
+
+	done := make(chan struct{})
+	for index := 0; index < 4; index++ {
+		go func() {
+			defer func() { done <- struct{}{} }()
+			_, err := factory.PageFromURL(context.Background(), "http://docs.example.com/paper.pdf")
+			suite.Nil(err, "Should not get an error")
+		}()
+	}
+	for index := 0; index < 4; index++ {
+		<-done
+	}
+	suite.Equal(Shorteners{"b.ex": "Base"}, factory.Shorteners, "Routed shorteners shouldn't leak into the factory")
+	suite.Equal(basePatterns, factory.PDFURLPatterns)
+	suite.Equal(TrackerDomains{"tracker.example.org"}, factory.TrackerDomains)
+}
+
+func TestRouterSuite(t *testing.T) {
+	suite.Run(t, new(RouterSuite))
+}
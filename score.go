@@ -0,0 +1,171 @@
+package resource
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// ContentScorer is passed into options to give each resolved page a quality score, stored in Page.Score, so that
+// curation tools can rank harvested links. Higher is better, the scale is the scorer's own.
+type ContentScorer interface {
+	ScoreContent(ctx context.Context, page *Page) (score float64, factors map[string]float64)
+}
+
+// Factors of QualityScorer, each between 0 and 1
+const (
+	ScoreFactorMetaCompleteness = "meta-completeness"
+	ScoreFactorWordCount        = "word-count"
+	ScoreFactorFreshness        = "freshness"
+	ScoreFactorTLSHealth        = "tls-health"
+)
+
+// QualityScorer is a ContentScorer whose score, between 0 and 1, is the weighted average of how complete the title,
+// description, image, and canonical meta data are, how much text there is, how recently the content was modified, and
+// how healthy its TLS is. Factors with no weight use DefaultQualityWeights.
+type QualityScorer struct {
+	Weights   map[string]float64
+	WordCount int           // the word count which scores fully, 300 if not set
+	MaxAge    time.Duration // the age at which freshness reaches 0, one year if not set
+	Clock     Clock         // SystemClock if not set
+}
+
+// DefaultQualityWeights are the weights of QualityScorer's factors
+var DefaultQualityWeights = map[string]float64{
+	ScoreFactorMetaCompleteness: 0.4,
+	ScoreFactorWordCount:        0.2,
+	ScoreFactorFreshness:        0.2,
+	ScoreFactorTLSHealth:        0.2,
+}
+
+// ScoreContent satisfies ContentScorer method
+func (s QualityScorer) ScoreContent(ctx context.Context, page *Page) (float64, map[string]float64) {
+	clock := s.Clock
+	if clock == nil {
+		clock = SystemClock{}
+	}
+	now := clock.Now()
+
+	factors := map[string]float64{
+		ScoreFactorMetaCompleteness: metaCompleteness(*page),
+		ScoreFactorWordCount:        s.wordCountFactor(page.WordCount),
+		ScoreFactorFreshness:        s.freshness(*page, now),
+		ScoreFactorTLSHealth:        tlsHealth(*page, now),
+	}
+	var total, weights float64
+	for factor, value := range factors {
+		weight, ok := s.Weights[factor]
+		if !ok {
+			weight = DefaultQualityWeights[factor]
+		}
+		total += weight * value
+		weights += weight
+	}
+	if weights == 0 {
+		return 0, factors
+	}
+	return total / weights, factors
+}
+
+func metaCompleteness(page Page) float64 {
+	present := 0.0
+	if len(pageMetaText(page, "og:title", "twitter:title", "title")) > 0 {
+		present++
+	}
+	if len(pageMetaText(page, "og:description", "twitter:description", "description")) > 0 {
+		present++
+	}
+	if len(pageMetaText(page, "og:image", "twitter:image")) > 0 {
+		present++
+	}
+	if _, ok := page.Canonical(); ok {
+		present++
+	}
+	return present / 4
+}
+
+func (s QualityScorer) wordCountFactor(words int) float64 {
+	target := s.WordCount
+	if target <= 0 {
+		target = 300
+	}
+	return math.Min(float64(words)/float64(target), 1)
+}
+
+// freshness decays linearly from 1, for content modified now, to 0 at MaxAge. Content of unknown age scores 0.5.
+func (s QualityScorer) freshness(page Page, now time.Time) float64 {
+	maxAge := s.MaxAge
+	if maxAge <= 0 {
+		maxAge = 365 * 24 * time.Hour
+	}
+	var modified time.Time
+	if tags, err := page.MetaTags(); err == nil {
+		for _, key := range []string{"article:modified_time", "og:updated_time", "article:published_time"} {
+			if t, ok, err := tags.GetTime(key); ok && err == nil {
+				modified = t
+				break
+			}
+		}
+	}
+	if modified.IsZero() && len(page.LastModified) > 0 {
+		modified, _ = http.ParseTime(page.LastModified)
+	}
+	if modified.IsZero() {
+		return 0.5
+	}
+	age := now.Sub(modified)
+	if age <= 0 {
+		return 1
+	}
+	return math.Max(1-float64(age)/float64(maxAge), 0)
+}
+
+// tlsHealth is 0 for plain HTTP, 1 for TLS 1.2 or later with a certificate that's valid for at least two weeks, and
+// 0.5 otherwise
+func tlsHealth(page Page, now time.Time) float64 {
+	if page.TLS == nil {
+		return 0
+	}
+	modern := page.TLS.Version == "TLS 1.2" || page.TLS.Version == "TLS 1.3"
+	if modern && now.Add(14*24*time.Hour).Before(page.TLS.NotAfter) {
+		return 1
+	}
+	return 0.5
+}
+
+// countWords counts the words of the text in a document's <body>, ignoring scripts and styles
+func countWords(doc *html.Node) int {
+	var count int
+	var walk func(n *html.Node, inBody bool)
+	walk = func(n *html.Node, inBody bool) {
+		if n.Type == html.ElementNode {
+			switch strings.ToLower(n.Data) {
+			case "script", "style", "noscript", "template":
+				return
+			case "body":
+				inBody = true
+			}
+		}
+		if inBody && n.Type == html.TextNode {
+			count += len(strings.Fields(n.Data))
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c, inBody)
+		}
+	}
+	walk(doc, false)
+	return count
+}
+
+// scoreContent stores the ContentScorer's score on a page
+func (f *DefaultFactory) scoreContent(ctx context.Context, content Content) {
+	page, ok := content.(*Page)
+	if f.ContentScorer == nil || !ok {
+		return
+	}
+	guardPolicy("ContentScorer", func() { page.Score, page.ScoreFactors = f.ContentScorer.ScoreContent(ctx, page) })
+}
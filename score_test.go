@@ -0,0 +1,70 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ScoreSuite struct {
+	suite.Suite
+	now time.Time
+}
+
+func (suite *ScoreSuite) SetupTest() {
+	suite.now = time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)
+}
+
+func (suite *ScoreSuite) TestQualityScore() {
+	html := `<html><head>
+		<meta property="og:title" content="Title">
+		<meta property="og:description" content="Description">
+		<meta property="article:modified_time" content="2019-09-01T12:00:00Z">
+		<script>var notCounted = "words in scripts";</script>
+	</head><body><p>` + strings.Repeat("word ", 150) + `</p><style>p { color: red }</style></body></html>`
+	archive := NewMemoryResponseArchive()
+	archive.Add("http://example.com/", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, html))
+
+	scorer := QualityScorer{MaxAge: 300 * 24 * time.Hour, Clock: NewManualClock(suite.now)}
+	content, err := NewFactory(archive, scorer).PageFromURL(context.Background(), "http://example.com/")
+	suite.Nil(err, "Should not get an error")
+	page := content.(*Page)
+	suite.Equal(150, page.WordCount)
+	suite.Equal(0.5, page.ScoreFactors[ScoreFactorMetaCompleteness])
+	suite.Equal(0.5, page.ScoreFactors[ScoreFactorWordCount])
+	suite.Equal(0.9, page.ScoreFactors[ScoreFactorFreshness])
+	suite.Equal(0.0, page.ScoreFactors[ScoreFactorTLSHealth], "Plain HTTP should have no TLS health")
+	suite.InDelta(0.4*0.5+0.2*0.5+0.2*0.9, page.Score, 0.0001)
+}
+
+func (suite *ScoreSuite) TestTLSHealth() {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, testHTMLPage)
+	}))
+	defer server.Close()
+
+	client := server.Client()
+	content, err := NewFactory(func(ctx context.Context) *http.Client { return client }, QualityScorer{}).PageFromURL(context.Background(), server.URL)
+	suite.Nil(err, "Should not get an error")
+	page := content.(*Page)
+	suite.NotNil(page.TLS)
+	suite.Equal(1.0, page.ScoreFactors[ScoreFactorTLSHealth])
+	suite.Equal(0.5, page.ScoreFactors[ScoreFactorFreshness], "Content of unknown age should be neutral")
+}
+
+func (suite *ScoreSuite) TestWeights() {
+	page := &Page{WordCount: 300, MetaPropertyTags: map[string]interface{}{}, HTMLParsed: true}
+	score, _ := QualityScorer{Weights: map[string]float64{ScoreFactorMetaCompleteness: 0, ScoreFactorFreshness: 0, ScoreFactorTLSHealth: 0}}.ScoreContent(context.Background(), page)
+	suite.Equal(1.0, score, "Only the word count should count")
+}
+
+func TestScoreSuite(t *testing.T) {
+	suite.Run(t, new(ScoreSuite))
+}
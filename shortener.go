@@ -0,0 +1,86 @@
+package resource
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Shorteners maps the hosts of URL shortening services to their provider names. It's passed into options to add
+// hosts (such as a publisher's own branded short domain) to DefaultShorteners, or to rename a provider; an empty
+// provider name stops a host being treated as a shortener.
+type Shorteners map[string]string
+
+// DefaultShorteners are the URL shortening services which are always recognized
+var DefaultShorteners = Shorteners{
+	"bit.ly":       "Bitly",
+	"bitly.com":    "Bitly",
+	"j.mp":         "Bitly",
+	"t.co":         "Twitter",
+	"goo.gl":       "Google",
+	"tinyurl.com":  "TinyURL",
+	"ow.ly":        "Hootsuite",
+	"buff.ly":      "Buffer",
+	"lnkd.in":      "LinkedIn",
+	"fb.me":        "Facebook",
+	"is.gd":        "is.gd",
+	"rebrand.ly":   "Rebrandly",
+	"cutt.ly":      "Cuttly",
+	"shorturl.at":  "ShortURL",
+	"amzn.to":      "Amazon",
+	"dlvr.it":      "dlvr.it",
+	"trib.al":      "Echobox",
+	"tiny.cc":      "tiny.cc",
+	"s.id":         "s.id",
+	"wp.me":        "WordPress",
+	"flip.it":      "Flipboard",
+	"spoti.fi":     "Spotify",
+	"apple.co":     "Apple",
+	"nyti.ms":      "The New York Times",
+	"wapo.st":      "The Washington Post",
+	"reut.rs":      "Reuters",
+	"econ.st":      "The Economist",
+	"bloom.bg":     "Bloomberg",
+	"on.ft.com":    "Financial Times",
+	"hubs.ly":      "HubSpot",
+	"ift.tt":       "IFTTT",
+	"po.st":        "po.st",
+	"qr.ae":        "Quora",
+	"redd.it":      "Reddit",
+	"t.ly":         "T.LY",
+	"v.gd":         "v.gd",
+	"x.co":         "GoDaddy",
+	"zpr.io":       "Zapier",
+	"aka.ms":       "Microsoft",
+	"db.tt":        "Dropbox",
+	"forms.gle":    "Google",
+	"maps.app.goo": "Google",
+}
+
+// ShortenedURL describes the shortener a page was reached through, the Page's TargetURL is where it led
+type ShortenedURL struct {
+	Provider  string `json:"provider"`
+	ShortURL  string `json:"shortURL"`
+	ShortCode string `json:"shortCode"` // the path of the short URL, without the leading slash
+}
+
+// Provider returns the shortening service of a host, if it's a known one
+func (s Shorteners) Provider(host string) (string, bool) {
+	provider, ok := s[strings.TrimPrefix(strings.ToLower(host), "www.")]
+	return provider, ok
+}
+
+// shortenedURL returns the shortener urlText belongs to, the factory's Shorteners win over DefaultShorteners
+func (f *DefaultFactory) shortenedURL(urlText string) *ShortenedURL {
+	short, err := url.Parse(urlText)
+	if err != nil {
+		return nil
+	}
+	provider, ok := f.Shorteners.Provider(short.Hostname())
+	if !ok {
+		provider, ok = DefaultShorteners.Provider(short.Hostname())
+	}
+	if !ok || len(provider) == 0 {
+		return nil
+	}
+	return &ShortenedURL{Provider: provider, ShortURL: urlText, ShortCode: strings.Trim(short.EscapedPath(), "/")}
+}
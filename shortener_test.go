@@ -0,0 +1,58 @@
+package resource
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ShortenerSuite struct {
+	suite.Suite
+	archive *MemoryResponseArchive
+}
+
+func (suite *ShortenerSuite) SetupTest() {
+	suite.archive = NewMemoryResponseArchive()
+	suite.archive.Add("https://bit.ly/2kbW5Qm", archivedResponse(301, http.Header{"Location": {"http://example.com/"}}, ""))
+	suite.archive.Add("https://go.example.org/launch", archivedResponse(302, http.Header{"Location": {"http://example.com/"}}, ""))
+	suite.archive.Add("http://example.com/", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, testHTMLPage))
+}
+
+func (suite *ShortenerSuite) TestKnownShortener() {
+	content, err := NewFactory(suite.archive).PageFromURL(context.Background(), "https://bit.ly/2kbW5Qm")
+	suite.Nil(err, "Should not get an error")
+	page := content.(*Page)
+	suite.Equal("http://example.com/", page.TargetURLText())
+	suite.Require().NotNil(page.ShortenedURL)
+	suite.Equal("Bitly", page.ShortenedURL.Provider)
+	suite.Equal("2kbW5Qm", page.ShortenedURL.ShortCode)
+	suite.Equal("https://bit.ly/2kbW5Qm", page.ShortenedURL.ShortURL)
+}
+
+func (suite *ShortenerSuite) TestCustomShortener() {
+	content, err := NewFactory(suite.archive).PageFromURL(context.Background(), "https://go.example.org/launch")
+	suite.Nil(err, "Should not get an error")
+	suite.Nil(content.(*Page).ShortenedURL, "Unknown hosts aren't shorteners")
+
+	content, err = NewFactory(suite.archive, Shorteners{"go.example.org": "Example"}).PageFromURL(context.Background(), "https://go.example.org/launch")
+	suite.Nil(err, "Should not get an error")
+	suite.Require().NotNil(content.(*Page).ShortenedURL)
+	suite.Equal("Example", content.(*Page).ShortenedURL.Provider)
+	suite.Equal("launch", content.(*Page).ShortenedURL.ShortCode)
+
+	content, err = NewFactory(suite.archive, Shorteners{"bit.ly": ""}).PageFromURL(context.Background(), "https://bit.ly/2kbW5Qm")
+	suite.Nil(err, "Should not get an error")
+	suite.Nil(content.(*Page).ShortenedURL, "An empty provider should turn a default shortener off")
+}
+
+func (suite *ShortenerSuite) TestNotShortened() {
+	content, err := NewFactory(suite.archive).PageFromURL(context.Background(), "http://example.com/")
+	suite.Nil(err, "Should not get an error")
+	suite.Nil(content.(*Page).ShortenedURL)
+}
+
+func TestShortenerSuite(t *testing.T) {
+	suite.Run(t, new(ShortenerSuite))
+}
@@ -0,0 +1,106 @@
+package resource
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// AttachmentURLSigner mints time-limited URLs a stored attachment can be fetched from without credentials, so
+// applications can serve archived files directly from where they're stored. Creators which store attachments in
+// object storage should implement it with the storage's own presigning.
+type AttachmentURLSigner interface {
+	SignAttachmentURL(ctx context.Context, attachment *FileAttachment, expires time.Duration) (*url.URL, error)
+}
+
+// SignAttachmentURL returns a URL for attachment which expires after expires, signed by the AttachmentURLSigner in
+// options (usually the FileAttachmentCreator the attachment was downloaded with)
+func SignAttachmentURL(ctx context.Context, attachment *FileAttachment, expires time.Duration, options ...interface{}) (_ *url.URL, err error) {
+	defer recoverPolicyPanic(&err)
+	signer, ok := OptionOf[AttachmentURLSigner](options)
+	if !ok {
+		return nil, xerrors.Errorf("No AttachmentURLSigner to sign %q with in resource.SignAttachmentURL", attachment.DestPath)
+	}
+	if expires <= 0 {
+		return nil, xerrors.Errorf("Signed URL for %q would already have expired in resource.SignAttachmentURL", attachment.DestPath)
+	}
+	var result *url.URL
+	guardPolicy("AttachmentURLSigner", func() { result, err = signer.SignAttachmentURL(ctx, attachment, expires) })
+	return result, err
+}
+
+// HMACAttachmentURLSigner is an AttachmentURLSigner for attachments an application serves itself, it signs URLs under
+// BaseURL with Key and VerifyAttachmentURL checks them before the attachment is served
+type HMACAttachmentURLSigner struct {
+	BaseURL *url.URL // attachments' paths are appended to it
+	Key     []byte
+	Clock   Clock // the system clock if nil
+}
+
+// SignAttachmentURL satisfies AttachmentURLSigner method
+func (s HMACAttachmentURLSigner) SignAttachmentURL(ctx context.Context, attachment *FileAttachment, expires time.Duration) (*url.URL, error) {
+	if s.BaseURL == nil || len(s.Key) == 0 {
+		return nil, xerrors.New("HMACAttachmentURLSigner needs a BaseURL and a Key")
+	}
+	attachmentPath := strings.TrimPrefix(path.Clean("/"+attachment.DestPath), "/")
+	expiresAt := strconv.FormatInt(s.clock().Now().Add(expires).Unix(), 10)
+
+	result := *s.BaseURL
+	result.Path = strings.TrimSuffix(result.Path, "/") + "/" + attachmentPath
+	result.RawPath = ""
+	query := result.Query()
+	query.Set("expires", expiresAt)
+	query.Set("signature", s.signature(attachmentPath, expiresAt))
+	result.RawQuery = query.Encode()
+	return &result, nil
+}
+
+// VerifyAttachmentURL returns the path of the attachment a URL signed by SignAttachmentURL is for, or an error if
+// the URL wasn't signed with Key or has expired
+func (s HMACAttachmentURLSigner) VerifyAttachmentURL(signed *url.URL) (string, error) {
+	basePath := strings.TrimSuffix(s.BaseURL.Path, "/") + "/"
+	if !strings.HasPrefix(signed.Path, basePath) {
+		return "", xerrors.Errorf("%q isn't under %q", signed.Path, basePath)
+	}
+	attachmentPath := strings.TrimPrefix(signed.Path, basePath)
+	if attachmentPath != strings.TrimPrefix(path.Clean("/"+attachmentPath), "/") {
+		return "", xerrors.Errorf("%q isn't a clean attachment path", attachmentPath)
+	}
+
+	query := signed.Query()
+	expiresAt := query.Get("expires")
+	signature, err := hex.DecodeString(query.Get("signature"))
+	if err != nil || !hmac.Equal(signature, s.rawSignature(attachmentPath, expiresAt)) {
+		return "", xerrors.Errorf("Signature of %q is invalid", attachmentPath)
+	}
+	expires, err := strconv.ParseInt(expiresAt, 10, 64)
+	if err != nil || !s.clock().Now().Before(time.Unix(expires, 0)) {
+		return "", xerrors.Errorf("Signed URL of %q has expired", attachmentPath)
+	}
+	return attachmentPath, nil
+}
+
+func (s HMACAttachmentURLSigner) signature(attachmentPath string, expiresAt string) string {
+	return hex.EncodeToString(s.rawSignature(attachmentPath, expiresAt))
+}
+
+func (s HMACAttachmentURLSigner) rawSignature(attachmentPath string, expiresAt string) []byte {
+	mac := hmac.New(sha256.New, s.Key)
+	mac.Write([]byte(attachmentPath + "\n" + expiresAt))
+	return mac.Sum(nil)
+}
+
+func (s HMACAttachmentURLSigner) clock() Clock {
+	if s.Clock != nil {
+		return s.Clock
+	}
+	return SystemClock{}
+}
@@ -0,0 +1,77 @@
+package resource
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SigningSuite struct {
+	suite.Suite
+	clock      *ManualClock
+	signer     HMACAttachmentURLSigner
+	attachment *FileAttachment
+}
+
+func (suite *SigningSuite) SetupTest() {
+	suite.clock = NewManualClock(time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC))
+	base, _ := url.Parse("https://cdn.example.com/attachments/")
+	suite.signer = HMACAttachmentURLSigner{BaseURL: base, Key: []byte("secret"), Clock: suite.clock}
+	suite.attachment = &FileAttachment{DestPath: "2019/06/paper-05.pdf"}
+}
+
+func (suite *SigningSuite) TestSignAndVerify() {
+	signed, err := SignAttachmentURL(context.Background(), suite.attachment, time.Hour, suite.signer)
+	suite.Nil(err, "Should not get an error")
+	suite.Equal("https://cdn.example.com/attachments/2019/06/paper-05.pdf", signed.Scheme+"://"+signed.Host+signed.Path)
+	suite.Equal("1559394000", signed.Query().Get("expires"))
+
+	path, err := suite.signer.VerifyAttachmentURL(signed)
+	suite.Nil(err, "Should not get an error")
+	suite.Equal("2019/06/paper-05.pdf", path)
+
+	suite.clock.Advance(time.Hour)
+	_, err = suite.signer.VerifyAttachmentURL(signed)
+	suite.Contains(err.Error(), "expired")
+}
+
+func (suite *SigningSuite) TestTamperedURLs() {
+	signed, _ := SignAttachmentURL(context.Background(), suite.attachment, time.Hour, suite.signer)
+
+	other := *signed
+	other.Path = "/attachments/2019/06/paper-06.pdf"
+	_, err := suite.signer.VerifyAttachmentURL(&other)
+	suite.Contains(err.Error(), "invalid", "A signature is only good for its own attachment")
+
+	query := signed.Query()
+	query.Set("expires", "1893456000")
+	other = *signed
+	other.RawQuery = query.Encode()
+	_, err = suite.signer.VerifyAttachmentURL(&other)
+	suite.Contains(err.Error(), "invalid", "Extending the expiry should invalidate the signature")
+
+	other = *signed
+	other.Path = "/attachments/../secrets/key.pem"
+	_, err = suite.signer.VerifyAttachmentURL(&other)
+	suite.NotNil(err, "Paths escaping the base should be refused")
+
+	wrongKey := suite.signer
+	wrongKey.Key = []byte("other")
+	_, err = wrongKey.VerifyAttachmentURL(signed)
+	suite.NotNil(err, "URLs signed with another key should be refused")
+}
+
+func (suite *SigningSuite) TestNoSigner() {
+	_, err := SignAttachmentURL(context.Background(), suite.attachment, time.Hour)
+	suite.Contains(err.Error(), "No AttachmentURLSigner")
+
+	_, err = SignAttachmentURL(context.Background(), suite.attachment, 0, suite.signer)
+	suite.NotNil(err, "URLs which have already expired shouldn't be signed")
+}
+
+func TestSigningSuite(t *testing.T) {
+	suite.Run(t, new(SigningSuite))
+}
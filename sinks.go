@@ -0,0 +1,108 @@
+package resource
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"hash"
+	"io"
+	"net/url"
+)
+
+// DownloadSink receives a copy of every byte of a download as it's written to the attachment's file
+type DownloadSink interface {
+	io.Writer
+
+	// FinishDownload is called once the download is complete (err is nil) or has failed (err is the reason)
+	FinishDownload(ctx context.Context, attachment Attachment, err error) error
+}
+
+// DownloadSinkChain is passed into options if we want to tee each download into additional sinks in the same pass,
+// e.g. hashes, inline parsers, or cloud uploads. New sinks are requested for every download.
+type DownloadSinkChain interface {
+	DownloadSinks(context.Context, *url.URL, Type) ([]DownloadSink, error)
+}
+
+// DownloadSinkChainFunc allows a plain function to be used as a DownloadSinkChain
+type DownloadSinkChainFunc func(context.Context, *url.URL, Type) ([]DownloadSink, error)
+
+// DownloadSinks satisfies DownloadSinkChain method
+func (fn DownloadSinkChainFunc) DownloadSinks(ctx context.Context, url *url.URL, t Type) ([]DownloadSink, error) {
+	return fn(ctx, url, t)
+}
+
+// HashSink is a DownloadSink which computes a digest of the downloaded content
+type HashSink struct {
+	hash.Hash
+	Algorithm string
+	Sum       []byte // available once the download has finished successfully
+}
+
+// NewHashSink creates a HashSink for one of the "md5", "sha-1", "sha-256", or "sha-512" algorithms
+func NewHashSink(algorithm string) (*HashSink, bool) {
+	var h hash.Hash
+	switch algorithm {
+	case "md5":
+		h = md5.New()
+	case "sha-1":
+		h = sha1.New()
+	case "sha-256":
+		h = sha256.New()
+	case "sha-512":
+		h = sha512.New()
+	default:
+		return nil, false
+	}
+	return &HashSink{Hash: h, Algorithm: algorithm}, true
+}
+
+// FinishDownload satisfies DownloadSink method
+func (s *HashSink) FinishDownload(ctx context.Context, attachment Attachment, err error) error {
+	if err == nil {
+		s.Sum = s.Hash.Sum(nil)
+	}
+	return nil
+}
+
+// HexSum returns the digest as a lowercase hex string
+func (s *HashSink) HexSum() string {
+	return hex.EncodeToString(s.Sum)
+}
+
+// WriterSink adapts any io.Writer, such as the pipe of a cloud upload, into a DownloadSink
+type WriterSink struct {
+	io.Writer
+	OnFinish func(ctx context.Context, attachment Attachment, err error) error
+}
+
+// FinishDownload satisfies DownloadSink method
+func (s WriterSink) FinishDownload(ctx context.Context, attachment Attachment, err error) error {
+	if s.OnFinish != nil {
+		return s.OnFinish(ctx, attachment, err)
+	}
+	return nil
+}
+
+// downloadSinks collects the sinks from every DownloadSinkChain found in the creator and options
+func downloadSinks(ctx context.Context, creator FileAttachmentCreator, url *url.URL, t Type, options []interface{}) ([]DownloadSink, error) {
+	var result []DownloadSink
+	chains := options
+	if creator != nil {
+		chains = append([]interface{}{creator}, options...)
+	}
+	for _, option := range chains {
+		if chain, ok := option.(DownloadSinkChain); ok {
+			var sinks []DownloadSink
+			var err error
+			guardPolicy("DownloadSinkChain", func() { sinks, err = chain.DownloadSinks(ctx, url, t) })
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, sinks...)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,261 @@
+// Package sqlstore is a resource.ContentStore kept in a SQLite or PostgreSQL database. Besides each page (encoded
+// with resource.MarshalStoredPage) the table has columns for its host, type and fetch date, which reporting and
+// editorial tools can query and join against, see Schema. It's a module of its own so that the resource package
+// doesn't depend on any database driver, open the database with whichever driver the application uses.
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lectio/resource"
+	"golang.org/x/xerrors"
+)
+
+// DefaultTable is the name of the table pages are stored in unless Store.Table says otherwise
+const DefaultTable = "lectio_pages"
+
+// Dialect is what differs between the SQL of the databases a Store supports
+type Dialect struct {
+	Name     string
+	blobType string
+	timeType string
+	jsonType string
+	// placeholder returns the n-th (1-based) parameter placeholder
+	placeholder func(n int) string
+	// timeValue returns what's stored for a time, so that times compare in order
+	timeValue func(t time.Time) interface{}
+}
+
+// SQLite stores times as fixed width UTC ISO 8601 text, which sorts in time order and which SQLite's date functions
+// understand
+var SQLite = Dialect{
+	Name:        "sqlite",
+	blobType:    "BLOB",
+	timeType:    "TEXT",
+	jsonType:    "TEXT",
+	placeholder: func(n int) string { return "?" },
+	timeValue:   func(t time.Time) interface{} { return t.UTC().Format("2006-01-02T15:04:05.000000000Z") },
+}
+
+// Postgres stores times as TIMESTAMPTZ and pages as JSONB
+var Postgres = Dialect{
+	Name:        "postgres",
+	blobType:    "BYTEA",
+	timeType:    "TIMESTAMPTZ",
+	jsonType:    "JSONB",
+	placeholder: func(n int) string { return fmt.Sprintf("$%d", n) },
+	timeValue:   func(t time.Time) interface{} { return t.UTC() },
+}
+
+// Schema returns the DDL of the table pages are stored in:
+//
+//	url              the page's TargetURL, the primary key
+//	host             the host of url, indexed
+//	media_type       e.g. "text/html", indexed
+//	title            the text of the HTML <title>
+//	language         the lang attribute of <html>
+//	word_count       of the text in the HTML <body>
+//	valid            false if the page couldn't be processed
+//	fetched_at       when the page was stored, indexed
+//	expires_at       from the cache headers or a ContentTTLPolicy, NULL if unknown
+//	etag             validators for conditional requests
+//	last_modified
+//	fingerprint      hex SHA-256 of the parsed body, for spotting changes
+//	attachment_path  where the page's downloaded attachment was written, NULL if there isn't one
+//	body             the retained HTML (see resource.RetainBodyPolicy), NULL if it wasn't retained
+//	body_encoding    how body is compressed (see resource.CompressedContentStore), NULL if it isn't
+//	page             the whole page as JSON, see resource.MarshalStoredPage
+func Schema(dialect Dialect, table string) []string {
+	return []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	url TEXT PRIMARY KEY,
+	host TEXT NOT NULL,
+	media_type TEXT NOT NULL,
+	title TEXT NOT NULL,
+	language TEXT NOT NULL,
+	word_count INTEGER NOT NULL,
+	valid BOOLEAN NOT NULL,
+	fetched_at %s NOT NULL,
+	expires_at %s,
+	etag TEXT NOT NULL,
+	last_modified TEXT NOT NULL,
+	fingerprint TEXT NOT NULL,
+	attachment_path TEXT,
+	body %s,
+	body_encoding TEXT,
+	page %s NOT NULL
+)`, table, dialect.timeType, dialect.timeType, dialect.blobType, dialect.jsonType),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_host ON %s (host)", table, table),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_media_type ON %s (media_type)", table, table),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_fetched_at ON %s (fetched_at)", table, table),
+	}
+}
+
+// columns are stored by StorePage in this order
+var columns = []string{"url", "host", "media_type", "title", "language", "word_count", "valid", "fetched_at", "expires_at",
+	"etag", "last_modified", "fingerprint", "attachment_path", "body", "body_encoding", "page"}
+
+// walkBatch is how many pages WalkPages reads at a time, so that fn may write to the store between batches
+const walkBatch = 100
+
+// Store is a resource.ContentStore kept in a table of a SQL database, safe for concurrent use
+type Store struct {
+	DB      *sql.DB
+	Dialect Dialect
+	Table   string         // DefaultTable if empty
+	Clock   resource.Clock // for the fetched_at column, the system clock if nil
+}
+
+// New creates a Store for db, call CreateSchema to create its table if it doesn't exist yet
+func New(db *sql.DB, dialect Dialect) *Store {
+	return &Store{DB: db, Dialect: dialect}
+}
+
+// CreateSchema creates the table and indexes of Schema if they don't exist
+func (s *Store) CreateSchema(ctx context.Context) error {
+	for _, statement := range Schema(s.Dialect, s.table()) {
+		if _, err := s.DB.ExecContext(ctx, statement); err != nil {
+			return xerrors.Errorf("Unable to create schema of %s: %w", s.table(), err)
+		}
+	}
+	return nil
+}
+
+// StorePage satisfies resource.ContentStore method, a page stored again replaces what was stored before
+func (s *Store) StorePage(ctx context.Context, page *resource.Page) error {
+	data, err := resource.MarshalStoredPage(page)
+	if err != nil {
+		return err
+	}
+	var host, mediaType string
+	if page.TargetURL != nil {
+		host = page.TargetURL.Hostname()
+	}
+	if page.PageType != nil {
+		mediaType = page.PageType.MediaType()
+	}
+	var expires, attachmentPath, body, bodyEncoding interface{}
+	if !page.Expires.IsZero() {
+		expires = s.Dialect.timeValue(page.Expires)
+	}
+	if attachment, ok := page.DownloadedAttachment.(*resource.FileAttachment); ok {
+		attachmentPath = attachment.DestPath
+	}
+	if page.Body != nil {
+		body = page.Body
+	}
+	if len(page.BodyEncoding) > 0 {
+		bodyEncoding = page.BodyEncoding
+	}
+
+	placeholders := make([]string, len(columns))
+	updates := make([]string, 0, len(columns)-1)
+	for i, column := range columns {
+		placeholders[i] = s.Dialect.placeholder(i + 1)
+		if i > 0 {
+			updates = append(updates, column+" = excluded."+column)
+		}
+	}
+	statement := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) ON CONFLICT (url) DO UPDATE SET %s",
+		s.table(), strings.Join(columns, ", "), strings.Join(placeholders, ", "), strings.Join(updates, ", "))
+	_, err = s.DB.ExecContext(ctx, statement, page.TargetURLText(), host, mediaType, page.Title, page.Language, page.WordCount,
+		page.IsValid(), s.Dialect.timeValue(s.clock().Now()), expires, page.ETag, page.LastModified, page.Fingerprint,
+		attachmentPath, body, bodyEncoding, string(data))
+	if err != nil {
+		return xerrors.Errorf("Unable to store %q in %s: %w", page.TargetURLText(), s.table(), err)
+	}
+	return nil
+}
+
+// LoadPage satisfies resource.ContentStore method, pages don't have their attachments when they're loaded
+func (s *Store) LoadPage(ctx context.Context, urlText string) (*resource.Page, bool, error) {
+	pages, err := s.queryPages(ctx, "url = "+s.Dialect.placeholder(1), "", urlText)
+	if err != nil || len(pages) == 0 {
+		return nil, false, err
+	}
+	return pages[0], true, nil
+}
+
+// WalkPages satisfies resource.ContentStore method, pages are walked in URL order
+func (s *Store) WalkPages(ctx context.Context, fn func(*resource.Page) error) error {
+	after := ""
+	for {
+		pages, err := s.queryPages(ctx, "url > "+s.Dialect.placeholder(1), fmt.Sprintf(" LIMIT %d", walkBatch), after)
+		if err != nil {
+			return err
+		}
+		for _, page := range pages {
+			if err := fn(page); err != nil {
+				return err
+			}
+		}
+		if len(pages) < walkBatch {
+			return nil
+		}
+		after = pages[len(pages)-1].TargetURLText()
+	}
+}
+
+// PagesByHost returns the pages of a host (e.g. "www.netspective.com"), in URL order
+func (s *Store) PagesByHost(ctx context.Context, host string) ([]*resource.Page, error) {
+	return s.queryPages(ctx, "host = "+s.Dialect.placeholder(1), "", host)
+}
+
+// PagesByMediaType returns the pages of a type (e.g. "text/html"), in URL order
+func (s *Store) PagesByMediaType(ctx context.Context, mediaType string) ([]*resource.Page, error) {
+	return s.queryPages(ctx, "media_type = "+s.Dialect.placeholder(1), "", mediaType)
+}
+
+// PagesFetchedBetween returns the pages stored at or after from and before to, in URL order
+func (s *Store) PagesFetchedBetween(ctx context.Context, from time.Time, to time.Time) ([]*resource.Page, error) {
+	where := fmt.Sprintf("fetched_at >= %s AND fetched_at < %s", s.Dialect.placeholder(1), s.Dialect.placeholder(2))
+	return s.queryPages(ctx, where, "", s.Dialect.timeValue(from), s.Dialect.timeValue(to))
+}
+
+func (s *Store) queryPages(ctx context.Context, where string, limit string, args ...interface{}) ([]*resource.Page, error) {
+	query := fmt.Sprintf("SELECT page, body, body_encoding FROM %s WHERE %s ORDER BY url%s", s.table(), where, limit)
+	rows, err := s.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to query %s: %w", s.table(), err)
+	}
+	defer rows.Close()
+
+	var result []*resource.Page
+	for rows.Next() {
+		var data string
+		var body []byte
+		var bodyEncoding sql.NullString
+		if err := rows.Scan(&data, &body, &bodyEncoding); err != nil {
+			return nil, xerrors.Errorf("Unable to read %s: %w", s.table(), err)
+		}
+		page, err := resource.UnmarshalStoredPage([]byte(data))
+		if err != nil {
+			return nil, err
+		}
+		page.Body = body
+		page.BodyEncoding = bodyEncoding.String
+		result = append(result, page)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, xerrors.Errorf("Unable to read %s: %w", s.table(), err)
+	}
+	return result, nil
+}
+
+func (s *Store) table() string {
+	if len(s.Table) > 0 {
+		return s.Table
+	}
+	return DefaultTable
+}
+
+func (s *Store) clock() resource.Clock {
+	if s.Clock != nil {
+		return s.Clock
+	}
+	return resource.SystemClock{}
+}
@@ -0,0 +1,154 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lectio/resource"
+	"github.com/stretchr/testify/suite"
+	_ "modernc.org/sqlite"
+)
+
+type StoreSuite struct {
+	suite.Suite
+	db    *sql.DB
+	clock *resource.ManualClock
+	store *Store
+}
+
+func (suite *StoreSuite) SetupTest() {
+	db, err := sql.Open("sqlite", ":memory:")
+	suite.Require().Nil(err, "Should not get an error")
+	// every connection to :memory: is a database of its own
+	db.SetMaxOpenConns(1)
+	suite.db = db
+	suite.clock = resource.NewManualClock(time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC))
+	suite.store = New(db, SQLite)
+	suite.store.Clock = suite.clock
+	suite.Require().Nil(suite.store.CreateSchema(context.Background()))
+}
+
+func (suite *StoreSuite) TearDownTest() {
+	suite.db.Close()
+}
+
+func (suite *StoreSuite) page(urlText string, contentType string, body string) *resource.Page {
+	archive := resource.NewMemoryResponseArchive()
+	archive.AddRaw(urlText, []byte(fmt.Sprintf("HTTP/1.1 200 OK\r\nContent-Type: %s\r\nETag: \"v1\"\r\nCache-Control: max-age=3600\r\n\r\n%s", contentType, body)))
+	content, err := resource.NewFactory(archive, suite.clock, resource.RetainBody(true)).PageFromURL(context.Background(), urlText)
+	suite.Require().Nil(err, "Should not get an error")
+	return content.(*resource.Page)
+}
+
+func (suite *StoreSuite) urls(pages []*resource.Page) []string {
+	var result []string
+	for _, page := range pages {
+		result = append(result, page.TargetURLText())
+	}
+	return result
+}
+
+func (suite *StoreSuite) TestStoreAndLoad() {
+	ctx := context.Background()
+	page := suite.page("https://www.netspective.com/", "text/html", `<html lang="en"><head><title>Netspective</title><meta property="og:site_name" content="Netspective"></head><body>Digital health</body></html>`)
+	suite.Nil(suite.store.StorePage(ctx, page))
+
+	loaded, ok, err := suite.store.LoadPage(ctx, "https://www.netspective.com/")
+	suite.True(ok)
+	suite.Nil(err, "Should not get an error")
+	suite.Equal("Netspective", loaded.Title)
+	suite.Equal(`"v1"`, loaded.ETag)
+	suite.Equal(page.Body, loaded.Body)
+	suite.True(loaded.IsValid())
+	value, _, _ := loaded.MetaTag("og:site_name")
+	suite.Equal("Netspective", value)
+
+	var host, mediaType, fetchedAt string
+	var wordCount int
+	suite.Nil(suite.db.QueryRow("SELECT host, media_type, word_count, fetched_at FROM lectio_pages").Scan(&host, &mediaType, &wordCount, &fetchedAt))
+	suite.Equal("www.netspective.com", host, "Reporting tools should be able to query the columns")
+	suite.Equal("text/html", mediaType)
+	suite.Equal(2, wordCount)
+	suite.Equal("2019-06-01T12:00:00.000000000Z", fetchedAt)
+
+	_, ok, err = suite.store.LoadPage(ctx, "https://www.netspective.com/missing")
+	suite.False(ok)
+	suite.Nil(err)
+}
+
+func (suite *StoreSuite) TestStoringAgainReplaces() {
+	ctx := context.Background()
+	suite.Nil(suite.store.StorePage(ctx, suite.page("https://www.netspective.com/", "text/html", "<title>Old</title>")))
+	suite.Nil(suite.store.StorePage(ctx, suite.page("https://www.netspective.com/", "text/html", "<title>New</title>")))
+	loaded, _, _ := suite.store.LoadPage(ctx, "https://www.netspective.com/")
+	suite.Equal("New", loaded.Title)
+
+	var count int
+	suite.db.QueryRow("SELECT COUNT(*) FROM lectio_pages").Scan(&count)
+	suite.Equal(1, count)
+}
+
+func (suite *StoreSuite) TestQueryHelpers() {
+	ctx := context.Background()
+	suite.Nil(suite.store.StorePage(ctx, suite.page("https://www.netspective.com/b", "text/html", "<title>B</title>")))
+	suite.clock.Advance(24 * time.Hour)
+	suite.Nil(suite.store.StorePage(ctx, suite.page("https://www.netspective.com/a", "text/html", "<title>A</title>")))
+	suite.Nil(suite.store.StorePage(ctx, suite.page("https://www.example.com/notes.md", "text/markdown", "# Notes")))
+
+	pages, err := suite.store.PagesByHost(ctx, "www.netspective.com")
+	suite.Nil(err, "Should not get an error")
+	suite.Equal([]string{"https://www.netspective.com/a", "https://www.netspective.com/b"}, suite.urls(pages))
+
+	pages, _ = suite.store.PagesByMediaType(ctx, "text/markdown")
+	suite.Equal([]string{"https://www.example.com/notes.md"}, suite.urls(pages))
+
+	day := time.Date(2019, 6, 2, 0, 0, 0, 0, time.UTC)
+	pages, _ = suite.store.PagesFetchedBetween(ctx, day, day.Add(24*time.Hour))
+	suite.Equal([]string{"https://www.example.com/notes.md", "https://www.netspective.com/a"}, suite.urls(pages))
+}
+
+func (suite *StoreSuite) TestWalkPagesInBatches() {
+	ctx := context.Background()
+	for i := 0; i < walkBatch+5; i++ {
+		suite.Nil(suite.store.StorePage(ctx, &resource.Page{TargetURL: suite.page(fmt.Sprintf("https://www.netspective.com/%03d", i), "text/html", "").TargetURL}))
+	}
+	var walked []string
+	err := suite.store.WalkPages(ctx, func(page *resource.Page) error {
+		walked = append(walked, page.TargetURLText())
+		// writing while walking shouldn't block
+		return suite.store.StorePage(ctx, page)
+	})
+	suite.Nil(err, "Should not get an error")
+	suite.Len(walked, walkBatch+5)
+	suite.Equal("https://www.netspective.com/000", walked[0])
+	suite.Equal("https://www.netspective.com/104", walked[len(walked)-1])
+}
+
+func (suite *StoreSuite) TestCompressedBodies() {
+	ctx := context.Background()
+	compressed := resource.NewCompressedContentStore(suite.store, nil)
+	page := suite.page("https://www.netspective.com/", "text/html", "<p>"+strings.Repeat("health ", 500)+"</p>")
+	suite.Nil(compressed.StorePage(ctx, page))
+
+	var encoding string
+	suite.db.QueryRow("SELECT body_encoding FROM lectio_pages").Scan(&encoding)
+	suite.Equal("gzip", encoding)
+	loaded, _, _ := compressed.LoadPage(ctx, "https://www.netspective.com/")
+	suite.Equal(page.Body, loaded.Body, "Bodies should be decompressed when they're loaded")
+}
+
+func (suite *StoreSuite) TestPostgresSchema() {
+	schema := strings.Join(Schema(Postgres, "pages"), ";\n")
+	suite.Contains(schema, "fetched_at TIMESTAMPTZ NOT NULL")
+	suite.Contains(schema, "page JSONB NOT NULL")
+	suite.Contains(schema, "CREATE INDEX IF NOT EXISTS pages_host ON pages (host)")
+	suite.Equal("$3", Postgres.placeholder(3))
+}
+
+func TestStoreSuite(t *testing.T) {
+	suite.Run(t, new(StoreSuite))
+}
@@ -0,0 +1,183 @@
+package resource
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// ContentStore keeps harvested pages, keyed by their TargetURL, so that they can be kept fresh
+type ContentStore interface {
+	StorePage(ctx context.Context, page *Page) error
+	LoadPage(ctx context.Context, urlText string) (*Page, bool, error)
+	WalkPages(ctx context.Context, fn func(*Page) error) error
+}
+
+// MemoryContentStore is a ContentStore for tests and small jobs, safe for concurrent use
+type MemoryContentStore struct {
+	mu    *sync.Mutex
+	pages map[string]*Page
+}
+
+// NewMemoryContentStore creates an empty MemoryContentStore
+func NewMemoryContentStore() *MemoryContentStore {
+	return &MemoryContentStore{mu: new(sync.Mutex), pages: make(map[string]*Page)}
+}
+
+// StorePage satisfies ContentStore method
+func (s *MemoryContentStore) StorePage(ctx context.Context, page *Page) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pages[page.TargetURLText()] = page
+	return nil
+}
+
+// LoadPage satisfies ContentStore method
+func (s *MemoryContentStore) LoadPage(ctx context.Context, urlText string) (*Page, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	page, ok := s.pages[urlText]
+	return page, ok, nil
+}
+
+// WalkPages satisfies ContentStore method, pages are walked in URL order
+func (s *MemoryContentStore) WalkPages(ctx context.Context, fn func(*Page) error) error {
+	s.mu.Lock()
+	urls := make([]string, 0, len(s.pages))
+	for urlText := range s.pages {
+		urls = append(urls, urlText)
+	}
+	s.mu.Unlock()
+
+	sort.Strings(urls)
+	for _, urlText := range urls {
+		page, ok, _ := s.LoadPage(ctx, urlText)
+		if !ok {
+			continue
+		}
+		if err := fn(page); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Kinds of RevalidationEvent
+const (
+	ContentUnchanged   = "unchanged"
+	ContentChanged     = "changed"
+	ContentGone        = "gone"
+	RevalidationFailed = "failed"
+)
+
+// RevalidationEvent reports the outcome of revalidating one stored page, New is what was stored in its place (nil if
+// the page is gone or revalidation failed) and Diff is set for changed pages
+type RevalidationEvent struct {
+	URL  string
+	Kind string
+	Old  *Page
+	New  *Page
+	Diff *PageDiff
+	Err  error
+}
+
+// RevalidationBatch controls a RevalidateStore sweep
+type RevalidationBatch struct {
+	MaxPages int                     // how many stale pages are revalidated, zero means all of them
+	Interval time.Duration           // the least time between requests, to limit the load on the hosts
+	OnEvent  func(RevalidationEvent) // called with each page's outcome, e.g. to act on changes
+}
+
+// RevalidateStore walks the pages in store which are stale at the factory's Clock time and revalidates them with
+// conditional GETs (If-None-Match and If-Modified-Since), storing what's fresh. Pages which answer 404 or 410 are
+// reported as gone but left in the store. The number of pages revalidated is returned.
+func (f *DefaultFactory) RevalidateStore(ctx context.Context, store ContentStore, batch RevalidationBatch) (_ int, err error) {
+	defer recoverPolicyPanic(&err)
+	now := f.clock().Now()
+	var stale []*Page
+	err = store.WalkPages(ctx, func(page *Page) error {
+		if page.IsStale(now) {
+			stale = append(stale, page)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, xerrors.Errorf("Unable to walk ContentStore: %w", err)
+	}
+	if batch.MaxPages > 0 && len(stale) > batch.MaxPages {
+		stale = stale[:batch.MaxPages]
+	}
+
+	for i, page := range stale {
+		if i > 0 && batch.Interval > 0 {
+			timer := f.clock().NewTimer(batch.Interval)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return i, ctx.Err()
+			case <-timer.C():
+			}
+		}
+
+		event := f.routed(page.TargetURLText()).revalidate(ctx, page)
+		if event.New != nil {
+			if err := store.StorePage(ctx, event.New); err != nil {
+				event.Kind, event.New, event.Err = RevalidationFailed, nil, xerrors.Errorf("Unable to store revalidated page: %w", err)
+			}
+		}
+		if event.Kind == ContentChanged {
+			f.emit(ctx, Event{Type: EventContentChanged, URL: event.URL, Page: event.New, Diff: event.Diff})
+		}
+		if batch.OnEvent != nil {
+			batch.OnEvent(event)
+		}
+	}
+	return len(stale), nil
+}
+
+// revalidate conditionally fetches the page again
+func (f *DefaultFactory) revalidate(ctx context.Context, old *Page) RevalidationEvent {
+	event := RevalidationEvent{URL: old.TargetURLText(), Old: old}
+	resp, err := f.fetch(ctx, event.URL, ValidatorsOf(old).header())
+	if err != nil {
+		var statusErr *InvalidHTTPRespStatusCodeError
+		if !xerrors.As(err, &statusErr) {
+			event.Kind, event.Err = RevalidationFailed, err
+			return event
+		}
+		switch statusErr.HTTPStatusCode {
+		case http.StatusNotModified:
+			fresh := *old
+			fresh.Expires = f.contentExpiry(ctx, old.TargetURL, &http.Response{Header: statusErr.Header}, old.PageType)
+			if etag := statusErr.Header.Get("ETag"); len(etag) > 0 {
+				fresh.ETag = etag
+			}
+			event.Kind, event.New = ContentUnchanged, &fresh
+		case http.StatusNotFound, http.StatusGone:
+			event.Kind, event.Err = ContentGone, err
+		default:
+			event.Kind, event.Err = RevalidationFailed, err
+		}
+		return event
+	}
+
+	content, err := f.pageFromHTTPResponse(ctx, resp.Request.URL, resp, old.Annotations)
+	page, ok := content.(*Page)
+	if err != nil || !ok {
+		event.Kind, event.Err = RevalidationFailed, err
+		return event
+	}
+	event.New = page
+	// servers without validators answer every request in full, so what was harvested decides
+	diff := DiffPages(*old, *page)
+	if !diff.HasChanges() && len(old.ETag) == 0 && len(old.LastModified) == 0 {
+		event.Kind = ContentUnchanged
+		return event
+	}
+	event.Kind, event.Diff = ContentChanged, &diff
+	return event
+}
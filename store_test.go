@@ -0,0 +1,104 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type StoreSuite struct {
+	suite.Suite
+}
+
+func (suite *StoreSuite) TestRevalidateStore() {
+	var mu sync.Mutex
+	versions := map[string]int{"/a": 1, "/b": 1, "/c": 1}
+	conditional := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		version, ok := versions[r.URL.Path]
+		if len(r.Header.Get("If-None-Match")) > 0 {
+			conditional++
+		}
+		mu.Unlock()
+		if !ok {
+			w.WriteHeader(http.StatusGone)
+			return
+		}
+		etag := fmt.Sprintf(`"%s-v%d"`, r.URL.Path, version)
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", "max-age=60")
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprintf(w, `<html><head><meta name="version" content="%d"></head></html>`, version)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	clock := NewManualClock(time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC))
+	factory := NewFactory(clock)
+	store := NewMemoryContentStore()
+	for _, path := range []string{"/a", "/b", "/c"} {
+		content, err := factory.PageFromURL(ctx, server.URL+path)
+		suite.Nil(err, "Should not get an error")
+		suite.Nil(store.StorePage(ctx, content.(*Page)))
+	}
+
+	count, err := factory.RevalidateStore(ctx, store, RevalidationBatch{})
+	suite.Nil(err, "Should not get an error")
+	suite.Equal(0, count, "Fresh pages should not be revalidated")
+
+	mu.Lock()
+	versions["/b"] = 2
+	delete(versions, "/c")
+	mu.Unlock()
+	clock.Advance(2 * time.Minute)
+
+	done := make(chan struct{})
+	go func() {
+		// stand in for the passing of the rate limiting interval
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if clock.Timers() > 0 {
+				clock.Advance(time.Second)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	events := make(map[string]RevalidationEvent)
+	count, err = factory.RevalidateStore(ctx, store, RevalidationBatch{Interval: time.Second, OnEvent: func(event RevalidationEvent) {
+		events[event.URL] = event
+	}})
+	close(done)
+	suite.Nil(err, "Should not get an error")
+	suite.Equal(3, count)
+	suite.Equal(3, conditional, "Every revalidation should be conditional")
+
+	suite.Equal(ContentUnchanged, events[server.URL+"/a"].Kind)
+	refreshed, _, _ := store.LoadPage(ctx, server.URL+"/a")
+	suite.False(refreshed.IsStale(clock.Now()), "Unchanged page should be fresh again")
+
+	suite.Equal(ContentChanged, events[server.URL+"/b"].Kind)
+	changed, _, _ := store.LoadPage(ctx, server.URL+"/b")
+	value, _, _ := changed.MetaTag("version")
+	suite.Equal("2", value)
+
+	suite.Equal(ContentGone, events[server.URL+"/c"].Kind)
+}
+
+func TestStoreSuite(t *testing.T) {
+	suite.Run(t, new(StoreSuite))
+}
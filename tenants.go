@@ -0,0 +1,292 @@
+package resource
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// The limits of a TenantPolicy, as reported in TenantRefusedError.Limit
+const (
+	TenantLimitHosts = "hosts"
+	TenantLimitPages = "pages"
+)
+
+// TenantPolicy is what a tenant is allowed to do. Its Bundle overrides the factory's own options for the tenant's
+// requests, AllowedHosts are DomainPolicyRoute patterns (none means every host is allowed), requests are limited to
+// RequestsPerSecond (zero means unlimited) in bursts of up to Burst, and MaxPages is how many URLs the tenant may
+// resolve until its usage is reset (zero means unlimited).
+//
+// With a HostStatsStore the rate limit adapts to the health of each host: a request to a host whose requests mostly
+// fail, or whose average latency is above TargetLatency (zero means latency isn't considered), costs more than one
+// request so the tenant slows down for it.
+type TenantPolicy struct {
+	Bundle            PolicyBundle
+	AllowedHosts      []string
+	RequestsPerSecond float64
+	Burst             int
+	MaxPages          int64
+	TargetLatency     time.Duration
+}
+
+// AllowsHost returns true if host matches one of the AllowedHosts, or there aren't any
+func (p TenantPolicy) AllowsHost(host string) bool {
+	if len(p.AllowedHosts) == 0 {
+		return true
+	}
+	for _, pattern := range p.AllowedHosts {
+		if (DomainPolicyRoute{Pattern: pattern}).Matches(host) {
+			return true
+		}
+	}
+	return false
+}
+
+// burst returns how many requests may be sent at once, at least one
+func (p TenantPolicy) burst() float64 {
+	if p.Burst < 1 {
+		return 1
+	}
+	return float64(p.Burst)
+}
+
+// hostPaceMinRequests is how many requests a host needs in its HostStats before its health changes the pace, and
+// maxHostPace is the most a single request can cost
+const (
+	hostPaceMinRequests = 5
+	maxHostPace         = 10
+)
+
+// pace returns how many requests of the rate limit a request to a host with stats costs, 1 for a healthy host
+func (p TenantPolicy) pace(stats HostStats) float64 {
+	if stats.Requests < hostPaceMinRequests {
+		return 1
+	}
+	cost := float64(maxHostPace)
+	if rate := stats.SuccessRate(); rate > 0 {
+		cost = 1 / rate
+	}
+	if latency := stats.AverageLatency(); p.TargetLatency > 0 && latency > p.TargetLatency {
+		cost *= float64(latency) / float64(p.TargetLatency)
+	}
+	if cost > maxHostPace {
+		return maxHostPace
+	}
+	return cost
+}
+
+// TenantUsage is how much a tenant has used since its usage was last reset
+type TenantUsage struct {
+	Pages   int64 `json:"pages"`   // the URLs the tenant was allowed to resolve
+	Refused int64 `json:"refused"` // the URLs refused because of the tenant's allowed hosts or quota
+}
+
+// TenantRouter is passed into options to serve several tenants from one factory. The tenant of each request is the
+// Tenant of the context's RequestIdentity (see ContextWithTenant), requests without a tenant, or with one that hasn't
+// been given a policy, are subject to Default. Every tenant gets its own rate limit, quota and host profile cache, and
+// its responses are cached apart from other tenants' (see TenantCacheKey).
+type TenantRouter struct {
+	Default TenantPolicy
+
+	mutex    sync.Mutex
+	policies map[string]TenantPolicy
+	states   map[string]*tenantState
+}
+
+type tenantState struct {
+	usage        TenantUsage
+	tokens       float64
+	refilled     time.Time
+	hostProfiles *hostProfileCache
+}
+
+// NewTenantRouter creates a router where every tenant is subject to the empty (unrestricted) Default policy
+func NewTenantRouter() *TenantRouter {
+	return &TenantRouter{policies: make(map[string]TenantPolicy), states: make(map[string]*tenantState)}
+}
+
+// Tenant sets the policy of a tenant, it returns the router so tenants can be chained
+func (r *TenantRouter) Tenant(tenant string, policy TenantPolicy) *TenantRouter {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.init()
+	r.policies[tenant] = policy
+	return r
+}
+
+// PolicyFor returns the policy of tenant, Default if it doesn't have its own
+func (r *TenantRouter) PolicyFor(tenant string) TenantPolicy {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.policyFor(tenant)
+}
+
+// Usage returns how much tenant has used since its usage was last reset
+func (r *TenantRouter) Usage(tenant string) TenantUsage {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if state, ok := r.states[tenant]; ok {
+		return state.usage
+	}
+	return TenantUsage{}
+}
+
+// ResetUsage starts tenant's quota over, e.g. at the start of a billing period
+func (r *TenantRouter) ResetUsage(tenant string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if state, ok := r.states[tenant]; ok {
+		state.usage = TenantUsage{}
+	}
+}
+
+func (r *TenantRouter) init() {
+	if r.policies == nil {
+		r.policies = make(map[string]TenantPolicy)
+	}
+	if r.states == nil {
+		r.states = make(map[string]*tenantState)
+	}
+}
+
+func (r *TenantRouter) policyFor(tenant string) TenantPolicy {
+	if policy, ok := r.policies[tenant]; ok {
+		return policy
+	}
+	return r.Default
+}
+
+// stateFor returns the state of tenant, starting with a full rate limit bucket the first time
+func (r *TenantRouter) stateFor(tenant string, now time.Time) *tenantState {
+	r.init()
+	state, ok := r.states[tenant]
+	if !ok {
+		state = &tenantState{
+			tokens:       r.policyFor(tenant).burst(),
+			refilled:     now,
+			hostProfiles: &hostProfileCache{profiles: make(map[string]*HostProfile)}}
+		r.states[tenant] = state
+	}
+	return state
+}
+
+// hostProfiles returns the host profile cache of tenant
+func (r *TenantRouter) hostProfiles(tenant string, now time.Time) *hostProfileCache {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.stateFor(tenant, now).hostProfiles
+}
+
+// admit checks tenant's allowed hosts and quota, and takes cost tokens from its rate limit. It returns how long to
+// wait before the request may be sent, along with the tenant's policy and state.
+func (r *TenantRouter) admit(tenant string, host string, cost float64, now time.Time) (TenantPolicy, *tenantState, time.Duration, string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	policy := r.policyFor(tenant)
+	state := r.stateFor(tenant, now)
+	if !policy.AllowsHost(host) {
+		state.usage.Refused++
+		return policy, state, 0, TenantLimitHosts
+	}
+	if policy.MaxPages > 0 && state.usage.Pages >= policy.MaxPages {
+		state.usage.Refused++
+		return policy, state, 0, TenantLimitPages
+	}
+	state.usage.Pages++
+
+	if policy.RequestsPerSecond <= 0 {
+		return policy, state, 0, ""
+	}
+	burst := policy.burst()
+	if elapsed := now.Sub(state.refilled); elapsed > 0 {
+		state.tokens += elapsed.Seconds() * policy.RequestsPerSecond
+		if state.tokens > burst {
+			state.tokens = burst
+		}
+		state.refilled = now
+	}
+	state.tokens -= cost
+	if state.tokens >= 0 {
+		return policy, state, 0, ""
+	}
+	return policy, state, time.Duration(-state.tokens / policy.RequestsPerSecond * float64(time.Second)), ""
+}
+
+// cancel gives back what admit took for a request which wasn't sent after all
+func (r *TenantRouter) cancel(state *tenantState, cost float64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	state.usage.Pages--
+	state.tokens += cost
+}
+
+// hostPace returns how many requests of tenant's rate limit a request to host costs, from the host's HostStats. The
+// statistics are advisory so a store which fails is treated as having none.
+func (f *DefaultFactory) hostPace(ctx context.Context, tenant string, host string) float64 {
+	if f.HostStatsStore == nil || len(host) == 0 {
+		return 1
+	}
+	policy := f.TenantRouter.PolicyFor(tenant)
+	if policy.RequestsPerSecond <= 0 {
+		return 1
+	}
+	var stats HostStats
+	var err error
+	guardPolicy("HostStatsStore", func() { stats, _, err = f.HostStatsStore.HostStats(ctx, host) })
+	if err != nil {
+		return 1
+	}
+	return policy.pace(stats)
+}
+
+// tenanted returns the factory to use for the context's tenant, a copy of f with the tenant's bundle and host profile
+// cache. It waits for the tenant's rate limit, paced by the host's health, and returns a TenantRefusedError if the
+// tenant may not resolve urlText.
+func (f *DefaultFactory) tenanted(ctx context.Context, urlText string) (*DefaultFactory, error) {
+	if f.TenantRouter == nil {
+		return f, nil
+	}
+	var host, statsHost string
+	if target, err := url.Parse(urlText); err == nil {
+		host, statsHost = target.Hostname(), hostKey(target)
+	}
+	tenant := RequestIdentityFromContext(ctx).Tenant
+	cost := f.hostPace(ctx, tenant, statsHost)
+	policy, state, wait, refused := f.TenantRouter.admit(tenant, host, cost, f.clock().Now())
+	if len(refused) > 0 {
+		return nil, &TenantRefusedError{
+			URL:    urlText,
+			Tenant: tenant,
+			Limit:  refused,
+			Frame:  xerrors.Caller(xErrorsFrameCaller),
+			Frames: callerFrames(xErrorsFrameCaller)}
+	}
+	if wait > 0 {
+		timer := f.clock().NewTimer(wait)
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+			timer.Stop()
+			f.TenantRouter.cancel(state, cost)
+			return nil, requestError(urlText, "Waiting for tenant's rate limit", ctx.Err(), callerFrames(xErrorsFrameCaller))
+		}
+	}
+
+	result := f.requestCopy()
+	result.initOptions(policy.Bundle)
+	result.hostProfiles = state.hostProfiles
+	// the tenant's factory is only used for this request so it mustn't be admitted again
+	result.TenantRouter = nil
+	return result, nil
+}
+
+// profileCache returns the host profile cache of the context's tenant, or the factory's own without a TenantRouter
+func (f *DefaultFactory) profileCache(ctx context.Context) *hostProfileCache {
+	if f.TenantRouter != nil {
+		return f.TenantRouter.hostProfiles(RequestIdentityFromContext(ctx).Tenant, f.clock().Now())
+	}
+	return f.hostProfiles
+}
@@ -0,0 +1,173 @@
+package resource
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/xerrors"
+)
+
+type TenantsSuite struct {
+	suite.Suite
+	archive *MemoryResponseArchive
+}
+
+func (suite *TenantsSuite) SetupTest() {
+	suite.archive = NewMemoryResponseArchive()
+	suite.archive.Add("https://www.netspective.com/", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, testHTMLPage))
+	suite.archive.Add("https://docs.example.com/paper.pdf", archivedResponse(200, http.Header{"Content-Type": {"application/pdf"}}, testPDFContent))
+}
+
+func (suite *TenantsSuite) TestAllowedHosts() {
+	router := NewTenantRouter().Tenant("acme", TenantPolicy{AllowedHosts: []string{"*.example.com"}})
+	factory := NewFactory(suite.archive, router)
+	acme := ContextWithTenant(context.Background(), "acme")
+
+	_, err := factory.PageFromURL(acme, "https://docs.example.com/paper.pdf")
+	suite.Nil(err, "Allowed host should be resolved")
+
+	_, err = factory.PageFromURL(acme, "https://www.netspective.com/")
+	var refused *TenantRefusedError
+	suite.True(xerrors.As(err, &refused), "Host which isn't allowed should be refused")
+	suite.Equal(TenantLimitHosts, refused.Limit)
+	suite.Equal("acme", refused.Tenant)
+	suite.Equal(ErrorCategoryPolicy, CategoryOf(err))
+
+	_, err = factory.PageFromURL(context.Background(), "https://www.netspective.com/")
+	suite.Nil(err, "Requests without a tenant should be subject to the unrestricted default")
+	suite.Equal(TenantUsage{Pages: 1, Refused: 1}, router.Usage("acme"))
+}
+
+func (suite *TenantsSuite) TestQuota() {
+	router := NewTenantRouter().
+		Tenant("acme", TenantPolicy{MaxPages: 1}).
+		Tenant("globex", TenantPolicy{MaxPages: 2})
+	factory := NewFactory(suite.archive, router)
+	acme := ContextWithTenant(context.Background(), "acme")
+	globex := ContextWithTenant(context.Background(), "globex")
+
+	_, err := factory.PageFromURL(acme, "https://www.netspective.com/")
+	suite.Nil(err, "Should not get an error")
+	_, err = factory.PageFromURL(acme, "https://www.netspective.com/")
+	suite.Equal(ErrorCodeTenantRefused, CodeOf(err), "Quota should be used up")
+
+	_, err = factory.PageFromURL(globex, "https://www.netspective.com/")
+	suite.Nil(err, "Quotas should be kept per tenant")
+
+	router.ResetUsage("acme")
+	_, err = factory.PageFromURL(acme, "https://www.netspective.com/")
+	suite.Nil(err, "Reset quota should allow requests again")
+}
+
+func (suite *TenantsSuite) TestTenantBundle() {
+	router := NewTenantRouter().Tenant("acme", TenantPolicy{Bundle: NewPolicyBundle("downloads", NewMemoryAttachmentCreator(nil))})
+	factory := NewFactory(suite.archive, router)
+
+	content, err := factory.PageFromURL(ContextWithTenant(context.Background(), "acme"), "https://docs.example.com/paper.pdf")
+	suite.Nil(err, "Should not get an error")
+	suite.NotNil(content.Attachment(), "Tenant's bundle should enable downloads")
+
+	content, err = factory.PageFromURL(ContextWithTenant(context.Background(), "globex"), "https://docs.example.com/paper.pdf")
+	suite.Nil(err, "Should not get an error")
+	suite.Nil(content.Attachment(), "Other tenants should use the factory's own options")
+	suite.Nil(factory.FileAttachmentCreator, "Tenants should not change the factory")
+}
+
+func (suite *TenantsSuite) TestHostProfilesAreIsolated() {
+	factory := NewFactory(suite.archive, NewTenantRouter())
+	acme := ContextWithTenant(context.Background(), "acme")
+
+	profile, err := factory.HostProfile(acme, "www.netspective.com")
+	suite.Nil(err, "Should not get an error")
+	cached, _ := factory.HostProfile(acme, "www.netspective.com")
+	suite.True(profile == cached, "Tenant's profiles should be cached")
+	other, _ := factory.HostProfile(ContextWithTenant(context.Background(), "globex"), "www.netspective.com")
+	suite.False(profile == other, "Tenants should not share profiles")
+}
+
+func (suite *TenantsSuite) TestRateLimit() {
+	clock := NewManualClock(time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC))
+	router := NewTenantRouter().Tenant("acme", TenantPolicy{RequestsPerSecond: 1, Burst: 1})
+	factory := NewFactory(suite.archive, clock, router)
+	acme := ContextWithTenant(context.Background(), "acme")
+
+	_, err := factory.PageFromURL(acme, "https://www.netspective.com/")
+	suite.Nil(err, "The first request should be sent right away")
+	suite.Equal(0, clock.Timers())
+
+	done := make(chan error)
+	go func() {
+		_, err := factory.PageFromURL(acme, "https://www.netspective.com/")
+		done <- err
+	}()
+	for clock.Timers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case <-done:
+		suite.Fail("The second request should wait for the rate limit")
+	default:
+	}
+	clock.Advance(time.Second)
+	suite.Nil(<-done, "Should not get an error")
+
+	ctx, cancel := context.WithCancel(acme)
+	cancel()
+	_, err = factory.PageFromURL(ctx, "https://www.netspective.com/")
+	suite.True(xerrors.Is(err, context.Canceled), "Cancelled wait should fail")
+	suite.Equal(int64(2), router.Usage("acme").Pages, "Cancelled request should not count")
+}
+
+func (suite *TenantsSuite) TestRateLimitAdaptsToHostStats() {
+	clock := NewManualClock(time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC))
+	stats := NewMemoryHostStatsStore()
+	for index := 0; index < 10; index++ {
+		var err error
+		if index%2 == 1 {
+			err = xerrors.New("connection reset")
+		}
+		stats.RecordFetch(context.Background(), "www.netspective.com", HostFetchResult{At: clock.Now(), Latency: time.Second, Err: err})
+	}
+	router := NewTenantRouter().Tenant("acme", TenantPolicy{RequestsPerSecond: 1, Burst: 2})
+	factory := NewFactory(suite.archive, clock, router, stats)
+	acme := ContextWithTenant(context.Background(), "acme")
+
+	_, err := factory.PageFromURL(acme, "https://docs.example.com/paper.pdf")
+	suite.Nil(err, "A host without statistics should cost one request")
+	_, err = factory.PageFromURL(acme, "https://docs.example.com/paper.pdf")
+	suite.Nil(err, "The burst should allow a second request to a healthy host")
+	suite.Equal(0, clock.Timers())
+
+	clock.Advance(2 * time.Second)
+	_, err = factory.PageFromURL(acme, "https://www.netspective.com/")
+	suite.Nil(err, "Should not get an error")
+	done := make(chan error)
+	go func() {
+		_, err := factory.PageFromURL(acme, "https://www.netspective.com/")
+		done <- err
+	}()
+	for clock.Timers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	clock.Advance(time.Second)
+	select {
+	case <-done:
+		suite.Fail("A host whose requests mostly fail should cost more than one request")
+	default:
+	}
+	clock.Advance(time.Second)
+	suite.Nil(<-done, "Should not get an error")
+
+	suite.Equal(1.0, TenantPolicy{}.pace(HostStats{Requests: 4}), "Too few requests shouldn't change the pace")
+	suite.Equal(2.0, TenantPolicy{}.pace(HostStats{Requests: 10, Successes: 5}))
+	suite.Equal(1.0, TenantPolicy{}.pace(HostStats{Requests: 10, Successes: 10, TotalLatency: time.Minute}), "Latency is only considered with a target")
+	suite.Equal(3.0, TenantPolicy{TargetLatency: time.Second}.pace(HostStats{Requests: 10, Successes: 10, TotalLatency: 30 * time.Second}))
+	suite.Equal(float64(maxHostPace), TenantPolicy{}.pace(HostStats{Requests: 10}))
+}
+
+func TestTenantsSuite(t *testing.T) {
+	suite.Run(t, new(TenantsSuite))
+}
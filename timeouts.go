@@ -0,0 +1,127 @@
+package resource
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RequestTimeoutPolicy is passed into options if requests should have per-host timeouts instead of only the HTTP
+// client's overall timeout, which still applies and so caps the policy's timeouts
+type RequestTimeoutPolicy interface {
+	RequestTimeout(context.Context, *url.URL) time.Duration
+}
+
+// HostFetchObserver may be implemented by a RequestTimeoutPolicy that learns from the outcome of every fetch
+type HostFetchObserver interface {
+	ObserveHostFetch(ctx context.Context, host string, result HostFetchResult)
+}
+
+// AdaptiveTimeouts is a RequestTimeoutPolicy which learns the latency percentiles of each host and sets timeouts to
+// a multiple of them, within Floor and Ceiling. Hosts without enough samples get Ceiling, hosts which keep failing
+// get Floor so that dead hosts waste as little time as possible.
+type AdaptiveTimeouts struct {
+	Floor               time.Duration
+	Ceiling             time.Duration
+	Percentile          float64 // e.g. 0.95 for the 95th percentile of recent fetch durations
+	Multiplier          float64 // headroom over the percentile
+	MinSamples          int     // successful fetches needed before the timeout adapts
+	MaxSamples          int     // recent fetch durations remembered per host
+	ConsecutiveFailures int     // failures in a row after which a host is treated as dead
+
+	hosts *adaptiveTimeoutHosts
+}
+
+type adaptiveTimeoutHosts struct {
+	mu    sync.Mutex
+	hosts map[string]*adaptiveTimeoutHost
+}
+
+type adaptiveTimeoutHost struct {
+	samples  []time.Duration
+	next     int
+	failures int
+}
+
+// NewAdaptiveTimeouts creates an AdaptiveTimeouts policy with timeouts of 3x the 95th percentile between floor and ceiling
+func NewAdaptiveTimeouts(floor time.Duration, ceiling time.Duration) *AdaptiveTimeouts {
+	return &AdaptiveTimeouts{
+		Floor:               floor,
+		Ceiling:             ceiling,
+		Percentile:          0.95,
+		Multiplier:          3,
+		MinSamples:          5,
+		MaxSamples:          100,
+		ConsecutiveFailures: 3,
+		hosts:               &adaptiveTimeoutHosts{hosts: make(map[string]*adaptiveTimeoutHost)},
+	}
+}
+
+// RequestTimeout satisfies RequestTimeoutPolicy method
+func (t *AdaptiveTimeouts) RequestTimeout(ctx context.Context, url *url.URL) time.Duration {
+	if t.hosts == nil {
+		// not created by NewAdaptiveTimeouts so nothing can be learned
+		return t.Ceiling
+	}
+	t.hosts.mu.Lock()
+	host, ok := t.hosts.hosts[hostKey(url)]
+	var samples []time.Duration
+	var failures int
+	if ok {
+		samples = append(samples, host.samples...)
+		failures = host.failures
+	}
+	t.hosts.mu.Unlock()
+
+	if t.ConsecutiveFailures > 0 && failures >= t.ConsecutiveFailures {
+		return t.Floor
+	}
+	if len(samples) == 0 || len(samples) < t.MinSamples {
+		return t.Ceiling
+	}
+
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	index := int(float64(len(samples)-1) * t.Percentile)
+	timeout := time.Duration(float64(samples[index]) * t.Multiplier)
+	if timeout < t.Floor {
+		return t.Floor
+	}
+	if t.Ceiling > 0 && timeout > t.Ceiling {
+		return t.Ceiling
+	}
+	return timeout
+}
+
+// ObserveHostFetch satisfies HostFetchObserver method
+func (t *AdaptiveTimeouts) ObserveHostFetch(ctx context.Context, hostName string, result HostFetchResult) {
+	if t.hosts == nil {
+		return
+	}
+	t.hosts.mu.Lock()
+	defer t.hosts.mu.Unlock()
+	key := strings.ToLower(hostName)
+	host, ok := t.hosts.hosts[key]
+	if !ok {
+		host = new(adaptiveTimeoutHost)
+		t.hosts.hosts[key] = host
+	}
+
+	if result.Err != nil {
+		host.failures++
+		return
+	}
+	host.failures = 0
+	duration := result.Duration
+	if duration == 0 {
+		duration = result.Latency
+	}
+	if len(host.samples) < t.MaxSamples || t.MaxSamples <= 0 {
+		host.samples = append(host.samples, duration)
+		return
+	}
+	host.samples[host.next] = duration
+	host.next = (host.next + 1) % len(host.samples)
+}
@@ -0,0 +1,63 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TimeoutsSuite struct {
+	suite.Suite
+}
+
+func (suite *TimeoutsSuite) TestAdaptiveTimeouts() {
+	ctx := context.Background()
+	policy := NewAdaptiveTimeouts(time.Second, 30*time.Second)
+	u, _ := url.Parse("https://www.netspective.com/")
+
+	suite.Equal(30*time.Second, policy.RequestTimeout(ctx, u), "Unknown hosts should get the ceiling")
+
+	for i := 1; i <= 10; i++ {
+		policy.ObserveHostFetch(ctx, "www.netspective.com", HostFetchResult{Duration: time.Duration(i) * 100 * time.Millisecond})
+	}
+	suite.Equal(2700*time.Millisecond, policy.RequestTimeout(ctx, u), "Timeout should be 3x the 95th percentile")
+
+	for i := 0; i < 10; i++ {
+		policy.ObserveHostFetch(ctx, "www.netspective.com", HostFetchResult{Duration: time.Minute})
+	}
+	suite.Equal(30*time.Second, policy.RequestTimeout(ctx, u), "Timeout should not exceed the ceiling")
+
+	for i := 0; i < 3; i++ {
+		policy.ObserveHostFetch(ctx, "WWW.netspective.com", HostFetchResult{Err: errors.New("dial tcp: i/o timeout")})
+	}
+	suite.Equal(time.Second, policy.RequestTimeout(ctx, u), "Dead hosts should get the floor")
+}
+
+func (suite *TimeoutsSuite) TestTimeoutIsApplied() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(2 * time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+
+	policy := NewAdaptiveTimeouts(50*time.Millisecond, 50*time.Millisecond)
+	_, err := NewFactory(policy).PageFromURL(context.Background(), server.URL)
+	suite.NotNil(err, "Request should time out")
+
+	u, _ := url.Parse(server.URL)
+	policy.ConsecutiveFailures = 1
+	suite.Equal(50*time.Millisecond, policy.RequestTimeout(context.Background(), u))
+	suite.Equal(1, policy.hosts.hosts[u.Host].failures, "Timeout should be observed as a failure")
+}
+
+func TestTimeoutsSuite(t *testing.T) {
+	suite.Run(t, new(TimeoutsSuite))
+}
@@ -0,0 +1,155 @@
+package resource
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/xerrors"
+)
+
+// DetectTrackersPolicy is passed into options if HTML pages should be checked for references to ad and tracking
+// domains, which are listed in the Page's Trackers
+type DetectTrackersPolicy interface {
+	DetectTrackers(ctx context.Context, url *url.URL) bool
+}
+
+// DetectTrackers is a DetectTrackersPolicy with the same answer for every page
+type DetectTrackers bool
+
+// DetectTrackers satisfies DetectTrackersPolicy method
+func (d DetectTrackers) DetectTrackers(ctx context.Context, url *url.URL) bool {
+	return bool(d)
+}
+
+// TrackerDomains is passed into options to recognize more ad and tracking domains than DefaultTrackerDomains, such as
+// the host set of EasyList or EasyPrivacy read with ParseTrackerDomains. Subdomains of each domain match too.
+type TrackerDomains []string
+
+// DefaultTrackerDomains are well-known ad and tracking domains, always recognized
+var DefaultTrackerDomains = TrackerDomains{
+	"google-analytics.com", "googletagmanager.com", "googletagservices.com", "googlesyndication.com",
+	"googleadservices.com", "doubleclick.net", "adservice.google.com", "connect.facebook.net", "facebook.com/tr",
+	"ads-twitter.com", "analytics.twitter.com", "bat.bing.com", "clarity.ms", "scorecardresearch.com",
+	"quantserve.com", "quantcount.com", "chartbeat.com", "chartbeat.net", "hotjar.com", "mixpanel.com",
+	"cdn.segment.com", "api.segment.io", "amazon-adsystem.com", "adnxs.com", "criteo.com", "criteo.net",
+	"taboola.com", "outbrain.com", "pubmatic.com", "rubiconproject.com", "openx.net", "moatads.com", "adsrvr.org",
+	"krxd.net", "bluekai.com", "demdex.net", "omtrdc.net", "everesttech.net", "mc.yandex.ru", "addthis.com",
+	"sharethis.com", "newrelic.com", "nr-data.net", "optimizely.com", "fullstory.com", "crazyegg.com",
+	"mouseflow.com", "parsely.com", "parse.ly", "snap.licdn.com", "ads.linkedin.com", "ct.pinterest.com",
+	"analytics.tiktok.com", "static.hotjar.com", "adroll.com", "bidswitch.net", "casalemedia.com", "smartadserver.com",
+}
+
+// abpHostRuleRegEx matches Adblock Plus host rules such as ||example.com^ and ||example.com^$third-party
+var abpHostRuleRegEx = regexp.MustCompile(`^\|\|([a-z0-9.-]+)\^(?:\$(?:third-party|3p|script|image|subdocument|,)+)?$`)
+
+// scriptURLRegEx finds protocol-relative or absolute URLs in inline scripts, e.g. '//www.google-analytics.com/analytics.js'
+var scriptURLRegEx = regexp.MustCompile(`(?i)(?:https?:)?//([a-z0-9](?:[a-z0-9-]*[a-z0-9])?(?:\.[a-z0-9](?:[a-z0-9-]*[a-z0-9])?)+)(/[^\s"'<>()]*)?`)
+
+// ParseTrackerDomains reads a list of domains, one per line, in the Adblock Plus (EasyList) "||example.com^" form,
+// the hosts file "0.0.0.0 example.com" form, or as plain domains. Comments and rules which block more specific
+// requests than a whole domain are skipped.
+func ParseTrackerDomains(r io.Reader) (TrackerDomains, error) {
+	var result TrackerDomains
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if len(line) == 0 || strings.HasPrefix(line, "!") || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[") {
+			continue
+		}
+		if match := abpHostRuleRegEx.FindStringSubmatch(line); match != nil {
+			result = append(result, match[1])
+			continue
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) >= 2 && (fields[0] == "0.0.0.0" || fields[0] == "127.0.0.1" || fields[0] == "::1"):
+			if fields[1] != "localhost" && fields[1] != "0.0.0.0" {
+				result = append(result, fields[1])
+			}
+		case len(fields) == 1 && !strings.ContainsAny(line, "|^$/*@") && strings.Contains(line, "."):
+			result = append(result, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return result, xerrors.Errorf("Unable to read tracker domains: %w", err)
+	}
+	return result, nil
+}
+
+// match returns the tracker domain host belongs to, path is only compared for the domains which list one
+func (domains TrackerDomains) match(host string, path string) (string, bool) {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for _, domain := range domains {
+		domainHost, domainPath := domain, ""
+		if slash := strings.Index(domain, "/"); slash >= 0 {
+			domainHost, domainPath = domain[:slash], domain[slash:]
+		}
+		if (host == domainHost || strings.HasSuffix(host, "."+domainHost)) && strings.HasPrefix(path, domainPath) {
+			return domain, true
+		}
+	}
+	return "", false
+}
+
+func (f *DefaultFactory) detectTrackers(ctx context.Context, url *url.URL) bool {
+	if f.DetectTrackersPolicy != nil {
+		defer policyPanicked("DetectTrackersPolicy")
+		return f.DetectTrackersPolicy.DetectTrackers(ctx, url)
+	}
+	return false
+}
+
+// addReferencedURLs records the URLs an element loads or links to, and those written in an inline script or in
+// <noscript>, which is only text to the parser
+func (p *Page) addReferencedURLs(base *url.URL, n *html.Node) {
+	if n.Type == html.TextNode && n.Parent != nil && (strings.EqualFold(n.Parent.Data, "script") || strings.EqualFold(n.Parent.Data, "noscript")) {
+		for _, match := range scriptURLRegEx.FindAllStringSubmatch(n.Data, -1) {
+			p.referencedURLs = append(p.referencedURLs, &url.URL{Scheme: "https", Host: match[1], Path: match[2]})
+		}
+		return
+	}
+	if n.Type != html.ElementNode {
+		return
+	}
+	for _, attr := range n.Attr {
+		switch strings.ToLower(attr.Key) {
+		case "src", "href", "data", "action", "poster":
+			if ref, err := base.Parse(strings.TrimSpace(attr.Val)); err == nil && (ref.Scheme == "http" || ref.Scheme == "https") {
+				p.referencedURLs = append(p.referencedURLs, ref)
+			}
+		}
+	}
+}
+
+// findTrackers lists the tracker domains, from DefaultTrackerDomains and the factory's TrackerDomains, that the page
+// references
+func (f *DefaultFactory) findTrackers(result *Page) {
+	if !result.detectTrackers {
+		return
+	}
+	found := make(map[string]bool)
+	for _, ref := range result.referencedURLs {
+		domain, ok := f.TrackerDomains.match(ref.Hostname(), ref.Path)
+		if !ok {
+			domain, ok = DefaultTrackerDomains.match(ref.Hostname(), ref.Path)
+		}
+		if ok && !found[domain] {
+			found[domain] = true
+			result.Trackers = append(result.Trackers, domain)
+		}
+	}
+	sort.Strings(result.Trackers)
+	result.referencedURLs = nil
+}
+
+// HasTrackers returns true if the page references any ad or tracking domain, it's only known if DetectTrackersPolicy
+// asked for it
+func (p Page) HasTrackers() bool {
+	return len(p.Trackers) > 0
+}
@@ -0,0 +1,64 @@
+package resource
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TrackersSuite struct {
+	suite.Suite
+	archive *MemoryResponseArchive
+}
+
+func (suite *TrackersSuite) SetupTest() {
+	suite.archive = NewMemoryResponseArchive()
+	suite.archive.Add("https://www.example.com/", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, `<html><head>
+		<script async src="https://www.googletagmanager.com/gtag/js?id=UA-1"></script>
+		<script>(function(i,s,o,g,r,a,m){})(window,document,'script','//www.google-analytics.com/analytics.js','ga');</script>
+		<script src="https://ads.example-network.com/show.js"></script>
+		<script src="/static/app.js"></script>
+	</head><body>
+		<noscript><img src="https://www.facebook.com/tr?id=1&ev=PageView"></noscript>
+		<a href="https://www.facebook.com/example">Follow us</a>
+	</body></html>`))
+}
+
+func (suite *TrackersSuite) TestDefaultTrackers() {
+	content, err := NewFactory(suite.archive, DetectTrackers(true)).PageFromURL(context.Background(), "https://www.example.com/")
+	suite.Nil(err, "Should not get an error")
+	page := content.(*Page)
+	suite.True(page.HasTrackers())
+	suite.Equal([]string{"facebook.com/tr", "google-analytics.com", "googletagmanager.com"}, page.Trackers, "Links to a profile aren't tracking")
+}
+
+func (suite *TrackersSuite) TestSuppliedTrackers() {
+	domains, err := ParseTrackerDomains(strings.NewReader(`[Adblock Plus 2.0]
+! Title: Example list
+||example-network.com^$third-party
+||tracker.example.org^
+||example.com/ads/*.js
+0.0.0.0 pixel.example.io
+# a comment
+plain.example.net`))
+	suite.Nil(err, "Should not get an error")
+	suite.Equal(TrackerDomains{"example-network.com", "tracker.example.org", "pixel.example.io", "plain.example.net"}, domains)
+
+	content, err := NewFactory(suite.archive, DetectTrackers(true), domains).PageFromURL(context.Background(), "https://www.example.com/")
+	suite.Nil(err, "Should not get an error")
+	suite.Contains(content.(*Page).Trackers, "example-network.com")
+	suite.Len(content.(*Page).Trackers, 4)
+}
+
+func (suite *TrackersSuite) TestOptIn() {
+	content, err := NewFactory(suite.archive).PageFromURL(context.Background(), "https://www.example.com/")
+	suite.Nil(err, "Should not get an error")
+	suite.False(content.(*Page).HasTrackers())
+}
+
+func TestTrackersSuite(t *testing.T) {
+	suite.Run(t, new(TrackersSuite))
+}
@@ -0,0 +1,130 @@
+package resource
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+)
+
+// AttachmentTransformer is passed into options if we want attachments enriched once they're downloaded, e.g.
+// thumbnails of images, the text of PDFs, or compressed copies. Every transformer found in the creator and options
+// runs in order, each on the original and on the attachments derived by the transformers before it, so that e.g. the
+// text extracted from a PDF can then be compressed. It returns the attachments it derived from source, if any.
+type AttachmentTransformer interface {
+	TransformAttachment(ctx context.Context, source *FileAttachment) ([]*FileAttachment, error)
+}
+
+// AttachmentTransformerFunc allows a plain function to be used as an AttachmentTransformer
+type AttachmentTransformerFunc func(ctx context.Context, source *FileAttachment) ([]*FileAttachment, error)
+
+// TransformAttachment satisfies AttachmentTransformer method
+func (fn AttachmentTransformerFunc) TransformAttachment(ctx context.Context, source *FileAttachment) ([]*FileAttachment, error) {
+	return fn(ctx, source)
+}
+
+// StreamTransformer is an AttachmentTransformer which streams the file of an attachment through Transform into a new
+// file next to it, named after the original with Extension appended (e.g. "paper.pdf.gz")
+type StreamTransformer struct {
+	Name        string          // recorded as the Transform of derived attachments, e.g. "gzip"
+	Extension   string          // without the dot
+	ContentType string          // of the derived attachments, e.g. "application/gzip"
+	Accept      func(Type) bool // nil transforms every attachment
+	Transform   func(ctx context.Context, src io.Reader, dst io.Writer) error
+}
+
+// NewGzipTransformer creates a StreamTransformer which derives a gzip compressed copy of every attachment
+func NewGzipTransformer() StreamTransformer {
+	return StreamTransformer{
+		Name:        "gzip",
+		Extension:   "gz",
+		ContentType: "application/gzip",
+		Transform: func(ctx context.Context, src io.Reader, dst io.Writer) error {
+			writer := gzip.NewWriter(dst)
+			if _, err := io.Copy(writer, src); err != nil {
+				return err
+			}
+			return writer.Close()
+		}}
+}
+
+// TransformAttachment satisfies AttachmentTransformer method
+func (t StreamTransformer) TransformAttachment(ctx context.Context, source *FileAttachment) ([]*FileAttachment, error) {
+	if t.Accept != nil && !t.Accept(source.Type()) {
+		return nil, nil
+	}
+	contentType, err := NewPageType(t.ContentType)
+	if err != nil {
+		return nil, err
+	}
+	src, err := source.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	destPath := source.DestPath + "." + t.Extension
+	dest, err := source.DestFS.Create(destPath)
+	if err != nil {
+		return nil, err
+	}
+	err = t.Transform(ctx, src, dest)
+	if closeErr := dest.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		source.DestFS.Remove(destPath)
+		return nil, err
+	}
+	return []*FileAttachment{{
+		ContentType: contentType,
+		TargetURL:   source.TargetURL,
+		DestFS:      source.DestFS,
+		DestPath:    destPath,
+		Valid:       true,
+		Transform:   t.Name}}, nil
+}
+
+// transformAttachment runs every AttachmentTransformer in the creator and options over a completed download, linking
+// what they derive to their sources. If a transformer fails the files derived so far are removed.
+func transformAttachment(ctx context.Context, creator FileAttachmentCreator, result *FileAttachment, options []interface{}) error {
+	transformers := OptionsOf[AttachmentTransformer](append([]interface{}{creator}, options...))
+	sources := []*FileAttachment{result}
+	for _, transformer := range transformers {
+		var derived []*FileAttachment
+		for _, source := range sources {
+			var produced []*FileAttachment
+			var err error
+			guardPolicy("AttachmentTransformer", func() { produced, err = transformer.TransformAttachment(ctx, source) })
+			if err != nil {
+				for _, attachment := range sources[1:] {
+					removeTrackedFile(attachment.DestFS, attachment.DestPath)
+				}
+				for _, attachment := range derived {
+					removeTrackedFile(attachment.DestFS, attachment.DestPath)
+				}
+				result.Derived = nil
+				return err
+			}
+			for _, attachment := range produced {
+				attachment.Source = source
+				if attachment.TargetURL == nil {
+					attachment.TargetURL = source.TargetURL
+				}
+				source.Derived = append(source.Derived, attachment)
+			}
+			derived = append(derived, produced...)
+		}
+		sources = append(sources, derived...)
+	}
+	return nil
+}
+
+// derivedFiles returns where every attachment derived from a, directly or not, was written
+func (a *FileAttachment) derivedFiles() []*FileAttachment {
+	var result []*FileAttachment
+	for _, derived := range a.Derived {
+		result = append(result, derived)
+		result = append(result, derived.derivedFiles()...)
+	}
+	return result
+}
@@ -0,0 +1,121 @@
+package resource
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/suite"
+)
+
+type TransformSuite struct {
+	suite.Suite
+	creator *FileSystemAttachmentCreator
+}
+
+func (suite *TransformSuite) SetupTest() {
+	suite.creator = NewMemoryAttachmentCreator(nil)
+}
+
+func (suite *TransformSuite) download(options ...interface{}) (bool, *FileAttachment, error) {
+	u, _ := url.Parse("http://ceur-ws.org/Vol-1401/paper-05.pdf")
+	t, _ := NewPageType("application/pdf")
+	resp := &http.Response{Header: make(http.Header), Body: ioutil.NopCloser(strings.NewReader(testPDFContent)), ContentLength: -1}
+	ok, attachment, err := DownloadFileFromHTTPResp(context.Background(), suite.creator, u, resp, t, options...)
+	result, _ := attachment.(*FileAttachment)
+	return ok, result, err
+}
+
+func (suite *TransformSuite) exists(path string) bool {
+	exists, _ := afero.Exists(suite.creator.FS, path)
+	return exists
+}
+
+// textOfPDFs stands in for a PDF-to-text transformer
+func textOfPDFs() StreamTransformer {
+	return StreamTransformer{
+		Name:        "text",
+		Extension:   "txt",
+		ContentType: "text/plain",
+		Accept:      func(t Type) bool { return t.MediaType() == "application/pdf" },
+		Transform: func(ctx context.Context, src io.Reader, dst io.Writer) error {
+			_, err := io.WriteString(dst, "extracted text")
+			return err
+		}}
+}
+
+func (suite *TransformSuite) TestTransformersAreChained() {
+	ok, attachment, err := suite.download(textOfPDFs(), NewGzipTransformer())
+	suite.True(ok)
+	suite.Nil(err, "Should not get an error")
+
+	suite.Len(attachment.Derived, 2, "The PDF should have its text and a compressed copy")
+	text := attachment.Derived[0]
+	suite.Equal("text", text.Transform)
+	suite.Equal(attachment.DestPath+".txt", text.DestPath)
+	suite.Equal("text/plain", text.Type().MediaType())
+	suite.Equal(attachment, text.Source, "Derived attachments should link to their source")
+	suite.Equal(attachment.URL(), text.URL())
+
+	compressed := attachment.Derived[1]
+	suite.Equal("gzip", compressed.Transform)
+	reader, _ := compressed.Open()
+	defer reader.Close()
+	unzipped, err := gzip.NewReader(reader)
+	suite.Nil(err)
+	content, _ := ioutil.ReadAll(unzipped)
+	suite.Equal(testPDFContent, string(content), "The compressed copy should have the original content")
+
+	suite.Len(text.Derived, 1, "Later transformers should also see derived attachments")
+	suite.Equal(text.DestPath+".gz", text.Derived[0].DestPath)
+	suite.Equal(text, text.Derived[0].Source)
+}
+
+func (suite *TransformSuite) TestFailedTransformRemovesDerivedFiles() {
+	failing := AttachmentTransformerFunc(func(ctx context.Context, source *FileAttachment) ([]*FileAttachment, error) {
+		return nil, errors.New("unsupported")
+	})
+	manager := NewCleanupManager()
+	ok, attachment, err := suite.download(NewGzipTransformer(), failing, manager)
+	suite.False(ok)
+	suite.NotNil(err, "A failing transformer should fail the download")
+	suite.Equal(ErrorCodeDownload, CodeOf(err))
+	suite.Empty(attachment.Derived)
+	suite.False(suite.exists(attachment.DestPath+".gz"), "Files already derived should be removed")
+	suite.Empty(manager.Files())
+}
+
+func (suite *TransformSuite) TestDerivedFilesAreTracked() {
+	manager := NewCleanupManager()
+	_, attachment, err := suite.download(NewGzipTransformer(), manager)
+	suite.Nil(err, "Should not get an error")
+	files := manager.Files()
+	suite.Len(files, 2)
+	suite.Equal(attachment.Derived[0].DestPath, files[1].Path)
+	suite.True(files[1].Complete)
+
+	suite.Nil(manager.RemoveAll())
+	suite.False(suite.exists(attachment.Derived[0].DestPath))
+}
+
+func (suite *TransformSuite) TestPreviewsAreNotTransformed() {
+	_, attachment, err := suite.download(NewGzipTransformer(), AttachmentPreviewSize(10))
+	suite.Nil(err, "Should not get an error")
+	suite.Empty(attachment.Derived)
+
+	var buffer bytes.Buffer
+	suite.Nil(NewGzipTransformer().Transform(context.Background(), strings.NewReader(""), &buffer))
+	suite.NotZero(buffer.Len())
+}
+
+func TestTransformSuite(t *testing.T) {
+	suite.Run(t, new(TransformSuite))
+}
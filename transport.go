@@ -0,0 +1,46 @@
+package resource
+
+import (
+	"net/http"
+)
+
+// RoundTripperDecorator is passed into options to wrap the transport of the HTTP client with another concern such
+// as logging, caching, retries, or recording. Decorators may be passed more than once and are stacked in the order
+// they were passed, the first one is outermost so it sees each request first and each response last.
+type RoundTripperDecorator interface {
+	DecorateRoundTripper(next http.RoundTripper) http.RoundTripper
+}
+
+// RoundTripperDecoratorFunc is a function which satisfies RoundTripperDecorator
+type RoundTripperDecoratorFunc func(next http.RoundTripper) http.RoundTripper
+
+// DecorateRoundTripper satisfies RoundTripperDecorator method
+func (fn RoundTripperDecoratorFunc) DecorateRoundTripper(next http.RoundTripper) http.RoundTripper {
+	return fn(next)
+}
+
+// RoundTripperFunc is a function which satisfies http.RoundTripper, convenient for writing decorators
+type RoundTripperFunc func(req *http.Request) (*http.Response, error)
+
+// RoundTrip satisfies http.RoundTripper method
+func (fn RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return fn(req)
+}
+
+// decorateClient returns a copy of client whose transport is wrapped by decorators, the client itself (which may be
+// shared) isn't changed
+func decorateClient(client *http.Client, decorators []RoundTripperDecorator) *http.Client {
+	if len(decorators) == 0 {
+		return client
+	}
+	transport := client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	for i := len(decorators) - 1; i >= 0; i-- {
+		transport = decorators[i].DecorateRoundTripper(transport)
+	}
+	result := *client
+	result.Transport = transport
+	return &result
+}
@@ -0,0 +1,59 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TransportSuite struct {
+	suite.Suite
+}
+
+func tracingDecorator(name string, trace *[]string) RoundTripperDecorator {
+	return RoundTripperDecoratorFunc(func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			*trace = append(*trace, name+" request")
+			resp, err := next.RoundTrip(req)
+			*trace = append(*trace, name+" response")
+			return resp, err
+		})
+	})
+}
+
+func (suite *TransportSuite) TestDecoratorOrder() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, testHTMLPage)
+	}))
+	defer server.Close()
+
+	var trace []string
+	recording := func(next http.RoundTripper) http.RoundTripper {
+		return tracingDecorator("recording", &trace).DecorateRoundTripper(next)
+	}
+	factory := NewFactory(tracingDecorator("logging", &trace), tracingDecorator("caching", &trace), recording)
+	_, err := factory.PageFromURL(context.Background(), server.URL)
+	suite.Nil(err, "Should not get an error")
+	suite.Equal([]string{
+		"logging request", "caching request", "recording request",
+		"recording response", "caching response", "logging response",
+	}, trace)
+}
+
+func (suite *TransportSuite) TestProvidedClientIsNotChanged() {
+	client := &http.Client{}
+	var trace []string
+	factory := NewFactory(func(ctx context.Context) *http.Client { return client }, tracingDecorator("logging", &trace))
+	decorated := factory.httpClient(context.Background())
+	suite.NotNil(decorated.Transport)
+	suite.Nil(client.Transport, "Shared client should keep its own transport")
+}
+
+func TestTransportSuite(t *testing.T) {
+	suite.Run(t, new(TransportSuite))
+}
@@ -2,7 +2,8 @@ package resource
 
 import (
 	"mime"
-	"net/url"
+
+	"golang.org/x/xerrors"
 )
 
 // PageType encapsulates the various descriptions of the kind of page / content
@@ -12,15 +13,14 @@ type PageType struct {
 	MedTypeParams MediaTypeParams `json:"mediaTypeParams"`
 }
 
-func NewPageType(url *url.URL, contentType string) (Type, error) {
-	result := new(PageType)
-	result.ContType = contentType
-	var mediaTypeError error
-	result.MedType, result.MedTypeParams, mediaTypeError = mime.ParseMediaType(contentType)
-	if mediaTypeError != nil {
-		return result, mediaTypeError
+// NewPageType creates a Type from a Content-Type header value such as "text/html; charset=utf-8", nil is returned
+// along with the error if it can't be parsed
+func NewPageType(contentType string) (Type, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to parse content type %q: %w", contentType, err)
 	}
-	return result, nil
+	return &PageType{ContType: contentType, MedType: mediaType, MedTypeParams: params}, nil
 }
 
 func (t PageType) ContentType() string {
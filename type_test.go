@@ -0,0 +1,44 @@
+package resource
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TypeSuite struct {
+	suite.Suite
+}
+
+func (suite *TypeSuite) TestFromContentType() {
+	t, err := NewPageType("text/html; charset=UTF-8")
+	suite.Nil(err, "Should not get an error")
+	suite.Equal("text/html; charset=UTF-8", t.ContentType())
+	suite.Equal("text/html", t.MediaType())
+	suite.Equal(MediaTypeParams{"charset": "UTF-8"}, t.MediaTypeParams())
+}
+
+func (suite *TypeSuite) TestInvalidContentType() {
+	t, err := NewPageType("text/html; charset")
+	suite.NotNil(err, "Should get an error")
+	suite.Nil(t, "No half-initialized type should be returned")
+	suite.Contains(err.Error(), `"text/html; charset"`)
+}
+
+func (suite *TypeSuite) TestInvalidContentTypeFromURL() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://www.netspective.com/broken", archivedResponse(200, http.Header{"Content-Type": {"text/html; charset"}}, testHTMLPage))
+
+	page, err := NewFactory(archive).PageFromURL(context.Background(), "https://www.netspective.com/broken")
+	suite.NotNil(err, "Should get an error")
+	suite.Contains(err.Error(), "https://www.netspective.com/broken", "The error should say which URL it was")
+	if page != nil {
+		suite.Nil(page.Type(), "No half-initialized type should be stored")
+	}
+}
+
+func TestTypeSuite(t *testing.T) {
+	suite.Run(t, new(TypeSuite))
+}
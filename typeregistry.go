@@ -0,0 +1,142 @@
+package resource
+
+import (
+	"strings"
+	"sync"
+)
+
+// TypeRegistry knows which media types are equivalent, so that checking for a type doesn't depend on how a server
+// chose to spell it. Patterns matched against it may be a media type ("text/html"), a wildcard ("image/*", "*/*"), or
+// a structured syntax suffix ("+json", or "application/*+json").
+type TypeRegistry struct {
+	mutex    sync.RWMutex
+	aliases  map[string]string // media type to the one it's equivalent to
+	suffixes map[string]string // structured syntax suffix, such as "+json", to the media type it's a kind of
+}
+
+// DefaultTypeRegistry is used by IsHTML, AllowedAttachmentTypes, and the other type checks. Add to it, as with
+// mime.AddExtensionType, to teach them other spellings.
+var DefaultTypeRegistry = NewTypeRegistry()
+
+// NewTypeRegistry creates a registry with the common aliases and suffixes
+func NewTypeRegistry() *TypeRegistry {
+	result := &TypeRegistry{aliases: make(map[string]string), suffixes: make(map[string]string)}
+	result.AddAlias("application/xhtml+xml", "text/html")
+	result.AddAlias("text/x-markdown", "text/markdown")
+	result.AddAlias("application/x-mimearchive", "multipart/related")
+	result.AddAlias("text/xml", "application/xml")
+	result.AddAlias("image/jpg", "image/jpeg")
+	result.AddAlias("image/pjpeg", "image/jpeg")
+	result.AddAlias("application/javascript", "text/javascript")
+	result.AddAlias("application/x-javascript", "text/javascript")
+	result.AddAlias("application/x-pdf", "application/pdf")
+	result.AddSuffix("+json", "application/json")
+	result.AddSuffix("+xml", "application/xml")
+	result.AddSuffix("+zip", "application/zip")
+	return result
+}
+
+// AddAlias makes alias equivalent to mediaType, in both directions
+func (r *TypeRegistry) AddAlias(alias string, mediaType string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.aliases[normalizeMediaType(alias)] = normalizeMediaType(mediaType)
+}
+
+// AddSuffix makes every media type ending in suffix (which starts with "+") a kind of mediaType, so that
+// "application/ld+json" is also "application/json"
+func (r *TypeRegistry) AddSuffix(suffix string, mediaType string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.suffixes[normalizeMediaType(suffix)] = normalizeMediaType(mediaType)
+}
+
+// Canonical returns the media type mediaType is an alias of, or mediaType itself in lower case
+func (r *TypeRegistry) Canonical(mediaType string) string {
+	mediaType = normalizeMediaType(mediaType)
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	if canonical, ok := r.aliases[mediaType]; ok {
+		return canonical
+	}
+	return mediaType
+}
+
+// Matches returns true if mediaType, any of its aliases, or the type its suffix makes it a kind of, matches pattern
+func (r *TypeRegistry) Matches(mediaType string, pattern string) bool {
+	mediaType = normalizeMediaType(mediaType)
+	pattern = r.Canonical(pattern)
+	if len(mediaType) == 0 || len(pattern) == 0 {
+		return false
+	}
+	if strings.HasPrefix(pattern, "+") || strings.Contains(pattern, "*+") {
+		// "+json" or "application/*+json"
+		suffix := mediaTypeSuffix(pattern)
+		prefix := strings.TrimSuffix(pattern, "*"+suffix)
+		return mediaTypeSuffix(mediaType) == suffix && (prefix == suffix || strings.HasPrefix(mediaType, prefix))
+	}
+	for _, candidate := range r.equivalents(mediaType) {
+		if matchMediaType(candidate, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// Is returns true if t matches any of patterns, false if t is nil
+func (r *TypeRegistry) Is(t Type, patterns ...string) bool {
+	if t == nil {
+		return false
+	}
+	for _, pattern := range patterns {
+		if r.Matches(t.MediaType(), pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// equivalents returns mediaType, what it's an alias of, and what its suffix makes it a kind of
+func (r *TypeRegistry) equivalents(mediaType string) []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	result := []string{mediaType}
+	if canonical, ok := r.aliases[mediaType]; ok {
+		result = append(result, canonical)
+	}
+	for alias, canonical := range r.aliases {
+		if canonical == mediaType {
+			result = append(result, alias)
+		}
+	}
+	if base, ok := r.suffixes[mediaTypeSuffix(mediaType)]; ok {
+		result = append(result, base)
+	}
+	return result
+}
+
+// matchMediaType compares a media type with a pattern which may be "*/*" or "type/*"
+func matchMediaType(mediaType string, pattern string) bool {
+	if pattern == mediaType || pattern == "*/*" || pattern == "*" {
+		return true
+	}
+	if strings.HasSuffix(pattern, "/*") {
+		return strings.HasPrefix(mediaType, strings.TrimSuffix(pattern, "*"))
+	}
+	return false
+}
+
+// mediaTypeSuffix returns the structured syntax suffix of a media type, such as "+xml" for "application/atom+xml"
+func mediaTypeSuffix(mediaType string) string {
+	if i := strings.LastIndex(mediaType, "+"); i >= 0 && !strings.Contains(mediaType[i:], "/") {
+		return mediaType[i:]
+	}
+	return ""
+}
+
+func normalizeMediaType(mediaType string) string {
+	if i := strings.Index(mediaType, ";"); i >= 0 {
+		mediaType = mediaType[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(mediaType))
+}
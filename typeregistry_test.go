@@ -0,0 +1,63 @@
+package resource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TypeRegistrySuite struct {
+	suite.Suite
+	registry *TypeRegistry
+}
+
+func (suite *TypeRegistrySuite) SetupTest() {
+	suite.registry = NewTypeRegistry()
+}
+
+func (suite *TypeRegistrySuite) TestAliases() {
+	suite.True(suite.registry.Matches("application/xhtml+xml", "text/html"))
+	suite.True(suite.registry.Matches("text/html", "application/xhtml+xml"), "Aliases should be equivalent in both directions")
+	suite.True(suite.registry.Matches("Text/HTML; charset=utf-8", "text/html"), "Case and parameters should be ignored")
+	suite.True(suite.registry.Matches("image/jpg", "image/jpeg"))
+	suite.Equal("text/markdown", suite.registry.Canonical("text/x-markdown"))
+	suite.False(suite.registry.Matches("text/plain", "text/html"))
+
+	suite.False(suite.registry.Matches("application/vnd.example.page", "text/html"))
+	suite.registry.AddAlias("application/vnd.example.page", "text/html")
+	suite.True(suite.registry.Matches("application/vnd.example.page", "text/html"), "Added aliases should be used")
+}
+
+func (suite *TypeRegistrySuite) TestSuffixes() {
+	suite.True(suite.registry.Matches("application/ld+json", "application/json"), "+json types are JSON")
+	suite.True(suite.registry.Matches("application/atom+xml", "application/xml"), "+xml types are XML")
+	suite.True(suite.registry.Matches("application/atom+xml", "text/xml"), "The suffix's type should be matched with its aliases")
+	suite.False(suite.registry.Matches("application/json", "application/ld+json"), "JSON isn't necessarily JSON-LD")
+
+	suite.True(suite.registry.Matches("application/activity+json", "+json"))
+	suite.True(suite.registry.Matches("application/activity+json", "application/*+json"))
+	suite.False(suite.registry.Matches("image/svg+xml", "application/*+xml"))
+	suite.False(suite.registry.Matches("application/atom+xml", "application/rss+xml"), "Sharing a suffix doesn't make types equivalent")
+}
+
+func (suite *TypeRegistrySuite) TestWildcards() {
+	suite.True(suite.registry.Matches("image/svg+xml", "image/*"))
+	suite.True(suite.registry.Matches("video/mp4", "*/*"))
+	suite.False(suite.registry.Matches("video/mp4", "image/*"))
+	suite.False(suite.registry.Matches("", "*/*"), "Missing types should never match")
+}
+
+func (suite *TypeRegistrySuite) TestPredicates() {
+	xhtml, _ := NewPageType("application/xhtml+xml; charset=utf-8")
+	suite.True((&Page{PageType: xhtml}).IsHTML(), "XHTML should be parsed as HTML")
+	suite.False((&Page{}).IsHTML())
+
+	jpg, _ := NewPageType("image/jpg")
+	suite.True(AllowedAttachmentTypes{"image/jpeg"}.ShouldDownload(context.Background(), nil, jpg, 0, nil))
+	suite.False(suite.registry.Is(nil, "*/*"))
+}
+
+func TestTypeRegistrySuite(t *testing.T) {
+	suite.Run(t, new(TypeRegistrySuite))
+}
@@ -0,0 +1,147 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"golang.org/x/xerrors"
+)
+
+// ConfigurationProblem is a conflicting or incomplete option found by Validate, Option is the field or type of the
+// option and Problem says what's wrong with it. Advisory problems are options which are ignored or have no effect,
+// the factory still works as it did before they were reported.
+type ConfigurationProblem struct {
+	Option   string `json:"option"`
+	Problem  string `json:"problem"`
+	Advisory bool   `json:"advisory,omitempty"`
+}
+
+// Validate checks the factory's options for conflicts and omissions which would otherwise only show up (or be
+// silently ignored) when URLs are fetched, and calls its ClientProvider to check the client. It returns an
+// InvalidConfigurationError listing every problem found, or nil. NewFactory checks the factory it creates without
+// calling any providers, and if there's a problem which isn't Advisory PageFromURL returns it without fetching.
+func (f *DefaultFactory) Validate() error {
+	return configurationError(f.configurationProblems(context.Background(), true))
+}
+
+// configurationError returns an InvalidConfigurationError listing problems, nil if there aren't any
+func configurationError(problems []ConfigurationProblem) error {
+	if len(problems) == 0 {
+		return nil
+	}
+	return &InvalidConfigurationError{Problems: problems, Frame: xerrors.Caller(xErrorsFrameCaller), Frames: callerFrames(xErrorsFrameCaller)}
+}
+
+// invalidConfiguration returns an InvalidConfigurationError listing the problems which aren't Advisory
+func invalidConfiguration(problems []ConfigurationProblem) error {
+	var result []ConfigurationProblem
+	for _, problem := range problems {
+		if !problem.Advisory {
+			result = append(result, problem)
+		}
+	}
+	return configurationError(result)
+}
+
+// configurationProblems returns the problems with the factory's options, the ClientProvider is only called to check
+// its client if callProviders is true
+func (f *DefaultFactory) configurationProblems(ctx context.Context, callProviders bool) []ConfigurationProblem {
+	var problems []ConfigurationProblem
+	problem := func(option string, format string, args ...interface{}) {
+		problems = append(problems, ConfigurationProblem{Option: option, Problem: fmt.Sprintf(format, args...)})
+	}
+	advise := func(option string, format string, args ...interface{}) {
+		problems = append(problems, ConfigurationProblem{Option: option, Problem: fmt.Sprintf(format, args...), Advisory: true})
+	}
+
+	if f.ClientProvider != nil && f.ProvideClientFunc != nil {
+		advise("ProvideClientFunc", "is ignored because there's also a ClientProvider")
+	}
+	if callProviders && (f.ClientProvider != nil || f.ProvideClientFunc != nil) {
+		provider := "ClientProvider"
+		if f.ClientProvider == nil {
+			provider = "ProvideClientFunc"
+		}
+		if client, err := f.providedClient(ctx); err != nil {
+			problem(provider, "panicked: %v", err.(*PolicyPanicError).Value)
+		} else if client == nil {
+			problem(provider, "returned a nil *http.Client")
+		}
+	}
+
+	for _, option := range f.options {
+		switch limit := option.(type) {
+		case HTMLBodyLimit:
+			if limit == 0 {
+				advise("HTMLBodyLimit", "is 0, leave it out to read whole pages")
+			} else if limit < 0 {
+				problem("HTMLBodyLimit", "is %d, leave it out to read whole pages", limit)
+			}
+		case DrainUnreadBody:
+			if limit < 0 {
+				problem("DrainUnreadBody", "is %d, use zero to close bodies without draining them", limit)
+			}
+		}
+	}
+
+	if budget := f.FetchBudget; budget != nil {
+		// a negative MaxRedirects means none are followed
+		if budget.MaxDuration < 0 || budget.MaxBytes < 0 {
+			problem("FetchBudget", "has a negative limit")
+		} else if *budget == (FetchBudget{}) {
+			advise("FetchBudget", "doesn't limit anything")
+		}
+	}
+
+	backoff, ok := f.RetryPolicy.(ExponentialBackoff)
+	if pointer, isPointer := f.RetryPolicy.(*ExponentialBackoff); isPointer && pointer != nil {
+		backoff, ok = *pointer, true
+	}
+	if ok && (backoff.MaxAttempts < 0 || backoff.Initial < 0 || backoff.Max < 0 || backoff.Jitter < 0) {
+		problem("RetryPolicy", "has a negative setting")
+	}
+
+	if pool := f.ConnectionPool; pool != nil {
+		if pool.MaxIdleConns < 0 || pool.MaxIdleConnsPerHost < 0 || pool.MaxConnsPerHost < 0 || pool.IdleConnTimeout < 0 {
+			problem("ConnectionPool", "has a negative limit")
+		}
+	}
+
+	if router := f.DomainPolicyRouter; router != nil {
+		for index, route := range router.Routes {
+			if len(route.Pattern) == 0 {
+				advise("DomainPolicyRouter", "route %d has no pattern", index)
+			}
+		}
+	}
+
+	if router := f.TenantRouter; router != nil {
+		router.mutex.Lock()
+		tenants := []string{""}
+		policies := map[string]TenantPolicy{"": router.Default}
+		for tenant, policy := range router.policies {
+			if len(tenant) > 0 {
+				tenants = append(tenants, tenant)
+			}
+			policies[tenant] = policy
+		}
+		router.mutex.Unlock()
+		sort.Strings(tenants[1:])
+		for _, tenant := range tenants {
+			policy := policies[tenant]
+			if policy.RequestsPerSecond < 0 || policy.Burst < 0 || policy.MaxPages < 0 {
+				problem("TenantRouter", "tenant %q has a negative limit", tenant)
+			}
+		}
+	}
+
+	return problems
+}
+
+// providedClient returns the client the ClientProvider or ProvideClientFunc provides, or a PolicyPanicError
+func (f *DefaultFactory) providedClient(ctx context.Context) (_ *http.Client, err error) {
+	defer recoverPolicyPanic(&err)
+	return f.baseHTTPClient(ctx), nil
+}
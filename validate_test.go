@@ -0,0 +1,104 @@
+package resource
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/xerrors"
+)
+
+type ValidateSuite struct {
+	suite.Suite
+}
+
+func (suite *ValidateSuite) HTTPClient(ctx context.Context) *http.Client {
+	return http.DefaultClient
+}
+
+func (suite *ValidateSuite) problems(err error) []ConfigurationProblem {
+	var invalid *InvalidConfigurationError
+	if !xerrors.As(err, &invalid) {
+		return nil
+	}
+	return invalid.Problems
+}
+
+func (suite *ValidateSuite) TestValidFactories() {
+	suite.Nil(NewFactory().Validate())
+	suite.Nil(NewFactory(NewMemoryAttachmentCreator(nil), AllowedAttachmentTypes{"application/pdf"}, HTMLBodyLimit(1024)).Validate())
+	suite.Nil(NewPreviewFactory().Validate(), "Presets should be valid")
+}
+
+func (suite *ValidateSuite) TestDownloadPoliciesWithPerCallCreator() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://www.netspective.com/paper.pdf", archivedResponse(200, http.Header{"Content-Type": {"application/pdf"}}, testPDFContent))
+	factory := NewFactory(archive, AllowedAttachmentTypes{"application/pdf"})
+	suite.Nil(factory.Validate(), "Download policies are valid without a factory creator, calls can pass their own")
+
+	content, err := factory.PageFromURL(context.Background(), "https://www.netspective.com/paper.pdf", NewMemoryAttachmentCreator(nil))
+	suite.Nil(err, "Should not get an error")
+	suite.NotNil(content.(*Page).DownloadedAttachment, "The call's creator should download the attachment")
+}
+
+func (suite *ValidateSuite) TestLimits() {
+	err := NewFactory(HTMLBodyLimit(0), DrainUnreadBody(-1), FetchBudget{}, &ConnectionPool{MaxConnsPerHost: -1}).Validate()
+	problems := suite.problems(err)
+	suite.Len(problems, 4)
+	suite.Contains(err.Error(), "HTMLBodyLimit is 0")
+	suite.Contains(err.Error(), "FetchBudget doesn't limit anything")
+	suite.Contains(err.Error(), "ConnectionPool has a negative limit")
+
+	err = NewFactory(FetchBudget{MaxDuration: -time.Second}).Validate()
+	suite.Equal([]ConfigurationProblem{{Option: "FetchBudget", Problem: "has a negative limit"}}, suite.problems(err))
+
+	router := NewTenantRouter().Tenant("acme", TenantPolicy{MaxPages: -1})
+	err = NewFactory(router).Validate()
+	suite.Equal([]ConfigurationProblem{{Option: "TenantRouter", Problem: `tenant "acme" has a negative limit`}}, suite.problems(err))
+}
+
+func (suite *ValidateSuite) TestClientProviders() {
+	nilClient := func(ctx context.Context) *http.Client { return nil }
+	err := NewFactory(nilClient).Validate()
+	suite.Equal([]ConfigurationProblem{{Option: "ProvideClientFunc", Problem: "returned a nil *http.Client"}}, suite.problems(err))
+
+	err = NewFactory(nilClient, suite).Validate()
+	suite.Equal([]ConfigurationProblem{{Option: "ProvideClientFunc", Problem: "is ignored because there's also a ClientProvider", Advisory: true}}, suite.problems(err))
+}
+
+func (suite *ValidateSuite) TestAdvisoryProblemsDontFailFetches() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://www.netspective.com/", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, testHTMLPage))
+	provided := 0
+	provider := func(ctx context.Context) *http.Client {
+		provided++
+		return http.DefaultClient
+	}
+	factory := NewFactory(archive, provider, suite, HTMLBodyLimit(0), FetchBudget{}, FetchBudget{MaxRedirects: -1})
+	suite.Equal(0, provided, "Providers shouldn't be called when the factory is created")
+
+	_, err := factory.PageFromURL(context.Background(), "https://www.netspective.com/")
+	suite.Nil(err, "Options which are ignored shouldn't stop the factory from working")
+	for _, problem := range suite.problems(factory.Validate()) {
+		suite.True(problem.Advisory, "%s %s should be advisory", problem.Option, problem.Problem)
+	}
+	suite.Nil(NewFactory(FetchBudget{MaxRedirects: -1}).Validate(), "A negative MaxRedirects means no redirects are followed")
+}
+
+func (suite *ValidateSuite) TestInvalidFactoryDoesNotFetch() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://www.netspective.com/", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, testHTMLPage))
+	factory := NewFactory(archive, HTMLBodyLimit(-1))
+
+	_, err := factory.PageFromURL(context.Background(), "https://www.netspective.com/")
+	suite.Equal(ErrorCodeInvalidConfiguration, CodeOf(err), "The configuration error should be returned instead of fetching")
+
+	_, err = NewFactoryFromConfig(FactoryConfig{}, HTMLBodyLimit(-1))
+	suite.Equal(ErrorCodeInvalidConfiguration, CodeOf(err), "Configured factories should be validated when they're created")
+}
+
+func TestValidateSuite(t *testing.T) {
+	suite.Run(t, new(ValidateSuite))
+}
@@ -0,0 +1,187 @@
+package resource
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// WARCRecord is a single record from a WARC file, Block is only readable until the next record is requested
+type WARCRecord struct {
+	Version string
+	Header  textproto.MIMEHeader
+	Block   io.Reader
+}
+
+// Type returns the WARC-Type of the record, e.g. "response", "request", or "warcinfo"
+func (r WARCRecord) Type() string {
+	return r.Header.Get("WARC-Type")
+}
+
+// TargetURI returns the WARC-Target-URI of the record
+func (r WARCRecord) TargetURI() string {
+	// some writers wrap the URI in angle brackets, as in the WARC 1.0 specification examples
+	return strings.Trim(r.Header.Get("WARC-Target-URI"), "<>")
+}
+
+// IsHTTPResponse returns true if the record's block is an HTTP response
+func (r WARCRecord) IsHTTPResponse() bool {
+	return r.Type() == "response" && strings.HasPrefix(r.Header.Get("Content-Type"), "application/http")
+}
+
+// HTTPResponse parses the record's block as an HTTP response, its body is only readable until the next record is requested
+func (r WARCRecord) HTTPResponse() (*http.Response, error) {
+	targetURL, err := url.Parse(r.TargetURI())
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to parse WARC-Target-URI %q: %w", r.TargetURI(), err)
+	}
+	req := &http.Request{Method: http.MethodGet, URL: targetURL}
+	return http.ReadResponse(bufio.NewReader(r.Block), req)
+}
+
+// WARCReader iterates the records of a WARC file, gzip compressed (.warc.gz) files are detected automatically
+type WARCReader struct {
+	reader  *bufio.Reader
+	current *io.LimitedReader
+}
+
+// NewWARCReader creates a WARCReader over a plain or gzip compressed WARC file
+func NewWARCReader(r io.Reader) (*WARCReader, error) {
+	buffered := bufio.NewReader(r)
+	magic, _ := buffered.Peek(2)
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		// each record of a .warc.gz is its own gzip member, which gzip.Reader reads as one stream
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, xerrors.Errorf("Unable to read gzip compressed WARC: %w", err)
+		}
+		buffered = bufio.NewReader(gz)
+	}
+	return &WARCReader{reader: buffered}, nil
+}
+
+// Next returns the next record, or io.EOF when there are no more records
+func (r *WARCReader) Next() (*WARCRecord, error) {
+	if r.current != nil {
+		// skip whatever wasn't read of the previous record's block
+		if _, err := io.Copy(ioutil.Discard, r.current); err != nil {
+			return nil, xerrors.Errorf("Unable to skip WARC record: %w", err)
+		}
+		r.current = nil
+	}
+
+	var version string
+	for {
+		line, err := r.reader.ReadString('\n')
+		line = strings.TrimSpace(line)
+		if len(line) > 0 {
+			version = line
+			break
+		}
+		if err != nil {
+			if err == io.EOF {
+				return nil, io.EOF
+			}
+			return nil, xerrors.Errorf("Unable to read WARC record: %w", err)
+		}
+	}
+	if !strings.HasPrefix(version, "WARC/") {
+		return nil, fmt.Errorf("Invalid WARC record, expected version line but got %q", version)
+	}
+
+	header, err := textproto.NewReader(r.reader).ReadMIMEHeader()
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to read WARC record header: %w", err)
+	}
+	length, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64)
+	if err != nil {
+		return nil, xerrors.Errorf("Invalid Content-Length in WARC record header: %w", err)
+	}
+
+	r.current = &io.LimitedReader{R: r.reader, N: length}
+	return &WARCRecord{Version: version, Header: header, Block: r.current}, nil
+}
+
+// ImportWARC adds every HTTP response record of a WARC file to the archive, returning how many were imported
+func ImportWARC(archive *MemoryResponseArchive, r io.Reader) (int, error) {
+	reader, err := NewWARCReader(r)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return count, err
+		}
+		if !record.IsHTTPResponse() {
+			continue
+		}
+		block, err := ioutil.ReadAll(record.Block)
+		if err != nil {
+			return count, xerrors.Errorf("Unable to read WARC record for %q: %w", record.TargetURI(), err)
+		}
+		archive.AddRaw(record.TargetURI(), block)
+		count++
+	}
+}
+
+// PagesFromWARC runs every successful HTTP response in a WARC file through the factory, so previously archived crawls
+// can be re-processed without refetching. Iteration stops at the first error returned by fn.
+func (f *DefaultFactory) PagesFromWARC(ctx context.Context, r io.Reader, fn func(context.Context, Content, error) error, options ...interface{}) (err error) {
+	defer recoverPolicyPanic(&err)
+	options = flattenOptions(options)
+	if f.invalid != nil {
+		return f.invalid
+	}
+	if err := validateOptions(options, pageOptions); err != nil {
+		return err
+	}
+	reader, err := NewWARCReader(r)
+	if err != nil {
+		return err
+	}
+
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !record.IsHTTPResponse() {
+			continue
+		}
+
+		resp, err := record.HTTPResponse()
+		if err != nil {
+			if err := fn(ctx, nil, err); err != nil {
+				return err
+			}
+			continue
+		}
+		if resp.StatusCode != 200 {
+			continue
+		}
+
+		content, err := f.pageFromHTTPResponse(ctx, resp.Request.URL, resp, options...)
+		if err := fn(ctx, content, err); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package resource
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type WARCSuite struct {
+	suite.Suite
+}
+
+func warcRecord(header string, block string) string {
+	return fmt.Sprintf("WARC/1.0\r\n%sContent-Length: %d\r\n\r\n%s\r\n\r\n", header, len(block), block)
+}
+
+func testWARC() string {
+	httpResponse := fmt.Sprintf("HTTP/1.1 200 OK\r\nContent-Type: text/html; charset=utf-8\r\nContent-Length: %d\r\n\r\n%s", len(testHTMLPage), testHTMLPage)
+	return warcRecord("WARC-Type: warcinfo\r\nContent-Type: application/warc-fields\r\n", "software: lectio/resource test\r\n") +
+		warcRecord("WARC-Type: request\r\nWARC-Target-URI: https://www.netspective.com/\r\nContent-Type: application/http; msgtype=request\r\n", "GET / HTTP/1.1\r\nHost: www.netspective.com\r\n\r\n") +
+		warcRecord("WARC-Type: response\r\nWARC-Target-URI: <https://www.netspective.com/>\r\nContent-Type: application/http; msgtype=response\r\n", httpResponse)
+}
+
+func (suite *WARCSuite) TestReader() {
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	gz.Write([]byte(testWARC()))
+	gz.Close()
+
+	for _, warc := range []*bytes.Buffer{bytes.NewBufferString(testWARC()), &compressed} {
+		reader, err := NewWARCReader(warc)
+		suite.Nil(err, "Should not get an error")
+
+		var types []string
+		for {
+			record, err := reader.Next()
+			if err != nil {
+				break
+			}
+			types = append(types, record.Type())
+		}
+		suite.Equal([]string{"warcinfo", "request", "response"}, types)
+	}
+}
+
+func (suite *WARCSuite) TestPagesFromWARC() {
+	ctx := context.Background()
+	factory := NewFactory()
+
+	var pages []Content
+	err := factory.PagesFromWARC(ctx, bytes.NewBufferString(testWARC()), func(ctx context.Context, content Content, err error) error {
+		suite.Nil(err, "Should not get an error")
+		pages = append(pages, content)
+		return nil
+	})
+	suite.Nil(err, "Should not get an error")
+	suite.Len(pages, 1, "Only the response record should be processed")
+	suite.Equal("https://www.netspective.com/", pages[0].URL().String())
+	value, _, _ := pages[0].MetaTag("og:title")
+	suite.Equal("Safety, privacy, and security focused technology consulting", value)
+}
+
+func (suite *WARCSuite) TestImportWARCForOfflineUse() {
+	ctx := context.Background()
+	archive := NewMemoryResponseArchive()
+	count, err := ImportWARC(archive, bytes.NewBufferString(testWARC()))
+	suite.Nil(err, "Should not get an error")
+	suite.Equal(1, count)
+
+	page, err := NewFactory(archive).PageFromURL(ctx, "https://www.netspective.com/")
+	suite.Nil(err, "Should not get an error")
+	value, _, _ := page.MetaTag("og:site_name")
+	suite.Equal("Netspective", value)
+}
+
+func TestWARCSuite(t *testing.T) {
+	suite.Run(t, new(WARCSuite))
+}
@@ -0,0 +1,141 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/base32"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// ResponseRecorder is passed into options if every response fetched from the network should be recorded, e.g. into
+// a WARC file. The body is what the factory read, complete is false if it stopped early (e.g. a preview).
+type ResponseRecorder interface {
+	RecordResponse(ctx context.Context, resp *http.Response, body []byte, complete bool) error
+}
+
+// WARCWriter is a ResponseRecorder which writes WARC/1.0 response records. Go's HTTP client has already decoded
+// chunked and (transparently) gzip encoded bodies, so the recorded headers are adjusted to describe the body as stored.
+type WARCWriter struct {
+	Clock  Clock        // the source of WARC-Date, defaults to SystemClock
+	Random RandomSource // the source of WARC-Record-ID, defaults to SystemRandom
+
+	mu *sync.Mutex
+	w  io.Writer
+}
+
+// NewWARCWriter creates a WARCWriter, safe for concurrent use, which writes uncompressed records to w
+func NewWARCWriter(w io.Writer) *WARCWriter {
+	return &WARCWriter{mu: new(sync.Mutex), w: w}
+}
+
+// RecordResponse satisfies ResponseRecorder method
+func (w *WARCWriter) RecordResponse(ctx context.Context, resp *http.Response, body []byte, complete bool) error {
+	header := make(http.Header, len(resp.Header))
+	for key, values := range resp.Header {
+		header[key] = append([]string(nil), values...)
+	}
+	header.Del("Transfer-Encoding")
+	if resp.Uncompressed {
+		header.Del("Content-Encoding")
+	}
+	if complete {
+		header.Set("Content-Length", strconv.Itoa(len(body)))
+	}
+
+	var block bytes.Buffer
+	fmt.Fprintf(&block, "HTTP/%d.%d %s\r\n", resp.ProtoMajor, resp.ProtoMinor, resp.Status)
+	header.Write(&block)
+	block.WriteString("\r\n")
+	block.Write(body)
+
+	var clock Clock = SystemClock{}
+	if w.Clock != nil {
+		clock = w.Clock
+	}
+	var random RandomSource = SystemRandom{}
+	if w.Random != nil {
+		random = w.Random
+	}
+
+	digest := sha1.Sum(body)
+	fields := []string{
+		"WARC-Type: response",
+		"WARC-Record-ID: <urn:uuid:" + newUUID(random) + ">",
+		"WARC-Date: " + clock.Now().UTC().Format(time.RFC3339),
+		"WARC-Target-URI: " + resp.Request.URL.String(),
+		"WARC-Payload-Digest: sha1:" + base32.StdEncoding.EncodeToString(digest[:]),
+		"Content-Type: application/http; msgtype=response",
+	}
+	if !complete {
+		fields = append(fields, "WARC-Truncated: unspecified")
+	}
+	fields = append(fields, "Content-Length: "+strconv.Itoa(block.Len()))
+
+	var record bytes.Buffer
+	record.WriteString("WARC/1.0\r\n")
+	for _, field := range fields {
+		record.WriteString(field + "\r\n")
+	}
+	record.WriteString("\r\n")
+	block.WriteTo(&record)
+	record.WriteString("\r\n\r\n")
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := record.WriteTo(w.w); err != nil {
+		return xerrors.Errorf("Unable to write WARC record for %q: %w", resp.Request.URL.String(), err)
+	}
+	return nil
+}
+
+// newUUID returns a random (version 4) UUID
+func newUUID(random RandomSource) string {
+	var u [16]byte
+	random.Read(u[:])
+	u[6] = (u[6] & 0x0f) | 0x40
+	u[8] = (u[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:])
+}
+
+// recordingBody keeps a copy of everything read from a response body and hands it to a ResponseRecorder on Close
+type recordingBody struct {
+	io.ReadCloser
+	record func(body []byte, complete bool) error
+	limit  int64 // the most bytes kept, zero for no limit; a longer body is handed over incomplete
+	buf    bytes.Buffer
+	eof    bool
+	over   bool
+	once   sync.Once
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if b.limit > 0 && int64(b.buf.Len()+n) > b.limit {
+		b.over = true
+	}
+	if !b.over {
+		b.buf.Write(p[:n])
+	}
+	if err == io.EOF {
+		b.eof = true
+	}
+	return n, err
+}
+
+func (b *recordingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() {
+		if recordErr := b.record(b.buf.Bytes(), b.eof && !b.over); recordErr != nil && err == nil {
+			err = recordErr
+		}
+	})
+	return err
+}
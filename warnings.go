@@ -0,0 +1,132 @@
+package resource
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Codes of the warnings that may be recorded on a Page
+const (
+	WarningBodyReadError         = "body-read-error"
+	WarningContentLengthMismatch = "content-length-mismatch"
+	WarningParseError            = "parse-error"
+	WarningTokenizerError        = "tokenizer-error"
+	WarningUnclosedHead          = "unclosed-head"
+	WarningMetaAfterBodyStart    = "meta-after-body-start"
+	WarningMetaMissingContent    = "meta-missing-content"
+	WarningMetaMissingKey        = "meta-missing-key"
+	WarningDuplicateAttribute    = "duplicate-attribute"
+	WarningRelatedFetchError     = "related-fetch-error" // a document the page links to, such as its ActivityPub actor or manifest, couldn't be fetched
+	WarningMissingAlt            = "missing-alt"         // only checked if CheckAccessibilityPolicy asks for it
+	WarningMissingLang           = "missing-lang"        // only checked if CheckAccessibilityPolicy asks for it
+	WarningHeadingOrder          = "heading-order"       // only checked if CheckAccessibilityPolicy asks for it
+	WarningIndexError            = "index-error"         // the Indexer couldn't index the page
+	WarningValidatorsError       = "validators-error"    // the ValidatorProvider failed, so the page was fetched unconditionally
+	WarningCacheError            = "cache-error"         // the CacheProvider couldn't load, store or invalidate a response, the page was fetched anyway
+)
+
+// PageWarning is a non-fatal anomaly found while processing a page, Line and Column are 1-based when known
+type PageWarning struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+}
+
+func (w PageWarning) String() string {
+	if w.Line > 0 {
+		return fmt.Sprintf("%s at %d:%d: %s", w.Code, w.Line, w.Column, w.Message)
+	}
+	return fmt.Sprintf("%s: %s", w.Code, w.Message)
+}
+
+// htmlPosition tracks the line and column of tokens as they're consumed
+type htmlPosition struct {
+	line   int
+	column int
+}
+
+func (p *htmlPosition) advance(raw []byte) {
+	if newlines := bytes.Count(raw, []byte{'\n'}); newlines > 0 {
+		p.line += newlines
+		p.column = len(raw) - bytes.LastIndexByte(raw, '\n')
+		return
+	}
+	p.column += len(raw)
+}
+
+func (p htmlPosition) warning(code string, format string, args ...interface{}) PageWarning {
+	return PageWarning{Code: code, Message: fmt.Sprintf(format, args...), Line: p.line, Column: p.column}
+}
+
+// scanHTMLAnomalies tokenizes the HTML looking for the kinds of problems the lenient tree parser silently fixes up
+func scanHTMLAnomalies(body []byte) []PageWarning {
+	var result []PageWarning
+	var headStart *htmlPosition
+	var headClosed, bodyStarted bool
+
+	z := html.NewTokenizer(bytes.NewReader(body))
+	pos := htmlPosition{line: 1, column: 1}
+	for {
+		tt := z.Next()
+		tokenPos := pos
+		pos.advance(z.Raw())
+
+		switch tt {
+		case html.ErrorToken:
+			if err := z.Err(); err != io.EOF {
+				result = append(result, tokenPos.warning(WarningTokenizerError, "%v", err))
+			}
+			if headStart != nil && !headClosed && !bodyStarted {
+				result = append(result, headStart.warning(WarningUnclosedHead, "<head> is never closed"))
+			}
+			return result
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := z.Token()
+			seen := make(map[string]bool, len(token.Attr))
+			for _, attr := range token.Attr {
+				if seen[attr.Key] {
+					result = append(result, tokenPos.warning(WarningDuplicateAttribute, "<%s> has more than one %q attribute", token.Data, attr.Key))
+				}
+				seen[attr.Key] = true
+			}
+
+			switch token.Data {
+			case "head":
+				if headStart == nil {
+					start := tokenPos
+					headStart = &start
+				}
+			case "body":
+				if headStart != nil && !headClosed {
+					result = append(result, headStart.warning(WarningUnclosedHead, "<head> is not closed before <body>"))
+					headClosed = true
+				}
+				bodyStarted = true
+			case "meta":
+				if bodyStarted {
+					result = append(result, tokenPos.warning(WarningMetaAfterBodyStart, "<meta> appears after <body> has started"))
+				}
+				switch {
+				case seen["charset"] || seen["itemprop"]:
+				case seen["name"] || seen["property"] || seen["http-equiv"]:
+					if !seen["content"] {
+						result = append(result, tokenPos.warning(WarningMetaMissingContent, "<meta> has no content attribute"))
+					}
+				default:
+					result = append(result, tokenPos.warning(WarningMetaMissingKey, "<meta> has no name, property, http-equiv, or charset attribute"))
+				}
+			}
+
+		case html.EndTagToken:
+			if strings.EqualFold(z.Token().Data, "head") {
+				headClosed = true
+			}
+		}
+	}
+}
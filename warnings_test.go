@@ -0,0 +1,54 @@
+package resource
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type WarningsSuite struct {
+	suite.Suite
+}
+
+func (suite *WarningsSuite) codes(warnings []PageWarning) []string {
+	var result []string
+	for _, warning := range warnings {
+		result = append(result, warning.Code)
+	}
+	return result
+}
+
+func (suite *WarningsSuite) TestWellFormedPageHasNoWarnings() {
+	suite.Empty(scanHTMLAnomalies([]byte(testHTMLPage)))
+}
+
+func (suite *WarningsSuite) TestAnomaliesWithPositions() {
+	warnings := scanHTMLAnomalies([]byte("<html>\n<head>\n<meta name=\"description\">\n<meta content=\"orphan\">\n<body>\n  <meta property=\"og:title\" property=\"og:title\" content=\"Late\">\n</body></html>"))
+	suite.Equal([]string{WarningMetaMissingContent, WarningMetaMissingKey, WarningUnclosedHead, WarningDuplicateAttribute, WarningMetaAfterBodyStart}, suite.codes(warnings))
+
+	suite.Equal(3, warnings[0].Line, "Missing content should be on line 3")
+	suite.Equal(1, warnings[0].Column)
+	suite.Equal(2, warnings[2].Line, "Unclosed head should point at <head>")
+	suite.Equal(6, warnings[4].Line, "Late meta should be on line 6")
+	suite.Equal(3, warnings[4].Column)
+}
+
+func (suite *WarningsSuite) TestTruncatedPage() {
+	u, _ := url.Parse("https://www.netspective.com/")
+	resp := &http.Response{Body: ioutil.NopCloser(strings.NewReader(testHTMLPage)), ContentLength: int64(len(testHTMLPage) * 2)}
+	page := &Page{MetaPropertyTags: make(map[string]interface{}), parseMetaData: true}
+	page.parsePageMetaData(context.Background(), u, resp)
+
+	suite.Equal([]string{WarningContentLengthMismatch}, suite.codes(page.Warnings))
+	value, _ := MetaTags(page.MetaPropertyTags).GetString("og:site_name")
+	suite.Equal("Netspective", value, "What was received should still be parsed")
+}
+
+func TestWarningsSuite(t *testing.T) {
+	suite.Run(t, new(WarningsSuite))
+}
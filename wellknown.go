@@ -0,0 +1,199 @@
+package resource
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// SecurityTxt is a parsed security.txt file (RFC 9116), field names in Fields are lower case
+type SecurityTxt struct {
+	URL                *url.URL            `json:"url"`
+	Contact            []string            `json:"contact"`
+	Expires            time.Time           `json:"expires,omitempty"`
+	Encryption         []string            `json:"encryption,omitempty"`
+	Policy             []string            `json:"policy,omitempty"`
+	Acknowledgments    []string            `json:"acknowledgments,omitempty"`
+	Hiring             []string            `json:"hiring,omitempty"`
+	Canonical          []string            `json:"canonical,omitempty"`
+	PreferredLanguages string              `json:"preferredLanguages,omitempty"`
+	Signed             bool                `json:"signed"` // true if the file is an OpenPGP cleartext signed message, the signature isn't verified
+	Fields             map[string][]string `json:"fields"`
+}
+
+// IsExpired returns true if the file's Expires date has passed
+func (s SecurityTxt) IsExpired(now time.Time) bool {
+	return !s.Expires.IsZero() && now.After(s.Expires)
+}
+
+// HumansTxtSection is a /* SECTION */ of a humans.txt file with its "Key: value" lines, keys are lower case
+type HumansTxtSection struct {
+	Name   string              `json:"name"`
+	Fields map[string][]string `json:"fields"`
+}
+
+// HumansTxt is a parsed humans.txt file (http://humanstxt.org), which is free form so only the conventional
+// "/* SECTION */" headings and "Key: value" lines are recognized
+type HumansTxt struct {
+	URL      *url.URL           `json:"url"`
+	Sections []HumansTxtSection `json:"sections"`
+}
+
+// humansTxtContactFields are the keys whose values Contacts returns
+var humansTxtContactFields = []string{"contact", "email", "twitter", "mastodon", "github", "site", "web"}
+
+// Contacts returns the contact details (email addresses, social handles, sites) from every section
+func (h HumansTxt) Contacts() []string {
+	var result []string
+	for _, section := range h.Sections {
+		for _, field := range humansTxtContactFields {
+			result = append(result, section.Fields[field]...)
+		}
+	}
+	return result
+}
+
+// SecurityTxt fetches and parses the security.txt of host, trying /.well-known/security.txt and then the legacy
+// /security.txt, using the factory's client (or ResponseArchive)
+func (f *DefaultFactory) SecurityTxt(ctx context.Context, host string) (_ *SecurityTxt, err error) {
+	defer recoverPolicyPanic(&err)
+	var lastErr error
+	for _, path := range []string{"/.well-known/security.txt", "/security.txt"} {
+		resp, err := f.fetch(ctx, wellKnownURL(host, path), http.Header{"Accept": {"text/plain"}})
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		defer resp.Body.Close()
+		result, err := parseSecurityTxt(resp.Body)
+		if err != nil {
+			return nil, xerrors.Errorf("Unable to read security.txt of %q: %w", host, err)
+		}
+		result.URL = resp.Request.URL
+		return result, nil
+	}
+	return nil, xerrors.Errorf("Unable to fetch security.txt of %q: %w", host, lastErr)
+}
+
+// HumansTxt fetches and parses the /humans.txt of host using the factory's client (or ResponseArchive)
+func (f *DefaultFactory) HumansTxt(ctx context.Context, host string) (_ *HumansTxt, err error) {
+	defer recoverPolicyPanic(&err)
+	resp, err := f.fetch(ctx, wellKnownURL(host, "/humans.txt"), http.Header{"Accept": {"text/plain"}})
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to fetch humans.txt of %q: %w", host, err)
+	}
+	defer resp.Body.Close()
+
+	result, err := parseHumansTxt(resp.Body)
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to read humans.txt of %q: %w", host, err)
+	}
+	result.URL = resp.Request.URL
+	return result, nil
+}
+
+// wellKnownURL returns the https URL of path on host, host may also be given as a URL
+func wellKnownURL(host string, path string) string {
+	if u, err := url.Parse(host); err == nil && len(u.Host) > 0 {
+		scheme := u.Scheme
+		if len(scheme) == 0 {
+			scheme = "https"
+		}
+		return scheme + "://" + u.Host + path
+	}
+	return "https://" + strings.TrimSuffix(host, "/") + path
+}
+
+func parseSecurityTxt(r io.Reader) (*SecurityTxt, error) {
+	result := &SecurityTxt{Fields: make(map[string][]string)}
+	scanner := bufio.NewScanner(r)
+	inSignature := false
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "-----BEGIN PGP SIGNED MESSAGE-----":
+			result.Signed = true
+			continue
+		case line == "-----BEGIN PGP SIGNATURE-----":
+			inSignature = true
+			continue
+		case line == "-----END PGP SIGNATURE-----":
+			inSignature = false
+			continue
+		case inSignature || len(line) == 0 || strings.HasPrefix(line, "#"):
+			continue
+		}
+		// dash-escaped lines (RFC 4880) start with "- "
+		line = strings.TrimPrefix(line, "- ")
+
+		index := strings.Index(line, ":")
+		if index <= 0 {
+			continue
+		}
+		name := strings.ToLower(strings.TrimSpace(line[:index]))
+		value := strings.TrimSpace(line[index+1:])
+		if result.Signed && name == "hash" && len(result.Fields) == 0 {
+			// the armor header of the signed message, not a field
+			continue
+		}
+		result.Fields[name] = append(result.Fields[name], value)
+
+		switch name {
+		case "contact":
+			result.Contact = append(result.Contact, value)
+		case "expires":
+			if expires, err := time.Parse(time.RFC3339, value); err == nil {
+				result.Expires = expires
+			}
+		case "encryption":
+			result.Encryption = append(result.Encryption, value)
+		case "policy":
+			result.Policy = append(result.Policy, value)
+		case "acknowledgments", "acknowledgements":
+			result.Acknowledgments = append(result.Acknowledgments, value)
+		case "hiring":
+			result.Hiring = append(result.Hiring, value)
+		case "canonical":
+			result.Canonical = append(result.Canonical, value)
+		case "preferred-languages":
+			result.PreferredLanguages = value
+		}
+	}
+	return result, scanner.Err()
+}
+
+func parseHumansTxt(r io.Reader) (*HumansTxt, error) {
+	result := new(HumansTxt)
+	var section *HumansTxtSection
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "/*") && strings.HasSuffix(line, "*/") {
+			result.Sections = append(result.Sections, HumansTxtSection{
+				Name:   strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(line, "/*"), "*/")),
+				Fields: make(map[string][]string),
+			})
+			section = &result.Sections[len(result.Sections)-1]
+			continue
+		}
+
+		index := strings.Index(line, ":")
+		// skip lines without a key, and URLs such as http://... which aren't "Key: value" lines
+		if index <= 0 || strings.HasPrefix(line[index:], "://") {
+			continue
+		}
+		if section == nil {
+			result.Sections = append(result.Sections, HumansTxtSection{Fields: make(map[string][]string)})
+			section = &result.Sections[len(result.Sections)-1]
+		}
+		name := strings.ToLower(strings.TrimSpace(line[:index]))
+		section.Fields[name] = append(section.Fields[name], strings.TrimSpace(line[index+1:]))
+	}
+	return result, scanner.Err()
+}
@@ -0,0 +1,78 @@
+package resource
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type WellKnownSuite struct {
+	suite.Suite
+}
+
+func (suite *WellKnownSuite) TestSecurityTxt() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://www.netspective.com/.well-known/security.txt", archivedResponse(200, http.Header{"Content-Type": {"text/plain"}}, `-----BEGIN PGP SIGNED MESSAGE-----
+Hash: SHA256
+
+# Netspective security contacts
+Contact: mailto:security@netspective.com
+Contact: https://www.netspective.com/security
+Expires: 2030-01-01T00:00:00Z
+Preferred-Languages: en, fr
+-----BEGIN PGP SIGNATURE-----
+Contact: not-a-field
+-----END PGP SIGNATURE-----
+`))
+
+	securityTxt, err := NewFactory(archive).SecurityTxt(context.Background(), "www.netspective.com")
+	suite.Nil(err, "Should not get an error")
+	suite.True(securityTxt.Signed)
+	suite.Equal([]string{"mailto:security@netspective.com", "https://www.netspective.com/security"}, securityTxt.Contact)
+	suite.Equal("en, fr", securityTxt.PreferredLanguages)
+	suite.False(securityTxt.IsExpired(time.Date(2029, 1, 1, 0, 0, 0, 0, time.UTC)))
+	suite.True(securityTxt.IsExpired(time.Date(2031, 1, 1, 0, 0, 0, 0, time.UTC)))
+	_, ok := securityTxt.Fields["hash"]
+	suite.False(ok, "Armor header should not be a field")
+}
+
+func (suite *WellKnownSuite) TestLegacySecurityTxtLocation() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://www.netspective.com/security.txt", archivedResponse(200, http.Header{}, "Contact: mailto:security@netspective.com\n"))
+
+	securityTxt, err := NewFactory(archive).SecurityTxt(context.Background(), "https://www.netspective.com/about")
+	suite.Nil(err, "Should not get an error")
+	suite.Equal("https://www.netspective.com/security.txt", securityTxt.URL.String())
+	suite.Equal([]string{"mailto:security@netspective.com"}, securityTxt.Contact)
+
+	_, err = NewFactory(archive).SecurityTxt(context.Background(), "example.com")
+	suite.NotNil(err, "Should get an error when neither location exists")
+}
+
+func (suite *WellKnownSuite) TestHumansTxt() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("https://www.netspective.com/humans.txt", archivedResponse(200, http.Header{}, `/* TEAM */
+	Founder: Shahid N. Shah
+	Contact: shahid [at] netspective.com
+	Twitter: @ShahidNShah
+
+/* SITE */
+	Last update: 2019/05/20
+	Standards: HTML5, CSS3
+	http://www.netspective.com
+`))
+
+	humansTxt, err := NewFactory(archive).HumansTxt(context.Background(), "www.netspective.com")
+	suite.Nil(err, "Should not get an error")
+	suite.Len(humansTxt.Sections, 2)
+	suite.Equal("TEAM", humansTxt.Sections[0].Name)
+	suite.Equal([]string{"2019/05/20"}, humansTxt.Sections[1].Fields["last update"])
+	suite.Equal([]string{"shahid [at] netspective.com", "@ShahidNShah"}, humansTxt.Contacts())
+}
+
+func TestWellKnownSuite(t *testing.T) {
+	suite.Run(t, new(WellKnownSuite))
+}
@@ -0,0 +1,186 @@
+package resource
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/xerrors"
+)
+
+// QueuedURL is a URL taken from a URLQueue, Receipt is whatever the queue needs to acknowledge it (e.g. an SQS
+// receipt handle) and Annotations are carried through to the resulting Page
+type QueuedURL struct {
+	URL         string
+	Receipt     string
+	Annotations Annotations
+}
+
+// URLQueue is a source of URLs for a Worker. Receive blocks until there's a URL and returns false once the queue is
+// closed, Complete acknowledges a URL with the error (if any) of resolving it so that the queue can delete or retry it.
+// ChannelURLQueue is built in, URLQueueFuncs adapts other queues such as Redis lists or SQS without this package
+// depending on their clients.
+type URLQueue interface {
+	Receive(ctx context.Context) (QueuedURL, bool, error)
+	Complete(ctx context.Context, item QueuedURL, err error) error
+}
+
+// ChannelURLQueue is a URLQueue fed by a channel, closing the channel closes the queue
+type ChannelURLQueue <-chan string
+
+// Receive satisfies URLQueue method
+func (q ChannelURLQueue) Receive(ctx context.Context) (QueuedURL, bool, error) {
+	select {
+	case urlText, ok := <-q:
+		return QueuedURL{URL: urlText}, ok, nil
+	case <-ctx.Done():
+		return QueuedURL{}, false, ctx.Err()
+	}
+}
+
+// Complete satisfies URLQueue method, channels have nothing to acknowledge
+func (q ChannelURLQueue) Complete(ctx context.Context, item QueuedURL, err error) error {
+	return nil
+}
+
+// URLQueueFuncs is a URLQueue made of functions, e.g. a BRPOP of a Redis list or an SQS ReceiveMessage for Receive
+// and an SQS DeleteMessage for Complete. A nil CompleteFunc acknowledges nothing.
+type URLQueueFuncs struct {
+	ReceiveFunc  func(ctx context.Context) (QueuedURL, bool, error)
+	CompleteFunc func(ctx context.Context, item QueuedURL, err error) error
+}
+
+// Receive satisfies URLQueue method
+func (q URLQueueFuncs) Receive(ctx context.Context) (QueuedURL, bool, error) {
+	return q.ReceiveFunc(ctx)
+}
+
+// Complete satisfies URLQueue method
+func (q URLQueueFuncs) Complete(ctx context.Context, item QueuedURL, err error) error {
+	if q.CompleteFunc == nil {
+		return nil
+	}
+	return q.CompleteFunc(ctx, item, err)
+}
+
+// WorkerStats counts what a Worker did
+type WorkerStats struct {
+	Resolved int `json:"resolved"`
+	Failed   int `json:"failed"`
+}
+
+// Worker resolves the URLs of a queue through a factory and stores the pages in a ContentStore, if there is one.
+// Events go to the factory's EventSink as usual, OnResult is told about every URL including the failures.
+type Worker struct {
+	Factory     *DefaultFactory
+	Queue       URLQueue
+	Store       ContentStore
+	Concurrency int // number of URLs resolved at the same time, 1 if not set
+	OnResult    func(item QueuedURL, content Content, err error)
+}
+
+// NewWorker creates a Worker which resolves the URLs of queue one at a time
+func NewWorker(factory *DefaultFactory, queue URLQueue) *Worker {
+	return &Worker{Factory: factory, Queue: queue, Concurrency: 1}
+}
+
+// Run resolves URLs until the queue is closed or ctx is done (which is returned as the error). Failing URLs are counted
+// and acknowledged but don't stop the worker, an error from the queue itself does.
+func (w *Worker) Run(ctx context.Context) (WorkerStats, error) {
+	concurrency := w.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var mu sync.Mutex
+	var stats WorkerStats
+	var firstErr error
+	var wg sync.WaitGroup
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	fail := func(err error) {
+		mu.Lock()
+		if firstErr == nil {
+			firstErr = err
+		}
+		mu.Unlock()
+		cancel()
+	}
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				item, ok, err := w.receive(ctx)
+				if err != nil {
+					if ctx.Err() == nil {
+						fail(xerrors.Errorf("Unable to receive from URLQueue: %w", err))
+					}
+					return
+				}
+				if !ok {
+					return
+				}
+
+				content, err := w.resolve(ctx, item)
+				mu.Lock()
+				if err != nil {
+					stats.Failed++
+				} else {
+					stats.Resolved++
+				}
+				mu.Unlock()
+				if resultErr := w.report(item, content, err); resultErr != nil {
+					fail(xerrors.Errorf("OnResult failed for %q: %w", item.URL, resultErr))
+					return
+				}
+				if completeErr := w.complete(ctx, item, err); completeErr != nil {
+					fail(xerrors.Errorf("Unable to complete %q in URLQueue: %w", item.URL, completeErr))
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if firstErr == nil {
+		firstErr = parent.Err()
+	}
+	return stats, firstErr
+}
+
+// receive, complete and report call into the caller's queue and OnResult, which run in the worker's goroutines, so
+// their panics are returned as PolicyPanicErrors
+func (w *Worker) receive(ctx context.Context) (item QueuedURL, ok bool, err error) {
+	defer recoverPolicyPanic(&err)
+	guardPolicy("URLQueue", func() { item, ok, err = w.Queue.Receive(ctx) })
+	return item, ok, err
+}
+
+func (w *Worker) complete(ctx context.Context, item QueuedURL, resolveErr error) (err error) {
+	defer recoverPolicyPanic(&err)
+	guardPolicy("URLQueue", func() { err = w.Queue.Complete(ctx, item, resolveErr) })
+	return err
+}
+
+func (w *Worker) report(item QueuedURL, content Content, resolveErr error) (err error) {
+	defer recoverPolicyPanic(&err)
+	if w.OnResult != nil {
+		guardPolicy("OnResult", func() { w.OnResult(item, content, resolveErr) })
+	}
+	return nil
+}
+
+func (w *Worker) resolve(ctx context.Context, item QueuedURL) (_ Content, err error) {
+	defer recoverPolicyPanic(&err)
+	content, err := w.Factory.PageFromURL(ctx, item.URL, item.Annotations)
+	if err != nil || w.Store == nil {
+		return content, err
+	}
+	if page, ok := content.(*Page); ok {
+		if err := callPolicy("ContentStore", func() error { return w.Store.StorePage(ctx, page) }); err != nil {
+			return content, xerrors.Errorf("Unable to store %q: %w", item.URL, err)
+		}
+	}
+	return content, nil
+}
@@ -0,0 +1,116 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/xerrors"
+)
+
+type WorkerSuite struct {
+	suite.Suite
+}
+
+func (suite *WorkerSuite) TestChannelQueue() {
+	archive := NewMemoryResponseArchive()
+	urls := make(chan string, 10)
+	for i := 0; i < 5; i++ {
+		urlText := fmt.Sprintf("http://example.com/%d", i)
+		archive.Add(urlText, archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, testHTMLPage))
+		urls <- urlText
+	}
+	urls <- "http://example.com/missing"
+	close(urls)
+
+	store := NewMemoryContentStore()
+	worker := NewWorker(NewFactory(archive), ChannelURLQueue(urls))
+	worker.Store = store
+	worker.Concurrency = 3
+	stats, err := worker.Run(context.Background())
+	suite.Nil(err, "Should not get an error")
+	suite.Equal(WorkerStats{Resolved: 5, Failed: 1}, stats)
+
+	_, ok, _ := store.LoadPage(context.Background(), "http://example.com/3")
+	suite.True(ok, "Resolved page should be stored")
+}
+
+func (suite *WorkerSuite) TestQueueAcknowledgement() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("http://example.com/", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, testHTMLPage))
+
+	var mu sync.Mutex
+	pending := []QueuedURL{{URL: "http://example.com/", Receipt: "r1"}, {URL: "http://example.com/missing", Receipt: "r2"}}
+	completed := make(map[string]error)
+	queue := URLQueueFuncs{
+		ReceiveFunc: func(ctx context.Context) (QueuedURL, bool, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if len(pending) == 0 {
+				return QueuedURL{}, false, nil
+			}
+			item := pending[0]
+			pending = pending[1:]
+			return item, true, nil
+		},
+		CompleteFunc: func(ctx context.Context, item QueuedURL, err error) error {
+			mu.Lock()
+			defer mu.Unlock()
+			completed[item.Receipt] = err
+			return nil
+		},
+	}
+
+	stats, err := NewWorker(NewFactory(archive), queue).Run(context.Background())
+	suite.Nil(err, "Should not get an error")
+	suite.Equal(WorkerStats{Resolved: 1, Failed: 1}, stats)
+	suite.Len(completed, 2)
+	suite.Nil(completed["r1"])
+	suite.NotNil(completed["r2"], "Failure should be passed back to the queue")
+}
+
+func (suite *WorkerSuite) TestCancelledContext() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := NewWorker(NewFactory(), ChannelURLQueue(make(chan string))).Run(ctx)
+	suite.Equal(context.Canceled, err)
+}
+
+func (suite *WorkerSuite) TestCallerPanics() {
+	archive := NewMemoryResponseArchive()
+	archive.Add("http://example.com/", archivedResponse(200, http.Header{"Content-Type": {"text/html"}}, testHTMLPage))
+	once := func() func(ctx context.Context) (QueuedURL, bool, error) {
+		received := false
+		return func(ctx context.Context) (QueuedURL, bool, error) {
+			if received {
+				return QueuedURL{}, false, nil
+			}
+			received = true
+			return QueuedURL{URL: "http://example.com/"}, true, nil
+		}
+	}
+	receivePanics := func(ctx context.Context) (QueuedURL, bool, error) { panic("queue is gone") }
+	completePanics := func(ctx context.Context, item QueuedURL, err error) error { panic("can't acknowledge") }
+
+	for _, test := range []struct {
+		policy string
+		worker *Worker
+	}{
+		{"URLQueue", NewWorker(NewFactory(archive), URLQueueFuncs{ReceiveFunc: receivePanics})},
+		{"URLQueue", NewWorker(NewFactory(archive), URLQueueFuncs{ReceiveFunc: once(), CompleteFunc: completePanics})},
+		{"OnResult", &Worker{Factory: NewFactory(archive), Queue: URLQueueFuncs{ReceiveFunc: once()},
+			OnResult: func(item QueuedURL, content Content, err error) { panic("can't report") }}},
+	} {
+		_, err := test.worker.Run(context.Background())
+		var panicked *PolicyPanicError
+		suite.Require().True(xerrors.As(err, &panicked), "A panic in %s should be returned, not crash the worker", test.policy)
+		suite.Equal(test.policy, panicked.Policy)
+	}
+}
+
+func TestWorkerSuite(t *testing.T) {
+	suite.Run(t, new(WorkerSuite))
+}